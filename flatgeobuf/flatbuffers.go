@@ -0,0 +1,396 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// The header and features of a FlatGeobuf file are FlatBuffers (see
+// https://google.github.io/flatbuffers). Only the small subset of FlatBuffers
+// needed for the FlatGeobuf schema is implemented here.
+
+var errInvalidBuffer = errors.New("invalid FlatBuffers buffer")
+
+// fbTable is a table within a FlatBuffers buffer.
+type fbTable struct {
+	pos       int // position of the table
+	vtable    int // position of the table's vtable
+	vtableLen int
+}
+
+// fbDecoder reads tables from a FlatBuffers buffer. Once an error occurs, all
+// subsequent reads give zero values and the error is retained.
+type fbDecoder struct {
+	buf []byte
+	err error
+}
+
+// check checks that n bytes can be read at pos.
+func (d *fbDecoder) check(pos, n int) bool {
+	if d.err != nil {
+		return false
+	}
+	if pos < 0 || n < 0 || pos > len(d.buf)-n {
+		d.err = errInvalidBuffer
+		return false
+	}
+	return true
+}
+
+func (d *fbDecoder) uint16At(pos int) uint16 {
+	if !d.check(pos, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(d.buf[pos:])
+}
+
+func (d *fbDecoder) uint32At(pos int) uint32 {
+	if !d.check(pos, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(d.buf[pos:])
+}
+
+// deref follows the offset stored at pos.
+func (d *fbDecoder) deref(pos int) int {
+	return pos + int(d.uint32At(pos))
+}
+
+// root gives the root table of the buffer.
+func (d *fbDecoder) root() fbTable {
+	return d.table(d.deref(0))
+}
+
+func (d *fbDecoder) table(pos int) fbTable {
+	t := fbTable{pos: pos}
+	t.vtable = pos - int(int32(d.uint32At(pos)))
+	t.vtableLen = int(d.uint16At(t.vtable))
+	if d.err == nil && (t.vtableLen < 4 || !d.check(t.vtable, t.vtableLen)) {
+		d.err = errInvalidBuffer
+	}
+	return t
+}
+
+// field gives the position of a field of a table, or 0 if the field isn't
+// present.
+func (d *fbDecoder) field(t fbTable, id int) int {
+	if d.err != nil || 4+2*id+2 > t.vtableLen {
+		return 0
+	}
+	off := d.uint16At(t.vtable + 4 + 2*id)
+	if off == 0 {
+		return 0
+	}
+	return t.pos + int(off)
+}
+
+func (d *fbDecoder) uint8Field(t fbTable, id int, def uint8) uint8 {
+	pos := d.field(t, id)
+	if pos == 0 || !d.check(pos, 1) {
+		return def
+	}
+	return d.buf[pos]
+}
+
+func (d *fbDecoder) boolField(t fbTable, id int, def bool) bool {
+	var defByte uint8
+	if def {
+		defByte = 1
+	}
+	return d.uint8Field(t, id, defByte) != 0
+}
+
+func (d *fbDecoder) uint16Field(t fbTable, id int, def uint16) uint16 {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return def
+	}
+	return d.uint16At(pos)
+}
+
+func (d *fbDecoder) int32Field(t fbTable, id int, def int32) int32 {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return def
+	}
+	return int32(d.uint32At(pos))
+}
+
+func (d *fbDecoder) uint64Field(t fbTable, id int, def uint64) uint64 {
+	pos := d.field(t, id)
+	if pos == 0 || !d.check(pos, 8) {
+		return def
+	}
+	return binary.LittleEndian.Uint64(d.buf[pos:])
+}
+
+// vector gives the position of the elements of a vector field, along with the
+// number of elements.
+func (d *fbDecoder) vector(t fbTable, id int, elemSize int) (int, int) {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return 0, 0
+	}
+	vec := d.deref(pos)
+	n := d.uint32At(vec)
+	if uint64(n)*uint64(elemSize) > uint64(len(d.buf)) || !d.check(vec+4, int(n)*elemSize) {
+		d.err = errInvalidBuffer
+		return 0, 0
+	}
+	return vec + 4, int(n)
+}
+
+func (d *fbDecoder) bytesField(t fbTable, id int) []byte {
+	pos, n := d.vector(t, id, 1)
+	if n == 0 {
+		return nil
+	}
+	return d.buf[pos : pos+n]
+}
+
+func (d *fbDecoder) stringField(t fbTable, id int) string {
+	return string(d.bytesField(t, id))
+}
+
+func (d *fbDecoder) float64sField(t fbTable, id int) []float64 {
+	pos, n := d.vector(t, id, 8)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]float64, n)
+	for i := range vs {
+		vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(d.buf[pos+8*i:]))
+	}
+	return vs
+}
+
+func (d *fbDecoder) uint32sField(t fbTable, id int) []uint32 {
+	pos, n := d.vector(t, id, 4)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]uint32, n)
+	for i := range vs {
+		vs[i] = binary.LittleEndian.Uint32(d.buf[pos+4*i:])
+	}
+	return vs
+}
+
+func (d *fbDecoder) tableField(t fbTable, id int) (fbTable, bool) {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return fbTable{}, false
+	}
+	sub := d.table(d.deref(pos))
+	return sub, d.err == nil
+}
+
+func (d *fbDecoder) tablesField(t fbTable, id int) []fbTable {
+	pos, n := d.vector(t, id, 4)
+	if n == 0 {
+		return nil
+	}
+	ts := make([]fbTable, n)
+	for i := range ts {
+		ts[i] = d.table(d.deref(pos + 4*i))
+	}
+	return ts
+}
+
+// fbField is a field of a table that's being built. Scalar fields hold their
+// little endian encoded value, and reference fields (strings, vectors, and
+// tables) hold a function that appends the referenced object and gives its
+// position. Fields that are absent hold neither.
+type fbField struct {
+	scalar []byte
+	ref    func(b *fbBuilder) int
+}
+
+func (f fbField) present() bool {
+	return f.scalar != nil || f.ref != nil
+}
+
+func (f fbField) size() int {
+	if f.ref != nil {
+		return 4
+	}
+	return len(f.scalar)
+}
+
+func fbUint8(v uint8) fbField {
+	return fbField{scalar: []byte{v}}
+}
+
+func fbBool(v bool) fbField {
+	if v {
+		return fbUint8(1)
+	}
+	return fbUint8(0)
+}
+
+func fbUint16(v uint16) fbField {
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, v)
+	return fbField{scalar: buf}
+}
+
+func fbInt32(v int32) fbField {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(v))
+	return fbField{scalar: buf}
+}
+
+func fbUint64(v uint64) fbField {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return fbField{scalar: buf}
+}
+
+func fbString(s string) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		pos := b.vector(len(s), 1, []byte(s))
+		b.buf = append(b.buf, 0) // strings are null terminated
+		return pos
+	}}
+}
+
+func fbBytes(p []byte) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		return b.vector(len(p), 1, p)
+	}}
+}
+
+func fbFloat64s(vs []float64) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		elems := make([]byte, 8*len(vs))
+		for i, v := range vs {
+			binary.LittleEndian.PutUint64(elems[8*i:], math.Float64bits(v))
+		}
+		return b.vector(len(vs), 8, elems)
+	}}
+}
+
+func fbUint32s(vs []uint32) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		elems := make([]byte, 4*len(vs))
+		for i, v := range vs {
+			binary.LittleEndian.PutUint32(elems[4*i:], v)
+		}
+		return b.vector(len(vs), 4, elems)
+	}}
+}
+
+func fbTableRef(fields []fbField) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		return b.table(fields)
+	}}
+}
+
+func fbTables(tables [][]fbField) fbField {
+	return fbField{ref: func(b *fbBuilder) int {
+		pos := b.vector(len(tables), 4, make([]byte, 4*len(tables)))
+		for i, fields := range tables {
+			b.patch(pos+4+4*i, b.table(fields))
+		}
+		return pos
+	}}
+}
+
+// fbBuilder builds a FlatBuffers buffer. Unlike the reference implementation
+// (which builds buffers back to front), buffers are built front to back: each
+// table is preceded by its vtable, and is followed by the objects that it
+// refers to. Offsets to referenced objects are always positive, so this is a
+// valid layout. Objects are aligned to their natural alignment.
+type fbBuilder struct {
+	buf []byte
+}
+
+// buildFlatBuffer builds a buffer with the given root table.
+func buildFlatBuffer(root []fbField) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	b.patch(0, b.table(root))
+	return b.buf
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// patch stores the offset from pos to target at pos.
+func (b *fbBuilder) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+// vector appends a vector of n elements, and gives its position.
+func (b *fbBuilder) vector(n, elemSize int, elems []byte) int {
+	b.pad(4)
+	if elemSize == 8 && len(b.buf)%8 == 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+	pos := len(b.buf)
+	var lenBuf [4]byte
+	binary.LittleEndian.PutUint32(lenBuf[:], uint32(n))
+	b.buf = append(b.buf, lenBuf[:]...)
+	b.buf = append(b.buf, elems...)
+	return pos
+}
+
+// table appends a table (preceded by its vtable) along with the objects that
+// it refers to, and gives its position. The fields are indexed by field ID.
+func (b *fbBuilder) table(fields []fbField) int {
+	for len(fields) > 0 && !fields[len(fields)-1].present() {
+		fields = fields[:len(fields)-1]
+	}
+
+	// Fields are laid out in order of decreasing size, so that each is
+	// naturally aligned without any padding (other than after the soffset to
+	// the vtable).
+	var order []int
+	for id, f := range fields {
+		if f.present() {
+			order = append(order, id)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return fields[order[i]].size() > fields[order[j]].size()
+	})
+	offsets := make([]int, len(fields))
+	align, size := 4, 4
+	for _, id := range order {
+		fs := fields[id].size()
+		if fs > align {
+			align = fs
+		}
+		for size%fs != 0 {
+			size++
+		}
+		offsets[id] = size
+		size += fs
+	}
+
+	b.pad(2)
+	vtable := len(b.buf)
+	var u16 [2]byte
+	for _, v := range append([]int{4 + 2*len(fields), size}, offsets...) {
+		binary.LittleEndian.PutUint16(u16[:], uint16(v))
+		b.buf = append(b.buf, u16[:]...)
+	}
+
+	b.pad(align)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(int32(pos-vtable)))
+	for _, id := range order {
+		copy(b.buf[pos+offsets[id]:], fields[id].scalar)
+	}
+	for _, id := range order {
+		if ref := fields[id].ref; ref != nil {
+			b.patch(pos+offsets[id], ref(b))
+		}
+	}
+	return pos
+}
@@ -0,0 +1,374 @@
+// Package flatgeobuf reads and writes FlatGeobuf files (version 3 of the
+// format, see https://flatgeobuf.org).
+//
+// A FlatGeobuf file consists of a header (describing the dataset and the
+// columns of its properties), an optional spatial index, and then the
+// features themselves. The spatial index is a packed Hilbert R-tree, which
+// allows the features that intersect a bounding box to be read without
+// decoding the other features.
+package flatgeobuf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// magic is the magic number at the start of each file. The last byte is the
+// patch version of the format, which readers should ignore.
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// DefaultIndexNodeSize is the node size of the spatial index that's
+// conventionally used by FlatGeobuf implementations.
+const DefaultIndexNodeSize = 16
+
+// GeometryType is the type of the geometries in a file.
+type GeometryType uint8
+
+// The geometry types supported by this package. Unknown is used for files
+// that contain geometries of more than one type.
+const (
+	Unknown GeometryType = iota
+	Point
+	LineString
+	Polygon
+	MultiPoint
+	MultiLineString
+	MultiPolygon
+	GeometryCollection
+)
+
+func (t GeometryType) String() string {
+	switch t {
+	case Unknown:
+		return "Unknown"
+	case Point:
+		return "Point"
+	case LineString:
+		return "LineString"
+	case Polygon:
+		return "Polygon"
+	case MultiPoint:
+		return "MultiPoint"
+	case MultiLineString:
+		return "MultiLineString"
+	case MultiPolygon:
+		return "MultiPolygon"
+	case GeometryCollection:
+		return "GeometryCollection"
+	default:
+		return fmt.Sprintf("GeometryType(%d)", uint8(t))
+	}
+}
+
+// ColumnType is the type of the values in a column.
+type ColumnType uint8
+
+// The column types. The names match the FlatGeobuf specification.
+const (
+	Byte ColumnType = iota
+	UByte
+	Bool
+	Short
+	UShort
+	Int
+	UInt
+	Long
+	ULong
+	Float
+	Double
+	String
+	JSON
+	DateTime
+	Binary
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case Byte:
+		return "Byte"
+	case UByte:
+		return "UByte"
+	case Bool:
+		return "Bool"
+	case Short:
+		return "Short"
+	case UShort:
+		return "UShort"
+	case Int:
+		return "Int"
+	case UInt:
+		return "UInt"
+	case Long:
+		return "Long"
+	case ULong:
+		return "ULong"
+	case Float:
+		return "Float"
+	case Double:
+		return "Double"
+	case String:
+		return "String"
+	case JSON:
+		return "JSON"
+	case DateTime:
+		return "DateTime"
+	case Binary:
+		return "Binary"
+	default:
+		return fmt.Sprintf("ColumnType(%d)", uint8(t))
+	}
+}
+
+// Column describes the values of a feature property.
+type Column struct {
+	Name        string
+	Type        ColumnType
+	Title       string
+	Description string
+}
+
+// CRS identifies the coordinate reference system of a file. It's typically
+// identified by an organisation and a code within that organisation (such as
+// EPSG and 4326).
+type CRS struct {
+	Org  string
+	Code int
+	Name string
+	WKT  string
+}
+
+// Header describes the contents of a file.
+type Header struct {
+	Name string
+
+	// GeometryType is the type of each feature's geometry, or Unknown if
+	// the features have geometries of different types.
+	GeometryType GeometryType
+
+	// HasZ and HasM indicate if the geometries have Z and M values.
+	HasZ, HasM bool
+
+	// Columns are the columns of the feature properties.
+	Columns []Column
+
+	// CRS is the coordinate reference system of the geometries. It's
+	// omitted if it's the zero value.
+	CRS CRS
+
+	Title       string
+	Description string
+	Metadata    string
+
+	// IndexNodeSize is the node size of the file's spatial index, or 0 if
+	// the file doesn't have a spatial index.
+	IndexNodeSize uint16
+
+	// FeaturesCount is the number of features in the file, or 0 if the
+	// number is unknown. It's ignored by NewWriter.
+	FeaturesCount uint64
+}
+
+func (h Header) coordinatesType() geom.CoordinatesType {
+	ctype := geom.DimXY
+	if h.HasZ {
+		ctype |= geom.DimXYZ
+	}
+	if h.HasM {
+		ctype |= geom.DimXYM
+	}
+	return ctype
+}
+
+// Header table field IDs.
+const (
+	headerName = iota
+	headerEnvelope
+	headerGeometryType
+	headerHasZ
+	headerHasM
+	headerHasT
+	headerHasTM
+	headerColumns
+	headerFeaturesCount
+	headerIndexNodeSize
+	headerCRS
+	headerTitle
+	headerDescription
+	headerMetadata
+)
+
+// Column table field IDs (the width, precision, scale, nullable, unique,
+// primary key, and metadata fields aren't used).
+const (
+	columnName = iota
+	columnType
+	columnTitle
+	columnDescription
+)
+
+// CRS table field IDs.
+const (
+	crsOrg = iota
+	crsCode
+	crsName
+	crsDescription
+	crsWKT
+)
+
+// Feature table field IDs.
+const (
+	featureGeometry = iota
+	featureProperties
+	featureColumns
+)
+
+// envelope is the bounding box of the features in a file.
+type envelope struct {
+	env   geom.Envelope
+	valid bool
+}
+
+func encodeHeader(h Header, env envelope) []byte {
+	fields := make([]fbField, headerMetadata+1)
+	if h.Name != "" {
+		fields[headerName] = fbString(h.Name)
+	}
+	if env.valid {
+		min, max := env.env.Min(), env.env.Max()
+		fields[headerEnvelope] = fbFloat64s([]float64{min.X, min.Y, max.X, max.Y})
+	}
+	fields[headerGeometryType] = fbUint8(uint8(h.GeometryType))
+	if h.HasZ {
+		fields[headerHasZ] = fbBool(true)
+	}
+	if h.HasM {
+		fields[headerHasM] = fbBool(true)
+	}
+	if len(h.Columns) > 0 {
+		fields[headerColumns] = fbTables(encodeColumns(h.Columns))
+	}
+	if h.FeaturesCount > 0 {
+		fields[headerFeaturesCount] = fbUint64(h.FeaturesCount)
+	}
+	// The node size is always written, since the default is non-zero.
+	fields[headerIndexNodeSize] = fbUint16(h.IndexNodeSize)
+	if h.CRS != (CRS{}) {
+		crs := make([]fbField, crsWKT+1)
+		if h.CRS.Org != "" {
+			crs[crsOrg] = fbString(h.CRS.Org)
+		}
+		if h.CRS.Code != 0 {
+			crs[crsCode] = fbInt32(int32(h.CRS.Code))
+		}
+		if h.CRS.Name != "" {
+			crs[crsName] = fbString(h.CRS.Name)
+		}
+		if h.CRS.WKT != "" {
+			crs[crsWKT] = fbString(h.CRS.WKT)
+		}
+		fields[headerCRS] = fbTableRef(crs)
+	}
+	if h.Title != "" {
+		fields[headerTitle] = fbString(h.Title)
+	}
+	if h.Description != "" {
+		fields[headerDescription] = fbString(h.Description)
+	}
+	if h.Metadata != "" {
+		fields[headerMetadata] = fbString(h.Metadata)
+	}
+	return buildFlatBuffer(fields)
+}
+
+func encodeColumns(cols []Column) [][]fbField {
+	tables := make([][]fbField, len(cols))
+	for i, col := range cols {
+		fields := make([]fbField, columnDescription+1)
+		fields[columnName] = fbString(col.Name)
+		fields[columnType] = fbUint8(uint8(col.Type))
+		if col.Title != "" {
+			fields[columnTitle] = fbString(col.Title)
+		}
+		if col.Description != "" {
+			fields[columnDescription] = fbString(col.Description)
+		}
+		tables[i] = fields
+	}
+	return tables
+}
+
+func decodeHeader(buf []byte) (Header, envelope, error) {
+	d := &fbDecoder{buf: buf}
+	t := d.root()
+	h := Header{
+		Name:          d.stringField(t, headerName),
+		GeometryType:  GeometryType(d.uint8Field(t, headerGeometryType, 0)),
+		HasZ:          d.boolField(t, headerHasZ, false),
+		HasM:          d.boolField(t, headerHasM, false),
+		Columns:       decodeColumns(d, d.tablesField(t, headerColumns)),
+		FeaturesCount: d.uint64Field(t, headerFeaturesCount, 0),
+		IndexNodeSize: d.uint16Field(t, headerIndexNodeSize, DefaultIndexNodeSize),
+		Title:         d.stringField(t, headerTitle),
+		Description:   d.stringField(t, headerDescription),
+		Metadata:      d.stringField(t, headerMetadata),
+	}
+	if crs, ok := d.tableField(t, headerCRS); ok {
+		h.CRS = CRS{
+			Org:  d.stringField(crs, crsOrg),
+			Code: int(d.int32Field(crs, crsCode, 0)),
+			Name: d.stringField(crs, crsName),
+			WKT:  d.stringField(crs, crsWKT),
+		}
+	}
+	var env envelope
+	if vs := d.float64sField(t, headerEnvelope); len(vs) >= 4 {
+		env.env = geom.NewEnvelope(geom.XY{X: vs[0], Y: vs[1]}, geom.XY{X: vs[2], Y: vs[3]})
+		env.valid = true
+	}
+	if d.err != nil {
+		return Header{}, envelope{}, fmt.Errorf("invalid header: %v", d.err)
+	}
+	if h.GeometryType > GeometryCollection {
+		return Header{}, envelope{}, fmt.Errorf("unsupported geometry type: %v", h.GeometryType)
+	}
+	if h.IndexNodeSize == 1 {
+		return Header{}, envelope{}, fmt.Errorf("invalid index node size: %d", h.IndexNodeSize)
+	}
+	return h, env, nil
+}
+
+func decodeColumns(d *fbDecoder, tables []fbTable) []Column {
+	var cols []Column
+	for _, t := range tables {
+		cols = append(cols, Column{
+			Name:        d.stringField(t, columnName),
+			Type:        ColumnType(d.uint8Field(t, columnType, 0)),
+			Title:       d.stringField(t, columnTitle),
+			Description: d.stringField(t, columnDescription),
+		})
+	}
+	return cols
+}
+
+// validateColumns checks that the columns can be used to encode properties.
+func validateColumns(cols []Column) error {
+	if len(cols) > 1<<16 {
+		return fmt.Errorf("too many columns: %d", len(cols))
+	}
+	names := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		if col.Name == "" {
+			return errors.New("column name is empty")
+		}
+		if names[col.Name] {
+			return fmt.Errorf("duplicate column name: %q", col.Name)
+		}
+		names[col.Name] = true
+		if col.Type > Binary {
+			return fmt.Errorf("column %q has unsupported type: %v", col.Name, col.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,686 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func geomFromWKT(t *testing.T, wkt string) geom.Geometry {
+	t.Helper()
+	g, err := geom.UnmarshalWKT(strings.NewReader(wkt))
+	if err != nil {
+		t.Fatalf("could not unmarshal WKT: %v", err)
+	}
+	return g
+}
+
+func expectNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, hdr Header, fs []geom.GeoJSONFeature) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, hdr)
+	expectNoErr(t, err)
+	for _, f := range fs {
+		expectNoErr(t, w.Write(f))
+	}
+	expectNoErr(t, w.Close())
+	return buf.Bytes()
+}
+
+func readFile(t *testing.T, buf []byte) (*Reader, []geom.GeoJSONFeature) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(buf))
+	expectNoErr(t, err)
+	var fs []geom.GeoJSONFeature
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			return r, fs
+		}
+		expectNoErr(t, err)
+		fs = append(fs, f)
+	}
+}
+
+func TestWriteReadGeometries(t *testing.T) {
+	for i, tt := range []struct {
+		typ        GeometryType
+		hasZ, hasM bool
+		input      string
+		want       string
+	}{
+		{Point, false, false, "POINT(1 2)", "POINT(1 2)"},
+		{Point, false, false, "POINT Z(1 2 3)", "POINT(1 2)"},
+		{Point, true, false, "POINT Z(1 2 3)", "POINT Z(1 2 3)"},
+		{Point, true, false, "POINT(1 2)", "POINT Z(1 2 0)"},
+		{Point, false, true, "POINT M(1 2 4)", "POINT M(1 2 4)"},
+		{Point, true, true, "POINT ZM(1 2 3 4)", "POINT ZM(1 2 3 4)"},
+		{Point, false, false, "POINT EMPTY", "GEOMETRYCOLLECTION EMPTY"},
+		{LineString, false, false, "LINESTRING(0 0,1 1,2 0)", "LINESTRING(0 0,1 1,2 0)"},
+		{LineString, false, false, "LINESTRING(0 0,1 1)", "LINESTRING(0 0,1 1)"},
+		{LineString, true, true, "LINESTRING ZM(0 0 1 2,1 1 3 4)", "LINESTRING ZM(0 0 1 2,1 1 3 4)"},
+		{Polygon, false, false, "POLYGON((0 0,1 0,0 1,0 0))", "POLYGON((0 0,1 0,0 1,0 0))"},
+		{
+			Polygon, false, false,
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,4 2,2 2),(6 6,8 6,8 8,6 8,6 6))",
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,4 2,2 2),(6 6,8 6,8 8,6 8,6 6))",
+		},
+		{MultiPoint, false, false, "MULTIPOINT((1 2),(3 4))", "MULTIPOINT((1 2),(3 4))"},
+		{MultiPoint, true, false, "MULTIPOINT Z((1 2 3))", "MULTIPOINT Z((1 2 3))"},
+		{MultiLineString, false, false, "MULTILINESTRING((0 0,1 1))", "MULTILINESTRING((0 0,1 1))"},
+		{
+			MultiLineString, false, true,
+			"MULTILINESTRING M((0 0 1,1 1 2),(2 2 3,3 3 4,4 2 5))",
+			"MULTILINESTRING M((0 0 1,1 1 2),(2 2 3,3 3 4,4 2 5))",
+		},
+		{
+			MultiPolygon, false, false,
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2)),((20 0,30 0,30 10,20 0)))",
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2)),((20 0,30 0,30 10,20 0)))",
+		},
+		{
+			GeometryCollection, false, false,
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1),POLYGON EMPTY,GEOMETRYCOLLECTION(MULTIPOINT((3 4))))",
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1),POLYGON EMPTY,GEOMETRYCOLLECTION(MULTIPOINT((3 4))))",
+		},
+		{Unknown, false, false, "POINT(1 2)", "POINT(1 2)"},
+		{Unknown, false, false, "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))", "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))"},
+		{Unknown, true, false, "GEOMETRYCOLLECTION Z(POINT Z(1 2 3))", "GEOMETRYCOLLECTION Z(POINT Z(1 2 3))"},
+	} {
+		for _, nodeSize := range []uint16{0, DefaultIndexNodeSize} {
+			t.Run(strconv.Itoa(i)+"_"+strconv.Itoa(int(nodeSize)), func(t *testing.T) {
+				hdr := Header{GeometryType: tt.typ, HasZ: tt.hasZ, HasM: tt.hasM, IndexNodeSize: nodeSize}
+				buf := writeFile(t, hdr, []geom.GeoJSONFeature{
+					{Geometry: geomFromWKT(t, tt.input)},
+				})
+				_, fs := readFile(t, buf)
+				if len(fs) != 1 {
+					t.Fatalf("expected 1 feature, got %d", len(fs))
+				}
+				got := fs[0].Geometry
+				want := geomFromWKT(t, tt.want)
+				if !got.EqualsExact(want) {
+					t.Errorf("\ngot:  %v\nwant: %v", got.AsText(), want.AsText())
+				}
+			})
+		}
+	}
+}
+
+func TestWriteMixedGeometries(t *testing.T) {
+	wkts := []string{
+		"POINT(1 2)",
+		"LINESTRING(0 0,1 1)",
+		"POLYGON((0 0,1 0,0 1,0 0))",
+		"MULTIPOINT((1 2),(3 4))",
+		"GEOMETRYCOLLECTION EMPTY",
+	}
+	var fs []geom.GeoJSONFeature
+	for _, wkt := range wkts {
+		fs = append(fs, geom.GeoJSONFeature{Geometry: geomFromWKT(t, wkt)})
+	}
+	_, got := readFile(t, writeFile(t, Header{}, fs))
+	if len(got) != len(wkts) {
+		t.Fatalf("expected %d features, got %d", len(wkts), len(got))
+	}
+	for i, f := range got {
+		if !f.Geometry.EqualsExact(fs[i].Geometry) {
+			t.Errorf("feature %d:\ngot:  %v\nwant: %v", i, f.Geometry.AsText(), wkts[i])
+		}
+	}
+}
+
+func TestWriteIncompatibleGeometry(t *testing.T) {
+	for i, tt := range []struct {
+		typ GeometryType
+		wkt string
+	}{
+		{Point, "MULTIPOINT((1 2))"},
+		{LineString, "POINT(1 2)"},
+		{Polygon, "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))"},
+		{MultiPolygon, "POLYGON((0 0,1 0,0 1,0 0))"},
+		{GeometryCollection, "POINT(1 2)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, Header{GeometryType: tt.typ})
+			expectNoErr(t, err)
+			if err := w.Write(geom.GeoJSONFeature{Geometry: geomFromWKT(t, tt.wkt)}); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestWriteReadHeader(t *testing.T) {
+	hdr := Header{
+		Name:          "places",
+		GeometryType:  Point,
+		HasZ:          true,
+		Columns:       []Column{{Name: "name", Type: String, Title: "Name", Description: "The name"}},
+		CRS:           CRS{Org: "EPSG", Code: 4326, Name: "WGS 84"},
+		Title:         "Places",
+		Description:   "Some places",
+		Metadata:      `{"source":"test"}`,
+		IndexNodeSize: DefaultIndexNodeSize,
+	}
+	buf := writeFile(t, hdr, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT Z(1 2 3)")},
+		{Geometry: geomFromWKT(t, "POINT Z(-4 5 6)")},
+	})
+	if !bytes.HasPrefix(buf, []byte("fgb\x03fgb")) {
+		t.Errorf("unexpected magic number: %q", buf[:8])
+	}
+
+	r, fs := readFile(t, buf)
+	want := hdr
+	want.FeaturesCount = 2
+	if got := r.Header(); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot:  %+v\nwant: %+v", got, want)
+	}
+	if len(fs) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fs))
+	}
+	env, ok := r.Envelope()
+	if !ok {
+		t.Fatal("expected envelope")
+	}
+	if env.Min() != (geom.XY{X: -4, Y: 2}) || env.Max() != (geom.XY{X: 1, Y: 5}) {
+		t.Errorf("unexpected envelope: %v %v", env.Min(), env.Max())
+	}
+}
+
+func TestWriteReadWithoutIndex(t *testing.T) {
+	buf := writeFile(t, Header{GeometryType: Point}, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT(1 2)")},
+		{Geometry: geomFromWKT(t, "POINT(3 4)")},
+	})
+	r, fs := readFile(t, buf)
+	hdr := r.Header()
+	if hdr.IndexNodeSize != 0 || hdr.FeaturesCount != 0 {
+		t.Errorf("unexpected header: %+v", hdr)
+	}
+	if _, ok := r.Envelope(); ok {
+		t.Error("unexpected envelope")
+	}
+	if len(fs) != 2 {
+		t.Errorf("expected 2 features, got %d", len(fs))
+	}
+}
+
+func TestWriteReadProperties(t *testing.T) {
+	cols := []Column{
+		{Name: "byte", Type: Byte},
+		{Name: "ubyte", Type: UByte},
+		{Name: "bool", Type: Bool},
+		{Name: "short", Type: Short},
+		{Name: "ushort", Type: UShort},
+		{Name: "int", Type: Int},
+		{Name: "uint", Type: UInt},
+		{Name: "long", Type: Long},
+		{Name: "ulong", Type: ULong},
+		{Name: "float", Type: Float},
+		{Name: "double", Type: Double},
+		{Name: "string", Type: String},
+		{Name: "json", Type: JSON},
+		{Name: "datetime", Type: DateTime},
+		{Name: "binary", Type: Binary},
+	}
+	buf := writeFile(t, Header{Columns: cols}, []geom.GeoJSONFeature{
+		{
+			Geometry: geomFromWKT(t, "POINT(1 2)"),
+			Properties: map[string]interface{}{
+				"byte":     -128,
+				"ubyte":    uint8(255),
+				"bool":     true,
+				"short":    int16(-300),
+				"ushort":   60000.0,
+				"int":      int32(-70000),
+				"uint":     uint(4000000000),
+				"long":     int64(-1 << 40),
+				"ulong":    uint64(1 << 63),
+				"float":    1.5,
+				"double":   0.1,
+				"string":   "hello",
+				"json":     map[string]interface{}{"a": []interface{}{1.0, "b"}},
+				"datetime": time.Date(2020, time.March, 14, 1, 2, 3, 0, time.UTC),
+				"binary":   []byte{0, 1, 2},
+				"ignored":  "not a column",
+			},
+		},
+		{
+			Geometry: geomFromWKT(t, "POINT(3 4)"),
+			Properties: map[string]interface{}{
+				"string":   "",
+				"datetime": "2021-01-01",
+				"int":      nil,
+			},
+		},
+		{
+			Geometry: geomFromWKT(t, "POINT(5 6)"),
+		},
+	})
+	_, fs := readFile(t, buf)
+
+	want := []map[string]interface{}{
+		{
+			"byte":     int64(-128),
+			"ubyte":    uint64(255),
+			"bool":     true,
+			"short":    int64(-300),
+			"ushort":   uint64(60000),
+			"int":      int64(-70000),
+			"uint":     uint64(4000000000),
+			"long":     int64(-1 << 40),
+			"ulong":    uint64(1 << 63),
+			"float":    1.5,
+			"double":   0.1,
+			"string":   "hello",
+			"json":     map[string]interface{}{"a": []interface{}{1.0, "b"}},
+			"datetime": "2020-03-14T01:02:03Z",
+			"binary":   []byte{0, 1, 2},
+		},
+		{
+			"string":   "",
+			"datetime": "2021-01-01",
+		},
+		nil,
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("expected %d features, got %d", len(want), len(fs))
+	}
+	for i, f := range fs {
+		if !reflect.DeepEqual(f.Properties, want[i]) {
+			t.Errorf("feature %d:\ngot:  %v\nwant: %v", i, f.Properties, want[i])
+		}
+	}
+}
+
+func TestWriteInvalidProperties(t *testing.T) {
+	for i, tt := range []struct {
+		typ   ColumnType
+		value interface{}
+	}{
+		{Byte, 128},
+		{Byte, 1.5},
+		{UByte, -1},
+		{UShort, 1 << 16},
+		{Int, "1"},
+		{ULong, -1.0},
+		{Bool, 1},
+		{Double, "1.5"},
+		{String, 1},
+		{JSON, func() {}},
+		{DateTime, 1},
+		{Binary, "abc"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, Header{Columns: []Column{{Name: "c", Type: tt.typ}}})
+			expectNoErr(t, err)
+			err = w.Write(geom.GeoJSONFeature{
+				Geometry:   geomFromWKT(t, "POINT(1 2)"),
+				Properties: map[string]interface{}{"c": tt.value},
+			})
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestNewWriterInvalidHeader(t *testing.T) {
+	for i, hdr := range []Header{
+		{GeometryType: 8},
+		{IndexNodeSize: 1},
+		{Columns: []Column{{Name: "", Type: Int}}},
+		{Columns: []Column{{Name: "c", Type: 15}}},
+		{Columns: []Column{{Name: "c", Type: Int}, {Name: "c", Type: Long}}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := NewWriter(&buf, hdr); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	// Features are points on a grid, along with some lines and an empty
+	// geometry (which never matches).
+	var fs []geom.GeoJSONFeature
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			fs = append(fs, geom.GeoJSONFeature{
+				Geometry: geom.NewPointF(float64(x), float64(y)).AsGeometry(),
+			})
+		}
+	}
+	for _, wkt := range []string{
+		"LINESTRING(-5 -5,25 25)",
+		"LINESTRING(30 30,40 40)",
+		"GEOMETRYCOLLECTION EMPTY",
+	} {
+		fs = append(fs, geom.GeoJSONFeature{Geometry: geomFromWKT(t, wkt)})
+	}
+	for i := range fs {
+		fs[i].Properties = map[string]interface{}{"id": i}
+	}
+	cols := []Column{{Name: "id", Type: Int}}
+
+	queries := []geom.Envelope{
+		geom.NewEnvelope(geom.XY{X: 2.5, Y: 3.5}, geom.XY{X: 6.5, Y: 4.5}),
+		geom.NewEnvelope(geom.XY{X: 0, Y: 0}, geom.XY{X: 0, Y: 0}),
+		geom.NewEnvelope(geom.XY{X: -10, Y: -10}, geom.XY{X: 50, Y: 50}),
+		geom.NewEnvelope(geom.XY{X: 21, Y: 21}, geom.XY{X: 22, Y: 22}),
+		geom.NewEnvelope(geom.XY{X: 100, Y: 100}, geom.XY{X: 200, Y: 200}),
+		geom.NewEnvelope(geom.XY{X: 35, Y: 0}, geom.XY{X: 36, Y: 50}),
+	}
+	for _, nodeSize := range []uint16{0, 2, 3, DefaultIndexNodeSize} {
+		buf := writeFile(t, Header{Columns: cols, IndexNodeSize: nodeSize}, fs)
+		for i, q := range queries {
+			t.Run(strconv.Itoa(int(nodeSize))+"_"+strconv.Itoa(i), func(t *testing.T) {
+				var want []int64
+				for j, f := range fs {
+					env, ok := f.Geometry.Envelope()
+					if ok && env.Intersects(q) {
+						want = append(want, int64(j))
+					}
+				}
+
+				r, err := NewReader(bytes.NewReader(buf))
+				expectNoErr(t, err)
+				results, err := r.Search(q)
+				expectNoErr(t, err)
+				var got []int64
+				for _, f := range results {
+					got = append(got, f.Properties["id"].(int64))
+				}
+				sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("\ngot:  %v\nwant: %v", got, want)
+				}
+				if _, err := r.Read(); err != io.EOF {
+					t.Errorf("expected io.EOF after Search, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchAfterRead(t *testing.T) {
+	buf := writeFile(t, Header{IndexNodeSize: DefaultIndexNodeSize}, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT(1 2)")},
+	})
+	r, err := NewReader(bytes.NewReader(buf))
+	expectNoErr(t, err)
+	_, err = r.Read()
+	expectNoErr(t, err)
+	if _, err := r.Search(geom.NewEnvelope(geom.XY{X: 0, Y: 0})); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+// The files in the testdata directory are generated by the program in
+// testdata/gen, which builds the FlatBuffers using the reference FlatBuffers
+// library (rather than using this package's Writer).
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	buf, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	expectNoErr(t, err)
+	return buf
+}
+
+// polygonsTestdataWKT gives the geometry of the i'th feature in
+// testdata/polygons.fgb.
+func polygonsTestdataWKT(i int) string {
+	x, y := i%5*2, i/5*2
+	square := fmt.Sprintf("(%d %d,%d %d,%d %d,%d %d,%d %d)", x, y, x+1, y, x+1, y+1, x, y+1, x, y)
+	if i == 7 {
+		hole := fmt.Sprintf("(%[1]v %[2]v,%[1]v %[4]v,%[3]v %[4]v,%[3]v %[2]v,%[1]v %[2]v)",
+			float64(x)+0.25, float64(y)+0.25, float64(x)+0.75, float64(y)+0.75)
+		return "POLYGON(" + square + "," + hole + ")"
+	}
+	return "POLYGON(" + square + ")"
+}
+
+func TestReadTestdataWithIndex(t *testing.T) {
+	r, fs := readFile(t, readTestdata(t, "polygons.fgb"))
+
+	hdr := r.Header()
+	if !strings.HasPrefix(hdr.CRS.WKT, `GEOGCS["WGS 84"`) {
+		t.Errorf("unexpected CRS WKT: %v", hdr.CRS.WKT)
+	}
+	hdr.CRS.WKT = ""
+	want := Header{
+		Name:         "polygons",
+		GeometryType: Polygon,
+		Columns: []Column{
+			{Name: "name", Type: String},
+			{Name: "id", Type: Int},
+			{Name: "value", Type: Double},
+		},
+		CRS:           CRS{Org: "EPSG", Code: 4326},
+		IndexNodeSize: DefaultIndexNodeSize,
+		FeaturesCount: 20,
+	}
+	if !reflect.DeepEqual(hdr, want) {
+		t.Errorf("\ngot:  %+v\nwant: %+v", hdr, want)
+	}
+	env, ok := r.Envelope()
+	if !ok || env.Min() != (geom.XY{X: 0, Y: 0}) || env.Max() != (geom.XY{X: 9, Y: 7}) {
+		t.Errorf("unexpected envelope: %v %v %v", env.Min(), env.Max(), ok)
+	}
+
+	if len(fs) != 20 {
+		t.Fatalf("expected 20 features, got %d", len(fs))
+	}
+	seen := make(map[int64]bool)
+	for _, f := range fs {
+		id := f.Properties["id"].(int64)
+		if seen[id] {
+			t.Fatalf("duplicate id: %d", id)
+		}
+		seen[id] = true
+
+		wantGeom := geomFromWKT(t, polygonsTestdataWKT(int(id)))
+		if !f.Geometry.EqualsExact(wantGeom) {
+			t.Errorf("\ngot:  %v\nwant: %v", f.Geometry.AsText(), wantGeom.AsText())
+		}
+		wantProps := map[string]interface{}{
+			"name": fmt.Sprintf("f%02d", id),
+			"id":   id,
+		}
+		if id%4 != 3 {
+			wantProps["value"] = float64(id) * 1.5
+		}
+		if !reflect.DeepEqual(f.Properties, wantProps) {
+			t.Errorf("\ngot:  %v\nwant: %v", f.Properties, wantProps)
+		}
+	}
+}
+
+func TestSearchTestdata(t *testing.T) {
+	for i, tt := range []struct {
+		file  string
+		query geom.Envelope
+		want  []string // name properties
+	}{
+		{"polygons.fgb", geom.NewEnvelope(geom.XY{X: 0, Y: 0}, geom.XY{X: 2.5, Y: 2.5}), []string{"f00", "f01", "f05", "f06"}},
+		{"polygons.fgb", geom.NewEnvelope(geom.XY{X: 6.5, Y: 4.5}), []string{"f13"}},
+		{"polygons.fgb", geom.NewEnvelope(geom.XY{X: 1.5, Y: 1.5}), nil},
+		{"polygons.fgb", geom.NewEnvelope(geom.XY{X: -1, Y: -1}, geom.XY{X: 100, Y: 0.5}), []string{"f00", "f01", "f02", "f03", "f04"}},
+		{"mixed.fgb", geom.NewEnvelope(geom.XY{X: 1, Y: 1}, geom.XY{X: 5, Y: 5}), []string{"point", "line", "multipolygon", "collection"}},
+		{"mixed.fgb", geom.NewEnvelope(geom.XY{X: 6.5, Y: 6.5}), []string{"collection"}},
+		{"mixed.fgb", geom.NewEnvelope(geom.XY{X: 10, Y: 10}), nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader(readTestdata(t, tt.file)))
+			expectNoErr(t, err)
+			results, err := r.Search(tt.query)
+			expectNoErr(t, err)
+			var got []string
+			for _, f := range results {
+				for _, key := range []string{"name", "label"} {
+					if v, ok := f.Properties[key]; ok {
+						got = append(got, v.(string))
+					}
+				}
+			}
+			if tt.file == "polygons.fgb" {
+				// Features are in Hilbert order within the file.
+				sort.Strings(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTestdataWithoutIndex(t *testing.T) {
+	r, fs := readFile(t, readTestdata(t, "mixed.fgb"))
+
+	want := Header{
+		Name:          "mixed",
+		GeometryType:  Unknown,
+		HasZ:          true,
+		Columns:       []Column{{Name: "label", Type: String}},
+		FeaturesCount: 5,
+	}
+	if hdr := r.Header(); !reflect.DeepEqual(hdr, want) {
+		t.Errorf("\ngot:  %+v\nwant: %+v", hdr, want)
+	}
+	if _, ok := r.Envelope(); ok {
+		t.Error("unexpected envelope")
+	}
+
+	for i, tt := range []struct {
+		label string
+		wkt   string
+	}{
+		{"point", "POINT Z(1 2 3)"},
+		{"line", "LINESTRING Z(0 0 0,1 1 1,2 0 2)"},
+		{"multipolygon", "MULTIPOLYGON Z(((0 0 1,1 0 1,0 1 1,0 0 1)),((2 2 2,3 2 2,2 3 2,2 2 2)))"},
+		{"nothing", "GEOMETRYCOLLECTION EMPTY"},
+		{"collection", "GEOMETRYCOLLECTION Z(POINT Z(5 5 5),LINESTRING Z(6 6 6,7 7 7))"},
+	} {
+		if i >= len(fs) {
+			t.Fatalf("expected %d features, got %d", i+1, len(fs))
+		}
+		if got := fs[i].Properties["label"]; got != tt.label {
+			t.Errorf("%d: got label %v want %v", i, got, tt.label)
+		}
+		wantGeom := geomFromWKT(t, tt.wkt)
+		if !fs[i].Geometry.EqualsExact(wantGeom) {
+			t.Errorf("%d:\ngot:  %v\nwant: %v", i, fs[i].Geometry.AsText(), wantGeom.AsText())
+		}
+	}
+}
+
+func TestLevelBounds(t *testing.T) {
+	for i, tt := range []struct {
+		numItems, nodeSize int
+		want               [][2]int
+	}{
+		{1, 16, [][2]int{{1, 2}, {0, 1}}},
+		{16, 16, [][2]int{{1, 17}, {0, 1}}},
+		{17, 16, [][2]int{{3, 20}, {1, 3}, {0, 1}}},
+		{5, 2, [][2]int{{6, 11}, {3, 6}, {1, 3}, {0, 1}}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := levelBounds(tt.numItems, tt.nodeSize)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatBufferAlignment(t *testing.T) {
+	// Vectors of doubles must be 8 byte aligned, regardless of what precedes
+	// them.
+	for i, s := range []string{"", "a", "ab", "abc", "abcd", "abcde"} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := buildFlatBuffer([]fbField{
+				fbString(s),
+				fbFloat64s([]float64{1, 2}),
+				fbUint8(7),
+				fbUint64(1 << 40),
+			})
+			d := &fbDecoder{buf: buf}
+			root := d.root()
+			pos, n := d.vector(root, 1, 8)
+			expectNoErr(t, d.err)
+			if pos%8 != 0 || n != 2 {
+				t.Errorf("got pos %d and length %d", pos, n)
+			}
+			if got := d.stringField(root, 0); got != s {
+				t.Errorf("got string %q, want %q", got, s)
+			}
+			if got := d.float64sField(root, 1); !reflect.DeepEqual(got, []float64{1, 2}) {
+				t.Errorf("got doubles %v", got)
+			}
+			if got := d.uint8Field(root, 2, 0); got != 7 {
+				t.Errorf("got ubyte %d", got)
+			}
+			if got := d.uint64Field(root, 3, 0); got != 1<<40 {
+				t.Errorf("got ulong %d", got)
+			}
+			if d.field(root, 3)%8 != 0 {
+				t.Errorf("ulong field isn't aligned")
+			}
+			expectNoErr(t, d.err)
+		})
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	valid := writeFile(t, Header{}, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT(1 2)")},
+	})
+	for i, buf := range [][]byte{
+		nil,
+		[]byte("fgb\x03fg"),
+		[]byte("fgb\x02fgb\x00"),
+		[]byte("fgb\x03fgb\x00\x10\x00\x00\x00"),
+		[]byte("fgb\x03fgb\x00\x04\x00\x00\x00\xff\xff\xff\xff"),
+		valid[:len(valid)-1],
+		valid[:len(valid)-10],
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader(buf))
+			if err != nil {
+				return
+			}
+			for {
+				_, err := r.Read()
+				if err == io.EOF {
+					t.Fatal("expected error but got io.EOF")
+				}
+				if err != nil {
+					return
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package flatgeobuf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Geometry table field IDs (the T and TM fields aren't used).
+const (
+	geometryEnds = iota
+	geometryXY
+	geometryZ
+	geometryM
+	geometryT
+	geometryTM
+	geometryType
+	geometryParts
+)
+
+// geometryTypeOf gives the FlatGeobuf type of a geometry. Empty sets are
+// typed according to their dimension.
+func geometryTypeOf(g geom.Geometry) GeometryType {
+	switch {
+	case g.IsPoint():
+		return Point
+	case g.IsLine(), g.IsLineString():
+		return LineString
+	case g.IsPolygon():
+		return Polygon
+	case g.IsMultiPoint():
+		return MultiPoint
+	case g.IsMultiLineString():
+		return MultiLineString
+	case g.IsMultiPolygon():
+		return MultiPolygon
+	case g.IsGeometryCollection():
+		return GeometryCollection
+	default:
+		switch g.AsEmptySet().Dimension() {
+		case 0:
+			return Point
+		case 1:
+			return LineString
+		default:
+			return Polygon
+		}
+	}
+}
+
+// encodeGeometry gives the fields of the Geometry table for a geometry. The
+// geometry's type is only included if includeType is true (it's omitted when
+// it's implied by the header or by the parent geometry).
+func encodeGeometry(g geom.Geometry, ctype geom.CoordinatesType, includeType bool) []fbField {
+	g = g.ForceCoordinatesType(ctype)
+	typ := geometryTypeOf(g)
+
+	// Rings holds the sequences of coordinates that are stored in the XY,
+	// Z, and M arrays. For Polygons and MultiLineStrings, the end of each
+	// sequence is stored in the ends array.
+	var rings [][]geom.Coordinates
+	var parts [][]fbField
+	switch {
+	case g.IsPoint():
+		rings = [][]geom.Coordinates{{g.AsPoint().Coordinates()}}
+	case g.IsLine():
+		rings = [][]geom.Coordinates{g.AsLine().Coordinates()}
+	case g.IsLineString():
+		rings = [][]geom.Coordinates{g.AsLineString().Coordinates()}
+	case g.IsPolygon():
+		rings = g.AsPolygon().Coordinates()
+	case g.IsMultiPoint():
+		rings = [][]geom.Coordinates{g.AsMultiPoint().Coordinates()}
+	case g.IsMultiLineString():
+		rings = g.AsMultiLineString().Coordinates()
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			parts = append(parts, encodeGeometry(mp.PolygonN(i).AsGeometry(), ctype, false))
+		}
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		for i := 0; i < gc.NumGeometries(); i++ {
+			parts = append(parts, encodeGeometry(gc.GeometryN(i), ctype, true))
+		}
+	}
+
+	var xy, z, m []float64
+	var ends []uint32
+	for _, ring := range rings {
+		for _, c := range ring {
+			xy = append(xy, c.X, c.Y)
+			if ctype.Is3D() {
+				z = append(z, c.Z)
+			}
+			if ctype.IsMeasured() {
+				m = append(m, c.M)
+			}
+		}
+		ends = append(ends, uint32(len(xy)/2))
+	}
+
+	fields := make([]fbField, geometryParts+1)
+	if len(ends) > 1 {
+		fields[geometryEnds] = fbUint32s(ends)
+	}
+	if len(xy) > 0 {
+		fields[geometryXY] = fbFloat64s(xy)
+	}
+	if len(z) > 0 {
+		fields[geometryZ] = fbFloat64s(z)
+	}
+	if len(m) > 0 {
+		fields[geometryM] = fbFloat64s(m)
+	}
+	if includeType {
+		fields[geometryType] = fbUint8(uint8(typ))
+	}
+	if len(parts) > 0 {
+		fields[geometryParts] = fbTables(parts)
+	}
+	return fields
+}
+
+// decodeGeometry decodes a Geometry table. The type of the geometry is typ,
+// unless it's overridden by the table's type.
+func decodeGeometry(d *fbDecoder, t fbTable, typ GeometryType, ctype geom.CoordinatesType, opts []geom.ConstructorOption) (geom.Geometry, error) {
+	if tableType := GeometryType(d.uint8Field(t, geometryType, 0)); tableType != Unknown {
+		typ = tableType
+	}
+	xy := d.float64sField(t, geometryXY)
+	z := d.float64sField(t, geometryZ)
+	m := d.float64sField(t, geometryM)
+	ends := d.uint32sField(t, geometryEnds)
+	if d.err != nil {
+		return geom.Geometry{}, d.err
+	}
+
+	n := len(xy) / 2
+	if len(xy)%2 != 0 {
+		return geom.Geometry{}, errors.New("odd number of XY values")
+	}
+	if (ctype.Is3D() && len(z) != n) || (ctype.IsMeasured() && len(m) != n) {
+		return geom.Geometry{}, errors.New("number of Z or M values doesn't match number of XY values")
+	}
+	coords := make([]geom.Coordinates, n)
+	for i := range coords {
+		coords[i].X = xy[2*i]
+		coords[i].Y = xy[2*i+1]
+		if ctype.Is3D() {
+			coords[i].Z = z[i]
+		}
+		if ctype.IsMeasured() {
+			coords[i].M = m[i]
+		}
+		coords[i].Type = ctype
+	}
+	rings, err := splitRings(coords, ends)
+	if err != nil {
+		return geom.Geometry{}, err
+	}
+
+	var g geom.Geometry
+	switch typ {
+	case Point:
+		switch n {
+		case 0:
+			g = geom.NewEmptyPoint(opts...).AsGeometry()
+		case 1:
+			g = geom.NewPointC(coords[0], opts...).AsGeometry()
+		default:
+			return geom.Geometry{}, fmt.Errorf("point has %d coordinates", n)
+		}
+	case LineString:
+		if n == 0 {
+			g = geom.NewEmptyLineString(opts...).AsGeometry()
+			break
+		}
+		ls, err := geom.NewLineStringC(coords, opts...)
+		if err != nil {
+			return geom.Geometry{}, err
+		}
+		g = ls.AsGeometry()
+	case Polygon:
+		if n == 0 {
+			g = geom.NewEmptyPolygon(opts...).AsGeometry()
+			break
+		}
+		poly, err := geom.NewPolygonC(rings, opts...)
+		if err != nil {
+			return geom.Geometry{}, err
+		}
+		g = poly.AsGeometry()
+	case MultiPoint:
+		g = geom.NewMultiPointC(coords, opts...).AsGeometry()
+	case MultiLineString:
+		mls, err := geom.NewMultiLineStringC(rings, opts...)
+		if err != nil {
+			return geom.Geometry{}, err
+		}
+		g = mls.AsGeometry()
+	case MultiPolygon:
+		var polys []geom.Polygon
+		for _, part := range d.tablesField(t, geometryParts) {
+			poly, err := decodeGeometry(d, part, Polygon, ctype, opts)
+			if err != nil {
+				return geom.Geometry{}, err
+			}
+			if !poly.IsPolygon() {
+				if poly.IsEmpty() {
+					continue
+				}
+				return geom.Geometry{}, errors.New("MultiPolygon part isn't a Polygon")
+			}
+			polys = append(polys, poly.AsPolygon())
+		}
+		mp, err := geom.NewMultiPolygon(polys, opts...)
+		if err != nil {
+			return geom.Geometry{}, err
+		}
+		g = mp.AsGeometry()
+	case GeometryCollection:
+		var geoms []geom.Geometry
+		for _, part := range d.tablesField(t, geometryParts) {
+			child, err := decodeGeometry(d, part, Unknown, ctype, opts)
+			if err != nil {
+				return geom.Geometry{}, err
+			}
+			geoms = append(geoms, child)
+		}
+		g = geom.NewGeometryCollection(geoms, opts...).AsGeometry()
+	default:
+		return geom.Geometry{}, fmt.Errorf("unsupported geometry type: %v", typ)
+	}
+	if d.err != nil {
+		return geom.Geometry{}, d.err
+	}
+	if g.IsEmpty() {
+		g = g.ForceCoordinatesType(ctype)
+	}
+	return g, nil
+}
+
+// splitRings splits coordinates into rings using the end index of each ring.
+// If there are no end indices, then all coordinates are in a single ring.
+func splitRings(coords []geom.Coordinates, ends []uint32) ([][]geom.Coordinates, error) {
+	if len(ends) == 0 {
+		return [][]geom.Coordinates{coords}, nil
+	}
+	rings := make([][]geom.Coordinates, len(ends))
+	var start uint32
+	for i, end := range ends {
+		if end < start || end > uint32(len(coords)) {
+			return nil, errors.New("invalid ends")
+		}
+		rings[i] = coords[start:end]
+		start = end
+	}
+	if int(start) != len(coords) {
+		return nil, errors.New("invalid ends")
+	}
+	return rings, nil
+}
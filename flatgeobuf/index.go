@@ -0,0 +1,251 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// The spatial index is a packed Hilbert R-tree. The leaves of the tree are
+// the bounding boxes of the features, sorted by the Hilbert value of their
+// centers. Each group of (up to) node size nodes on one level has a parent on
+// the level above, up to a single root node. The nodes are stored level by
+// level, starting with the root and ending with the leaves. The layout
+// matches the reference implementation.
+
+// nodeItemSize is the size of each node of the index (in bytes).
+const nodeItemSize = 40
+
+// nodeItem is a node of the index. For leaf nodes, offset is the byte offset
+// of the feature (relative to the start of the features). For other nodes,
+// it's the index of the node's first child.
+type nodeItem struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+// emptyNodeItem gives a node that doesn't intersect with anything, and can
+// be expanded to include other nodes.
+func emptyNodeItem() nodeItem {
+	return nodeItem{
+		minX: math.Inf(+1),
+		minY: math.Inf(+1),
+		maxX: math.Inf(-1),
+		maxY: math.Inf(-1),
+	}
+}
+
+// envelopeNodeItem gives the node for a geometry's envelope.
+func envelopeNodeItem(env geom.Envelope, ok bool) nodeItem {
+	if !ok {
+		return emptyNodeItem()
+	}
+	min, max := env.Min(), env.Max()
+	return nodeItem{minX: min.X, minY: min.Y, maxX: max.X, maxY: max.Y}
+}
+
+func (n *nodeItem) expand(o nodeItem) {
+	n.minX = math.Min(n.minX, o.minX)
+	n.minY = math.Min(n.minY, o.minY)
+	n.maxX = math.Max(n.maxX, o.maxX)
+	n.maxY = math.Max(n.maxY, o.maxY)
+}
+
+func (n nodeItem) intersects(o nodeItem) bool {
+	return n.maxX >= o.minX && n.maxY >= o.minY && n.minX <= o.maxX && n.minY <= o.maxY
+}
+
+func (n nodeItem) isEmpty() bool {
+	return n.minX > n.maxX
+}
+
+func (n nodeItem) encode(dst []byte) []byte {
+	var buf [nodeItemSize]byte
+	binary.LittleEndian.PutUint64(buf[0:], math.Float64bits(n.minX))
+	binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(n.minY))
+	binary.LittleEndian.PutUint64(buf[16:], math.Float64bits(n.maxX))
+	binary.LittleEndian.PutUint64(buf[24:], math.Float64bits(n.maxY))
+	binary.LittleEndian.PutUint64(buf[32:], n.offset)
+	return append(dst, buf[:]...)
+}
+
+func decodeNodeItem(buf []byte) nodeItem {
+	return nodeItem{
+		minX:   math.Float64frombits(binary.LittleEndian.Uint64(buf[0:])),
+		minY:   math.Float64frombits(binary.LittleEndian.Uint64(buf[8:])),
+		maxX:   math.Float64frombits(binary.LittleEndian.Uint64(buf[16:])),
+		maxY:   math.Float64frombits(binary.LittleEndian.Uint64(buf[24:])),
+		offset: binary.LittleEndian.Uint64(buf[32:]),
+	}
+}
+
+// levelBounds gives the range of node indices that make up each level of an
+// index, starting with the leaves and ending with the root.
+func levelBounds(numItems, nodeSize int) [][2]int {
+	n := numItems
+	numNodes := n
+	levelNumNodes := []int{n}
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+	bounds := make([][2]int, len(levelNumNodes))
+	end := numNodes
+	for i, size := range levelNumNodes {
+		bounds[i] = [2]int{end - size, end}
+		end -= size
+	}
+	return bounds
+}
+
+// buildIndex builds an index from its leaves (which must already be sorted),
+// and gives the nodes of the index.
+func buildIndex(leaves []nodeItem, nodeSize int) []nodeItem {
+	bounds := levelBounds(len(leaves), nodeSize)
+	nodes := make([]nodeItem, bounds[0][1])
+	copy(nodes[bounds[0][0]:], leaves)
+	for level := 0; level+1 < len(bounds); level++ {
+		parent := bounds[level+1][0]
+		for pos := bounds[level][0]; pos < bounds[level][1]; parent++ {
+			node := emptyNodeItem()
+			node.offset = uint64(pos)
+			for j := 0; j < nodeSize && pos < bounds[level][1]; j++ {
+				node.expand(nodes[pos])
+				pos++
+			}
+			nodes[parent] = node
+		}
+	}
+	return nodes
+}
+
+// searchIndex finds the leaves of an index that intersect with a query, and
+// gives the offsets of the corresponding features in increasing order.
+func searchIndex(nodes []nodeItem, numItems, nodeSize int, query nodeItem) ([]uint64, error) {
+	bounds := levelBounds(numItems, nodeSize)
+	if len(nodes) != bounds[0][1] {
+		return nil, errors.New("index has wrong number of nodes")
+	}
+	type entry struct {
+		pos, level int
+	}
+	var offsets []uint64
+	queue := []entry{{0, len(bounds) - 1}}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		end := e.pos + nodeSize
+		if end > bounds[e.level][1] {
+			end = bounds[e.level][1]
+		}
+		for pos := e.pos; pos < end; pos++ {
+			node := nodes[pos]
+			if !node.intersects(query) {
+				continue
+			}
+			if e.level == 0 {
+				offsets = append(offsets, node.offset)
+				continue
+			}
+			child := bounds[e.level-1]
+			if node.offset < uint64(child[0]) || node.offset >= uint64(child[1]) {
+				return nil, errors.New("index node has invalid child")
+			}
+			queue = append(queue, entry{int(node.offset), e.level - 1})
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, nil
+}
+
+// hilbertSort sorts items by the Hilbert value of the centers of their
+// nodes, within the extent of all of the nodes.
+func hilbertSort(items []indexedFeature, extent nodeItem) {
+	const hilbertMax = 1<<16 - 1
+	scale := func(v, min, max float64) uint32 {
+		if !(max > min) {
+			return 0
+		}
+		s := math.Floor(hilbertMax * (v - min) / (max - min))
+		if !(s >= 0) { // also catches NaN
+			return 0
+		}
+		return uint32(math.Min(s, hilbertMax))
+	}
+	values := make([]uint32, len(items))
+	for i, item := range items {
+		n := item.node
+		x := scale((n.minX+n.maxX)/2, extent.minX, extent.maxX)
+		y := scale((n.minY+n.maxY)/2, extent.minY, extent.maxY)
+		values[i] = hilbert(x, y)
+	}
+	sort.Stable(hilbertSorter{items, values})
+}
+
+type hilbertSorter struct {
+	items  []indexedFeature
+	values []uint32
+}
+
+func (s hilbertSorter) Len() int           { return len(s.items) }
+func (s hilbertSorter) Less(i, j int) bool { return s.values[i] < s.values[j] }
+func (s hilbertSorter) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// hilbert gives the position of a point (with 16 bit coordinates) along a
+// Hilbert curve. It's based on the public domain algorithm from
+// http://threadlocalmutex.com/?p=126 (which is also used by the reference
+// implementation).
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+	return (interleave(i1) << 1) | interleave(i0)
+}
+
+// interleave spreads the lower 16 bits of v so that there's a zero bit
+// between each of them.
+func interleave(v uint32) uint32 {
+	v = (v | (v << 8)) & 0x00FF00FF
+	v = (v | (v << 4)) & 0x0F0F0F0F
+	v = (v | (v << 2)) & 0x33333333
+	v = (v | (v << 1)) & 0x55555555
+	return v
+}
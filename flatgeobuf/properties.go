@@ -0,0 +1,287 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// encodeProperties appends the binary encoding of a feature's properties.
+// Each property is encoded as its column index followed by its value.
+// Properties that are nil or don't have a column are omitted.
+func encodeProperties(dst []byte, cols []Column, props map[string]interface{}) ([]byte, error) {
+	for i, col := range cols {
+		v, ok := props[col.Name]
+		if !ok || v == nil {
+			continue
+		}
+		var idx [2]byte
+		binary.LittleEndian.PutUint16(idx[:], uint16(i))
+		dst = append(dst, idx[:]...)
+		var err error
+		dst, err = appendValue(dst, col.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", col.Name, err)
+		}
+	}
+	return dst, nil
+}
+
+func appendValue(dst []byte, typ ColumnType, v interface{}) ([]byte, error) {
+	switch typ {
+	case Byte, Short, Int, Long:
+		i, ok := toInt64(v)
+		bits := 8 * valueSize(typ)
+		if !ok || (bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1))) {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendUint(dst, uint64(i), bits/8), nil
+	case UByte, UShort, UInt, ULong:
+		u, ok := toUint64(v)
+		bits := 8 * valueSize(typ)
+		if !ok || (bits < 64 && u >= 1<<bits) {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendUint(dst, u, bits/8), nil
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, incompatibleValue(v, typ)
+		}
+		if b {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case Float:
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendUint(dst, uint64(math.Float32bits(float32(f))), 4), nil
+	case Double:
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendUint(dst, math.Float64bits(f), 8), nil
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendBytes(dst, []byte(s)), nil
+	case JSON:
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(dst, buf), nil
+	case DateTime:
+		switch v := v.(type) {
+		case time.Time:
+			return appendBytes(dst, []byte(v.Format(time.RFC3339Nano))), nil
+		case string:
+			return appendBytes(dst, []byte(v)), nil
+		default:
+			return nil, incompatibleValue(v, typ)
+		}
+	case Binary:
+		p, ok := v.([]byte)
+		if !ok {
+			return nil, incompatibleValue(v, typ)
+		}
+		return appendBytes(dst, p), nil
+	default:
+		return nil, fmt.Errorf("unsupported column type: %v", typ)
+	}
+}
+
+func incompatibleValue(v interface{}, typ ColumnType) error {
+	return fmt.Errorf("can't write %T value to %v column", v, typ)
+}
+
+func appendUint(dst []byte, v uint64, size uint) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(dst, buf[:size]...)
+}
+
+func appendBytes(dst []byte, p []byte) []byte {
+	dst = appendUint(dst, uint64(len(p)), 4)
+	return append(dst, p...)
+}
+
+// valueSize gives the size of fixed size values, or 0 for variable size
+// values.
+func valueSize(typ ColumnType) uint {
+	switch typ {
+	case Byte, UByte, Bool:
+		return 1
+	case Short, UShort:
+		return 2
+	case Int, UInt, Float:
+		return 4
+	case Long, ULong, Double:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// toInt64 converts integers (and floats with integral values) to int64.
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUint64(v)
+		return int64(u), u <= math.MaxInt64
+	case float32:
+		return toInt64(float64(v))
+	case float64:
+		if v != math.Trunc(v) || v < -(1<<63) || v >= 1<<63 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toUint64 converts non-negative integers (and floats with integral values)
+// to uint64.
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case float32:
+		return toUint64(float64(v))
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= 1<<64 {
+			return 0, false
+		}
+		return uint64(v), true
+	default:
+		i, ok := toInt64(v)
+		return uint64(i), ok && i >= 0
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int, int8, int16, int32, int64:
+		i, _ := toInt64(v)
+		return float64(i), true
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUint64(v)
+		return float64(u), true
+	default:
+		return 0, false
+	}
+}
+
+// decodeProperties decodes the binary encoding of a feature's properties.
+func decodeProperties(buf []byte, cols []Column) (map[string]interface{}, error) {
+	props := make(map[string]interface{}, len(cols))
+	for len(buf) > 0 {
+		if len(buf) < 2 {
+			return nil, errors.New("properties are truncated")
+		}
+		idx := int(binary.LittleEndian.Uint16(buf))
+		buf = buf[2:]
+		if idx >= len(cols) {
+			return nil, fmt.Errorf("invalid column index: %d", idx)
+		}
+		var (
+			v   interface{}
+			err error
+		)
+		v, buf, err = decodeValue(buf, cols[idx].Type)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", cols[idx].Name, err)
+		}
+		props[cols[idx].Name] = v
+	}
+	return props, nil
+}
+
+// decodeValue decodes a single value, and gives the remaining bytes.
+func decodeValue(buf []byte, typ ColumnType) (interface{}, []byte, error) {
+	size := int(valueSize(typ))
+	if size == 0 {
+		if typ > Binary {
+			return nil, nil, fmt.Errorf("unsupported column type: %v", typ)
+		}
+		if len(buf) < 4 {
+			return nil, nil, errors.New("properties are truncated")
+		}
+		n := binary.LittleEndian.Uint32(buf)
+		buf = buf[4:]
+		if uint64(n) > uint64(len(buf)) {
+			return nil, nil, errors.New("properties are truncated")
+		}
+		size = int(n)
+	}
+	if len(buf) < size {
+		return nil, nil, errors.New("properties are truncated")
+	}
+	p, rest := buf[:size], buf[size:]
+
+	var u uint64
+	if size <= 8 {
+		var padded [8]byte
+		copy(padded[:], p)
+		u = binary.LittleEndian.Uint64(padded[:])
+	}
+	switch typ {
+	case Byte:
+		return int64(int8(u)), rest, nil
+	case Short:
+		return int64(int16(u)), rest, nil
+	case Int:
+		return int64(int32(u)), rest, nil
+	case Long:
+		return int64(u), rest, nil
+	case UByte, UShort, UInt, ULong:
+		return u, rest, nil
+	case Bool:
+		return u != 0, rest, nil
+	case Float:
+		return float64(math.Float32frombits(uint32(u))), rest, nil
+	case Double:
+		return math.Float64frombits(u), rest, nil
+	case String, DateTime:
+		return string(p), rest, nil
+	case JSON:
+		var v interface{}
+		if err := json.Unmarshal(p, &v); err != nil {
+			return nil, nil, err
+		}
+		return v, rest, nil
+	default:
+		return append([]byte(nil), p...), rest, nil
+	}
+}
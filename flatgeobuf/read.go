@@ -0,0 +1,280 @@
+package flatgeobuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// maxFeaturesCount limits the number of features in a file, so that the size
+// of its index doesn't overflow.
+const maxFeaturesCount = 1 << 48
+
+// Reader reads the features of a FlatGeobuf file. Features can either be read
+// one at a time (using Read), or the features that intersect with a bounding
+// box can be found using the spatial index (using Search).
+type Reader struct {
+	r     *bufio.Reader
+	hdr   Header
+	env   envelope
+	ctype geom.CoordinatesType
+	opts  []geom.ConstructorOption
+
+	started bool   // true once the index (if any) has been read or skipped
+	offset  uint64 // offset of the next feature, relative to the first feature
+	numRead uint64
+	err     error
+	scratch []byte
+}
+
+// NewReader creates a Reader that reads a FlatGeobuf file. The header is read
+// immediately. The geometries are constructed using the supplied options.
+func NewReader(r io.Reader, opts ...geom.ConstructorOption) (*Reader, error) {
+	fr := &Reader{
+		r:    bufio.NewReader(r),
+		opts: opts,
+	}
+	var gotMagic [8]byte
+	if _, err := io.ReadFull(fr.r, gotMagic[:]); err != nil {
+		return nil, fmt.Errorf("reading magic number: %v", err)
+	}
+	if !bytes.Equal(gotMagic[:7], magic[:7]) {
+		return nil, errors.New("invalid FlatGeobuf magic number")
+	}
+
+	buf, err := fr.readSizePrefixed()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	fr.hdr, fr.env, err = decodeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if fr.hdr.FeaturesCount > maxFeaturesCount {
+		return nil, fmt.Errorf("too many features: %d", fr.hdr.FeaturesCount)
+	}
+	if err := validateColumns(fr.hdr.Columns); err != nil {
+		return nil, err
+	}
+	fr.ctype = fr.hdr.coordinatesType()
+	return fr, nil
+}
+
+// readSizePrefixed reads a size prefixed buffer. The buffer is only valid
+// until the next read.
+func (r *Reader) readSizePrefixed() ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.r, prefix[:]); err != nil {
+		return nil, err
+	}
+	return r.readN(uint64(binary.LittleEndian.Uint32(prefix[:])))
+}
+
+// readN reads n bytes. Large reads are made incrementally, so that a corrupt
+// size doesn't cause a large allocation. The result is only valid until the
+// next read.
+func (r *Reader) readN(n uint64) ([]byte, error) {
+	if n <= uint64(cap(r.scratch)) || n <= 1<<20 {
+		if uint64(cap(r.scratch)) < n {
+			r.scratch = make([]byte, n)
+		}
+		buf := r.scratch[:n]
+		_, err := io.ReadFull(r.r, buf)
+		return buf, err
+	}
+	buf, err := ioutil.ReadAll(io.LimitReader(r.r, int64(n)))
+	if err == nil && uint64(len(buf)) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	r.scratch = buf
+	return buf, err
+}
+
+// Header gives the header of the file.
+func (r *Reader) Header() Header {
+	hdr := r.hdr
+	hdr.Columns = append([]Column(nil), hdr.Columns...)
+	return hdr
+}
+
+// Envelope gives the bounding box of the features in the file (if it's
+// recorded in the header).
+func (r *Reader) Envelope() (geom.Envelope, bool) {
+	return r.env.env, r.env.valid
+}
+
+func (r *Reader) hasIndex() bool {
+	return r.hdr.IndexNodeSize != 0 && r.hdr.FeaturesCount != 0
+}
+
+func (r *Reader) indexSize() uint64 {
+	bounds := levelBounds(int(r.hdr.FeaturesCount), int(r.hdr.IndexNodeSize))
+	return uint64(bounds[0][1]) * nodeItemSize
+}
+
+// Read reads the next feature. It returns io.EOF once all features have been
+// read. Once Read returns an error, subsequent calls return the same error.
+//
+// Features without a geometry are read with an empty GeometryCollection.
+// Integer properties are read as int64 (for signed columns) or uint64 (for
+// unsigned columns), Float and Double properties are read as float64, String
+// and DateTime properties are read as strings, JSON properties are read as
+// their unmarshalled JSON values, and Binary properties are read as []byte.
+func (r *Reader) Read() (geom.GeoJSONFeature, error) {
+	if r.err != nil {
+		return geom.GeoJSONFeature{}, r.err
+	}
+	f, err := r.read()
+	if err != nil {
+		r.err = err
+		return geom.GeoJSONFeature{}, err
+	}
+	return f, nil
+}
+
+func (r *Reader) read() (geom.GeoJSONFeature, error) {
+	if !r.started {
+		r.started = true
+		if r.hasIndex() {
+			if _, err := io.CopyN(ioutil.Discard, r.r, int64(r.indexSize())); err != nil {
+				return geom.GeoJSONFeature{}, fmt.Errorf("skipping index: %v", err)
+			}
+		}
+	}
+	if r.hdr.FeaturesCount != 0 && r.numRead == r.hdr.FeaturesCount {
+		return geom.GeoJSONFeature{}, io.EOF
+	}
+	if r.hdr.FeaturesCount == 0 {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			return geom.GeoJSONFeature{}, io.EOF
+		}
+	}
+
+	buf, err := r.readSizePrefixed()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return geom.GeoJSONFeature{}, fmt.Errorf("reading feature %d: %v", r.numRead, err)
+	}
+	f, err := r.decodeFeature(buf)
+	if err != nil {
+		return geom.GeoJSONFeature{}, fmt.Errorf("feature %d: %v", r.numRead, err)
+	}
+	r.offset += 4 + uint64(len(buf))
+	r.numRead++
+	return f, nil
+}
+
+func (r *Reader) decodeFeature(buf []byte) (geom.GeoJSONFeature, error) {
+	d := &fbDecoder{buf: buf}
+	t := d.root()
+	var f geom.GeoJSONFeature
+	if gt, ok := d.tableField(t, featureGeometry); ok {
+		g, err := decodeGeometry(d, gt, r.hdr.GeometryType, r.ctype, r.opts)
+		if err != nil {
+			return geom.GeoJSONFeature{}, err
+		}
+		f.Geometry = g
+	}
+
+	// Features may have their own columns, which take precedence over the
+	// columns in the header.
+	cols := r.hdr.Columns
+	if tables := d.tablesField(t, featureColumns); len(tables) > 0 {
+		cols = decodeColumns(d, tables)
+	}
+	props := d.bytesField(t, featureProperties)
+	if d.err != nil {
+		return geom.GeoJSONFeature{}, d.err
+	}
+	if len(props) > 0 {
+		var err error
+		f.Properties, err = decodeProperties(props, cols)
+		if err != nil {
+			return geom.GeoJSONFeature{}, err
+		}
+	}
+	return f, nil
+}
+
+// Search finds the features whose bounding boxes intersect with a query
+// bounding box, and gives them in the order that they appear in the file.
+// The spatial index is used to find the matching features, so the other
+// features are skipped without being decoded. If the file doesn't have a
+// spatial index, then every feature is read and checked against the query.
+//
+// Search must be called before any call to Read, and may only be called once
+// (after it returns, subsequent calls to Read return io.EOF).
+func (r *Reader) Search(query geom.Envelope) ([]geom.GeoJSONFeature, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.started {
+		return nil, errors.New("can't search after reading features")
+	}
+	features, err := r.search(envelopeNodeItem(query, true))
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	r.err = io.EOF
+	return features, nil
+}
+
+func (r *Reader) search(query nodeItem) ([]geom.GeoJSONFeature, error) {
+	var features []geom.GeoJSONFeature
+	if !r.hasIndex() {
+		for {
+			f, err := r.read()
+			if err == io.EOF {
+				return features, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if envelopeNodeItem(f.Geometry.Envelope()).intersects(query) {
+				features = append(features, f)
+			}
+		}
+	}
+
+	r.started = true
+	buf, err := r.readN(r.indexSize())
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %v", err)
+	}
+	nodes := make([]nodeItem, len(buf)/nodeItemSize)
+	for i := range nodes {
+		nodes[i] = decodeNodeItem(buf[i*nodeItemSize:])
+	}
+	offsets, err := searchIndex(nodes, int(r.hdr.FeaturesCount), int(r.hdr.IndexNodeSize), query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, offset := range offsets {
+		if i > 0 && offset == offsets[i-1] {
+			continue
+		}
+		if offset < r.offset {
+			return nil, errors.New("index has overlapping features")
+		}
+		if _, err := io.CopyN(ioutil.Discard, r.r, int64(offset-r.offset)); err != nil {
+			return nil, fmt.Errorf("skipping to feature at offset %d: %v", offset, err)
+		}
+		r.offset = offset
+		f, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, f)
+	}
+	return features, nil
+}
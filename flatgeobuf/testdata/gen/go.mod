@@ -0,0 +1,5 @@
+module github.com/peterstace/simplefeatures/flatgeobuf/testdata/gen
+
+go 1.12
+
+require github.com/google/flatbuffers v25.2.10+incompatible
@@ -0,0 +1,434 @@
+// This program generates the FlatGeobuf files in the parent directory. Run it
+// from this directory using "go run .".
+//
+// It deliberately doesn't use the flatgeobuf package. Instead, it follows the
+// FlatGeobuf schema and builds the FlatBuffers using the reference FlatBuffers
+// library (the Go port of the builder used by GDAL and the reference
+// FlatGeobuf implementations). That builder works back to front, shares
+// identical vtables, and omits default valued fields. The flatgeobuf package
+// builds buffers front to back, so these files check that it can read the
+// layout produced by other implementations.
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"sort"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+func main() {
+	if err := ioutil.WriteFile("../polygons.fgb", polygonsFile(), 0644); err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("../mixed.fgb", mixedFile(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Geometry types.
+const (
+	typeUnknown            = 0
+	typePoint              = 1
+	typeLineString         = 2
+	typePolygon            = 3
+	typeMultiPolygon       = 6
+	typeGeometryCollection = 7
+)
+
+// Column types.
+const (
+	colInt    = 5
+	colDouble = 10
+	colString = 11
+)
+
+const wgs84WKT = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]]`
+
+// polygonsFile has 20 square polygons (one with a hole) laid out on a 5x4
+// grid, along with a spatial index.
+func polygonsFile() []byte {
+	type feature struct {
+		buf                    []byte
+		minX, minY, maxX, maxY float64
+	}
+	var features []feature
+	for i := 0; i < 20; i++ {
+		x, y := float64(i%5*2), float64(i/5*2)
+		xy := []float64{x, y, x + 1, y, x + 1, y + 1, x, y + 1, x, y}
+		var ends []uint32
+		if i == 7 {
+			xy = append(xy, x+0.25, y+0.25, x+0.25, y+0.75, x+0.75, y+0.75, x+0.75, y+0.25, x+0.25, y+0.25)
+			ends = []uint32{5, 10}
+		}
+
+		var props []byte
+		props = appendUint16(props, 0)
+		props = appendString(props, fmt.Sprintf("f%02d", i))
+		props = appendUint16(props, 1)
+		props = appendUint32(props, uint32(i))
+		if i%4 != 3 {
+			props = appendUint16(props, 2)
+			props = appendUint64(props, math.Float64bits(float64(i)*1.5))
+		}
+
+		b := newBuilder()
+		geom := b.geometry(xy, nil, ends, 0, nil)
+		b.finish(b.feature(geom, props))
+		features = append(features, feature{b.bytes(), x, y, x + 1, y + 1})
+	}
+
+	minX, minY, maxX, maxY := 0.0, 0.0, 9.0, 7.0
+	values := make([]uint32, len(features))
+	for i, f := range features {
+		hx := uint32(math.Floor(0xFFFF * ((f.minX+f.maxX)/2 - minX) / (maxX - minX)))
+		hy := uint32(math.Floor(0xFFFF * ((f.minY+f.maxY)/2 - minY) / (maxY - minY)))
+		values[i] = hilbert(hx, hy)
+	}
+	order := make([]int, len(features))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	var leaves []node
+	var offset uint64
+	for _, i := range order {
+		f := features[i]
+		leaves = append(leaves, node{f.minX, f.minY, f.maxX, f.maxY, offset})
+		offset += uint64(len(f.buf))
+	}
+
+	b := newBuilder()
+	name := b.string("polygons")
+	cols := []int{
+		b.column("name", colString, 16, true),
+		b.column("id", colInt, -1, false),
+		b.column("value", colDouble, -1, true),
+	}
+	hdr := b.header(header{
+		name:          name,
+		envelope:      b.float64s([]float64{minX, minY, maxX, maxY}),
+		geometryType:  typePolygon,
+		columns:       b.offsets(cols),
+		featuresCount: uint64(len(features)),
+		indexNodeSize: 16,
+		crs:           b.crs("EPSG", 4326, b.string(wgs84WKT)),
+	})
+	b.finish(hdr)
+
+	file := append([]byte("fgb\x03fgb\x00"), b.bytes()...)
+	for _, n := range buildIndex(leaves, 16) {
+		file = appendFloat64(file, n.minX)
+		file = appendFloat64(file, n.minY)
+		file = appendFloat64(file, n.maxX)
+		file = appendFloat64(file, n.maxY)
+		file = appendUint64(file, n.offset)
+	}
+	for _, i := range order {
+		file = append(file, features[i].buf...)
+	}
+	return file
+}
+
+// mixedFile has features with different geometry types (including one
+// without a geometry), Z values, and no spatial index.
+func mixedFile() []byte {
+	var features [][]byte
+	add := func(label string, geom func(b *builder) int) {
+		var props []byte
+		props = appendUint16(props, 0)
+		props = appendString(props, label)
+		b := newBuilder()
+		g := 0
+		if geom != nil {
+			g = geom(b)
+		}
+		b.finish(b.feature(g, props))
+		features = append(features, b.bytes())
+	}
+	add("point", func(b *builder) int {
+		return b.geometry([]float64{1, 2}, []float64{3}, nil, typePoint, nil)
+	})
+	add("line", func(b *builder) int {
+		return b.geometry([]float64{0, 0, 1, 1, 2, 0}, []float64{0, 1, 2}, nil, typeLineString, nil)
+	})
+	add("multipolygon", func(b *builder) int {
+		parts := []int{
+			b.geometry([]float64{0, 0, 1, 0, 0, 1, 0, 0}, []float64{1, 1, 1, 1}, nil, 0, nil),
+			b.geometry([]float64{2, 2, 3, 2, 2, 3, 2, 2}, []float64{2, 2, 2, 2}, nil, 0, nil),
+		}
+		return b.geometry(nil, nil, nil, typeMultiPolygon, parts)
+	})
+	add("nothing", nil)
+	add("collection", func(b *builder) int {
+		parts := []int{
+			b.geometry([]float64{5, 5}, []float64{5}, nil, typePoint, nil),
+			b.geometry([]float64{6, 6, 7, 7}, []float64{6, 7}, nil, typeLineString, nil),
+		}
+		return b.geometry(nil, nil, nil, typeGeometryCollection, parts)
+	})
+
+	b := newBuilder()
+	name := b.string("mixed")
+	cols := []int{b.column("label", colString, -1, true)}
+	hdr := b.header(header{
+		name:          name,
+		geometryType:  typeUnknown,
+		hasZ:          true,
+		columns:       b.offsets(cols),
+		featuresCount: uint64(len(features)),
+		indexNodeSize: 0,
+	})
+	b.finish(hdr)
+
+	file := append([]byte("fgb\x03fgb\x00"), b.bytes()...)
+	for _, f := range features {
+		file = append(file, f...)
+	}
+	return file
+}
+
+// builder wraps the reference FlatBuffers builder with helpers for building
+// the FlatGeobuf tables. The tables are built the same way as the code
+// generated by flatc from the FlatGeobuf schema (e.g. fields are added in
+// decreasing order of size, and default valued fields are omitted).
+type builder struct {
+	fb *flatbuffers.Builder
+}
+
+func newBuilder() *builder {
+	return &builder{flatbuffers.NewBuilder(0)}
+}
+
+func (b *builder) string(s string) int {
+	return int(b.fb.CreateString(s))
+}
+
+func (b *builder) bytesVector(p []byte) int {
+	return int(b.fb.CreateByteVector(p))
+}
+
+func (b *builder) float64s(vs []float64) int {
+	b.fb.StartVector(8, len(vs), 8)
+	for i := len(vs) - 1; i >= 0; i-- {
+		b.fb.PrependFloat64(vs[i])
+	}
+	return int(b.fb.EndVector(len(vs)))
+}
+
+func (b *builder) uint32s(vs []uint32) int {
+	b.fb.StartVector(4, len(vs), 4)
+	for i := len(vs) - 1; i >= 0; i-- {
+		b.fb.PrependUint32(vs[i])
+	}
+	return int(b.fb.EndVector(len(vs)))
+}
+
+func (b *builder) offsets(offs []int) int {
+	b.fb.StartVector(4, len(offs), 4)
+	for i := len(offs) - 1; i >= 0; i-- {
+		b.fb.PrependUOffsetT(flatbuffers.UOffsetT(offs[i]))
+	}
+	return int(b.fb.EndVector(len(offs)))
+}
+
+func (b *builder) offsetField(id, off int) {
+	b.fb.PrependUOffsetTSlot(id, flatbuffers.UOffsetT(off), 0)
+}
+
+func (b *builder) finish(root int) {
+	b.fb.FinishSizePrefixed(flatbuffers.UOffsetT(root))
+}
+
+func (b *builder) bytes() []byte {
+	return b.fb.FinishedBytes()
+}
+
+type header struct {
+	name, envelope, columns, crs int
+	geometryType                 uint8
+	hasZ                         bool
+	featuresCount                uint64
+	indexNodeSize                uint16
+}
+
+func (b *builder) header(h header) int {
+	b.fb.StartObject(14)
+	b.fb.PrependUint64Slot(8, h.featuresCount, 0)
+	b.offsetField(10, h.crs)
+	b.offsetField(7, h.columns)
+	b.offsetField(1, h.envelope)
+	b.offsetField(0, h.name)
+	b.fb.PrependUint16Slot(9, h.indexNodeSize, 16)
+	b.fb.PrependBoolSlot(3, h.hasZ, false)
+	b.fb.PrependByteSlot(2, h.geometryType, 0)
+	return int(b.fb.EndObject())
+}
+
+func (b *builder) column(name string, typ uint8, width int32, nullable bool) int {
+	nameOff := b.string(name)
+	b.fb.StartObject(11)
+	b.fb.PrependInt32Slot(4, width, -1)
+	b.offsetField(0, nameOff)
+	b.fb.PrependBoolSlot(7, nullable, true)
+	b.fb.PrependByteSlot(1, typ, 0)
+	return int(b.fb.EndObject())
+}
+
+func (b *builder) crs(org string, code int32, wkt int) int {
+	orgOff := b.string(org)
+	b.fb.StartObject(6)
+	b.offsetField(4, wkt)
+	b.fb.PrependInt32Slot(1, code, 0)
+	b.offsetField(0, orgOff)
+	return int(b.fb.EndObject())
+}
+
+func (b *builder) geometry(xy, z []float64, ends []uint32, typ uint8, parts []int) int {
+	var partsOff, zOff, xyOff, endsOff int
+	if len(parts) > 0 {
+		partsOff = b.offsets(parts)
+	}
+	if len(z) > 0 {
+		zOff = b.float64s(z)
+	}
+	if len(xy) > 0 {
+		xyOff = b.float64s(xy)
+	}
+	if len(ends) > 0 {
+		endsOff = b.uint32s(ends)
+	}
+	b.fb.StartObject(8)
+	b.offsetField(7, partsOff)
+	b.offsetField(2, zOff)
+	b.offsetField(1, xyOff)
+	b.offsetField(0, endsOff)
+	b.fb.PrependByteSlot(6, typ, 0)
+	return int(b.fb.EndObject())
+}
+
+func (b *builder) feature(geom int, props []byte) int {
+	propsOff := b.bytesVector(props)
+	b.fb.StartObject(3)
+	b.offsetField(1, propsOff)
+	b.offsetField(0, geom)
+	return int(b.fb.EndObject())
+}
+
+// node is a node of a packed Hilbert R-tree.
+type node struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+// buildIndex builds a packed Hilbert R-tree in the same way as the reference
+// implementation, and gives its nodes starting with the root.
+func buildIndex(leaves []node, nodeSize int) []node {
+	levels := [][]node{leaves}
+	for len(levels[len(levels)-1]) > 1 {
+		level := levels[len(levels)-1]
+		var parents []node
+		for i := 0; i < len(level); i += nodeSize {
+			p := node{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1), 0}
+			for j := i; j < i+nodeSize && j < len(level); j++ {
+				p.minX = math.Min(p.minX, level[j].minX)
+				p.minY = math.Min(p.minY, level[j].minY)
+				p.maxX = math.Max(p.maxX, level[j].maxX)
+				p.maxY = math.Max(p.maxY, level[j].maxY)
+			}
+			parents = append(parents, p)
+		}
+		levels = append(levels, parents)
+	}
+
+	// Non-leaf nodes refer to their first child by its index in the
+	// combined list of nodes.
+	var nodes []node
+	start := make([]int, len(levels))
+	for i := len(levels) - 1; i >= 0; i-- {
+		start[i] = len(nodes)
+		nodes = append(nodes, levels[i]...)
+	}
+	for i := 1; i < len(levels); i++ {
+		for j := range levels[i] {
+			nodes[start[i]+j].offset = uint64(start[i-1] + j*nodeSize)
+		}
+	}
+	return nodes
+}
+
+// hilbert is the public domain algorithm from http://threadlocalmutex.com/?p=126
+// (which is used by the reference implementation).
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+	return (interleave(i1) << 1) | interleave(i0)
+}
+
+func interleave(v uint32) uint32 {
+	v = (v | (v << 8)) & 0x00FF00FF
+	v = (v | (v << 4)) & 0x0F0F0F0F
+	v = (v | (v << 2)) & 0x33333333
+	v = (v | (v << 1)) & 0x55555555
+	return v
+}
+
+func appendUint16(dst []byte, v uint16) []byte {
+	var buf [2]byte
+	binary.LittleEndian.PutUint16(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+func appendUint64(dst []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+func appendFloat64(dst []byte, v float64) []byte {
+	return appendUint64(dst, math.Float64bits(v))
+}
+
+func appendString(dst []byte, s string) []byte {
+	return append(appendUint32(dst, uint32(len(s))), s...)
+}
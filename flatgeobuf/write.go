@@ -0,0 +1,213 @@
+package flatgeobuf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Writer writes features to a FlatGeobuf file, one at a time.
+//
+// If the file has a spatial index, then the features are buffered in memory
+// (since the index is written before the features, and depends on all of
+// them), and the file is written when the Writer is closed. Otherwise, the
+// features are streamed to the underlying io.Writer as they're written. In
+// both cases, Close must be called once all features have been written.
+type Writer struct {
+	w     *bufio.Writer
+	hdr   Header
+	ctype geom.CoordinatesType
+
+	// indexed holds the features (in the order they were written) if the
+	// file has a spatial index.
+	indexed []indexedFeature
+
+	closed bool
+	err    error
+}
+
+// indexedFeature is an encoded feature (including its size prefix), along
+// with the node used for it in the spatial index.
+type indexedFeature struct {
+	buf  []byte
+	node nodeItem
+}
+
+// NewWriter creates a Writer that writes a FlatGeobuf file with the given
+// header. The file has a spatial index if the header's IndexNodeSize is
+// non-zero (DefaultIndexNodeSize is a good choice). The header's
+// FeaturesCount is ignored, and is populated automatically for files with a
+// spatial index.
+func NewWriter(w io.Writer, hdr Header) (*Writer, error) {
+	if hdr.GeometryType > GeometryCollection {
+		return nil, fmt.Errorf("unsupported geometry type: %v", hdr.GeometryType)
+	}
+	if hdr.IndexNodeSize == 1 {
+		return nil, errors.New("index node size must be at least 2")
+	}
+	if err := validateColumns(hdr.Columns); err != nil {
+		return nil, err
+	}
+	hdr.Columns = append([]Column(nil), hdr.Columns...)
+	hdr.FeaturesCount = 0
+
+	fw := &Writer{
+		w:     bufio.NewWriter(w),
+		hdr:   hdr,
+		ctype: hdr.coordinatesType(),
+	}
+	if hdr.IndexNodeSize == 0 {
+		if err := fw.writeHeader(envelope{}); err != nil {
+			return nil, err
+		}
+	}
+	return fw, nil
+}
+
+func (w *Writer) writeHeader(env envelope) error {
+	buf := encodeHeader(w.hdr, env)
+	if _, err := w.w.Write(magic[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(sizePrefixed(buf))
+	return err
+}
+
+// sizePrefixed prefixes a FlatBuffers buffer with its size.
+func sizePrefixed(buf []byte) []byte {
+	prefixed := make([]byte, 4+len(buf))
+	binary.LittleEndian.PutUint32(prefixed, uint32(len(buf)))
+	copy(prefixed[4:], buf)
+	return prefixed
+}
+
+// Write writes a single feature. The feature's geometry must have the type
+// given in the header (unless the header's geometry type is Unknown). Its Z
+// and M values are written according to the header's HasZ and HasM fields
+// (missing values are written as 0, and extra values are dropped). Empty
+// geometries are written as features without a geometry.
+//
+// Properties of the feature are written to the columns with the same name.
+// Properties without a column and properties with nil values are omitted.
+// Integer columns accept integer values (and floats with integral values)
+// that are in range, Float and Double columns accept any numeric values,
+// JSON columns accept any value that can be marshalled to JSON, and DateTime
+// columns accept time.Time values and strings (in ISO 8601 format). The
+// remaining columns accept values with the obvious Go type.
+//
+// Once Write returns an error (other than an error caused by an incompatible
+// geometry or property), subsequent calls return the same error.
+func (w *Writer) Write(f geom.GeoJSONFeature) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return errors.New("write to closed flatgeobuf Writer")
+	}
+
+	fields := make([]fbField, featureProperties+1)
+	if !f.Geometry.IsEmpty() {
+		typ := geometryTypeOf(f.Geometry)
+		if w.hdr.GeometryType != Unknown && typ != w.hdr.GeometryType {
+			return fmt.Errorf("can't write %v geometry to %v file", typ, w.hdr.GeometryType)
+		}
+		includeType := w.hdr.GeometryType == Unknown
+		fields[featureGeometry] = fbTableRef(encodeGeometry(f.Geometry, w.ctype, includeType))
+	}
+	props, err := encodeProperties(nil, w.hdr.Columns, f.Properties)
+	if err != nil {
+		return err
+	}
+	if len(props) > 0 {
+		fields[featureProperties] = fbBytes(props)
+	}
+	buf := sizePrefixed(buildFlatBuffer(fields))
+
+	if w.hdr.IndexNodeSize != 0 {
+		w.indexed = append(w.indexed, indexedFeature{
+			buf:  buf,
+			node: envelopeNodeItem(f.Geometry.Envelope()),
+		})
+		return nil
+	}
+	if _, err := w.w.Write(buf); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Close finishes writing the file. For files with a spatial index, this is
+// when the header, index, and features are written. It doesn't close the
+// underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.hdr.IndexNodeSize != 0 {
+		if err := w.writeIndexed(); err != nil {
+			w.err = err
+			return err
+		}
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// writeIndexed writes the header, index, and features of a file with a
+// spatial index.
+func (w *Writer) writeIndexed() error {
+	extent := emptyNodeItem()
+	for _, f := range w.indexed {
+		extent.expand(f.node)
+	}
+	var env envelope
+	if !extent.isEmpty() {
+		env.env = geom.NewEnvelope(
+			geom.XY{X: extent.minX, Y: extent.minY},
+			geom.XY{X: extent.maxX, Y: extent.maxY},
+		)
+		env.valid = true
+	}
+
+	w.hdr.FeaturesCount = uint64(len(w.indexed))
+	if err := w.writeHeader(env); err != nil {
+		return err
+	}
+	if len(w.indexed) == 0 {
+		return nil
+	}
+
+	hilbertSort(w.indexed, extent)
+	leaves := make([]nodeItem, len(w.indexed))
+	var offset uint64
+	for i, f := range w.indexed {
+		leaves[i] = f.node
+		leaves[i].offset = offset
+		offset += uint64(len(f.buf))
+	}
+	var buf []byte
+	for _, node := range buildIndex(leaves, int(w.hdr.IndexNodeSize)) {
+		buf = node.encode(buf)
+	}
+	if _, err := w.w.Write(buf); err != nil {
+		return err
+	}
+	for _, f := range w.indexed {
+		if _, err := w.w.Write(f.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
@@ -84,6 +85,39 @@ func hexStringToBytes(s string) ([]byte, error) {
 	return buf, nil
 }
 
+func CheckHexEWKBParse(t *testing.T, pg PostGIS, candidates []string) {
+	var any bool
+	for i, ewkb := range candidates {
+		if _, err := hex.DecodeString(ewkb); err != nil {
+			continue
+		}
+		any = true
+		t.Run(fmt.Sprintf("CheckHexEWKBParse_%d", i), func(t *testing.T) {
+			g, sfErr := geom.UnmarshalHexEWKB(ewkb)
+			isValid, srid, reason := pg.EWKBIsValidWithReason(t, ewkb)
+			if (sfErr == nil) != isValid {
+				t.Logf("EWKB: %v", ewkb)
+				t.Logf("SimpleFeatures err: %v", sfErr)
+				t.Logf("PostGIS IsValid: %v", isValid)
+				t.Logf("PostGIS Reason: %v", reason)
+				t.Errorf("mismatch")
+				return
+			}
+			if sfErr == nil && g.SRID() != srid {
+				t.Logf("EWKB: %v", ewkb)
+				t.Logf("SimpleFeatures SRID: %v", g.SRID())
+				t.Logf("PostGIS SRID: %v", srid)
+				t.Errorf("mismatch")
+			}
+		})
+	}
+	if !any {
+		// We know there are some some valid hex strings, so if this happens
+		// then something is wrong with the extraction or conversion logic.
+		t.Errorf("could not extract any EWKBs")
+	}
+}
+
 func CheckGeoJSONParse(t *testing.T, pg PostGIS, candidates []string) {
 	var any bool
 	for i, geojson := range candidates {
@@ -178,6 +212,62 @@ func CheckGeoJSON(t *testing.T, want UnaryResult, g geom.Geometry) {
 	})
 }
 
+func CheckTWKB(t *testing.T, pg PostGIS, g geom.Geometry) {
+	t.Run("CheckTWKB", func(t *testing.T) {
+		for _, tt := range []struct {
+			prec   int
+			precZM int
+			size   bool
+			bbox   bool
+		}{
+			{0, 0, false, false},
+			{3, 1, false, false},
+			{-1, 0, false, false},
+			{2, 2, true, true},
+		} {
+			opts := []geom.TWKBOption{
+				geom.TWKBPrecisionZ(tt.precZM),
+				geom.TWKBPrecisionM(tt.precZM),
+			}
+			if tt.size {
+				opts = append(opts, geom.TWKBIncludeSize)
+			}
+			if tt.bbox {
+				opts = append(opts, geom.TWKBIncludeBBox)
+			}
+			got, err := geom.AsTWKB(g, tt.prec, opts...)
+			if err != nil {
+				t.Fatalf("could not marshal twkb: %v", err)
+			}
+			want := pg.AsTWKB(t, g, tt.prec, tt.precZM, tt.precZM, tt.size, tt.bbox)
+			if !bytes.Equal(got, want) {
+				t.Logf("prec: %d, precZM: %d, size: %t, bbox: %t", tt.prec, tt.precZM, tt.size, tt.bbox)
+				t.Logf("got:  %v", hex.EncodeToString(got))
+				t.Logf("want: %v", hex.EncodeToString(want))
+				t.Error("mismatch")
+				continue
+			}
+
+			// Check that PostGIS parses the TWKB to the same geometry as
+			// simplefeatures does.
+			gotGeom, err := geom.UnmarshalTWKB(bytes.NewReader(got), geom.DisableAllValidations)
+			if err != nil {
+				t.Fatalf("could not unmarshal twkb: %v", err)
+			}
+			wantGeom, err := geom.UnmarshalWKB(bytes.NewReader(pg.GeomFromTWKB(t, got)), geom.DisableAllValidations)
+			if err != nil {
+				t.Fatalf("could not unmarshal wkb from postgis: %v", err)
+			}
+			if !gotGeom.EqualsExact(wantGeom) {
+				t.Logf("twkb: %v", hex.EncodeToString(got))
+				t.Logf("got:  %v", gotGeom.AsText())
+				t.Logf("want: %v", wantGeom.AsText())
+				t.Error("mismatch")
+			}
+		}
+	})
+}
+
 func CheckIsEmpty(t *testing.T, want UnaryResult, g geom.Geometry) {
 	t.Run("CheckIsEmpty", func(t *testing.T) {
 		got := g.IsEmpty()
@@ -287,8 +377,9 @@ func CheckBoundary(t *testing.T, want UnaryResult, g geom.Geometry) {
 
 func CheckConvexHull(t *testing.T, want UnaryResult, g geom.Geometry) {
 	t.Run("CheckConvexHull", func(t *testing.T) {
+		// Z and M values are not retained by ConvexHull.
 		got := g.ConvexHull()
-		want := want.ConvexHull
+		want := want.ConvexHull.Force2D()
 		if !got.EqualsExact(want, geom.IgnoreOrder) {
 			t.Logf("got:  %v", got.AsText())
 			t.Logf("want: %v", want.AsText())
@@ -393,7 +484,7 @@ func CheckIntersection(t *testing.T, pg PostGIS, g1, g2 geom.Geometry) {
 	t.Run("CheckIntersection", func(t *testing.T) {
 		got, err := g1.Intersection(g2)
 		if err != nil {
-			return // operation not implemented
+			t.Fatalf("could not calculate intersection: %v", err)
 		}
 		want := pg.Intersection(t, g1, g2)
 
@@ -421,6 +512,129 @@ func CheckIntersection(t *testing.T, pg PostGIS, g1, g2 geom.Geometry) {
 	})
 }
 
+func CheckOverlay(t *testing.T, pg PostGIS, g1, g2 geom.Geometry) {
+	for _, op := range []struct {
+		name string
+		sf   func(geom.Geometry, geom.Geometry) (geom.Geometry, error)
+		pg   func(*testing.T, geom.Geometry, geom.Geometry) geom.Geometry
+	}{
+		{"Union", geom.Geometry.Union, pg.Union},
+		{"Difference", geom.Geometry.Difference, pg.Difference},
+		{"SymmetricDifference", geom.Geometry.SymmetricDifference, pg.SymDifference},
+	} {
+		t.Run("Check"+op.name, func(t *testing.T) {
+			got, err := op.sf(g1, g2)
+			if err != nil {
+				t.Fatalf("could not calculate %s: %v", op.name, err)
+			}
+			want := op.pg(t, g1, g2)
+
+			if got.IsEmpty() && want.IsEmpty() {
+				return // Both empty, so they match.
+			}
+
+			if got.IsGeometryCollection() || want.IsGeometryCollection() {
+				// GeometryCollections are not supported by ST_Equals.
+				return
+			}
+
+			// As with intersection, the overlay operations aren't implemented
+			// in exactly the same way as PostGIS, so TolerantEquals is used.
+			if !pg.TolerantEquals(t, got, want) {
+				t.Logf("g1:   %s", g1.AsText())
+				t.Logf("g2:   %s", g2.AsText())
+				t.Logf("got:  %s", got.AsText())
+				t.Logf("want: %s", want.AsText())
+				t.Error("mismatch")
+			}
+		})
+	}
+}
+
+func CheckDistance(t *testing.T, pg PostGIS, g1, g2 geom.Geometry) {
+	t.Run("CheckDistance", func(t *testing.T) {
+		if g1.IsEmpty() || g2.IsEmpty() {
+			return // PostGIS gives NULL for empty geometries.
+		}
+		got, ok := g1.Distance(g2)
+		if !ok {
+			t.Fatal("distance unexpectedly undefined")
+		}
+		want := pg.Distance(t, g1, g2)
+		const eps = 0.000000001
+		if math.Abs(got-want) > eps {
+			t.Logf("g1:   %s", g1.AsText())
+			t.Logf("g2:   %s", g2.AsText())
+			t.Logf("got:  %v", got)
+			t.Logf("want: %v", want)
+			t.Error("mismatch")
+		}
+
+		pt1, pt2, ok := g1.ClosestPoints(g2)
+		if !ok {
+			t.Fatal("closest points unexpectedly undefined")
+		}
+		if d := pt1.XY().Sub(pt2.XY()).Length(); math.Abs(d-want) > eps {
+			t.Logf("closest points: %s %s", pt1.AsText(), pt2.AsText())
+			t.Error("closest points distance mismatch")
+		}
+
+		if !g1.DWithin(g2, want+eps) {
+			t.Error("expected to be within distance")
+		}
+		if want > eps && g1.DWithin(g2, want-eps) {
+			t.Error("expected not to be within distance")
+		}
+	})
+}
+
+func CheckSimplify(t *testing.T, want UnaryResult, g geom.Geometry) {
+	t.Run("CheckSimplify", func(t *testing.T) {
+		if !want.IsValid {
+			return
+		}
+		for _, tol := range []float64{0, 0.1, 1, 10} {
+			// Simplify and SimplifyVW may give invalid results, but should
+			// never panic.
+			_, _ = g.Simplify(tol)
+			_, _ = g.SimplifyVW(tol)
+
+			got, err := g.SimplifyPreserveTopology(tol)
+			if err != nil {
+				t.Logf("g:   %v", g.AsText())
+				t.Logf("tol: %v", tol)
+				t.Errorf("could not simplify: %v", err)
+				continue
+			}
+			if got.Dimension() != g.Dimension() && !got.IsEmpty() {
+				t.Logf("g:   %v", g.AsText())
+				t.Logf("got: %v", got.AsText())
+				t.Error("dimension changed")
+			}
+		}
+	})
+}
+
+func CheckRelate(t *testing.T, pg PostGIS, g1, g2 geom.Geometry) {
+	t.Run("CheckRelate", func(t *testing.T) {
+		if g1.IsGeometryCollection() || g2.IsGeometryCollection() {
+			return // PostGIS doesn't support GeometryCollections in ST_Relate.
+		}
+		got, err := geom.Relate(g1, g2)
+		if err != nil {
+			t.Fatalf("could not calculate relate: %v", err)
+		}
+		want := pg.Relate(t, g1, g2)
+		if got != want {
+			t.Logf("g1:   %s", g1.AsText())
+			t.Logf("g2:   %s", g2.AsText())
+			t.Logf("got:  %v", got)
+			t.Logf("want: %v", want)
+			t.Error("mismatch")
+		}
+	})
+}
+
 func CheckArea(t *testing.T, want UnaryResult, g geom.Geometry) {
 	t.Run("CheckArea", func(t *testing.T) {
 		got := g.Area()
@@ -436,8 +650,9 @@ func CheckArea(t *testing.T, want UnaryResult, g geom.Geometry) {
 
 func CheckCentroid(t *testing.T, want UnaryResult, g geom.Geometry) {
 	t.Run("CheckCentroid", func(t *testing.T) {
+		// Z and M values are not retained by Centroid.
 		got, ok := g.Centroid()
-		want := want.Cetroid
+		want := want.Cetroid.Force2D()
 
 		if !ok {
 			if !want.IsEmpty() {
@@ -468,3 +683,27 @@ func CheckReverse(t *testing.T, want UnaryResult, g geom.Geometry) {
 		}
 	})
 }
+
+func CheckBuffer(t *testing.T, want UnaryResult, g geom.Geometry) {
+	t.Run("CheckBuffer", func(t *testing.T) {
+		if !want.BufferArea.Valid {
+			return
+		}
+		got, err := g.Buffer(1)
+		if err != nil {
+			t.Fatalf("could not calculate buffer: %v", err)
+		}
+
+		// The curves in the buffer are approximated slightly differently
+		// compared to PostGIS, so only the area is compared (and only
+		// approximately).
+		gotArea := got.Area()
+		wantArea := want.BufferArea.Float64
+		if math.Abs(gotArea-wantArea) > 0.01*wantArea {
+			t.Logf("g:    %v", g.AsText())
+			t.Logf("got:  %v", gotArea)
+			t.Logf("want: %v", wantArea)
+			t.Error("mismatch")
+		}
+	})
+}
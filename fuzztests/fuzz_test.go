@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"database/sql"
 	"fmt"
 	"go/ast"
@@ -24,6 +23,7 @@ func TestFuzz(t *testing.T) {
 
 	CheckWKTParse(t, pg, candidates)
 	CheckWKBParse(t, pg, candidates)
+	CheckHexEWKBParse(t, pg, candidates)
 	CheckGeoJSONParse(t, pg, candidates)
 
 	geoms := convertToGeometries(t, candidates)
@@ -41,6 +41,7 @@ func TestFuzz(t *testing.T) {
 			CheckWKT(t, want, g)
 			CheckWKB(t, want, g)
 			CheckGeoJSON(t, want, g)
+			CheckTWKB(t, pg, g)
 			CheckIsEmpty(t, want, g)
 			CheckDimension(t, want, g)
 			CheckEnvelope(t, want, g)
@@ -53,6 +54,8 @@ func TestFuzz(t *testing.T) {
 			CheckArea(t, want, g)
 			CheckCentroid(t, want, g)
 			CheckReverse(t, want, g)
+			CheckBuffer(t, want, g)
+			CheckSimplify(t, want, g)
 		})
 	}
 	for i, g1 := range geoms {
@@ -62,6 +65,9 @@ func TestFuzz(t *testing.T) {
 				CheckEquals(t, pg, g1, g2)
 				CheckIntersects(t, pg, g1, g2)
 				CheckIntersection(t, pg, g1, g2)
+				CheckOverlay(t, pg, g1, g2)
+				CheckDistance(t, pg, g1, g2)
+				CheckRelate(t, pg, g1, g2)
 			})
 		}
 	}
@@ -138,11 +144,7 @@ func convertToGeometries(t *testing.T, candidates []string) []geom.Geometry {
 
 	oldCount := len(geoms)
 	for _, c := range candidates {
-		buf, err := hexStringToBytes(c)
-		if err != nil {
-			continue
-		}
-		g, err := geom.UnmarshalWKB(bytes.NewReader(buf))
+		g, err := geom.UnmarshalHexWKB(c)
 		if err == nil {
 			geoms = append(geoms, g)
 		}
@@ -26,6 +26,7 @@ type UnaryResult struct {
 	Area       float64
 	Cetroid    geom.Geometry
 	Reverse    geom.Geometry
+	BufferArea sql.NullFloat64
 }
 
 func (p BatchPostGIS) Unary(g geom.Geometry) (UnaryResult, error) {
@@ -83,7 +84,14 @@ func (p BatchPostGIS) Unary(g geom.Geometry) (UnaryResult, error) {
 		ST_Length(ST_GeomFromWKB($1)),
 		ST_Area(ST_GeomFromWKB($1)),
 		ST_AsBinary(ST_Centroid(ST_GeomFromWKB($1))),
-		ST_AsBinary(ST_Reverse(ST_GeomFromWKB($1)))
+		ST_AsBinary(ST_Reverse(ST_GeomFromWKB($1))),
+
+		-- Buffer gives undefined results for invalid geometries.
+		CASE
+			WHEN ST_IsValid(ST_GeomFromWKB($1))
+			THEN ST_Area(ST_Buffer(ST_GeomFromWKB($1), 1))
+			ELSE NULL
+		END
 		`, g, isNestedGeometryCollection(g),
 	).Scan(
 		&result.AsText,
@@ -101,6 +109,7 @@ func (p BatchPostGIS) Unary(g geom.Geometry) (UnaryResult, error) {
 		&result.Area,
 		&result.Cetroid,
 		&result.Reverse,
+		&result.BufferArea,
 	)
 	return result, err
 }
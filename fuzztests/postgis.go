@@ -44,6 +44,25 @@ func (p PostGIS) WKBIsValidWithReason(t *testing.T, wkb string) (bool, string) {
 	return isValid, reason
 }
 
+func (p PostGIS) EWKBIsValidWithReason(t *testing.T, ewkb string) (bool, int, string) {
+	var isValid bool
+	var srid int
+	err := p.db.QueryRow(`
+		SELECT ST_IsValid(g), ST_SRID(g)
+		FROM (SELECT ST_GeomFromEWKB(decode($1, 'hex')) AS g) AS q`,
+		ewkb,
+	).Scan(&isValid, &srid)
+	if err != nil {
+		return false, 0, err.Error()
+	}
+	var reason string
+	err = p.db.QueryRow(`SELECT ST_IsValidReason(ST_GeomFromEWKB(decode($1, 'hex')))`, ewkb).Scan(&reason)
+	if err != nil {
+		return false, 0, err.Error()
+	}
+	return isValid, srid, reason
+}
+
 func (p PostGIS) GeoJSONIsValidWithReason(t *testing.T, geojson string) (bool, string) {
 	var isValid bool
 	err := p.db.QueryRow(`SELECT ST_IsValid(ST_GeomFromGeoJSON($1))`, geojson).Scan(&isValid)
@@ -237,6 +256,30 @@ func (p PostGIS) Intersection(t *testing.T, g1, g2 geom.Geometry) geom.Geometry
 	return p.geomBinary(t, g1, g2, "ST_Intersection")
 }
 
+func (p PostGIS) Union(t *testing.T, g1, g2 geom.Geometry) geom.Geometry {
+	return p.geomBinary(t, g1, g2, "ST_Union")
+}
+
+func (p PostGIS) Difference(t *testing.T, g1, g2 geom.Geometry) geom.Geometry {
+	return p.geomBinary(t, g1, g2, "ST_Difference")
+}
+
+func (p PostGIS) SymDifference(t *testing.T, g1, g2 geom.Geometry) geom.Geometry {
+	return p.geomBinary(t, g1, g2, "ST_SymDifference")
+}
+
+func (p PostGIS) Distance(t *testing.T, g1, g2 geom.Geometry) float64 {
+	var d float64
+	p.binary(t, g1, g2, "ST_Distance", &d)
+	return d
+}
+
+func (p PostGIS) Relate(t *testing.T, g1, g2 geom.Geometry) string {
+	var m string
+	p.binary(t, g1, g2, "ST_Relate", &m)
+	return m
+}
+
 func (p PostGIS) Length(t *testing.T, g geom.Geometry) float64 {
 	return p.float64Func(t, g, "ST_Length")
 }
@@ -252,3 +295,25 @@ func (p PostGIS) Centroid(t *testing.T, g geom.Geometry) geom.Geometry {
 func (p PostGIS) Reverse(t *testing.T, g geom.Geometry) geom.Geometry {
 	return p.geomFunc(t, g, "ST_Reverse")
 }
+
+func (p PostGIS) AsTWKB(t *testing.T, g geom.Geometry, prec, precZ, precM int, size, bbox bool) []byte {
+	var buf []byte
+	if err := p.db.QueryRow(
+		"SELECT ST_AsTWKB(ST_GeomFromWKB($1), $2, $3, $4, $5, $6)",
+		g, prec, precZ, precM, size, bbox,
+	).Scan(&buf); err != nil {
+		t.Fatalf("pg error: %v", err)
+	}
+	return buf
+}
+
+// GeomFromTWKB parses TWKB using PostGIS, giving the result as WKB.
+func (p PostGIS) GeomFromTWKB(t *testing.T, twkb []byte) []byte {
+	var buf []byte
+	if err := p.db.QueryRow(
+		"SELECT ST_AsBinary(ST_GeomFromTWKB($1))", twkb,
+	).Scan(&buf); err != nil {
+		t.Fatalf("pg error: %v", err)
+	}
+	return buf
+}
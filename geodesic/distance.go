@@ -0,0 +1,320 @@
+package geodesic
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// GeometryDistance gives the shortest distance between two geometries, in the
+// same way as the PostGIS ST_Distance function does for geography values. It
+// returns false if either geometry is empty.
+//
+// The distance is zero if the geometries intersect (including when one is
+// inside the area of the other). Otherwise, the closest points are found
+// treating the edges of each geometry as great circle arcs on a sphere, and
+// the distance between them is found on the ellipsoid.
+func (e Ellipsoid) GeometryDistance(g1, g2 geom.Geometry) (float64, bool) {
+	if g1.IsEmpty() || g2.IsEmpty() {
+		return 0, false
+	}
+	var p1, p2 sphereParts
+	p1.add(g1)
+	p2.add(g2)
+	if p1.intersects(p2) {
+		return 0, true
+	}
+
+	best := math.Inf(1)
+	update := func(a, b geom.XY) {
+		best = math.Min(best, e.Distance(a, b))
+	}
+	for _, a := range p1.points {
+		for _, b := range p2.points {
+			update(a.xy, b.xy)
+		}
+		for _, s := range p2.segs {
+			update(a.xy, s.closestPoint(a).xy)
+		}
+	}
+	for _, s := range p1.segs {
+		for _, b := range p2.points {
+			update(s.closestPoint(b).xy, b.xy)
+		}
+		for _, t := range p2.segs {
+			// The segments don't intersect, so the closest points are at
+			// the endpoint of at least one of them.
+			update(s.a.xy, t.closestPoint(s.a).xy)
+			update(s.b.xy, t.closestPoint(s.b).xy)
+			update(s.closestPoint(t.a).xy, t.a.xy)
+			update(s.closestPoint(t.b).xy, t.b.xy)
+		}
+	}
+	return best, true
+}
+
+// spherePoint is a longitude/latitude point, along with its position as a
+// unit vector on the sphere.
+type spherePoint struct {
+	xy geom.XY
+	v  vec3
+}
+
+func newSpherePoint(xy geom.XY) spherePoint {
+	sinLat, cosLat := sincosd(xy.Y)
+	sinLon, cosLon := sincosd(xy.X)
+	return spherePoint{xy, vec3{cosLat * cosLon, cosLat * sinLon, sinLat}}
+}
+
+func newSpherePointFromVec(v vec3) spherePoint {
+	v = v.unit()
+	lat := atan2d(v[2], math.Hypot(v[0], v[1]))
+	lon := atan2d(v[1], v[0])
+	return spherePoint{geom.XY{X: lon, Y: lat}, v}
+}
+
+// sphereSegment is a great circle arc between two points (taking the shorter
+// way around).
+type sphereSegment struct {
+	a, b spherePoint
+	n    vec3 // normal to the plane of the great circle
+}
+
+func newSphereSegment(a, b geom.XY) sphereSegment {
+	s := sphereSegment{a: newSpherePoint(a), b: newSpherePoint(b)}
+	s.n = s.a.v.cross(s.b.v)
+	return s
+}
+
+// contains checks if a point that's on the segment's great circle is
+// between the segment's endpoints.
+func (s sphereSegment) contains(v vec3) bool {
+	const eps = 1e-15
+	return s.a.v.cross(v).dot(s.n) >= -eps && v.cross(s.b.v).dot(s.n) >= -eps
+}
+
+// onSegment checks if a point is on the segment.
+func (s sphereSegment) onSegment(p spherePoint) bool {
+	if p.v == s.a.v || p.v == s.b.v {
+		return true
+	}
+	if s.n.norm() == 0 {
+		return false
+	}
+	const eps = 1e-15
+	return math.Abs(p.v.dot(s.n.unit())) <= eps && s.contains(p.v)
+}
+
+// closestPoint finds the point on the segment that's closest to p.
+func (s sphereSegment) closestPoint(p spherePoint) spherePoint {
+	if s.n.norm() != 0 {
+		n := s.n.unit()
+		q := p.v.sub(n.scale(p.v.dot(n)))
+		if q.norm() != 0 && s.contains(q) {
+			return newSpherePointFromVec(q)
+		}
+	}
+	if p.v.sub(s.a.v).norm() <= p.v.sub(s.b.v).norm() {
+		return s.a
+	}
+	return s.b
+}
+
+// intersects checks if two segments have any points in common.
+func (s sphereSegment) intersects(t sphereSegment) bool {
+	if s.onSegment(t.a) || s.onSegment(t.b) || t.onSegment(s.a) || t.onSegment(s.b) {
+		return true
+	}
+	l := s.n.cross(t.n)
+	if l.norm() == 0 {
+		// The segments are on the same great circle (or degenerate), and
+		// neither contains an endpoint of the other.
+		return false
+	}
+	for _, v := range [2]vec3{l, l.scale(-1)} {
+		if s.contains(v) && t.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sphereParts are the parts of a geometry, with each edge treated as a great
+// circle arc.
+type sphereParts struct {
+	points []spherePoint
+	segs   []sphereSegment
+	polys  [][][]sphereSegment // the segments of each ring of each polygon
+}
+
+func (p *sphereParts) add(g geom.Geometry) {
+	switch {
+	case g.IsEmpty():
+	case g.IsPoint():
+		p.points = append(p.points, newSpherePoint(g.AsPoint().XY()))
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		for i := 0; i < mp.NumPoints(); i++ {
+			p.add(mp.PointN(i).AsGeometry())
+		}
+	case g.IsLine():
+		p.addLineString(g.AsLine().AsLineString())
+	case g.IsLineString():
+		p.addLineString(g.AsLineString())
+	case g.IsMultiLineString():
+		mls := g.AsMultiLineString()
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			p.addLineString(mls.LineStringN(i))
+		}
+	case g.IsPolygon():
+		p.addPolygon(g.AsPolygon())
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			p.addPolygon(mp.PolygonN(i))
+		}
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		for i := 0; i < gc.NumGeometries(); i++ {
+			p.add(gc.GeometryN(i))
+		}
+	}
+}
+
+func (p *sphereParts) addLineString(ls geom.LineString) []sphereSegment {
+	n := ls.NumPoints()
+	if n == 1 {
+		p.points = append(p.points, newSpherePoint(ls.PointN(0).XY()))
+	}
+	var segs []sphereSegment
+	for i := 0; i+1 < n; i++ {
+		segs = append(segs, newSphereSegment(ls.PointN(i).XY(), ls.PointN(i+1).XY()))
+	}
+	p.segs = append(p.segs, segs...)
+	return segs
+}
+
+func (p *sphereParts) addPolygon(poly geom.Polygon) {
+	rings := [][]sphereSegment{p.addLineString(poly.ExteriorRing())}
+	for i := 0; i < poly.NumInteriorRings(); i++ {
+		rings = append(rings, p.addLineString(poly.InteriorRingN(i)))
+	}
+	p.polys = append(p.polys, rings)
+}
+
+// inArea checks if a point is inside the area of any of the polygons, i.e.
+// inside the exterior ring of a polygon but not inside any of its interior
+// rings.
+func (p sphereParts) inArea(pt spherePoint) bool {
+	for _, rings := range p.polys {
+		var inside bool
+		for _, ring := range rings {
+			if windingAngle(pt, ring) > math.Pi {
+				inside = !inside
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// windingAngle gives the absolute value of the total angle subtended at a
+// point by a ring. It's 2π if the point is inside the ring, and 0 if it's
+// outside. The point must not be on the ring.
+func windingAngle(pt spherePoint, segs []sphereSegment) float64 {
+	var sum float64
+	for _, s := range segs {
+		y := pt.v.dot(s.n)
+		x := s.a.v.dot(s.b.v) - pt.v.dot(s.a.v)*pt.v.dot(s.b.v)
+		sum += math.Atan2(y, x)
+	}
+	return math.Abs(sum)
+}
+
+// intersects checks if two sets of parts have any points in common, or if
+// any part of one is in the area of the other.
+func (p sphereParts) intersects(o sphereParts) bool {
+	for _, a := range p.points {
+		for _, b := range o.points {
+			if a.v == b.v {
+				return true
+			}
+		}
+		for _, s := range o.segs {
+			if s.onSegment(a) {
+				return true
+			}
+		}
+	}
+	for _, b := range o.points {
+		for _, s := range p.segs {
+			if s.onSegment(b) {
+				return true
+			}
+		}
+	}
+	for _, s := range p.segs {
+		for _, t := range o.segs {
+			if s.intersects(t) {
+				return true
+			}
+		}
+	}
+
+	// There are no boundary intersections, so each part is either entirely
+	// inside or entirely outside of the other's area. Checking a single
+	// point of each part is enough.
+	for _, pt := range p.points {
+		if o.inArea(pt) {
+			return true
+		}
+	}
+	for _, s := range p.segs {
+		if o.inArea(s.a) {
+			return true
+		}
+	}
+	for _, pt := range o.points {
+		if p.inArea(pt) {
+			return true
+		}
+	}
+	for _, s := range o.segs {
+		if p.inArea(s.a) {
+			return true
+		}
+	}
+	return false
+}
+
+type vec3 [3]float64
+
+func (v vec3) dot(o vec3) float64 {
+	return v[0]*o[0] + v[1]*o[1] + v[2]*o[2]
+}
+
+func (v vec3) cross(o vec3) vec3 {
+	return vec3{
+		v[1]*o[2] - v[2]*o[1],
+		v[2]*o[0] - v[0]*o[2],
+		v[0]*o[1] - v[1]*o[0],
+	}
+}
+
+func (v vec3) sub(o vec3) vec3 {
+	return vec3{v[0] - o[0], v[1] - o[1], v[2] - o[2]}
+}
+
+func (v vec3) scale(s float64) vec3 {
+	return vec3{v[0] * s, v[1] * s, v[2] * s}
+}
+
+func (v vec3) norm() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+func (v vec3) unit() vec3 {
+	return v.scale(1 / v.norm())
+}
@@ -0,0 +1,605 @@
+// Package geodesic calculates lengths, areas, and distances on the surface of
+// an ellipsoid, using the algorithms described in Karney, C. F. F. (2013),
+// "Algorithms for geodesics", Journal of Geodesy 87, 43-55.
+//
+// Geometries are expected to have longitude/latitude coordinates (in degrees,
+// with the longitude stored in X and the latitude stored in Y). Results are
+// given in the units of the ellipsoid's semi-major axis (metres for WGS84).
+// The calculations are equivalent to those performed by PostGIS for its
+// geography type.
+package geodesic
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Ellipsoid is an ellipsoid of revolution, along with some precomputed
+// values that are used when solving geodesic problems on it.
+type Ellipsoid struct {
+	a, f float64 // semi-major axis, flattening
+
+	f1, e2, ep2, n, b float64
+	c2                float64 // authalic radius squared
+	etol2             float64
+
+	a3x [nA3x]float64
+	c3x [nC3x]float64
+	c4x [nC4x]float64
+}
+
+// WGS84 is the ellipsoid used by the World Geodetic System 1984 (and GPS).
+var WGS84 = NewEllipsoid(6378137, 1/298.257223563)
+
+// NewEllipsoid creates an Ellipsoid with the given semi-major axis (the
+// equatorial radius) and flattening. A flattening of zero gives a sphere, and
+// a negative flattening gives a prolate ellipsoid.
+func NewEllipsoid(semiMajorAxis, flattening float64) Ellipsoid {
+	e := Ellipsoid{a: semiMajorAxis, f: flattening}
+	e.f1 = 1 - e.f
+	e.e2 = e.f * (2 - e.f)
+	e.ep2 = e.e2 / sq(e.f1)
+	e.n = e.f / (2 - e.f)
+	e.b = e.a * e.f1
+
+	var t float64
+	switch {
+	case e.e2 == 0:
+		t = 1
+	case e.e2 > 0:
+		t = math.Atanh(math.Sqrt(e.e2)) / math.Sqrt(e.e2)
+	default:
+		t = math.Atan(math.Sqrt(-e.e2)) / math.Sqrt(-e.e2)
+	}
+	e.c2 = (sq(e.a) + sq(e.b)*t) / 2
+
+	e.etol2 = 0.1 * tol2 / math.Sqrt(
+		math.Max(0.001, math.Abs(e.f))*math.Min(1, 1-e.f/2)/2)
+
+	e.a3x = a3coeff(e.n)
+	e.c3x = c3coeff(e.n)
+	e.c4x = c4coeff(e.n)
+	return e
+}
+
+// SemiMajorAxis gives the equatorial radius of the ellipsoid.
+func (e Ellipsoid) SemiMajorAxis() float64 {
+	return e.a
+}
+
+// Flattening gives the flattening of the ellipsoid.
+func (e Ellipsoid) Flattening() float64 {
+	return e.f
+}
+
+// SurfaceArea gives the total surface area of the ellipsoid.
+func (e Ellipsoid) SurfaceArea() float64 {
+	return 4 * math.Pi * e.c2
+}
+
+// InverseResult is the solution to the inverse geodesic problem, i.e. the
+// shortest path between two points.
+type InverseResult struct {
+	// Distance is the length of the geodesic between the two points.
+	Distance float64
+
+	// Azimuth1 and Azimuth2 are the azimuths (forward bearings, in degrees
+	// clockwise from north) of the geodesic at the first and second points.
+	Azimuth1, Azimuth2 float64
+
+	// area is the area between the geodesic and the equator.
+	area float64
+}
+
+// Inverse solves the inverse geodesic problem, finding the shortest path
+// between two longitude/latitude points. The result is accurate to within 15
+// nanometres for the WGS84 ellipsoid.
+func (e Ellipsoid) Inverse(from, to geom.XY) InverseResult {
+	return e.inverse(from.Y, from.X, to.Y, to.X)
+}
+
+// Distance gives the length of the shortest path (along the surface of the
+// ellipsoid) between two longitude/latitude points.
+func (e Ellipsoid) Distance(from, to geom.XY) float64 {
+	return e.Inverse(from, to).Distance
+}
+
+var (
+	tiny    = math.Sqrt(math.SmallestNonzeroFloat64 * (1 << 52))
+	tol0    = math.Nextafter(1, 2) - 1
+	tol1    = 200 * tol0
+	tol2    = math.Sqrt(tol0)
+	tolb    = tol0 * tol2
+	xthresh = 1000 * tol2
+)
+
+const (
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+)
+
+// inverse is a port of the GeographicLib Geodesic::GenInverse method,
+// calculating the distance, azimuths, and area.
+func (e Ellipsoid) inverse(lat1, lon1, lat2, lon2 float64) InverseResult {
+	// Compute the longitude difference (exactly), and make it positive.
+	lon12, lon12s := angDiff(lon1, lon2)
+	lonsign := 1.0
+	if lon12 < 0 {
+		lonsign = -1
+	}
+	lon12 = lonsign * angRound(lon12)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * degToRad
+	var slam12, clam12 float64
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+
+	// Swap the points so that the first is furthest from the equator, and
+	// make its latitude negative.
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) {
+		swapp = -1
+		lonsign *= -1
+		lat1, lat2 = lat2, lat1
+	}
+	latsign := -1.0
+	if lat1 < 0 {
+		latsign = 1
+	}
+	lat1 *= latsign
+	lat2 *= latsign
+
+	// Reduced latitudes.
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1, cbet1 = norm(e.f1*sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2, cbet2 = norm(e.f1*sbet2, cbet2)
+	cbet2 = math.Max(tiny, cbet2)
+
+	// Ensure that the latitudes are treated as exactly equal (or opposite)
+	// if they are up to rounding error.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+
+	dn1 := math.Sqrt(1 + e.ep2*sq(sbet1))
+	dn2 := math.Sqrt(1 + e.ep2*sq(sbet2))
+
+	var (
+		c1a [nC1 + 1]float64
+		c2a [nC2 + 1]float64
+		c3a [nC3]float64
+
+		s12x, m12x, sig12 float64
+		salp1, calp1      float64
+		salp2, calp2      float64
+		ssig1, csig1      float64
+		ssig2, csig2      float64
+		somg12, comg12    float64
+		eps, domg12, dnm  float64
+	)
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// The endpoints are on a single meridian (or the first point is at a
+		// pole), so the geodesic is along the meridian (unless it's more
+		// than half way around the ellipsoid).
+		salp1, calp1 = slam12, clam12
+		salp2, calp2 = 0, 1
+		ssig1, csig1 = sbet1, calp1*cbet1
+		ssig2, csig2 = sbet2, calp2*cbet2
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+		s12x, m12x, _ = e.lengths(e.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a[:], c2a[:])
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*tiny || (sig12 < tol0 && (s12x < 0 || m12x < 0)) {
+				sig12, m12x, s12x = 0, 0, 0
+			}
+			s12x *= e.b
+		} else {
+			meridian = false
+		}
+	}
+
+	switch {
+	case meridian:
+	case sbet1 == 0 && (e.f <= 0 || lon12s >= e.f*180):
+		// The geodesic runs along the equator.
+		calp1, calp2 = 0, 0
+		salp1, salp2 = 1, 1
+		s12x = e.a * lam12
+		sig12 = lam12 / e.f1
+		somg12, comg12 = math.Sincos(sig12)
+	default:
+		sig12, salp1, calp1, salp2, calp2, dnm = e.inverseStart(
+			sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12, c1a[:], c2a[:])
+		if sig12 >= 0 {
+			// Short lines (inverseStart sets salp2, calp2, and dnm).
+			s12x = sig12 * e.b * dnm
+			somg12, comg12 = math.Sincos(lam12 / (e.f1 * dnm))
+			break
+		}
+
+		// Newton's method, falling back to bisection if it fails to
+		// converge. The root is bracketed by the azimuths (salp1a,
+		// calp1a) and (salp1b, calp1b).
+		var (
+			tripn, tripb bool
+			salp1a       = tiny
+			calp1a       = 1.0
+			salp1b       = tiny
+			calp1b       = -1.0
+		)
+		for numit := 0; numit < maxit2; {
+			var v, dv float64
+			v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dv = e.lambda12(
+				sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12,
+				numit < maxit1, c1a[:], c2a[:], c3a[:])
+			tol := tol0
+			if tripn {
+				tol *= 8
+			}
+			if tripb || !(math.Abs(v) >= tol) {
+				break
+			}
+			// Update the bracketing range.
+			if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+				salp1b, calp1b = salp1, calp1
+			} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+				salp1a, calp1a = salp1, calp1
+			}
+			numit++
+			if numit < maxit1 && dv > 0 {
+				dalp1 := -v / dv
+				if math.Abs(dalp1) < math.Pi {
+					sdalp1, cdalp1 := math.Sincos(dalp1)
+					nsalp1 := salp1*cdalp1 + calp1*sdalp1
+					if nsalp1 > 0 {
+						calp1 = calp1*cdalp1 - salp1*sdalp1
+						salp1, calp1 = norm(nsalp1, calp1)
+						tripn = math.Abs(v) <= 16*tol0
+						continue
+					}
+				}
+			}
+			// Newton's method didn't give a usable update, so bisect
+			// instead.
+			salp1, calp1 = norm((salp1a+salp1b)/2, (calp1a+calp1b)/2)
+			tripn = false
+			tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb ||
+				math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+		}
+		s12x, _, _ = e.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a[:], c2a[:])
+		s12x *= e.b
+		sdomg12, cdomg12 := math.Sincos(domg12)
+		somg12 = slam12*cdomg12 - clam12*sdomg12
+		comg12 = clam12*cdomg12 + slam12*sdomg12
+	}
+
+	// Area between the geodesic and the equator.
+	var s12Area float64
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	if calp0 != 0 && salp0 != 0 {
+		ssig1, csig1 = norm(sbet1, calp1*cbet1)
+		ssig2, csig2 = norm(sbet2, calp2*cbet2)
+		k2 := sq(calp0) * e.ep2
+		eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+		a4 := sq(e.a) * calp0 * salp0 * e.e2
+		var c4a [nC4]float64
+		e.c4f(eps, c4a[:])
+		b41 := sinCosSeries(false, ssig1, csig1, c4a[:])
+		b42 := sinCosSeries(false, ssig2, csig2, c4a[:])
+		s12Area = a4 * (b42 - b41)
+	}
+	var alp12 float64
+	if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+		// Use tan(Gamma/2) = tan(omg12/2) * (tan(bet1/2) + tan(bet2/2)) /
+		// (1 + tan(bet1/2)*tan(bet2/2)) with tan(x/2) = sin(x)/(1+cos(x)).
+		domg12 := 1 + comg12
+		dbet1 := 1 + cbet1
+		dbet2 := 1 + cbet2
+		alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+	} else {
+		// alp12 = alp2 - alp1, used in atan2 so no need to normalise.
+		salp12 := salp2*calp1 - calp2*salp1
+		calp12 := calp2*calp1 + salp2*salp1
+		if salp12 == 0 && calp12 < 0 {
+			salp12 = tiny * calp1
+			calp12 = -1
+		}
+		alp12 = math.Atan2(salp12, calp12)
+	}
+	s12Area += e.c2 * alp12
+	s12Area *= swapp * lonsign * latsign
+	s12Area += 0
+
+	// Convert the azimuths back to the original configuration.
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+
+	return InverseResult{
+		Distance: 0 + s12x,
+		Azimuth1: atan2d(salp1, calp1),
+		Azimuth2: atan2d(salp2, calp2),
+		area:     s12Area,
+	}
+}
+
+func latFix(lat float64) float64 {
+	if math.Abs(lat) > 90 {
+		return math.NaN()
+	}
+	return lat
+}
+
+// lengths calculates the distance (s12b) and reduced length (m12b) of a
+// geodesic, scaled by the semi-minor axis. It also gives m0, the
+// coefficient of the secular term in the reduced length.
+func (e Ellipsoid) lengths(
+	eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64,
+	c1a, c2a []float64,
+) (s12b, m12b, m0 float64) {
+	a1 := a1m1f(eps)
+	c1f(eps, c1a)
+	a2 := a2m1f(eps)
+	c2f(eps, c2a)
+	m0 = a1 - a2
+	a1++
+	a2++
+	b1 := sinCosSeries(true, ssig2, csig2, c1a) - sinCosSeries(true, ssig1, csig1, c1a)
+	b2 := sinCosSeries(true, ssig2, csig2, c2a) - sinCosSeries(true, ssig1, csig1, c2a)
+	s12b = a1 * (sig12 + b1)
+	j12 := m0*sig12 + (a1*b1 - a2*b2)
+	// Missing a factor of b. Add parens around (csig1 * ssig2) and (ssig1 *
+	// csig2) to ensure accurate cancellation in the case of coincident
+	// points.
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0
+}
+
+// astroid solves k^4+2*k^3-(x^2+y^2-1)*k^2-2*y^2*k-y^2 = 0 for the positive
+// root k.
+func astroid(x, y float64) float64 {
+	p := sq(x)
+	q := sq(y)
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		return 0
+	}
+	s := p * q / 4
+	r2 := sq(r)
+	r3 := r * r2
+	disc := s * (s + 2*r3)
+	u := r
+	if disc >= 0 {
+		t3 := s + r3
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+		t := math.Cbrt(t3)
+		u += t
+		if t != 0 {
+			u += r2 / t
+		}
+	} else {
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(sq(u) + q)
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v
+	}
+	w := (uv - q) / (2 * v)
+	return uv / (math.Sqrt(uv+sq(w)) + w)
+}
+
+// inverseStart finds a starting value for Newton's method. If the points are
+// close enough together, then the solution is found directly (and sig12 is
+// non-negative).
+func (e Ellipsoid) inverseStart(
+	sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64,
+	c1a, c2a []float64,
+) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := sq(sbet1 + sbet2)
+		// sin((bet1+bet2)/2)^2 = (sbet1 + sbet2)^2 / ((sbet1 + sbet2)^2 +
+		// (cbet1 + cbet2)^2)
+		sbetm2 /= sbetm2 + sq(cbet1+cbet2)
+		dnm = math.Sqrt(1 + e.ep2*sbetm2)
+		omg12 := lam12 / (e.f1 * dnm)
+		somg12, comg12 = math.Sincos(omg12)
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*sq(somg12)/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+	}
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortline && ssig12 < e.etol2:
+		// Really short lines.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*sq(somg12)/(1+comg12)
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm(salp2, calp2)
+		// Set return value.
+		sig12 = math.Atan2(ssig12, csig12)
+	case math.Abs(e.n) > 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(e.n)*math.Pi*sq(cbet1):
+		// Nothing to do, zeroth order spherical approximation is OK.
+	default:
+		// Nearly antipodal points, so scale the problem to an astroid.
+		lam12x := math.Atan2(-slam12, -clam12)
+		var x, y, lamscale, betscale float64
+		if e.f >= 0 {
+			k2 := sq(sbet1) * e.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = e.f * cbet1 * e.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+			_, m12b, m0 := e.lengths(e.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2, c1a, c2a)
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -e.f * sq(cbet1) * math.Pi
+			}
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+
+		if y > -tol1 && x > -1-xthresh {
+			if e.f >= 0 {
+				salp1 = math.Min(1, -x)
+				calp1 = -math.Sqrt(1 - sq(salp1))
+			} else {
+				lim := -1.0
+				if x > -tol1 {
+					lim = 0
+				}
+				calp1 = math.Max(lim, x)
+				salp1 = math.Sqrt(1 - sq(calp1))
+			}
+		} else {
+			k := astroid(x, y)
+			var omg12a float64
+			if e.f >= 0 {
+				omg12a = lamscale * (-x * k / (1 + k))
+			} else {
+				omg12a = lamscale * (-y * (1 + k) / k)
+			}
+			somg12, comg12 = math.Sincos(omg12a)
+			comg12 = -comg12
+			// Update spherical estimate of alp1 using omg12 instead of
+			// lam12.
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+		}
+	}
+
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// lambda12 calculates the longitude difference (lam12) given a starting
+// azimuth (which is what Newton's method solves for), along with its
+// derivative (dlam12) with respect to that azimuth.
+func (e Ellipsoid) lambda12(
+	sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64,
+	diffp bool, c1a, c2a, c3a []float64,
+) (lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12 float64) {
+	if sbet1 == 0 && calp1 == 0 {
+		// Break degeneracy of equatorial line.
+		calp1 = -tiny
+	}
+
+	// sin(alp1) * cos(bet1) = sin(alp0)
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+
+	// tan(bet1) = tan(sig1) * cos(alp1)
+	// tan(omg1) = sin(alp0) * tan(sig1) = tan(omg1)=tan(alp1)*sin(bet1)
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm(ssig1, csig1)
+
+	// Enforce symmetries in the case abs(bet2) = -bet1.
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var t float64
+		if cbet1 < -sbet1 {
+			t = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			t = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt(sq(calp1*cbet1)+t) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+
+	// tan(bet2) = tan(sig2) * cos(alp2)
+	// tan(omg2) = sin(alp0) * tan(sig2).
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm(ssig2, csig2)
+
+	// sig12 = sig2 - sig1, limit to [0, pi]
+	sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+	// omg12 = omg2 - omg1, limit to [0, pi]
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+	// eta = omg12 - lam120
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+
+	k2 := sq(calp0) * e.ep2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	e.c3f(eps, c3a)
+	b312 := sinCosSeries(true, ssig2, csig2, c3a) - sinCosSeries(true, ssig1, csig1, c3a)
+	domg12 = -e.f * e.a3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * e.f1 * dn1 / sbet1
+		} else {
+			_, dlam12, _ = e.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a, c2a)
+			dlam12 *= e.f1 / (calp2 * cbet2)
+		}
+	} else {
+		dlam12 = math.NaN()
+	}
+	return lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12
+}
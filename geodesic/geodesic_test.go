@@ -0,0 +1,219 @@
+package geodesic
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func geomFromWKT(t *testing.T, wkt string) geom.Geometry {
+	t.Helper()
+	g, err := geom.UnmarshalWKT(strings.NewReader(wkt))
+	if err != nil {
+		t.Fatalf("could not unmarshal WKT:\n  wkt: %s\n  err: %v", wkt, err)
+	}
+	return g
+}
+
+func expectFloatWithin(t *testing.T, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("got=%.9f want=%.9f", got, want)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	for i, tt := range []struct {
+		from, to   geom.XY
+		dist       float64
+		azi1, azi2 float64
+	}{
+		// Examples from the GeographicLib documentation.
+		{geom.XY{X: 174.81, Y: -41.32}, geom.XY{X: -5.50, Y: 40.96}, 19959679.26735382, 161.067669986160, 18.825195123247},
+		{geom.XY{X: -73.8, Y: 40.6}, geom.XY{X: -0.5, Y: 51.6}, 5551759.400318676, 51.198882845580, 107.821776735514},
+
+		// Along the equator and along a meridian.
+		{geom.XY{X: 10, Y: 0}, geom.XY{X: 20, Y: 0}, 1113194.907932736, 90, 90},
+		{geom.XY{X: 0, Y: 1}, geom.XY{X: 0, Y: 2}, 110575.064814335, 0, 0},
+		{geom.XY{X: 0, Y: 2}, geom.XY{X: 0, Y: 1}, 110575.064814335, 180, 180},
+
+		// Pole to pole.
+		{geom.XY{X: 0, Y: -90}, geom.XY{X: 10, Y: 90}, 20003931.458625447, 10, 0},
+
+		// Nearly antipodal.
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 179.5, Y: 0.5}, 19936288.578965306, 25.671872868292, 154.327085469942},
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 179.7, Y: 0}, 19995624.889961270, 29.828768395683, 150.171231604317},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := WGS84.Inverse(tt.from, tt.to)
+			expectFloatWithin(t, got.Distance, tt.dist, 1e-6)
+			expectFloatWithin(t, got.Azimuth1, tt.azi1, 1e-9)
+			expectFloatWithin(t, got.Azimuth2, tt.azi2, 1e-9)
+
+			// The reverse direction has the same distance.
+			expectFloatWithin(t, WGS84.Distance(tt.to, tt.from), tt.dist, 1e-6)
+		})
+	}
+}
+
+func TestDistanceSphere(t *testing.T) {
+	const r = 6371000
+	sphere := NewEllipsoid(r, 0)
+	for i, tt := range []struct {
+		from, to geom.XY
+		angle    float64
+	}{
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 90, Y: 0}, math.Pi / 2},
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 0, Y: 90}, math.Pi / 2},
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 180, Y: 0}, math.Pi},
+		{geom.XY{X: 30, Y: 45}, geom.XY{X: -150, Y: -45}, math.Pi},
+		{geom.XY{X: 0, Y: 45}, geom.XY{X: 90, Y: 45}, math.Pi / 3},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectFloatWithin(t, sphere.Distance(tt.from, tt.to), r*tt.angle, 1e-6)
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		want float64
+	}{
+		{"POINT(1 2)", 0},
+		{"LINESTRING EMPTY", 0},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", 0},
+		{"LINESTRING(10 0,20 0)", 1113194.907932736},
+		{"LINESTRING(10 0,15 0,20 0)", 1113194.907932736},
+		{"LINESTRING(0 1,0 2,0 1)", 2 * 110575.064814335},
+		{"MULTILINESTRING((10 0,20 0),(0 1,0 2))", 1113194.907932736 + 110575.064814335},
+		{"GEOMETRYCOLLECTION(POINT(0 0),LINESTRING(0 1,0 2),MULTILINESTRING((10 0,20 0)))", 1113194.907932736 + 110575.064814335},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectFloatWithin(t, WGS84.Length(geomFromWKT(t, tt.wkt)), tt.want, 1e-6)
+		})
+	}
+}
+
+func TestArea(t *testing.T) {
+	const (
+		octant      = 63758202715511.055
+		squareAtEq  = 12308778361.469452
+		rectAtEq    = 24619443759.277203
+		squareAt80N = 2058174639.519043
+	)
+	for i, tt := range []struct {
+		wkt  string
+		want float64
+	}{
+		{"POINT(1 2)", 0},
+		{"LINESTRING(0 0,1 1)", 0},
+		{"POLYGON EMPTY", 0},
+
+		// Ring orientation doesn't matter.
+		{"POLYGON((0 0,90 0,0 90,0 0))", octant},
+		{"POLYGON((0 0,0 90,90 0,0 0))", octant},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", squareAtEq},
+		{"POLYGON((0 0,0 1,1 1,1 0,0 0))", squareAtEq},
+
+		// Cells of the same angular size get smaller at high latitudes.
+		{"POLYGON((0 80,1 80,1 81,0 81,0 80))", squareAt80N},
+		{"POLYGON((0 -81,1 -81,1 -80,0 -80,0 -81))", squareAt80N},
+
+		// Crossing the prime meridian and the antimeridian.
+		{"POLYGON((0 0,2 0,2 1,0 1,0 0))", rectAtEq},
+		{"POLYGON((-1 0,1 0,1 1,-1 1,-1 0))", rectAtEq},
+		{"POLYGON((179 0,181 0,181 1,179 1,179 0))", rectAtEq},
+		{"POLYGON((179 0,-179 0,-179 1,179 1,179 0))", rectAtEq},
+
+		// Holes are subtracted.
+		{"POLYGON((-1 -1,1 -1,1 1,-1 1,-1 -1),(-0.5 -0.5,-0.5 0.5,0.5 0.5,0.5 -0.5,-0.5 -0.5))", 36929652936.051453},
+
+		{"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((0 80,1 80,1 81,0 81,0 80)))", squareAtEq + squareAt80N},
+		{"GEOMETRYCOLLECTION(POLYGON((0 0,1 0,1 1,0 1,0 0)),LINESTRING(0 0,1 1))", squareAtEq},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectFloatWithin(t, WGS84.Area(geomFromWKT(t, tt.wkt)), tt.want, 0.01)
+		})
+	}
+}
+
+func TestSurfaceArea(t *testing.T) {
+	expectFloatWithin(t, WGS84.SurfaceArea(), 510065621724088.4, 1)
+	expectFloatWithin(t, NewEllipsoid(1, 0).SurfaceArea(), 4*math.Pi, 1e-12)
+
+	// The octants of the ellipsoid sum to the total surface area.
+	var sum float64
+	for _, lon := range []int{-180, -90, 0, 90} {
+		for _, lat := range []int{-90, 0} {
+			lon0 := strconv.Itoa(lon)
+			lon1 := strconv.Itoa(lon + 90)
+			lat0 := strconv.Itoa(lat)
+			lat1 := strconv.Itoa(lat + 90)
+			wkt := "POLYGON((" + lon0 + " " + lat0 + "," + lon1 + " " + lat0 + "," +
+				lon1 + " " + lat1 + "," + lon0 + " " + lat1 + "," + lon0 + " " + lat0 + "))"
+			sum += WGS84.Area(geomFromWKT(t, wkt))
+		}
+	}
+	expectFloatWithin(t, sum, WGS84.SurfaceArea(), 1)
+}
+
+func TestGeometryDistance(t *testing.T) {
+	// The distance along a meridian between the equator and 1 degree of
+	// latitude.
+	const degLat = 110574.388557799
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+		want       float64
+	}{
+		{"POINT(0 1)", "POINT(0 2)", 110575.064814335},
+		{"POINT(10 0)", "MULTIPOINT(20 0,0 0)", 1113194.907932736},
+		{"POINT(0.5 1)", "LINESTRING(0 0,1 0)", degLat},
+		{"POINT(180 -1)", "LINESTRING(179 0,-179 0)", degLat},
+		{"POINT(0 -1)", "LINESTRING(0 0,0 1)", degLat},
+		{"POINT(0 0.5)", "LINESTRING(0 0,0 1)", 0},
+		{"LINESTRING(0 1,0 2)", "LINESTRING(0 3,0 4)", 110576.416524153},
+		{"LINESTRING(-1 0.5,1 0.5)", "LINESTRING(0 0,0 1)", 0},
+
+		{"POINT(0.5 1)", "POLYGON((0 -1,1 -1,1 0,0 0,0 -1))", degLat},
+		{"LINESTRING(0.2 1,0.8 1)", "POLYGON((0 -1,1 -1,1 0,0 0,0 -1))", degLat},
+		{"POINT(0.5 -0.5)", "POLYGON((0 -1,1 -1,1 0,0 0,0 -1))", 0},
+		{"LINESTRING(0.2 -0.5,0.8 -0.5)", "POLYGON((0 -1,1 -1,1 0,0 0,0 -1))", 0},
+		{"POLYGON((0.2 -0.8,0.8 -0.8,0.8 -0.2,0.2 -0.8))", "POLYGON((0 -1,1 -1,1 0,0 0,0 -1))", 0},
+		{"POINT(5 1)", "POLYGON((0 -10,10 -10,10 10,0 10,0 -10),(2 0,8 0,8 8,2 8,2 0))", degLat},
+		{"POINT(5 -1)", "POLYGON((0 -10,10 -10,10 10,0 10,0 -10),(2 0,8 0,8 8,2 8,2 0))", 0},
+		{"POINT(5 1)", "GEOMETRYCOLLECTION(POINT(5 20),POLYGON((0 -1,10 -1,10 0,0 0,0 -1)))", degLat},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.wkt1)
+			g2 := geomFromWKT(t, tt.wkt2)
+			for _, order := range [][2]geom.Geometry{{g1, g2}, {g2, g1}} {
+				got, ok := WGS84.GeometryDistance(order[0], order[1])
+				if !ok {
+					t.Fatal("expected ok")
+				}
+				expectFloatWithin(t, got, tt.want, 1e-6)
+			}
+		})
+	}
+}
+
+func TestGeometryDistanceEmpty(t *testing.T) {
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+	}{
+		{"POINT EMPTY", "POINT(1 2)"},
+		{"LINESTRING(0 0,1 1)", "POLYGON EMPTY"},
+		{"GEOMETRYCOLLECTION EMPTY", "GEOMETRYCOLLECTION EMPTY"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, ok := WGS84.GeometryDistance(geomFromWKT(t, tt.wkt1), geomFromWKT(t, tt.wkt2))
+			if ok {
+				t.Error("expected not ok")
+			}
+		})
+	}
+}
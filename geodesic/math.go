@@ -0,0 +1,123 @@
+package geodesic
+
+import "math"
+
+const (
+	degToRad = math.Pi / 180
+	radToDeg = 180 / math.Pi
+)
+
+func sq(x float64) float64 {
+	return x * x
+}
+
+// norm normalises (x, y) so that it's a unit vector.
+func norm(x, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	return x / r, y / r
+}
+
+// polyval evaluates the polynomial of degree n with coefficients p (highest
+// degree first) at x.
+func polyval(n int, p []float64, x float64) float64 {
+	y := p[0]
+	for i := 1; i <= n; i++ {
+		y = y*x + p[i]
+	}
+	return y
+}
+
+// twoSum gives the sum of u and v, and the rounding error in that sum.
+func twoSum(u, v float64) (float64, float64) {
+	s := u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	return s, -(up + vpp)
+}
+
+// angNormalize reduces an angle (in degrees) to the range [-180, 180].
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if math.Abs(y) == 180 {
+		return math.Copysign(180, x)
+	}
+	return y
+}
+
+// angDiff gives the difference y - x between two angles (in degrees),
+// reduced to the range [-180, 180], along with its rounding error.
+func angDiff(x, y float64) (float64, float64) {
+	d, t := twoSum(angNormalize(-x), angNormalize(y))
+	d = angNormalize(d)
+	if d == 180 && t > 0 {
+		d = -180
+	}
+	return twoSum(d, t)
+}
+
+// angRound rounds tiny angles (in degrees) so that they are exactly zero.
+// This prevents problems with underflow and ill-conditioned calculations
+// near the poles.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	if y < z {
+		y = z - (z - y)
+	}
+	return math.Copysign(y, x)
+}
+
+// sincosd gives the sine and cosine of an angle (in degrees), with exact
+// results for multiples of 90 degrees.
+func sincosd(x float64) (float64, float64) {
+	r := math.Mod(x, 360)
+	var q int
+	if !math.IsNaN(r) {
+		q = int(math.Round(r / 90))
+	}
+	r -= 90 * float64(q)
+	s, c := math.Sincos(r * degToRad)
+	switch ((q % 4) + 4) % 4 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	c += 0
+	if s == 0 {
+		s = math.Copysign(s, x)
+	}
+	return s, c
+}
+
+// atan2d gives atan2(y, x) in degrees, with exact results for multiples of
+// 90 degrees.
+func atan2d(y, x float64) float64 {
+	var q int
+	if math.Abs(y) > math.Abs(x) {
+		q = 2
+		x, y = y, x
+	}
+	if x < 0 {
+		q++
+		x = -x
+	}
+	ang := math.Atan2(y, x) * radToDeg
+	switch q {
+	case 1:
+		if y >= 0 {
+			ang = 180 - ang
+		} else {
+			ang = -180 - ang
+		}
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+	return ang
+}
@@ -0,0 +1,137 @@
+package geodesic
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Length gives the geodesic length of a geometry's linear elements (Lines,
+// LineStrings, and MultiLineStrings, including those inside
+// GeometryCollections). Other geometries have zero length.
+func (e Ellipsoid) Length(g geom.Geometry) float64 {
+	switch {
+	case g.IsEmpty():
+		return 0
+	case g.IsLine():
+		return e.lineStringLength(g.AsLine().AsLineString())
+	case g.IsLineString():
+		return e.lineStringLength(g.AsLineString())
+	case g.IsMultiLineString():
+		mls := g.AsMultiLineString()
+		var sum float64
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			sum += e.lineStringLength(mls.LineStringN(i))
+		}
+		return sum
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		var sum float64
+		for i := 0; i < gc.NumGeometries(); i++ {
+			sum += e.Length(gc.GeometryN(i))
+		}
+		return sum
+	default:
+		return 0
+	}
+}
+
+// Area gives the geodesic area of a geometry's areal elements (Polygons and
+// MultiPolygons, including those inside GeometryCollections). Other
+// geometries have zero area. The area of each hole is subtracted from the
+// area of its polygon. Ring orientation is ignored, however rings that
+// enclose more than half of the ellipsoid aren't supported.
+func (e Ellipsoid) Area(g geom.Geometry) float64 {
+	switch {
+	case g.IsEmpty():
+		return 0
+	case g.IsPolygon():
+		return e.polygonArea(g.AsPolygon())
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		var sum float64
+		for i := 0; i < mp.NumPolygons(); i++ {
+			sum += e.polygonArea(mp.PolygonN(i))
+		}
+		return sum
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		var sum float64
+		for i := 0; i < gc.NumGeometries(); i++ {
+			sum += e.Area(gc.GeometryN(i))
+		}
+		return sum
+	default:
+		return 0
+	}
+}
+
+func (e Ellipsoid) lineStringLength(ls geom.LineString) float64 {
+	var sum float64
+	for i := 0; i+1 < ls.NumPoints(); i++ {
+		sum += e.Distance(ls.PointN(i).XY(), ls.PointN(i+1).XY())
+	}
+	return sum
+}
+
+func (e Ellipsoid) polygonArea(p geom.Polygon) float64 {
+	area := math.Abs(e.ringArea(p.ExteriorRing()))
+	for i := 0; i < p.NumInteriorRings(); i++ {
+		area -= math.Abs(e.ringArea(p.InteriorRingN(i)))
+	}
+	return area
+}
+
+// ringArea gives the signed area of a ring. It's positive if the ring is
+// counterclockwise, and negative if it's clockwise. This is a port of the
+// GeographicLib PolygonArea::Compute method.
+func (e Ellipsoid) ringArea(ring geom.LineString) float64 {
+	var (
+		area      float64
+		crossings int
+	)
+	n := ring.NumPoints()
+	for i := 0; i < n; i++ {
+		// The ring may not have its closing point repeated (e.g. when it has
+		// been constructed without validation), so wrap around explicitly.
+		p1 := ring.PointN(i).XY()
+		p2 := ring.PointN((i + 1) % n).XY()
+		area += e.Inverse(p1, p2).area
+		crossings += transit(p1.X, p2.X)
+	}
+
+	area0 := e.SurfaceArea()
+	area = math.Remainder(area, area0)
+	if crossings%2 != 0 {
+		if area < 0 {
+			area += area0 / 2
+		} else {
+			area -= area0 / 2
+		}
+	}
+	// The area accumulated above is to the right of each edge, so negate it
+	// to give counterclockwise rings a positive area.
+	area = -area
+	if area > area0/2 {
+		area -= area0
+	} else if area <= -area0/2 {
+		area += area0
+	}
+	return 0 + area
+}
+
+// transit gives 1 if the edge from lon1 to lon2 crosses the prime meridian
+// heading east, -1 if it crosses heading west, and 0 otherwise.
+func transit(lon1, lon2 float64) int {
+	lon1 = angNormalize(lon1)
+	lon2 = angNormalize(lon2)
+	lon12, _ := angDiff(lon1, lon2)
+	switch {
+	case lon1 <= 0 && lon2 > 0 && lon12 > 0:
+		return 1
+	case lon2 <= 0 && lon1 > 0 && lon12 < 0:
+		return -1
+	default:
+		return 0
+	}
+}
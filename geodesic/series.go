@@ -0,0 +1,229 @@
+package geodesic
+
+// The series expansions in this file are from Karney (2013), and are to 6th
+// order in the small parameter eps (or the third flattening, n).
+const (
+	nA1  = 6
+	nC1  = 6
+	nA2  = 6
+	nC2  = 6
+	nA3  = 6
+	nA3x = nA3
+	nC3  = 6
+	nC3x = (nC3 * (nC3 - 1)) / 2
+	nC4  = 6
+	nC4x = (nC4 * (nC4 + 1)) / 2
+)
+
+// a1m1f gives A1 - 1.
+func a1m1f(eps float64) float64 {
+	coeff := []float64{1, 4, 64, 0, 256}
+	const m = nA1 / 2
+	t := polyval(m, coeff, sq(eps)) / coeff[m+1]
+	return (t + eps) / (1 - eps)
+}
+
+// c1f populates the C1 coefficients (c[1] to c[nC1]).
+func c1f(eps float64, c []float64) {
+	coeff := []float64{
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+	fillSeries(eps, nC1, coeff, c)
+}
+
+// a2m1f gives A2 - 1.
+func a2m1f(eps float64) float64 {
+	coeff := []float64{-11, -28, -192, 0, 256}
+	const m = nA2 / 2
+	t := polyval(m, coeff, sq(eps)) / coeff[m+1]
+	return (t - eps) / (1 + eps)
+}
+
+// c2f populates the C2 coefficients (c[1] to c[nC2]).
+func c2f(eps float64, c []float64) {
+	coeff := []float64{
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+	fillSeries(eps, nC2, coeff, c)
+}
+
+// fillSeries evaluates the coefficients of a series in eps, where the l-th
+// coefficient is eps^l multiplied by a polynomial in eps^2.
+func fillSeries(eps float64, n int, coeff, c []float64) {
+	eps2 := sq(eps)
+	d := eps
+	o := 0
+	for l := 1; l <= n; l++ {
+		m := (n - l) / 2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// a3coeff gives the coefficients of A3 (as polynomials in eps), which depend
+// on the third flattening n.
+func a3coeff(n float64) [nA3x]float64 {
+	coeff := []float64{
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+	var a3x [nA3x]float64
+	o, k := 0, 0
+	for j := nA3 - 1; j >= 0; j-- {
+		m := minInt(nA3-j-1, j)
+		a3x[k] = polyval(m, coeff[o:], n) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+	return a3x
+}
+
+// c3coeff gives the coefficients of C3 (as polynomials in eps), which depend
+// on the third flattening n.
+func c3coeff(n float64) [nC3x]float64 {
+	coeff := []float64{
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		5, 256,
+		1, 3, 128,
+		-3, -2, 3, 64,
+		1, -3, 2, 32,
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		7, 512,
+		-14, 7, 512,
+		21, 2560,
+	}
+	var c3x [nC3x]float64
+	o, k := 0, 0
+	for l := 1; l < nC3; l++ {
+		for j := nC3 - 1; j >= l; j-- {
+			m := minInt(nC3-j-1, j)
+			c3x[k] = polyval(m, coeff[o:], n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+	return c3x
+}
+
+// c4coeff gives the coefficients of C4 (as polynomials in eps), which depend
+// on the third flattening n.
+func c4coeff(n float64) [nC4x]float64 {
+	coeff := []float64{
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		-128, 135135,
+		-2560, 832, 405405,
+		128, 99099,
+	}
+	var c4x [nC4x]float64
+	o, k := 0, 0
+	for l := 0; l < nC4; l++ {
+		for j := nC4 - 1; j >= l; j-- {
+			m := nC4 - j - 1
+			c4x[k] = polyval(m, coeff[o:], n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+	return c4x
+}
+
+// a3f gives A3.
+func (e Ellipsoid) a3f(eps float64) float64 {
+	return polyval(nA3x-1, e.a3x[:], eps)
+}
+
+// c3f populates the C3 coefficients (c[1] to c[nC3-1]).
+func (e Ellipsoid) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < nC3; l++ {
+		m := nC3 - l - 1
+		mult *= eps
+		c[l] = mult * polyval(m, e.c3x[o:], eps)
+		o += m + 1
+	}
+}
+
+// c4f populates the C4 coefficients (c[0] to c[nC4-1]).
+func (e Ellipsoid) c4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 0; l < nC4; l++ {
+		m := nC4 - l - 1
+		c[l] = mult * polyval(m, e.c4x[o:], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// sinCosSeries evaluates either sum(c[l] * sin(2*l*x), l = 1..n) (if sinp is
+// true) or sum(c[l] * cos((2*l+1)*x), l = 0..n-1) (if sinp is false) using
+// Clenshaw summation.
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64) float64 {
+	k := len(c)
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	for n /= 2; n > 0; n-- {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0
+	}
+	return cosx * (y0 - y1)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,163 @@
+package geom
+
+import "math"
+
+// AffineTransform is a 2D affine transformation. It maps each (x, y) to (x',
+// y') where:
+//
+//	x' = a*x + b*y + c
+//	y' = d*x + e*y + f
+//
+// The zero value isn't the identity transformation (it maps everything to
+// the origin). Use IdentityTransform or NewAffineTransform to create an
+// AffineTransform, and the Translate, Scale, Rotate, Shear, and Compose
+// methods to build up more complex transformations.
+type AffineTransform struct {
+	a, b, c float64
+	d, e, f float64
+}
+
+// IdentityTransform gives the AffineTransform that maps each point to itself.
+func IdentityTransform() AffineTransform {
+	return AffineTransform{a: 1, e: 1}
+}
+
+// NewAffineTransform creates an AffineTransform from the coefficients of its
+// matrix. Each (x, y) is mapped to (a*x + b*y + xoff, d*x + e*y + yoff). The
+// argument order matches the PostGIS ST_Affine function.
+func NewAffineTransform(a, b, d, e, xoff, yoff float64) AffineTransform {
+	return AffineTransform{a: a, b: b, c: xoff, d: d, e: e, f: yoff}
+}
+
+// Apply transforms a single XY.
+func (t AffineTransform) Apply(xy XY) XY {
+	return XY{
+		t.a*xy.X + t.b*xy.Y + t.c,
+		t.d*xy.X + t.e*xy.Y + t.f,
+	}
+}
+
+// Determinant gives the determinant of the linear part of the
+// transformation. It's negative if the transformation is a reflection (which
+// flips the orientation of rings), and zero if the transformation collapses
+// the plane onto a line or point.
+func (t AffineTransform) Determinant() float64 {
+	return t.a*t.e - t.b*t.d
+}
+
+// Compose gives the AffineTransform that applies t, and then applies u.
+func (t AffineTransform) Compose(u AffineTransform) AffineTransform {
+	return AffineTransform{
+		a: u.a*t.a + u.b*t.d,
+		b: u.a*t.b + u.b*t.e,
+		c: u.a*t.c + u.b*t.f + u.c,
+		d: u.d*t.a + u.e*t.d,
+		e: u.d*t.b + u.e*t.e,
+		f: u.d*t.c + u.e*t.f + u.f,
+	}
+}
+
+// Invert gives the AffineTransform that undoes t. It returns false if t isn't
+// invertible (i.e. its determinant is zero).
+func (t AffineTransform) Invert() (AffineTransform, bool) {
+	det := t.Determinant()
+	if det == 0 {
+		return AffineTransform{}, false
+	}
+	inv := AffineTransform{
+		a: t.e / det,
+		b: -t.b / det,
+		d: -t.d / det,
+		e: t.a / det,
+	}
+	inv.c = -(inv.a*t.c + inv.b*t.f)
+	inv.f = -(inv.d*t.c + inv.e*t.f)
+	return inv, true
+}
+
+// Translate gives the AffineTransform that applies t, and then translates by
+// dx and dy.
+func (t AffineTransform) Translate(dx, dy float64) AffineTransform {
+	return t.Compose(AffineTransform{a: 1, c: dx, e: 1, f: dy})
+}
+
+// Scale gives the AffineTransform that applies t, and then scales by sx and
+// sy (relative to the origin).
+func (t AffineTransform) Scale(sx, sy float64) AffineTransform {
+	return t.Compose(AffineTransform{a: sx, e: sy})
+}
+
+// Rotate gives the AffineTransform that applies t, and then rotates
+// counterclockwise by the given angle (in radians) around a point.
+func (t AffineTransform) Rotate(radians float64, around XY) AffineTransform {
+	sin, cos := math.Sincos(radians)
+	return t.
+		Translate(-around.X, -around.Y).
+		Compose(AffineTransform{a: cos, b: -sin, d: sin, e: cos}).
+		Translate(around.X, around.Y)
+}
+
+// Shear gives the AffineTransform that applies t, and then shears by shx in
+// the X direction and shy in the Y direction, i.e. (x, y) is mapped to (x +
+// shx*y, y + shy*x).
+func (t AffineTransform) Shear(shx, shy float64) AffineTransform {
+	return t.Compose(AffineTransform{a: 1, b: shx, d: shy, e: 1})
+}
+
+// Transform applies an AffineTransform to the geometry. The result is
+// constructed using the supplied options (so is validated by default).
+//
+// If the transformation is a reflection (i.e. its determinant is negative),
+// then the rings of any polygons are reversed so that they keep their
+// original orientation. Z and M values are unchanged.
+func (g Geometry) Transform(t AffineTransform, opts ...ConstructorOption) (Geometry, error) {
+	if t.Determinant() >= 0 {
+		return g.TransformXY(t.Apply, opts...)
+	}
+	var (
+		result Geometry
+		err    error
+	)
+	switch g.tag {
+	case polygonTag:
+		p := g.AsPolygon()
+		coords := p.Coordinates()
+		transform2dCoords(coords, t.Apply)
+		reverseRings(coords)
+		var poly Polygon
+		poly, err = NewPolygonC(coords, opts...)
+		result = poly.ForceCoordinatesType(p.CoordinatesType()).AsGeometry()
+	case multiPolygonTag:
+		m := g.AsMultiPolygon()
+		coords := m.Coordinates()
+		transform3dCoords(coords, t.Apply)
+		for _, p := range coords {
+			reverseRings(p)
+		}
+		var mp MultiPolygon
+		mp, err = NewMultiPolygonC(coords, opts...)
+		result = mp.ForceCoordinatesType(m.CoordinatesType()).AsGeometry()
+	case geometryCollectionTag:
+		c := g.AsGeometryCollection()
+		geoms := make([]Geometry, c.NumGeometries())
+		for i := range geoms {
+			geoms[i], err = c.GeometryN(i).Transform(t, opts...)
+			if err != nil {
+				return Geometry{}, err
+			}
+		}
+		result = NewGeometryCollection(geoms, opts...).ForceCoordinatesType(c.CoordinatesType()).AsGeometry()
+	default:
+		result, err = g.TransformXY(t.Apply, opts...)
+	}
+	return result.WithSRID(g.SRID()), err
+}
+
+// reverseRings reverses the order of the coordinates in each ring.
+func reverseRings(rings [][]Coordinates) {
+	for _, r := range rings {
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+	}
+}
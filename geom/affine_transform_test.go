@@ -0,0 +1,149 @@
+package geom_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestAffineTransformApply(t *testing.T) {
+	for i, tt := range []struct {
+		tr   AffineTransform
+		in   XY
+		want XY
+	}{
+		{IdentityTransform(), XY{1, 2}, XY{1, 2}},
+		{NewAffineTransform(1, 2, 3, 4, 5, 6), XY{1, 1}, XY{8, 13}},
+		{IdentityTransform().Translate(3, -4), XY{1, 2}, XY{4, -2}},
+		{IdentityTransform().Scale(2, 3), XY{1, 2}, XY{2, 6}},
+		{IdentityTransform().Scale(-1, 1), XY{1, 2}, XY{-1, 2}},
+		{IdentityTransform().Shear(2, 0), XY{1, 2}, XY{5, 2}},
+		{IdentityTransform().Shear(0, 3), XY{1, 2}, XY{1, 5}},
+		{IdentityTransform().Rotate(math.Pi/2, XY{0, 0}), XY{1, 0}, XY{0, 1}},
+		{IdentityTransform().Rotate(math.Pi, XY{1, 1}), XY{2, 1}, XY{0, 1}},
+		{IdentityTransform().Rotate(-math.Pi/2, XY{1, 1}), XY{1, 2}, XY{2, 1}},
+
+		// Operations are applied in the order that they're chained.
+		{IdentityTransform().Translate(1, 0).Scale(2, 2), XY{1, 1}, XY{4, 2}},
+		{IdentityTransform().Scale(2, 2).Translate(1, 0), XY{1, 1}, XY{3, 2}},
+		{IdentityTransform().Translate(1, 0).Compose(IdentityTransform().Scale(2, 2)), XY{1, 1}, XY{4, 2}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := tt.tr.Apply(tt.in)
+			if got.Sub(tt.want).Length() > 1e-15 {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffineTransformInvert(t *testing.T) {
+	for i, tr := range []AffineTransform{
+		IdentityTransform(),
+		IdentityTransform().Translate(3, -4),
+		IdentityTransform().Scale(2, -0.5),
+		IdentityTransform().Rotate(1, XY{3, 4}),
+		IdentityTransform().Shear(2, 0.25).Translate(1, 2).Rotate(-0.5, XY{1, 0}),
+		NewAffineTransform(1, 2, 3, 4, 5, 6),
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			inv, ok := tr.Invert()
+			expectBoolEq(t, ok, true)
+			for _, xy := range []XY{{0, 0}, {1, 2}, {-3, 7}} {
+				for _, got := range []XY{
+					inv.Apply(tr.Apply(xy)),
+					tr.Compose(inv).Apply(xy),
+					inv.Compose(tr).Apply(xy),
+				} {
+					if got.Sub(xy).Length() > 1e-12 {
+						t.Errorf("got=%v want=%v", got, xy)
+					}
+				}
+			}
+		})
+	}
+	t.Run("singular", func(t *testing.T) {
+		_, ok := IdentityTransform().Scale(1, 0).Invert()
+		expectBoolEq(t, ok, false)
+	})
+}
+
+func TestAffineTransformDeterminant(t *testing.T) {
+	for i, tt := range []struct {
+		tr   AffineTransform
+		want float64
+	}{
+		{IdentityTransform(), 1},
+		{IdentityTransform().Translate(5, 5), 1},
+		{IdentityTransform().Scale(2, 3), 6},
+		{IdentityTransform().Scale(-2, 3), -6},
+		{IdentityTransform().Shear(2, 0), 1},
+		{NewAffineTransform(1, 2, 3, 4, 5, 6), -2},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := tt.tr.Determinant(); got != tt.want {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometryTransform(t *testing.T) {
+	var (
+		shift   = IdentityTransform().Translate(10, 20)
+		flipY   = IdentityTransform().Scale(1, -1)
+		flipX   = IdentityTransform().Scale(-1, 1)
+		flatten = IdentityTransform().Scale(1, 0)
+	)
+	for i, tt := range []struct {
+		tr      AffineTransform
+		wktIn   string
+		wktOut  string
+		wantErr bool
+	}{
+		{shift, "POINT EMPTY", "POINT EMPTY", false},
+		{shift, "POINT(1 2)", "POINT(11 22)", false},
+		{shift, "POINT Z (1 2 3)", "POINT Z (11 22 3)", false},
+		{shift, "LINESTRING(0 0,1 1,2 0)", "LINESTRING(10 20,11 21,12 20)", false},
+		{shift, "POLYGON((0 0,1 0,0 1,0 0))", "POLYGON((10 20,11 20,10 21,10 20))", false},
+
+		// Reflections keep the orientation of rings, but not of LineStrings.
+		{flipY, "LINESTRING(0 0,1 1,2 0)", "LINESTRING(0 0,1 -1,2 0)", false},
+		{flipY, "POLYGON((0 0,1 0,0 1,0 0))", "POLYGON((0 0,0 -1,1 0,0 0))", false},
+		{flipX, "POLYGON M ((0 0 1,4 0 2,0 4 3,0 0 1),(1 1 4,1 2 5,2 1 6,1 1 4))", "POLYGON M ((0 0 1,0 4 3,-4 0 2,0 0 1),(-1 1 4,-2 1 6,-1 2 5,-1 1 4))", false},
+		{flipX, "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((2 0,3 0,2 1,2 0)))", "MULTIPOLYGON(((0 0,0 1,-1 0,0 0)),((-2 0,-2 1,-3 0,-2 0)))", false},
+		{flipX, "GEOMETRYCOLLECTION(POINT(1 2),POLYGON((0 0,1 0,0 1,0 0)))", "GEOMETRYCOLLECTION(POINT(-1 2),POLYGON((0 0,0 1,-1 0,0 0)))", false},
+		{flipX, "GEOMETRYCOLLECTION Z EMPTY", "GEOMETRYCOLLECTION Z EMPTY", false},
+
+		// Transformations that collapse polygons result in validation errors.
+		{flatten, "POLYGON((0 0,1 0,0 1,0 0))", "", true},
+		{flatten, "LINESTRING(0 0,1 1)", "LINESTRING(0 0,1 0)", false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			in := geomFromWKT(t, tt.wktIn)
+			got, err := in.Transform(tt.tr)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.wktOut))
+		})
+	}
+}
+
+func TestGeometryTransformKeepsSRID(t *testing.T) {
+	in := geomFromWKT(t, "POLYGON((0 0,1 0,0 1,0 0))").WithSRID(4326)
+	for _, tr := range []AffineTransform{
+		IdentityTransform().Translate(1, 1),
+		IdentityTransform().Scale(-1, 1),
+	} {
+		got, err := in.Transform(tr)
+		expectNoErr(t, err)
+		expectIntEq(t, got.SRID(), 4326)
+	}
+}
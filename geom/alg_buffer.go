@@ -0,0 +1,377 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+)
+
+// BufferOption allows the behaviour of the Buffer method to be modified.
+type BufferOption func(s *bufferOptionSet)
+
+type bufferOptionSet struct {
+	quadSegs   int
+	join       bufferJoin
+	mitreLimit float64
+	endCap     bufferEndCap
+}
+
+type bufferJoin int
+
+const (
+	bufferJoinRound bufferJoin = iota
+	bufferJoinMitre
+	bufferJoinBevel
+)
+
+type bufferEndCap int
+
+const (
+	bufferEndCapRound bufferEndCap = iota
+	bufferEndCapFlat
+	bufferEndCapSquare
+)
+
+func newBufferOptionSet(opts []BufferOption) bufferOptionSet {
+	// The defaults match the defaults used by PostGIS.
+	s := bufferOptionSet{
+		quadSegs:   8,
+		join:       bufferJoinRound,
+		mitreLimit: 5,
+		endCap:     bufferEndCapRound,
+	}
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+// BufferQuadSegments sets the number of line segments used to approximate a
+// quarter circle in a buffer. It affects round joins and round end caps. The
+// default is 8. Values less than 1 are treated as 1.
+func BufferQuadSegments(n int) BufferOption {
+	return func(s *bufferOptionSet) {
+		if n < 1 {
+			n = 1
+		}
+		s.quadSegs = n
+	}
+}
+
+// BufferJoinRound causes the outside corners of a buffer to be rounded. This
+// is the default.
+var BufferJoinRound = BufferOption(
+	func(s *bufferOptionSet) {
+		s.join = bufferJoinRound
+	},
+)
+
+// BufferJoinBevel causes the outside corners of a buffer to be cut off with a
+// single straight line.
+var BufferJoinBevel = BufferOption(
+	func(s *bufferOptionSet) {
+		s.join = bufferJoinBevel
+	},
+)
+
+// BufferJoinMitre causes the outside corners of a buffer to be sharp. The
+// limit controls how far the corner can extend away from the original
+// geometry (as a multiple of the buffer distance) before it is cut off. The
+// limit used by PostGIS when no limit is specified is 5.
+func BufferJoinMitre(limit float64) BufferOption {
+	return func(s *bufferOptionSet) {
+		s.join = bufferJoinMitre
+		s.mitreLimit = limit
+	}
+}
+
+// BufferEndCapRound causes the ends of buffered lines (and buffered points) to
+// be rounded. This is the default.
+var BufferEndCapRound = BufferOption(
+	func(s *bufferOptionSet) {
+		s.endCap = bufferEndCapRound
+	},
+)
+
+// BufferEndCapFlat causes the ends of buffered lines to be cut off
+// perpendicular to the line at the line's endpoints. Points (and lines with
+// zero length) produce empty buffers.
+var BufferEndCapFlat = BufferOption(
+	func(s *bufferOptionSet) {
+		s.endCap = bufferEndCapFlat
+	},
+)
+
+// BufferEndCapSquare causes the ends of buffered lines to be squared off,
+// extending past the line's endpoints by the buffer distance. Buffered points
+// become squares.
+var BufferEndCapSquare = BufferOption(
+	func(s *bufferOptionSet) {
+		s.endCap = bufferEndCapSquare
+	},
+)
+
+// buffer calculates the buffer of a geometry. The buffer is built up from
+// simple pieces (rectangles around each line segment, wedges at each corner,
+// and caps at the end of each line) that are then unioned together with the
+// original geometry's area. Negative buffers are found by subtracting the
+// (positive) buffer of each polygon's rings from the polygon.
+func buffer(g Geometry, distance float64, opts bufferOptionSet) (Geometry, error) {
+	var input overlayInput
+	input.add(g.Force2D())
+
+	b := bufferBuilder{opts: opts, dist: math.Abs(distance)}
+	if b.dist > 0 {
+		for _, r := range input.rings {
+			b.addRing(r)
+		}
+		if distance > 0 {
+			for _, pt := range input.points {
+				b.addPoint(pt)
+			}
+			for _, ln := range input.lines {
+				b.addPath(ln)
+			}
+		}
+	}
+	var (
+		d   *dcel
+		op  overlayOperator
+		err error
+	)
+	if distance >= 0 {
+		all := append(input.polys, b.pieces...)
+		d, err = newDCEL(polygonsAsCollection(all), NewGeometryCollection(nil).AsGeometry())
+		op = selectUnion
+	} else {
+		d, err = newDCEL(polygonsAsCollection(input.polys), polygonsAsCollection(b.pieces))
+		op = selectDifference
+	}
+	if err != nil {
+		return Geometry{}, fmt.Errorf("could not calculate buffer: %v", err)
+	}
+	polys, err := d.extractPolygons(op)
+	if err != nil {
+		return Geometry{}, fmt.Errorf("could not calculate buffer: %v", err)
+	}
+	return overlayResult(polys, nil, nil, 2)
+}
+
+func polygonsAsCollection(polys []Polygon) Geometry {
+	geoms := make([]Geometry, len(polys))
+	for i, p := range polys {
+		geoms[i] = p.AsGeometry()
+	}
+	return NewGeometryCollection(geoms).AsGeometry()
+}
+
+// bufferBuilder accumulates the polygonal pieces that make up a buffer.
+type bufferBuilder struct {
+	opts   bufferOptionSet
+	dist   float64
+	pieces []Polygon
+}
+
+func (b *bufferBuilder) addPiece(ring []XY) {
+	ring = append(ring, ring[0])
+	// Pieces may be degenerate (e.g. a wedge at a very shallow corner), but
+	// are still usable because they are only ever noded and checked for
+	// point containment.
+	poly, err := NewPolygonXY([][]XY{ring}, DisableAllValidations)
+	if err != nil {
+		// Cannot occur, since validations are disabled.
+		panic(err)
+	}
+	b.pieces = append(b.pieces, poly)
+}
+
+func (b *bufferBuilder) addPoint(pt XY) {
+	switch b.opts.endCap {
+	case bufferEndCapRound:
+		n := 4 * b.opts.quadSegs
+		ring := make([]XY, n)
+		for i := range ring {
+			theta := 2 * math.Pi * float64(i) / float64(n)
+			ring[i] = XY{
+				pt.X + b.dist*math.Cos(theta),
+				pt.Y + b.dist*math.Sin(theta),
+			}
+		}
+		b.addPiece(ring)
+	case bufferEndCapSquare:
+		b.addPiece([]XY{
+			{pt.X - b.dist, pt.Y - b.dist},
+			{pt.X + b.dist, pt.Y - b.dist},
+			{pt.X + b.dist, pt.Y + b.dist},
+			{pt.X - b.dist, pt.Y + b.dist},
+		})
+	}
+}
+
+// addPath adds the pieces for an open line. Lines with no length are
+// buffered in the same way as points.
+func (b *bufferBuilder) addPath(pts []XY) {
+	pts = dedupXYs(pts)
+	if len(pts) == 1 {
+		b.addPoint(pts[0])
+		return
+	}
+	for i := 0; i+1 < len(pts); i++ {
+		b.addSegment(pts[i], pts[i+1])
+	}
+	for i := 1; i+1 < len(pts); i++ {
+		b.addJoin(pts[i-1], pts[i], pts[i+1])
+	}
+	n := len(pts)
+	b.addCap(pts[n-2], pts[n-1])
+	b.addCap(pts[1], pts[0])
+}
+
+// addRing adds the pieces for a closed ring. There are no end caps, and each
+// vertex (including the start and end vertex) has a join.
+func (b *bufferBuilder) addRing(pts []XY) {
+	pts = dedupXYs(pts)
+	if len(pts) == 1 {
+		b.addPoint(pts[0])
+		return
+	}
+	pts = pts[:len(pts)-1]
+	n := len(pts)
+	for i := range pts {
+		b.addSegment(pts[i], pts[(i+1)%n])
+		b.addJoin(pts[(i-1+n)%n], pts[i], pts[(i+1)%n])
+	}
+}
+
+func dedupXYs(pts []XY) []XY {
+	var dedup []XY
+	for i, pt := range pts {
+		if i == 0 || pt != pts[i-1] {
+			dedup = append(dedup, pt)
+		}
+	}
+	return dedup
+}
+
+// offset gives the vector perpendicular and to the left of the segment from a
+// to b, with a length equal to the buffer distance.
+func (b *bufferBuilder) offset(from, to XY) XY {
+	dir := to.Sub(from)
+	return XY{-dir.Y, dir.X}.Scale(b.dist / dir.Length())
+}
+
+func (b *bufferBuilder) addSegment(from, to XY) {
+	off := b.offset(from, to)
+	b.addPiece([]XY{from.Add(off), from.Sub(off), to.Sub(off), to.Add(off)})
+}
+
+// addJoin adds the piece that fills the gap on the outside of the corner
+// formed by the segments from prev to v and from v to next.
+func (b *bufferBuilder) addJoin(prev, v, next XY) {
+	u1 := v.Sub(prev)
+	u2 := next.Sub(v)
+	turn := orient2d(prev, v, next)
+	if turn == 0 && u1.Dot(u2) > 0 {
+		return // collinear, so there's no gap
+	}
+
+	// The outside of the corner is on the left for right turns and on the
+	// right for left turns (or either side if the line doubles back).
+	off1 := b.offset(prev, v)
+	off2 := b.offset(v, next)
+	clockwise := true
+	if turn > 0 {
+		off1, off2 = off1.Scale(-1), off2.Scale(-1)
+		clockwise = false
+	}
+
+	switch b.opts.join {
+	case bufferJoinRound:
+		b.addArc(v, off1, off2, clockwise)
+	case bufferJoinBevel:
+		b.addPiece([]XY{v, v.Add(off1), v.Add(off2)})
+	case bufferJoinMitre:
+		b.addMitre(v, off1, off2, u1, u2)
+	}
+}
+
+// addArc adds a wedge shaped piece, centered at c, that sweeps from c+off1 to
+// c+off2.
+func (b *bufferBuilder) addArc(c, off1, off2 XY, clockwise bool) {
+	start := math.Atan2(off1.Y, off1.X)
+	sweep := math.Atan2(off2.Y, off2.X) - start
+	if clockwise {
+		for sweep >= 0 {
+			sweep -= 2 * math.Pi
+		}
+	} else {
+		for sweep <= 0 {
+			sweep += 2 * math.Pi
+		}
+	}
+
+	segs := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2 / float64(b.opts.quadSegs))))
+	ring := []XY{c, c.Add(off1)}
+	for i := 1; i < segs; i++ {
+		theta := start + sweep*float64(i)/float64(segs)
+		ring = append(ring, XY{
+			c.X + b.dist*math.Cos(theta),
+			c.Y + b.dist*math.Sin(theta),
+		})
+	}
+	ring = append(ring, c.Add(off2))
+	b.addPiece(ring)
+}
+
+// addMitre adds a piece that extends the offset lines on the outside of a
+// corner until they meet. If they would meet further than the mitre limit
+// away from the corner, then the piece is cut off at the limit.
+func (b *bufferBuilder) addMitre(v, off1, off2, u1, u2 XY) {
+	u1 = u1.Scale(1 / u1.Length())
+	u2 = u2.Scale(1 / u2.Length())
+	bisector := off1.Add(off2)
+	if bisector.Length() == 0 {
+		// The line doubles back on itself, so the bisector is the
+		// direction of the incoming segment.
+		bisector = u1
+	}
+	bisector = bisector.Scale(1 / bisector.Length())
+
+	p1 := v.Add(off1)
+	p2 := v.Add(off2)
+	limit := b.opts.mitreLimit * b.dist
+	base := off1.Dot(bisector) // distance from v to the bevel line
+	switch {
+	case limit <= base:
+		b.addPiece([]XY{v, p1, p2})
+	case base*limit >= b.dist*b.dist:
+		// The mitre point is within the limit. Its distance from v is
+		// dist/cos(a) where a is the angle between off1 and the bisector,
+		// and base is dist*cos(a).
+		mitre := v.Add(bisector.Scale(b.dist * b.dist / base))
+		b.addPiece([]XY{v, p1, mitre, p2})
+	default:
+		// Extend each offset line to where it meets the line that is
+		// perpendicular to the bisector at the mitre limit.
+		t1 := (limit - base) / u1.Dot(bisector)
+		t2 := (limit - base) / -u2.Dot(bisector)
+		b.addPiece([]XY{v, p1, p1.Add(u1.Scale(t1)), p2.Sub(u2.Scale(t2)), p2})
+	}
+}
+
+// addCap adds the end cap at the end of the segment from prev to end.
+func (b *bufferBuilder) addCap(prev, end XY) {
+	off := b.offset(prev, end)
+	switch b.opts.endCap {
+	case bufferEndCapRound:
+		b.addArc(end, off, off.Scale(-1), true)
+	case bufferEndCapSquare:
+		dir := end.Sub(prev)
+		ext := dir.Scale(b.dist / dir.Length())
+		b.addPiece([]XY{
+			end.Add(off),
+			end.Sub(off),
+			end.Sub(off).Add(ext),
+			end.Add(off).Add(ext),
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package geom_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestBuffer(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		dist float64
+		opts []BufferOption
+		want string
+	}{
+		{"POINT(0 0)", 1, []BufferOption{BufferQuadSegments(1)}, "POLYGON((1 0,0 1,-1 0,0 -1,1 0))"},
+		{"POINT(0 0)", 1, []BufferOption{BufferEndCapSquare}, "POLYGON((-1 -1,1 -1,1 1,-1 1,-1 -1))"},
+		{"POINT(0 0)", 1, []BufferOption{BufferEndCapFlat}, "POLYGON EMPTY"},
+		{"POINT(0 0)", -1, nil, "POLYGON EMPTY"},
+		{"POINT EMPTY", 1, nil, "POLYGON EMPTY"},
+		{"GEOMETRYCOLLECTION EMPTY", 1, nil, "POLYGON EMPTY"},
+
+		{"LINESTRING(0 0,10 0)", 1, []BufferOption{BufferEndCapFlat}, "POLYGON((0 -1,10 -1,10 1,0 1,0 -1))"},
+		{"LINESTRING(0 0,10 0)", 1, []BufferOption{BufferEndCapSquare}, "POLYGON((-1 -1,0 -1,10 -1,11 -1,11 1,10 1,0 1,-1 1,-1 -1))"},
+		{"LINESTRING(0 0,10 0)", 1, []BufferOption{BufferQuadSegments(1)}, "POLYGON((0 -1,10 -1,11 0,10 1,0 1,-1 0,0 -1))"},
+		{"LINESTRING(0 0,10 0)", 0, nil, "POLYGON EMPTY"},
+		{"LINESTRING(0 0,10 0)", -1, nil, "POLYGON EMPTY"},
+		{
+			"LINESTRING(0 0,10 0,10 10)", 1,
+			[]BufferOption{BufferJoinBevel, BufferEndCapFlat},
+			"POLYGON((0 -1,10 -1,11 0,11 10,9 10,9 1,0 1,0 -1))",
+		},
+		{
+			"LINESTRING(0 0,10 0,10 10)", 1,
+			[]BufferOption{BufferJoinMitre(5), BufferEndCapFlat},
+			"POLYGON((0 -1,10 -1,11 -1,11 0,11 10,9 10,9 1,0 1,0 -1))",
+		},
+
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", 0, nil, "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", -1, nil, "POLYGON((1 1,9 1,9 9,1 9,1 1))"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", -5, nil, "POLYGON EMPTY"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", -6, nil, "POLYGON EMPTY"},
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0))", 1,
+			[]BufferOption{BufferJoinMitre(5)},
+			"POLYGON((-1 -1,0 -1,10 -1,11 -1,11 0,11 10,11 11,10 11,0 11,-1 11,-1 10,-1 0,-1 -1))",
+		},
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(3 3,7 3,7 7,3 7,3 3))", -1,
+			[]BufferOption{BufferJoinMitre(5)},
+			"POLYGON((1 1,9 1,9 9,1 9,1 1),(2 2,2 3,2 7,2 8,3 8,7 8,8 8,8 7,8 3,8 2,7 2,3 2,2 2))",
+		},
+		{
+			"MULTIPOLYGON(((0 0,4 0,4 4,0 4,0 0)),((6 0,10 0,10 4,6 4,6 0)))", 1,
+			[]BufferOption{BufferJoinMitre(5)},
+			"POLYGON((-1 -1,0 -1,4 -1,5 -1,6 -1,10 -1,11 -1,11 0,11 4,11 5,10 5,6 5,5 5,4 5,0 5,-1 5,-1 4,-1 0,-1 -1))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := geomFromWKT(t, tt.wkt).Buffer(tt.dist, tt.opts...)
+			expectNoErr(t, err)
+			if !got.EqualsExact(geomFromWKT(t, tt.want), IgnoreOrder, Tolerance(1e-9)) {
+				t.Errorf("\ninput: %s\ndist:  %v\nwant:  %v\ngot:   %v", tt.wkt, tt.dist, tt.want, got.AsText())
+			}
+		})
+	}
+}
+
+func TestBufferArea(t *testing.T) {
+	// Area of a circle approximated using 8 segments per quadrant (which
+	// matches PostGIS).
+	const circle = 16 * 0.19509032201612825
+
+	for i, tt := range []struct {
+		wkt  string
+		dist float64
+		opts []BufferOption
+		want float64
+	}{
+		{"POINT(0 0)", 1, nil, circle},
+		{"POINT(3 4)", 2, nil, 4 * circle},
+		{"MULTIPOINT(0 0,10 0)", 1, nil, 2 * circle},
+		{"LINESTRING(0 0,10 0)", 1, nil, 20 + circle},
+		{"LINESTRING(0 0,10 0,10 10)", 1, nil, 39 + 1.25*circle},
+		{"LINESTRING(0 0,10 0,10 10)", 1, []BufferOption{BufferEndCapFlat, BufferJoinBevel}, 39.5},
+		{"LINESTRING(0 0,10 0,10 10)", 1, []BufferOption{BufferEndCapFlat, BufferJoinMitre(5)}, 40},
+		{
+			// The mitre extends sqrt(2) times the distance, so is cut off.
+			"LINESTRING(0 0,10 0,10 10)", 1,
+			[]BufferOption{BufferEndCapFlat, BufferJoinMitre(1.2)},
+			40 - math.Pow(2-1.2*math.Sqrt2, 2)/2,
+		},
+		{"LINESTRING(0 0,10 0,0 0)", 1, nil, 20 + circle},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", 1, nil, 140 + circle},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", 1, []BufferOption{BufferJoinBevel}, 142},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(4 4,6 4,6 6,4 6,4 4))", 0.5, nil, 120 + 0.25*circle - 1},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(3 3,7 3,7 7,3 7,3 3))", -1, nil, 64 - 32 - circle},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,8 2,8 8,2 8,2 2))", -1, nil, 4 - circle},
+		{"GEOMETRYCOLLECTION(POINT(20 20),LINESTRING(0 0,10 0))", 1, nil, 20 + 2*circle},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := geomFromWKT(t, tt.wkt).Buffer(tt.dist, tt.opts...)
+			expectNoErr(t, err)
+			if !got.IsPolygon() && !got.IsMultiPolygon() {
+				t.Errorf("expected Polygon or MultiPolygon but got %v", got.AsText())
+			}
+			if math.Abs(got.Area()-tt.want) > 1e-9 {
+				t.Errorf("want area %v but got %v", tt.want, got.Area())
+			}
+		})
+	}
+}
+
+func TestBufferDropsZM(t *testing.T) {
+	got, err := geomFromWKT(t, "POINT ZM (0 0 1 2)").Buffer(1, BufferEndCapSquare)
+	expectNoErr(t, err)
+	expectGeomEq(t, got, geomFromWKT(t, "POLYGON((-1 -1,1 -1,1 1,-1 1,-1 -1))"), IgnoreOrder)
+}
+
+func BenchmarkBufferLineString(b *testing.B) {
+	for _, sz := range []int{100, 400, 1600} {
+		b.Run("n="+strconv.Itoa(sz), func(b *testing.B) {
+			// A zigzag, so that the buffered segments overlap their
+			// neighbours.
+			xys := make([]XY, sz)
+			for i := range xys {
+				xys[i] = XY{X: float64(i), Y: float64(i % 2)}
+			}
+			ls, err := NewLineStringXY(xys)
+			if err != nil {
+				b.Fatal(err)
+			}
+			g := ls.AsGeometry()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.Buffer(1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
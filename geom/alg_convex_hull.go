@@ -26,8 +26,8 @@ func convexHull(g Geometry) Geometry {
 		return NewPointXY(hull[0]).AsGeometry()
 	case 2:
 		ln, err := NewLineC(
-			Coordinates{hull[0]},
-			Coordinates{hull[1]},
+			Coordinates{XY: hull[0]},
+			Coordinates{XY: hull[1]},
 		)
 		if err != nil {
 			panic("bug in grahamScan routine - output 2 coincident points")
@@ -212,8 +212,16 @@ func sortByPolarAngle(ps []XY) {
 			return false
 		}
 		// In the normal case, check which order the points are in relative to
-		// the anchor.
-		return orientation(anchor, ps[i], ps[j]) == leftTurn
+		// the anchor. Points that are collinear with the anchor are ordered by
+		// their distance from it, so that the ordering is consistent.
+		switch orientation(anchor, ps[i], ps[j]) {
+		case leftTurn:
+			return true
+		case rightTurn:
+			return false
+		default:
+			return distanceSq(anchor, ps[i]) < distanceSq(anchor, ps[j])
+		}
 	})
 }
 
@@ -0,0 +1,138 @@
+package geom
+
+import "math"
+
+// distanceElement is either a point or a line segment (points are represented
+// as segments with equal endpoints).
+type distanceElement struct {
+	a, b XY
+	env  Envelope
+}
+
+func distanceElements(input overlayInput) []distanceElement {
+	var elems []distanceElement
+	for _, pt := range input.points {
+		elems = append(elems, distanceElement{pt, pt, NewEnvelope(pt)})
+	}
+	for _, ls := range append(input.lines, input.rings...) {
+		if len(ls) == 1 {
+			elems = append(elems, distanceElement{ls[0], ls[0], NewEnvelope(ls[0])})
+		}
+		for i := 0; i+1 < len(ls); i++ {
+			elems = append(elems, distanceElement{ls[i], ls[i+1], NewEnvelope(ls[i], ls[i+1])})
+		}
+	}
+	return elems
+}
+
+// closestPoints finds a point on each geometry such that the distance between
+// the two points is the shortest distance between the geometries. The search
+// may stop early (giving a non-optimal result) once a pair of points within
+// the stopAt distance of each other is found. It returns false if either
+// geometry is empty.
+func closestPoints(g1, g2 Geometry, stopAt float64) (XY, XY, float64, bool) {
+	var in1, in2 overlayInput
+	in1.add(g1.Force2D())
+	in2.add(g2.Force2D())
+	elems1 := distanceElements(in1)
+	elems2 := distanceElements(in2)
+	if len(elems1) == 0 || len(elems2) == 0 {
+		return XY{}, XY{}, 0, false
+	}
+
+	// If one geometry is inside an area of the other, then their boundaries
+	// don't necessarily intersect (even though the distance is zero). Testing
+	// a single vertex of each component is enough to detect this, since the
+	// boundaries would intersect if only part of a component was inside.
+	if pt, ok := representativeInArea(in1, in2); ok {
+		return pt, pt, 0, true
+	}
+	if pt, ok := representativeInArea(in2, in1); ok {
+		return pt, pt, 0, true
+	}
+
+	best := math.Inf(+1)
+	var best1, best2 XY
+	for _, e1 := range elems1 {
+		for _, e2 := range elems2 {
+			if e1.env.Distance(e2.env) >= best {
+				continue
+			}
+			p1, p2 := closestPointsBetweenSegments(e1.a, e1.b, e2.a, e2.b)
+			if d := p1.Sub(p2).Length(); d < best {
+				best, best1, best2 = d, p1, p2
+				if best <= stopAt {
+					return best1, best2, best, true
+				}
+			}
+		}
+	}
+	return best1, best2, best, true
+}
+
+// representativeInArea checks if any component of the first input has a
+// vertex inside (or on the boundary of) an area from the second input.
+func representativeInArea(in, areas overlayInput) (XY, bool) {
+	if len(areas.polys) == 0 {
+		return XY{}, false
+	}
+	var reps []XY
+	reps = append(reps, in.points...)
+	for _, ls := range append(in.lines, in.rings...) {
+		if len(ls) > 0 {
+			reps = append(reps, ls[0])
+		}
+	}
+	for _, pt := range reps {
+		for _, p := range areas.polys {
+			if pointPolygonSide(pt, p) != exterior {
+				return pt, true
+			}
+		}
+	}
+	return XY{}, false
+}
+
+// closestPointsBetweenSegments finds the closest pair of points between the
+// line segment from a to b and the line segment from c to d. Either segment
+// may be degenerate (i.e. a point).
+func closestPointsBetweenSegments(a, b, c, d XY) (XY, XY) {
+	if a != b && c != d {
+		inter := intersectLineWithLineNoAlloc(
+			Line{Coordinates{XY: a}, Coordinates{XY: b}},
+			Line{Coordinates{XY: c}, Coordinates{XY: d}},
+		)
+		if !inter.empty {
+			return inter.ptA, inter.ptA
+		}
+	}
+
+	// When the segments don't intersect, at least one of the closest points
+	// is an endpoint of one of the segments.
+	candidates := [4][2]XY{
+		{a, closestPointOnSegment(a, c, d)},
+		{b, closestPointOnSegment(b, c, d)},
+		{closestPointOnSegment(c, a, b), c},
+		{closestPointOnSegment(d, a, b), d},
+	}
+	best := candidates[0]
+	for _, cand := range candidates[1:] {
+		if cand[0].Sub(cand[1]).Length() < best[0].Sub(best[1]).Length() {
+			best = cand
+		}
+	}
+	return best[0], best[1]
+}
+
+// closestPointOnSegment finds the point on the line segment from a to b that
+// is closest to pt.
+func closestPointOnSegment(pt, a, b XY) XY {
+	ab := b.Sub(a)
+	lenSq := ab.Dot(ab)
+	if lenSq == 0 {
+		return a
+	}
+	t := pt.Sub(a).Dot(ab) / lenSq
+	t = math.Max(0, math.Min(1, t))
+	return a.Add(ab.Scale(t))
+}
@@ -0,0 +1,89 @@
+package geom_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+		dist       float64
+		pt1, pt2   string
+	}{
+		{"POINT(1 2)", "POINT(1 2)", 0, "POINT(1 2)", "POINT(1 2)"},
+		{"POINT(0 0)", "POINT(3 4)", 5, "POINT(0 0)", "POINT(3 4)"},
+		{"POINT(1 1)", "LINESTRING(0 0,2 0)", 1, "POINT(1 1)", "POINT(1 0)"},
+		{"POINT(3 1)", "LINESTRING(0 0,2 0)", math.Sqrt2, "POINT(3 1)", "POINT(2 0)"},
+		{"POINT(1 0)", "LINESTRING(0 0,2 0)", 0, "POINT(1 0)", "POINT(1 0)"},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)", 0, "POINT(1 1)", "POINT(1 1)"},
+		{"LINESTRING(0 0,2 0)", "LINESTRING(3 1,3 5)", math.Sqrt2, "POINT(2 0)", "POINT(3 1)"},
+		{"LINESTRING(0 0,4 0)", "LINESTRING(1 3,2 1,3 3)", 1, "POINT(2 0)", "POINT(2 1)"},
+		{"MULTIPOINT(0 0,10 0)", "MULTIPOINT(5 5,9 1)", math.Sqrt2, "POINT(10 0)", "POINT(9 1)"},
+
+		// Polygons are areal, so geometries inside them have zero distance
+		// even if they don't touch the boundary.
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POINT(1 1)", 0, "POINT(1 1)", "POINT(1 1)"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", "LINESTRING(1 1,2 2)", 0, "POINT(1 1)", "POINT(1 1)"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POLYGON((1 1,2 1,2 2,1 2,1 1))", 0, "POINT(1 1)", "POINT(1 1)"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POINT(6 2)", 2, "POINT(4 2)", "POINT(6 2)"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,3 1,3 3,1 3,1 1))", "POINT(2 2.5)", 0.5, "POINT(2 3)", "POINT(2 2.5)"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POLYGON((5 5,6 5,6 6,5 6,5 5))", math.Sqrt2, "POINT(4 4)", "POINT(5 5)"},
+		{"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((10 0,11 0,11 1,10 1,10 0)))", "POINT(12 0.5)", 1, "POINT(11 0.5)", "POINT(12 0.5)"},
+		{"GEOMETRYCOLLECTION(POINT(0 0),LINESTRING(10 0,10 10))", "POINT(7 5)", 3, "POINT(10 5)", "POINT(7 5)"},
+		{"POINT Z (0 0 10)", "POINT M (3 4 20)", 5, "POINT(0 0)", "POINT(3 4)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.wkt1)
+			g2 := geomFromWKT(t, tt.wkt2)
+
+			t.Run("forward", func(t *testing.T) {
+				dist, ok := g1.Distance(g2)
+				expectBoolEq(t, ok, true)
+				if math.Abs(dist-tt.dist) > 1e-15 {
+					t.Errorf("want distance %v but got %v", tt.dist, dist)
+				}
+				pt1, pt2, ok := g1.ClosestPoints(g2)
+				expectBoolEq(t, ok, true)
+				expectGeomEq(t, pt1.AsGeometry(), geomFromWKT(t, tt.pt1))
+				expectGeomEq(t, pt2.AsGeometry(), geomFromWKT(t, tt.pt2))
+			})
+			t.Run("reversed", func(t *testing.T) {
+				dist, ok := g2.Distance(g1)
+				expectBoolEq(t, ok, true)
+				if math.Abs(dist-tt.dist) > 1e-15 {
+					t.Errorf("want distance %v but got %v", tt.dist, dist)
+				}
+			})
+			t.Run("dwithin", func(t *testing.T) {
+				expectBoolEq(t, g1.DWithin(g2, tt.dist), true)
+				expectBoolEq(t, g2.DWithin(g1, tt.dist+1), true)
+				if tt.dist > 0 {
+					expectBoolEq(t, g1.DWithin(g2, tt.dist*0.99), false)
+				}
+			})
+		})
+	}
+}
+
+func TestDistanceEmpty(t *testing.T) {
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+	}{
+		{"POINT EMPTY", "POINT(1 2)"},
+		{"POINT(1 2)", "LINESTRING EMPTY"},
+		{"GEOMETRYCOLLECTION EMPTY", "POLYGON((0 0,1 0,0 1,0 0))"},
+		{"GEOMETRYCOLLECTION(POINT EMPTY)", "GEOMETRYCOLLECTION(POINT EMPTY)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.wkt1)
+			g2 := geomFromWKT(t, tt.wkt2)
+			_, ok := g1.Distance(g2)
+			expectBoolEq(t, ok, false)
+			_, _, ok = g1.ClosestPoints(g2)
+			expectBoolEq(t, ok, false)
+			expectBoolEq(t, g1.DWithin(g2, math.Inf(+1)), false)
+		})
+	}
+}
@@ -19,16 +19,35 @@ func newEqualsExactOptionSet(opts []EqualsExactOption) equalsExactOptionSet {
 
 // Tolerance modifies the behaviour of the EqualsExact method by allowing two
 // geometry control points be be considered equal if they are within the given
-// euclidean distance of each other.
+// euclidean distance of each other. Any Z and M values must also be within the
+// given tolerance of each other.
 func Tolerance(within float64) EqualsExactOption {
 	return func(s *equalsExactOptionSet) {
 		s.toleranceSq = within * within
 	}
 }
 
-func (os equalsExactOptionSet) eq(a XY, b XY) bool {
-	asb := a.Sub(b)
-	return asb.Dot(asb) <= os.toleranceSq
+func (os equalsExactOptionSet) eq(a, b Coordinates) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	asb := a.XY.Sub(b.XY)
+	if asb.Dot(asb) > os.toleranceSq {
+		return false
+	}
+	if a.Type.Is3D() && !os.eqOrdinate(a.Z, b.Z) {
+		return false
+	}
+	if a.Type.IsMeasured() && !os.eqOrdinate(a.M, b.M) {
+		return false
+	}
+	return true
+}
+
+// eqOrdinate checks if two Z or M values are equal (within tolerance).
+func (os equalsExactOptionSet) eqOrdinate(a, b float64) bool {
+	d := a - b
+	return d*d <= os.toleranceSq
 }
 
 // IgnoreOrder modifies the behaviour of the EqualsExact method by ignoring
@@ -76,8 +95,8 @@ func curvesExactEqual(c1, c2 curve, opts []EqualsExactOption) bool {
 	type curveMapping func(int) int
 	sameCurve := func(m1, m2 curveMapping) bool {
 		for i := 0; i < n; i++ {
-			pt1 := c1.PointN(m1(i)).XY()
-			pt2 := c2.PointN(m2(i)).XY()
+			pt1 := c1.PointN(m1(i)).Coordinates()
+			pt2 := c2.PointN(m2(i)).Coordinates()
 			if !os.eq(pt1, pt2) {
 				return false
 			}
@@ -121,13 +140,13 @@ func isRing(c curve) bool {
 
 func multiPointExactEqual(mp1, mp2 MultiPoint, opts []EqualsExactOption) bool {
 	n := mp1.NumPoints()
-	if mp2.NumPoints() != n {
+	if mp2.NumPoints() != n || mp1.CoordinatesType() != mp2.CoordinatesType() {
 		return false
 	}
 	os := newEqualsExactOptionSet(opts)
 	ptsEq := func(i, j int) bool {
-		ptA := mp1.PointN(i).XY()
-		ptB := mp2.PointN(j).XY()
+		ptA := mp1.PointN(i).Coordinates()
+		ptB := mp2.PointN(j).Coordinates()
 		return os.eq(ptA, ptB)
 	}
 	return structureEqual(n, ptsEq, os.ignoreOrder)
@@ -151,7 +170,7 @@ func polygonExactEqual(p1, p2 Polygon, opts []EqualsExactOption) bool {
 
 func multiLineStringExactEqual(mls1, mls2 MultiLineString, opts []EqualsExactOption) bool {
 	n := mls1.NumLineStrings()
-	if n != mls2.NumLineStrings() {
+	if n != mls2.NumLineStrings() || mls1.CoordinatesType() != mls2.CoordinatesType() {
 		return false
 	}
 	lsEq := func(i, j int) bool {
@@ -164,7 +183,7 @@ func multiLineStringExactEqual(mls1, mls2 MultiLineString, opts []EqualsExactOpt
 
 func multiPolygonExactEqual(mp1, mp2 MultiPolygon, opts []EqualsExactOption) bool {
 	n := mp1.NumPolygons()
-	if n != mp2.NumPolygons() {
+	if n != mp2.NumPolygons() || mp1.CoordinatesType() != mp2.CoordinatesType() {
 		return false
 	}
 	polyEq := func(i, j int) bool {
@@ -177,7 +196,7 @@ func multiPolygonExactEqual(mp1, mp2 MultiPolygon, opts []EqualsExactOption) boo
 
 func geometryCollectionExactEqual(gc1, gc2 GeometryCollection, opts []EqualsExactOption) bool {
 	n := gc1.NumGeometries()
-	if n != gc2.NumGeometries() {
+	if n != gc2.NumGeometries() || gc1.CoordinatesType() != gc2.CoordinatesType() {
 		return false
 	}
 	eq := func(i, j int) bool {
@@ -6,10 +6,6 @@ import (
 	"sort"
 )
 
-func noImpl(t1, t2 interface{}) error {
-	return fmt.Errorf("operation not implemented for type pair %T and %T", t1, t2)
-}
-
 func mustIntersection(g1, g2 Geometry) Geometry {
 	g, err := intersection(g1, g2)
 	if err != nil {
@@ -50,11 +46,11 @@ func intersection(g1, g2 Geometry) (Geometry, error) {
 		case g2.IsMultiPoint():
 			return intersectPointWithMultiPoint(g1.AsPoint(), g2.AsMultiPoint()), nil
 		case g2.IsMultiLineString():
-			return Geometry{}, noImpl(g1.AsPoint(), g2.AsMultiLineString())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsPoint(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsPoint(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsLine():
 		switch {
@@ -70,15 +66,15 @@ func intersection(g1, g2 Geometry) (Geometry, error) {
 				NewMultiLineString([]LineString{g2.AsLineString()}),
 			)
 		case g2.IsPolygon():
-			return Geometry{}, noImpl(g1.AsLine(), g2.AsPolygon)
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPoint():
 			return intersectLineWithMultiPoint(g1.AsLine(), g2.AsMultiPoint())
 		case g2.IsMultiLineString():
-			return Geometry{}, noImpl(g1.AsLine(), g2.AsMultiLineString())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsLine(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsLine(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsLineString():
 		switch {
@@ -88,63 +84,63 @@ func intersection(g1, g2 Geometry) (Geometry, error) {
 				NewMultiLineString([]LineString{g2.AsLineString()}),
 			)
 		case g2.IsPolygon():
-			return Geometry{}, noImpl(g1.AsLineString(), g2.AsPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPoint():
-			return Geometry{}, noImpl(g1.AsLineString(), g2.AsMultiPoint())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiLineString():
 			return intersectMultiLineStringWithMultiLineString(
 				NewMultiLineString([]LineString{g1.AsLineString()}),
 				g2.AsMultiLineString(),
 			)
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsLineString(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsLineString(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsPolygon():
 		switch {
 		case g2.IsPolygon():
-			return Geometry{}, noImpl(g1.AsPolygon(), g2.AsPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPoint():
 			return intersectMultiPointWithPolygon(g2.AsMultiPoint(), g1.AsPolygon())
 		case g2.IsMultiLineString():
-			return Geometry{}, noImpl(g1.AsPolygon(), g2.AsMultiLineString())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsPolygon(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsPolygon(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsMultiPoint():
 		switch {
 		case g2.IsMultiPoint():
 			return intersectMultiPointWithMultiPoint(g1.AsMultiPoint(), g2.AsMultiPoint())
 		case g2.IsMultiLineString():
-			return Geometry{}, noImpl(g1.AsMultiPoint(), g2.AsMultiLineString())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsMultiPoint(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsMultiPoint(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsMultiLineString():
 		switch {
 		case g2.IsMultiLineString():
 			return intersectMultiLineStringWithMultiLineString(g1.AsMultiLineString(), g2.AsMultiLineString())
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsMultiLineString(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsMultiLineString(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsMultiPolygon():
 		switch {
 		case g2.IsMultiPolygon():
-			return Geometry{}, noImpl(g1.AsMultiPolygon(), g2.AsMultiPolygon())
+			return intersectWithOverlay(g1, g2)
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsMultiPolygon(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	case g1.IsGeometryCollection():
 		switch {
 		case g2.IsGeometryCollection():
-			return Geometry{}, noImpl(g1.AsGeometryCollection(), g2.AsGeometryCollection())
+			return intersectWithOverlay(g1, g2)
 		}
 	}
 
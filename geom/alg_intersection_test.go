@@ -169,6 +169,55 @@ func TestIntersection(t *testing.T) {
 		{"LINESTRING(0 0,1 0,0 1,0 0)", "LINESTRING(0 0,1 0,1 1,0 1)", "GEOMETRYCOLLECTION(POINT(0 1),LINESTRING(0 0,1 0))"},
 		{"LINESTRING(0 0,1 0,0 1,0 0)", "MULTILINESTRING((0 0,0 1,1 1),(0 1,0 0,1 0))", "MULTILINESTRING((0 0,1 0),(0 1,0 0))"},
 
+		// Point/MultiLineString
+		{"POINT(1 1)", "MULTILINESTRING((0 0,2 2),(3 3,4 4))", "POINT(1 1)"},
+		{"POINT(1 0)", "MULTILINESTRING((0 0,2 2),(3 3,4 4))", "GEOMETRYCOLLECTION EMPTY"},
+
+		// Point/MultiPolygon
+		{"POINT(5 5)", "MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((4 4,6 4,6 6,4 6,4 4)))", "POINT(5 5)"},
+		{"POINT(2 2)", "MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((4 4,6 4,6 6,4 6,4 4)))", "GEOMETRYCOLLECTION EMPTY"},
+
+		// Line/Polygon
+		{"LINESTRING(-1 1,3 1)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "LINESTRING(0 1,2 1)"},
+		{"LINESTRING(-1 3,3 3)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "GEOMETRYCOLLECTION EMPTY"},
+		{"LINESTRING(0 0,2 0)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "LINESTRING(0 0,2 0)"},
+
+		// LineString/Polygon
+		{"LINESTRING(-1 1,1 1,1 3)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "LINESTRING(0 1,1 1,1 2)"},
+		{"LINESTRING(-1 1,1 1,1 3)", "POLYGON((0 0,2 0,2 2,0 2,0 0),(0.5 0.5,1.5 0.5,1.5 1.5,0.5 1.5,0.5 0.5))", "MULTILINESTRING((0 1,0.5 1),(1 1.5,1 2))"},
+		{"LINESTRING(2 2,3 3)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "POINT(2 2)"},
+
+		// LineString/MultiPoint
+		{"LINESTRING(0 0,2 2,4 0)", "MULTIPOINT(1 1,2 0,4 0)", "MULTIPOINT(1 1,4 0)"},
+
+		// Polygon/Polygon
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POLYGON((1 1,3 1,3 3,1 3,1 1))", "POLYGON((1 1,2 1,2 2,1 2,1 1))"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((1 0,2 0,2 1,1 1,1 0))", "LINESTRING(1 0,1 1)"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((1 1,2 1,2 2,1 2,1 1))", "POINT(1 1)"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((2 2,3 2,3 3,2 3,2 2))", "GEOMETRYCOLLECTION EMPTY"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,3 1,3 3,1 3,1 1))", "POLYGON((2 2,5 2,5 5,2 5,2 2))", "POLYGON((3 2,4 2,4 4,2 4,2 3,3 3,3 2))"},
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POLYGON((1 0,3 0,3 1,2 2,1 2,1 0))", "POLYGON((1 0,2 0,2 2,1 2,1 0))"},
+
+		// Polygon/MultiLineString
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "MULTILINESTRING((-1 1,3 1),(1 -1,1 3))", "MULTILINESTRING((0 1,1 1),(1 1,2 1),(1 0,1 1),(1 1,1 2))"},
+
+		// MultiPolygon/MultiPolygon
+		{
+			"MULTIPOLYGON(((0 0,2 0,2 2,0 2,0 0)),((3 0,5 0,5 2,3 2,3 0)))",
+			"MULTIPOLYGON(((1 1,4 1,4 3,1 3,1 1)))",
+			"MULTIPOLYGON(((1 1,2 1,2 2,1 2,1 1)),((3 1,4 1,4 2,3 2,3 1)))",
+		},
+		{
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)))",
+			"MULTIPOLYGON(((1 0,2 0,2 1,1 1,1 0)),((0 1,1 1,1 2,0 2,0 1)))",
+			"LINESTRING(1 0,1 1,0 1)",
+		},
+
+		// GeometryCollection/ANY
+		{"GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 3,3 3))", "POLYGON((0 0,2 0,2 4,0 4,0 0))", "GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 3,2 3))"},
+		{"GEOMETRYCOLLECTION(POINT(1 1))", "GEOMETRYCOLLECTION(MULTIPOINT(1 1,2 2))", "POINT(1 1)"},
+		{"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 2,0 2,0 0)))", "GEOMETRYCOLLECTION(POINT(5 5))", "GEOMETRYCOLLECTION EMPTY"},
+
 		// The following two test cases were fonud using fuzz, however they
 		// currently don't pass. The difference in the result is cosmetic --
 		// the difference between "MULTILINESTRING((0 0,0.5 0.5),(0.5 0.5,1 1))"
@@ -365,7 +365,7 @@ func hasIntersectionMultiLineStringWithMultiLineString(
 						if env.Min() != env.Max() {
 							return true, mlsWithMLSIntersectsExtension{
 								multiplePoints: true,
-								singlePoint:    env.Min(),
+								singlePoint:    inter.ptA,
 							}
 						}
 					}
@@ -404,12 +404,7 @@ func hasIntersectionPointWithLine(point Point, line Line) bool {
 	if !env.Contains(point.coords.XY) {
 		return false
 	}
-	lhs := (point.coords.X - line.a.X) * (line.b.Y - line.a.Y)
-	rhs := (point.coords.Y - line.a.Y) * (line.b.X - line.a.X)
-	if lhs == rhs {
-		return true
-	}
-	return false
+	return orientation(line.a.XY, line.b.XY, point.coords.XY) == collinear
 }
 
 func hasIntersectionPointWithLineString(pt Point, ls LineString) bool {
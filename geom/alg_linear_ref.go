@@ -0,0 +1,348 @@
+package geom
+
+import "math"
+
+// The functions in this file implement linear referencing, i.e. locating
+// points along linear geometries using either the distance along the
+// geometry, or the M (measure) values of its coordinates.
+//
+// MultiLineStrings are treated as a single path, made up of each of their
+// LineStrings in order. Fractions are relative to the total length of the
+// path.
+
+// InterpolatePoint gives the point that is the given fraction of the way
+// along the LineString (where 0 is the start, and 1 is the end). The fraction
+// is clamped to be between 0 and 1. Any Z and M values are interpolated.
+func (s LineString) InterpolatePoint(fraction float64) Point {
+	pt, _ := interpolatePoint([]LineString{s}, fraction)
+	return pt
+}
+
+// InterpolatePoint gives the point that is the given fraction of the way
+// along the MultiLineString (where 0 is the start of the first LineString,
+// and 1 is the end of the last LineString). The fraction is clamped to be
+// between 0 and 1. Any Z and M values are interpolated. It returns false if
+// the MultiLineString is empty.
+func (m MultiLineString) InterpolatePoint(fraction float64) (Point, bool) {
+	return interpolatePoint(m.lines, fraction)
+}
+
+// InterpolatePoints gives the points that are at each multiple of the step
+// fraction along the LineString, i.e. at step, 2*step, 3*step and so on (up
+// to and including 1). A step that is zero, negative, or NaN results in just
+// the start point. Steps smaller than 2^-20 are treated as 2^-20 (so that at
+// most 2^20 points are given). Any Z and M values are interpolated.
+func (s LineString) InterpolatePoints(step float64) MultiPoint {
+	return interpolatePoints([]LineString{s}, step)
+}
+
+// InterpolatePoints gives the points that are at each multiple of the step
+// fraction along the MultiLineString, i.e. at step, 2*step, 3*step and so on
+// (up to and including 1). A step that is zero, negative, or NaN results in
+// just the start point. Steps smaller than 2^-20 are treated as 2^-20 (so that
+// at most 2^20 points are given). Any Z and M values are interpolated.
+func (m MultiLineString) InterpolatePoints(step float64) MultiPoint {
+	return interpolatePoints(m.lines, step).ForceCoordinatesType(m.ctype)
+}
+
+// LocatePoint finds the point on the LineString that is closest to pt, and
+// gives the fraction of the way along the LineString that the closest point
+// is.
+func (s LineString) LocatePoint(pt Point) float64 {
+	f, _ := locatePoint([]LineString{s}, pt.XY())
+	return f
+}
+
+// LocatePoint finds the point on the MultiLineString that is closest to pt,
+// and gives the fraction of the way along the MultiLineString that the
+// closest point is. It returns false if the MultiLineString is empty.
+func (m MultiLineString) LocatePoint(pt Point) (float64, bool) {
+	return locatePoint(m.lines, pt.XY())
+}
+
+// Substring gives the part of the LineString between two fractions of the
+// way along it. The fractions are clamped to be between 0 and 1, and are
+// swapped if from is greater than to. The result is a LineString, or a Point
+// if the fractions are the same. Any Z and M values are interpolated.
+func (s LineString) Substring(from, to float64) Geometry {
+	lss := []LineString{s}
+	parts := substring(lss, from, to)
+	if len(parts) == 0 {
+		pt, _ := interpolatePoint(lss, from)
+		return pt.AsGeometry()
+	}
+	return parts[0].AsGeometry()
+}
+
+// Substring gives the part of the MultiLineString between two fractions of
+// the way along it. The fractions are clamped to be between 0 and 1, and are
+// swapped if from is greater than to. The result is a MultiLineString, or a
+// Point if the fractions are the same. Any Z and M values are interpolated.
+func (m MultiLineString) Substring(from, to float64) Geometry {
+	parts := substring(m.lines, from, to)
+	if len(parts) == 0 && clampFraction(from) == clampFraction(to) {
+		if pt, ok := m.InterpolatePoint(from); ok {
+			return pt.AsGeometry()
+		}
+	}
+	return NewMultiLineString(parts).ForceCoordinatesType(m.ctype).AsGeometry()
+}
+
+// LocateAlong finds the points along the LineString where the M value is
+// equal to the given measure. M values are linearly interpolated between
+// control points. The result is empty if the LineString doesn't have M
+// values.
+func (s LineString) LocateAlong(measure float64) MultiPoint {
+	return locateAlong([]LineString{s}, measure).ForceCoordinatesType(s.CoordinatesType())
+}
+
+// LocateAlong finds the points along the MultiLineString where the M value is
+// equal to the given measure. M values are linearly interpolated between
+// control points. The result is empty if the MultiLineString doesn't have M
+// values.
+func (m MultiLineString) LocateAlong(measure float64) MultiPoint {
+	return locateAlong(m.lines, measure).ForceCoordinatesType(m.ctype)
+}
+
+// LocateBetween finds the parts of the LineString where the M value is
+// between from and to (inclusive). M values are linearly interpolated between
+// control points. Parts that consist of a single point are omitted. The
+// result is empty if the LineString doesn't have M values.
+func (s LineString) LocateBetween(from, to float64) MultiLineString {
+	return locateBetween([]LineString{s}, from, to).ForceCoordinatesType(s.CoordinatesType())
+}
+
+// LocateBetween finds the parts of the MultiLineString where the M value is
+// between from and to (inclusive). M values are linearly interpolated between
+// control points. Parts that consist of a single point are omitted. The
+// result is empty if the MultiLineString doesn't have M values.
+func (m MultiLineString) LocateBetween(from, to float64) MultiLineString {
+	return locateBetween(m.lines, from, to).ForceCoordinatesType(m.ctype)
+}
+
+func clampFraction(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// interpolateCoordinates gives the coordinates that are the fraction t of the
+// way from a to b. The endpoints are reproduced exactly when t is 0 or 1.
+func interpolateCoordinates(a, b Coordinates, t float64) Coordinates {
+	switch t {
+	case 0:
+		return a
+	case 1:
+		return b
+	}
+	return Coordinates{
+		XY:   a.XY.Add(b.XY.Sub(a.XY).Scale(t)),
+		Z:    a.Z + t*(b.Z-a.Z),
+		M:    a.M + t*(b.M-a.M),
+		Type: a.Type,
+	}
+}
+
+func totalLength(lss []LineString) float64 {
+	var total float64
+	for _, ls := range lss {
+		total += ls.Length()
+	}
+	return total
+}
+
+// coordinatesAtDistance finds the coordinates that are the given distance
+// along the path made up of the LineStrings.
+func coordinatesAtDistance(lss []LineString, dist float64) Coordinates {
+	var last Line
+	for _, ls := range lss {
+		for i := 0; i < ls.NumLines(); i++ {
+			last = ls.LineN(i)
+			segLen := last.Length()
+			if dist <= segLen {
+				return interpolateCoordinates(last.a, last.b, dist/segLen)
+			}
+			dist -= segLen
+		}
+	}
+	// Only reached due to rounding errors, when dist is very close to the
+	// total length.
+	return last.b
+}
+
+func interpolatePoint(lss []LineString, fraction float64) (Point, bool) {
+	if len(lss) == 0 {
+		return Point{}, false
+	}
+	dist := clampFraction(fraction) * totalLength(lss)
+	return NewPointC(coordinatesAtDistance(lss, dist)), true
+}
+
+// maxInterpolatedPoints limits the number of points that InterpolatePoints
+// gives, so that tiny steps don't cause huge allocations.
+const maxInterpolatedPoints = 1 << 20
+
+func interpolatePoints(lss []LineString, step float64) MultiPoint {
+	if len(lss) == 0 {
+		return NewMultiPoint(nil)
+	}
+	total := totalLength(lss)
+	if !(step > 0) { // also catches NaN
+		return NewMultiPoint([]Point{NewPointC(coordinatesAtDistance(lss, 0))})
+	}
+	step = math.Max(math.Min(step, 1), 1.0/maxInterpolatedPoints)
+	n := int(math.Floor(1 / step))
+	pts := make([]Point, n)
+	for i := range pts {
+		f := math.Min(1, float64(i+1)*step)
+		pts[i] = NewPointC(coordinatesAtDistance(lss, f*total))
+	}
+	return NewMultiPoint(pts)
+}
+
+func locatePoint(lss []LineString, pt XY) (float64, bool) {
+	if len(lss) == 0 {
+		return 0, false
+	}
+	var (
+		bestDist   = math.Inf(+1)
+		bestOffset float64
+		offset     float64
+	)
+	for _, ls := range lss {
+		for i := 0; i < ls.NumLines(); i++ {
+			ln := ls.LineN(i)
+			closest := closestPointOnSegment(pt, ln.a.XY, ln.b.XY)
+			if d := closest.Sub(pt).Length(); d < bestDist {
+				bestDist = d
+				bestOffset = offset + closest.Sub(ln.a.XY).Length()
+			}
+			offset += ln.Length()
+		}
+	}
+	return clampFraction(bestOffset / offset), true
+}
+
+// substring finds the parts of the LineStrings between two fractions of the
+// way along the path that they make up. Parts that have fewer than two
+// distinct points are omitted (so there are no parts if the fractions are the
+// same).
+func substring(lss []LineString, from, to float64) []LineString {
+	from, to = clampFraction(from), clampFraction(to)
+	if from > to {
+		from, to = to, from
+	}
+	total := totalLength(lss)
+	start, end := from*total, to*total
+
+	var parts []LineString
+	var offset float64
+	for _, ls := range lss {
+		var coords []Coordinates
+		add := func(c Coordinates) {
+			if len(coords) == 0 || coords[len(coords)-1].XY != c.XY {
+				coords = append(coords, c)
+			}
+		}
+		for i := 0; i < ls.NumLines(); i++ {
+			ln := ls.LineN(i)
+			segLen := ln.Length()
+			segStart, segEnd := offset, offset+segLen
+			offset = segEnd
+			if segEnd < start || segStart > end {
+				continue
+			}
+			if len(coords) == 0 {
+				t := math.Max(0, (start-segStart)/segLen)
+				add(interpolateCoordinates(ln.a, ln.b, t))
+			}
+			t := math.Min(1, (end-segStart)/segLen)
+			add(interpolateCoordinates(ln.a, ln.b, t))
+		}
+		if part, err := NewLineStringC(coords); err == nil {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func locateAlong(lss []LineString, measure float64) MultiPoint {
+	var pts []Point
+	add := func(c Coordinates) {
+		if len(pts) == 0 || !pts[len(pts)-1].Coordinates().Equals(c) {
+			pts = append(pts, NewPointC(c))
+		}
+	}
+	for _, ls := range lss {
+		if !ls.CoordinatesType().IsMeasured() {
+			continue
+		}
+		for i := 0; i < ls.NumLines(); i++ {
+			ln := ls.LineN(i)
+			ma, mb := ln.a.M, ln.b.M
+			if ma == mb {
+				if ma == measure {
+					add(ln.a)
+					add(ln.b)
+				}
+				continue
+			}
+			t := (measure - ma) / (mb - ma)
+			if t >= 0 && t <= 1 {
+				add(interpolateCoordinates(ln.a, ln.b, t))
+			}
+		}
+	}
+	return NewMultiPoint(pts)
+}
+
+func locateBetween(lss []LineString, from, to float64) MultiLineString {
+	if from > to {
+		from, to = to, from
+	}
+	var parts []LineString
+	var current []Coordinates
+	flush := func() {
+		if part, err := NewLineStringC(current); err == nil {
+			parts = append(parts, part)
+		}
+		current = nil
+	}
+	for _, ls := range lss {
+		if !ls.CoordinatesType().IsMeasured() {
+			continue
+		}
+		for i := 0; i < ls.NumLines(); i++ {
+			ln := ls.LineN(i)
+			ma, mb := ln.a.M, ln.b.M
+			t0, t1 := 0.0, 1.0
+			if ma == mb {
+				if ma < from || ma > to {
+					flush()
+					continue
+				}
+			} else {
+				t0 = (from - ma) / (mb - ma)
+				t1 = (to - ma) / (mb - ma)
+				if t0 > t1 {
+					t0, t1 = t1, t0
+				}
+				t0, t1 = math.Max(0, t0), math.Min(1, t1)
+				if t0 >= t1 {
+					flush()
+					continue
+				}
+			}
+			if t0 > 0 {
+				flush()
+			}
+			if len(current) == 0 {
+				current = append(current, interpolateCoordinates(ln.a, ln.b, t0))
+			}
+			current = append(current, interpolateCoordinates(ln.a, ln.b, t1))
+			if t1 < 1 {
+				flush()
+			}
+		}
+		flush()
+	}
+	return NewMultiLineString(parts)
+}
@@ -0,0 +1,249 @@
+package geom_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+// toLineString converts a geometry parsed from WKT to a LineString (2 point
+// LineStrings are parsed as Lines).
+func toLineString(g Geometry) LineString {
+	if g.IsLine() {
+		return g.AsLine().AsLineString()
+	}
+	return g.AsLineString()
+}
+
+func TestInterpolatePoint(t *testing.T) {
+	for i, tt := range []struct {
+		wkt      string
+		fraction float64
+		want     string
+	}{
+		{"LINESTRING(0 0,10 0)", 0, "POINT(0 0)"},
+		{"LINESTRING(0 0,10 0)", 0.25, "POINT(2.5 0)"},
+		{"LINESTRING(0 0,10 0)", 1, "POINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", -1, "POINT(0 0)"},
+		{"LINESTRING(0 0,10 0)", 2, "POINT(10 0)"},
+		{"LINESTRING(0 0,1 0,1 1,0 1)", 0.5, "POINT(1 0.5)"},
+		{"LINESTRING(0 0,1 0,1 0,1 1)", 0.75, "POINT(1 0.5)"},
+		{"LINESTRING Z (0 0 10,4 0 20)", 0.5, "POINT Z (2 0 15)"},
+		{"LINESTRING M (0 0 10,4 0 20)", 0.25, "POINT M (1 0 12.5)"},
+		{"LINESTRING ZM (0 0 1 2,0 4 3 6)", 0.5, "POINT ZM (0 2 2 4)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ls := toLineString(geomFromWKT(t, tt.wkt))
+			got := ls.InterpolatePoint(tt.fraction)
+			expectGeomEq(t, got.AsGeometry(), geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestInterpolatePointMultiLineString(t *testing.T) {
+	mls := geomFromWKT(t, "MULTILINESTRING((0 0,2 0),(5 0,5 2))").AsMultiLineString()
+	for i, tt := range []struct {
+		fraction float64
+		want     string
+	}{
+		{0, "POINT(0 0)"},
+		{0.25, "POINT(1 0)"},
+		{0.5, "POINT(2 0)"},
+		{0.75, "POINT(5 1)"},
+		{1, "POINT(5 2)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, ok := mls.InterpolatePoint(tt.fraction)
+			expectBoolEq(t, ok, true)
+			expectGeomEq(t, got.AsGeometry(), geomFromWKT(t, tt.want))
+		})
+	}
+	t.Run("empty", func(t *testing.T) {
+		_, ok := NewMultiLineString(nil).InterpolatePoint(0.5)
+		expectBoolEq(t, ok, false)
+	})
+}
+
+func TestInterpolatePoints(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		step float64
+		want string
+	}{
+		{"LINESTRING(0 0,10 0)", 0.25, "MULTIPOINT(2.5 0,5 0,7.5 0,10 0)"},
+		{"LINESTRING(0 0,10 0)", 0.3, "MULTIPOINT(3 0,6 0,9 0)"},
+		{"LINESTRING(0 0,10 0)", 1, "MULTIPOINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", 5, "MULTIPOINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", 0, "MULTIPOINT(0 0)"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", 0.5, "MULTIPOINT(2 0,5 2)"},
+		{"MULTILINESTRING EMPTY", 0.5, "MULTIPOINT EMPTY"},
+		{"LINESTRING(0 0,10 0)", math.NaN(), "MULTIPOINT(0 0)"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", math.NaN(), "MULTIPOINT(0 0)"},
+		{"LINESTRING(0 0,10 0)", math.Inf(+1), "MULTIPOINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", math.Inf(-1), "MULTIPOINT(0 0)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			var got MultiPoint
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).InterpolatePoints(tt.step)
+			} else {
+				got = g.AsMultiLineString().InterpolatePoints(tt.step)
+			}
+			expectGeomEq(t, got.AsGeometry(), geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestInterpolatePointsTinyStep(t *testing.T) {
+	// Tiny steps are limited to 2^20 points, so only the number of points
+	// and the last point are checked.
+	for i, tt := range []struct {
+		wkt      string
+		step     float64
+		wantLast string
+	}{
+		{"LINESTRING(0 0,10 0)", 1e-300, "POINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", math.SmallestNonzeroFloat64, "POINT(10 0)"},
+		{"LINESTRING(0 0,10 0)", 1.0 / (1 << 21), "POINT(10 0)"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", 1e-300, "POINT(5 2)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			var got MultiPoint
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).InterpolatePoints(tt.step)
+			} else {
+				got = g.AsMultiLineString().InterpolatePoints(tt.step)
+			}
+			expectIntEq(t, got.NumPoints(), 1<<20)
+			last := got.PointN(got.NumPoints() - 1)
+			expectGeomEq(t, last.AsGeometry(), geomFromWKT(t, tt.wantLast))
+		})
+	}
+}
+
+func TestLocatePoint(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		pt   string
+		want float64
+	}{
+		{"LINESTRING(0 0,10 0)", "POINT(0 0)", 0},
+		{"LINESTRING(0 0,10 0)", "POINT(2.5 0)", 0.25},
+		{"LINESTRING(0 0,10 0)", "POINT(5 3)", 0.5},
+		{"LINESTRING(0 0,10 0)", "POINT(-3 -3)", 0},
+		{"LINESTRING(0 0,10 0)", "POINT(13 3)", 1},
+		{"LINESTRING(0 0,1 0,1 1,0 1)", "POINT(2 0.5)", 0.5},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", "POINT(6 1)", 0.75},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", "POINT(1 -1)", 0.25},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			pt := geomFromWKT(t, tt.pt).AsPoint()
+			var got float64
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).LocatePoint(pt)
+			} else {
+				var ok bool
+				got, ok = g.AsMultiLineString().LocatePoint(pt)
+				expectBoolEq(t, ok, true)
+			}
+			if got != tt.want {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstring(t *testing.T) {
+	for i, tt := range []struct {
+		wkt      string
+		from, to float64
+		want     string
+	}{
+		{"LINESTRING(0 0,10 0)", 0, 1, "LINESTRING(0 0,10 0)"},
+		{"LINESTRING(0 0,10 0)", 0.25, 0.5, "LINESTRING(2.5 0,5 0)"},
+		{"LINESTRING(0 0,10 0)", 0.5, 0.25, "LINESTRING(2.5 0,5 0)"},
+		{"LINESTRING(0 0,10 0)", -1, 2, "LINESTRING(0 0,10 0)"},
+		{"LINESTRING(0 0,10 0)", 0.5, 0.5, "POINT(5 0)"},
+		{"LINESTRING(0 0,1 0,1 1,0 1)", 0.25, 0.75, "LINESTRING(0.75 0,1 0,1 1,0.75 1)"},
+		{"LINESTRING(0 0,1 0,1 1,0 1)", 1.0 / 3, 2.0 / 3, "LINESTRING(1 0,1 1)"},
+		{"LINESTRING M (0 0 0,4 0 8)", 0.25, 0.5, "LINESTRING M (1 0 2,2 0 4)"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", 0.25, 0.75, "MULTILINESTRING((1 0,2 0),(5 0,5 1))"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", 0.5, 1, "MULTILINESTRING((5 0,5 2))"},
+		{"MULTILINESTRING((0 0,2 0),(5 0,5 2))", 0.75, 0.75, "POINT(5 1)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			var got Geometry
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).Substring(tt.from, tt.to)
+			} else {
+				got = g.AsMultiLineString().Substring(tt.from, tt.to)
+			}
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestLocateAlong(t *testing.T) {
+	for i, tt := range []struct {
+		wkt     string
+		measure float64
+		want    string
+	}{
+		{"LINESTRING M (0 0 0,10 0 10)", 2.5, "MULTIPOINT M (2.5 0 2.5)"},
+		{"LINESTRING M (0 0 0,10 0 10)", 0, "MULTIPOINT M (0 0 0)"},
+		{"LINESTRING M (0 0 0,10 0 10)", 11, "MULTIPOINT M EMPTY"},
+		{"LINESTRING M (0 0 0,10 0 10,10 10 0)", 5, "MULTIPOINT M (5 0 5,10 5 5)"},
+		{"LINESTRING M (0 0 0,10 0 10,10 10 20)", 10, "MULTIPOINT M (10 0 10)"},
+		{"LINESTRING M (0 0 5,10 0 5)", 5, "MULTIPOINT M (0 0 5,10 0 5)"},
+		{"LINESTRING ZM (0 0 1 0,10 0 3 10)", 5, "MULTIPOINT ZM (5 0 2 5)"},
+		{"LINESTRING(0 0,10 0)", 5, "MULTIPOINT EMPTY"},
+		{"MULTILINESTRING M ((0 0 0,1 0 1),(5 5 1,5 6 2))", 1.5, "MULTIPOINT M (5 5.5 1.5)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			var got MultiPoint
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).LocateAlong(tt.measure)
+			} else {
+				got = g.AsMultiLineString().LocateAlong(tt.measure)
+			}
+			expectGeomEq(t, got.AsGeometry(), geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestLocateBetween(t *testing.T) {
+	for i, tt := range []struct {
+		wkt      string
+		from, to float64
+		want     string
+	}{
+		{"LINESTRING M (0 0 0,10 0 10)", 2, 4, "MULTILINESTRING M ((2 0 2,4 0 4))"},
+		{"LINESTRING M (0 0 0,10 0 10)", 4, 2, "MULTILINESTRING M ((2 0 2,4 0 4))"},
+		{"LINESTRING M (0 0 0,10 0 10)", -5, 15, "MULTILINESTRING M ((0 0 0,10 0 10))"},
+		{"LINESTRING M (0 0 0,10 0 10)", 10, 15, "MULTILINESTRING M EMPTY"},
+		{"LINESTRING M (0 0 0,10 0 10,10 10 20)", 5, 15, "MULTILINESTRING M ((5 0 5,10 0 10,10 5 15))"},
+		{"LINESTRING M (0 0 0,10 0 10,10 10 0)", 8, 20, "MULTILINESTRING M ((8 0 8,10 0 10,10 2 8))"},
+		{"LINESTRING M (0 0 0,10 0 10,10 10 0,20 10 10)", 0, 2, "MULTILINESTRING M ((0 0 0,2 0 2),(10 8 2,10 10 0,12 10 2))"},
+		{"LINESTRING M (0 0 5,10 0 5,10 10 6)", 5, 5, "MULTILINESTRING M ((0 0 5,10 0 5))"},
+		{"LINESTRING(0 0,10 0)", 0, 1, "MULTILINESTRING EMPTY"},
+		{"MULTILINESTRING M ((0 0 0,1 0 1),(1 0 1,2 0 2))", 0.5, 1.5, "MULTILINESTRING M ((0.5 0 0.5,1 0 1),(1 0 1,1.5 0 1.5))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			var got MultiLineString
+			if g.IsLine() || g.IsLineString() {
+				got = toLineString(g).LocateBetween(tt.from, tt.to)
+			} else {
+				got = g.AsMultiLineString().LocateBetween(tt.from, tt.to)
+			}
+			expectGeomEq(t, got.AsGeometry(), geomFromWKT(t, tt.want))
+		})
+	}
+}
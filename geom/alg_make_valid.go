@@ -0,0 +1,162 @@
+package geom
+
+// MakeValid attempts to create a valid representation of an invalid geometry
+// that covers the same points. Vertices are only dropped when they're inside
+// the area of the result (e.g. spikes that point into a polygon's interior).
+// It has similar semantics to the PostGIS ST_MakeValid function:
+//
+// 1. The area of each polygon is found using the even-odd rule over all of its
+// rings. This means that self intersecting rings (e.g. bow-ties) are split
+// into multiple polygons, holes that are outside of the exterior ring become
+// polygons in their own right, and overlapping holes cancel each other out.
+// Rings that aren't closed are closed, and duplicate points are removed. The
+// areas of the polygons in a MultiPolygon are combined.
+//
+// 2. Parts of polygons that collapse to lines or points (such as spikes, or
+// rings with less than 3 distinct points) are retained as lines or points,
+// unless they're inside the area of the result.
+//
+// 3. LineStrings that have fewer than 2 distinct points collapse to points.
+//
+// 4. Each element of a GeometryCollection is made valid independently.
+//
+// Valid geometries are returned unchanged. Z and M values are not retained in
+// geometries that are repaired.
+func MakeValid(g Geometry) (Geometry, error) {
+	if g.IsValid() {
+		return g, nil
+	}
+	switch g.tag {
+	case lineTag, lineStringTag, multiLineStringTag:
+		mls := toMultiLineString(g)
+		lines := make([]LineString, mls.NumLineStrings())
+		for i := range lines {
+			lines[i] = mls.LineStringN(i)
+		}
+		return makeValidLineStrings(lines), nil
+	case polygonTag:
+		return makeValidPolygons([]Polygon{g.AsPolygon()})
+	case multiPolygonTag:
+		mp := g.AsMultiPolygon()
+		polys := make([]Polygon, mp.NumPolygons())
+		for i := range polys {
+			polys[i] = mp.PolygonN(i)
+		}
+		return makeValidPolygons(polys)
+	case geometryCollectionTag:
+		gc := g.AsGeometryCollection()
+		geoms := make([]Geometry, gc.NumGeometries())
+		for i := range geoms {
+			var err error
+			geoms[i], err = MakeValid(gc.GeometryN(i))
+			if err != nil {
+				return Geometry{}, err
+			}
+		}
+		return NewGeometryCollection(geoms).AsGeometry(), nil
+	default:
+		// Points, MultiPoints, and empty geometries are always valid.
+		return g, nil
+	}
+}
+
+// makeValidLineStrings repairs a set of LineStrings. LineStrings that have at
+// least 2 distinct points are retained, and others collapse to points.
+func makeValidLineStrings(lss []LineString) Geometry {
+	var lines []LineString
+	var points []Point
+	for _, ls := range lss {
+		xys := dedupXYs(lineStringXYs(ls))
+		switch len(xys) {
+		case 0:
+		case 1:
+			points = append(points, NewPointXY(xys[0]))
+		default:
+			line, err := NewLineStringXY(xys)
+			if err != nil {
+				// Cannot occur, since there are at least 2 distinct points.
+				panic(err)
+			}
+			lines = append(lines, line)
+		}
+	}
+	switch {
+	case len(lines) == 0 && len(points) == 0:
+		return NewEmptyLineString().AsGeometry()
+	case len(points) == 0 && len(lines) == 1:
+		return lines[0].AsGeometry()
+	case len(points) == 0:
+		return NewMultiLineString(lines).AsGeometry()
+	case len(lines) == 0 && len(points) == 1:
+		return points[0].AsGeometry()
+	}
+	var geoms []Geometry
+	for _, ls := range lines {
+		geoms = append(geoms, ls.AsGeometry())
+	}
+	for _, pt := range points {
+		geoms = append(geoms, pt.AsGeometry())
+	}
+	return NewGeometryCollection(geoms).AsGeometry()
+}
+
+// makeValidPolygons repairs a set of polygons by overlaying their rings, and
+// finding the area using the even-odd rule.
+func makeValidPolygons(polys []Polygon) (Geometry, error) {
+	input := overlayInput{evenOdd: true}
+	for _, p := range polys {
+		input.polys = append(input.polys, p.Force2D())
+		for _, r := range p.rings() {
+			ring := dedupXYs(lineStringXYs(r))
+			switch len(ring) {
+			case 0:
+			case 1:
+				input.points = append(input.points, ring[0])
+			default:
+				if ring[0] != ring[len(ring)-1] {
+					ring = append(ring, ring[0])
+				}
+				input.rings = append(input.rings, ring)
+			}
+		}
+	}
+
+	d, err := newDCELFromInputs(input, overlayInput{})
+	if err != nil {
+		return Geometry{}, err
+	}
+	polyParts, err := d.extractPolygons(selectUnion)
+	if err != nil {
+		return Geometry{}, err
+	}
+	lineParts, err := d.extractLines(selectUnion)
+	if err != nil {
+		return Geometry{}, err
+	}
+	pointParts := d.extractPoints(selectUnion)
+	return overlayResult(polyParts, lineParts, pointParts, 2)
+}
+
+// pointInPolygonEvenOdd checks if a point is inside a polygon, using the
+// even-odd rule over all of the polygon's rings. Unlike pointPolygonSide, it
+// gives a sensible result for invalid polygons. Rings that aren't closed are
+// treated as if they were.
+//
+// The point must not be on the boundary of the resultant area. It may be on
+// a part of a ring that doesn't bound the area (e.g. a spike, or a collapsed
+// hole), in which case those parts of the ring are ignored since they cross
+// the ray an even number of times.
+func pointInPolygonEvenOdd(pt XY, p Polygon) bool {
+	var inside bool
+	for _, r := range p.rings() {
+		n := r.NumPoints()
+		for i := 0; i < n; i++ {
+			a := r.PointN(i).XY()
+			b := r.PointN((i + 1) % n).XY()
+			if crosses, _ := rayCrossing(pt, a, b); crosses {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
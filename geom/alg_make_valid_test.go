@@ -0,0 +1,111 @@
+package geom_test
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestMakeValid(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		want string
+	}{
+		// Valid geometries are unchanged.
+		{"POINT(1 2)", "POINT(1 2)"},
+		{"LINESTRING(0 0,1 1)", "LINESTRING(0 0,1 1)"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((0 0,1 0,1 1,0 1,0 0))"},
+		{"POLYGON Z((0 0 1,1 0 1,1 1 1,0 1 1,0 0 1))", "POLYGON Z((0 0 1,1 0 1,1 1 1,0 1 1,0 0 1))"},
+
+		// Collapsed lines.
+		{"LINESTRING(1 1,1 1)", "POINT(1 1)"},
+		{"MULTILINESTRING((1 1,1 1),(0 0,1 0))", "GEOMETRYCOLLECTION(LINESTRING(0 0,1 0),POINT(1 1))"},
+
+		// Bow-ties.
+		{"POLYGON((0 0,2 2,2 0,0 2,0 0))", "MULTIPOLYGON(((0 0,0 2,1 1,0 0)),((1 1,2 2,2 0,1 1)))"},
+		{"POLYGON((0 0,4 0,4 4,2 0,0 4,0 0))", "MULTIPOLYGON(((0 0,0 4,2 0,0 0)),((2 0,4 4,4 0,2 0)))"},
+		{"POLYGON((0 0,10 0,5 5,10 10,0 10,5 5,0 0))", "MULTIPOLYGON(((0 0,5 5,10 0,0 0)),((5 5,0 10,10 10,5 5)))"},
+		{"POLYGON Z((0 0 1,2 2 1,2 0 1,0 2 1,0 0 1))", "MULTIPOLYGON(((0 0,0 2,1 1,0 0)),((1 1,2 2,2 0,1 1)))"},
+
+		// Unclosed ring.
+		{"POLYGON((0 0,10 0,10 10,0 10))", "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+
+		// Holes outside of, or overlapping, the exterior ring.
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(15 15,15 20,20 20,20 15,15 15))",
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0)),((15 15,15 20,20 20,20 15,15 15)))",
+		},
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,15 5,15 15,5 15,5 5))",
+			"MULTIPOLYGON(((0 0,0 10,5 10,5 5,10 5,10 0,0 0)),((10 10,5 10,5 15,15 15,15 5,10 5,10 10)))",
+		},
+
+		// Nested holes.
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,8 2,8 8,2 8,2 2),(4 4,6 4,6 6,4 6,4 4))",
+			"MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0),(2 2,8 2,8 8,2 8,2 2)),((4 4,4 6,6 6,6 4,4 4)))",
+		},
+
+		// Collapsed parts.
+		{"POLYGON((0 0,1 1,0 0))", "LINESTRING(0 0,1 1)"},
+		{"POLYGON((0 0,0 0,0 0,0 0))", "POINT(0 0)"},
+		{
+			"POLYGON((0 0,10 0,10 10,15 15,10 10,0 10,0 0))",
+			"GEOMETRYCOLLECTION(POLYGON((0 0,10 0,10 10,0 10,0 0)),LINESTRING(10 10,15 15))",
+		},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,5 5))", "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0,5 5,0 0))", "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(4 5,6 5,4 5))", "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+
+		// Overlapping polygons in a MultiPolygon are combined.
+		{
+			"MULTIPOLYGON(((0 0,2 0,2 2,0 2,0 0)),((1 1,3 1,3 3,1 3,1 1)))",
+			"POLYGON((0 0,0 2,1 2,1 3,3 3,3 1,2 1,2 0,0 0))",
+		},
+
+		// GeometryCollection elements are made valid independently.
+		{
+			"GEOMETRYCOLLECTION(POINT(1 1),POLYGON((0 0,2 2,2 0,0 2,0 0)))",
+			"GEOMETRYCOLLECTION(POINT(1 1),MULTIPOLYGON(((0 0,0 2,1 1,0 0)),((1 1,2 2,2 0,1 1))))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := UnmarshalWKT(strings.NewReader(tt.wkt), DisableAllValidations)
+			expectNoErr(t, err)
+			got, err := MakeValid(g)
+			expectNoErr(t, err)
+			if !got.IsValid() {
+				t.Errorf("result is invalid: %v", got.AsText())
+			}
+			want := geomFromWKT(t, tt.want)
+			if !got.EqualsExact(want, IgnoreOrder) {
+				t.Errorf("\ngot:  %v\nwant: %v\n", got.AsText(), want.AsText())
+			}
+		})
+	}
+}
+
+func TestMakeValidRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 500; i++ {
+		var rings [][]XY
+		for r := 0; r < 1+rnd.Intn(3); r++ {
+			var ring []XY
+			for j := 0; j < 3+rnd.Intn(8); j++ {
+				ring = append(ring, XY{float64(rnd.Intn(10)), float64(rnd.Intn(10))})
+			}
+			ring = append(ring, ring[0])
+			rings = append(rings, ring)
+		}
+		poly, err := NewPolygonXY(rings, DisableAllValidations)
+		expectNoErr(t, err)
+		got, err := MakeValid(poly.AsGeometry())
+		expectNoErr(t, err)
+		if err := got.IsValidReason(); err != nil {
+			t.Fatalf("invalid result for %v: %v", poly.AsText(), err)
+		}
+	}
+}
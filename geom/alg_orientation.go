@@ -25,8 +25,9 @@ func (o threePointOrientation) String() string {
 }
 
 // orientation checks if s is on the right hand side or left hand side of the line formed by p and q.
+// The result is exact (it doesn't suffer from floating point rounding errors).
 func orientation(p, q, s XY) threePointOrientation {
-	cp := q.Sub(p).Cross(s.Sub(q))
+	cp := orient2d(p, q, s)
 	switch {
 	case cp > 0:
 		return leftTurn
@@ -0,0 +1,396 @@
+package geom
+
+import (
+	"fmt"
+	"sort"
+)
+
+// overlayOperator decides whether or not a part of the plane is in the result
+// of an overlay operation, based on whether or not it's in each of the two
+// input geometries.
+type overlayOperator func(inA, inB bool) bool
+
+func selectIntersection(inA, inB bool) bool        { return inA && inB }
+func selectUnion(inA, inB bool) bool               { return inA || inB }
+func selectDifference(inA, inB bool) bool          { return inA && !inB }
+func selectSymmetricDifference(inA, inB bool) bool { return inA != inB }
+
+// intersectWithOverlay calculates the intersection between two geometries
+// using the general overlay algorithm. It's used for the type pairs that don't
+// have a more specialised intersection implementation. The empty geometry
+// collection is used for empty results, which is consistent with the
+// specialised implementations.
+func intersectWithOverlay(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, selectIntersection, -1)
+}
+
+func union(a, b Geometry) (Geometry, error) {
+	dim := max(overlayDimension(a), overlayDimension(b))
+	return overlay(a, b, selectUnion, dim)
+}
+
+func difference(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, selectDifference, overlayDimension(a))
+}
+
+func symmetricDifference(a, b Geometry) (Geometry, error) {
+	dim := max(overlayDimension(a), overlayDimension(b))
+	return overlay(a, b, selectSymmetricDifference, dim)
+}
+
+// overlayDimension is the same as Dimension, except that it gives -1 for
+// GeometryCollections that don't contain any other geometries.
+func overlayDimension(g Geometry) int {
+	if !g.IsGeometryCollection() {
+		return g.Dimension()
+	}
+	dim := -1
+	gc := g.AsGeometryCollection()
+	for i := 0; i < gc.NumGeometries(); i++ {
+		dim = max(dim, overlayDimension(gc.GeometryN(i)))
+	}
+	return dim
+}
+
+// overlay calculates the result of an overlay operation between two
+// geometries. If the result is empty, then an empty geometry of the
+// dimension emptyDim is returned (which matches the behaviour of PostGIS). An
+// emptyDim of -1 results in an empty GeometryCollection.
+//
+// Z and M values are not retained in the result.
+func overlay(a, b Geometry, op overlayOperator, emptyDim int) (Geometry, error) {
+	d, err := newDCEL(a.Force2D(), b.Force2D())
+	if err != nil {
+		return Geometry{}, err
+	}
+	polys, err := d.extractPolygons(op)
+	if err != nil {
+		return Geometry{}, err
+	}
+	lines, err := d.extractLines(op)
+	if err != nil {
+		return Geometry{}, err
+	}
+	points := d.extractPoints(op)
+	return overlayResult(polys, lines, points, emptyDim)
+}
+
+func (d *dcel) faceInResult(f *dcelFace, op overlayOperator) bool {
+	return op(f.inArea[0], f.inArea[1])
+}
+
+func (d *dcel) edgeInResult(e *dcelEdge, op overlayOperator) bool {
+	return op(e.inSet[0], e.inSet[1])
+}
+
+// extractPolygons finds the polygons that make up the areal part of the
+// result. The rings of the polygons are made up of the half edges that
+// separate result faces from non-result faces.
+func (d *dcel) extractPolygons(op overlayOperator) ([]Polygon, error) {
+	isBoundary := func(e *dcelHalfEdge) bool {
+		return d.faceInResult(e.face(), op) && !d.faceInResult(e.twin.face(), op)
+	}
+
+	var shells, holes [][]XY
+	visited := make(map[*dcelHalfEdge]bool)
+	for _, edge := range d.edges {
+		for _, start := range [2]*dcelHalfEdge{edge.halfEdge, edge.halfEdge.twin} {
+			if visited[start] || !isBoundary(start) {
+				continue
+			}
+			var ring []XY
+			e := start
+			for {
+				visited[e] = true
+				ring = append(ring, e.origin.coords)
+
+				// Rotate clockwise around the destination vertex until
+				// the next boundary half edge is found. This gives the
+				// tightest possible turn, so rings never self-intersect.
+				e = e.next
+				for !isBoundary(e) {
+					e = e.twin.next
+				}
+				if e == start {
+					break
+				}
+			}
+			ring = append(ring, ring[0])
+
+			// The result area is to the left of each half edge, so shells
+			// are counter-clockwise and holes are clockwise.
+			for _, r := range splitSelfTouchingRing(ring) {
+				if ringIsCCW(r) {
+					shells = append(shells, r)
+				} else {
+					holes = append(holes, r)
+				}
+			}
+		}
+	}
+
+	// Assign each hole to the smallest shell that contains it. The midpoint
+	// of the first hole segment is used for the containment test, since it
+	// can't be on the boundary of any shell.
+	shellHoles := make([][][]XY, len(shells))
+	for _, hole := range holes {
+		pt := hole[0].Midpoint(hole[1])
+		best := -1
+		for i, shell := range shells {
+			if !pointInRingXY(pt, shell) {
+				continue
+			}
+			if best == -1 || signedAreaXY(shell) < signedAreaXY(shells[best]) {
+				best = i
+			}
+		}
+		if best == -1 {
+			return nil, fmt.Errorf("overlay produced a hole that isn't inside any shell")
+		}
+		shellHoles[best] = append(shellHoles[best], hole)
+	}
+
+	// Rings are oriented with clockwise shells and counter-clockwise holes,
+	// to match the output of PostGIS.
+	polys := make([]Polygon, len(shells))
+	for i, shell := range shells {
+		reverseXYs(shell)
+		rings := [][]XY{shell}
+		for _, hole := range shellHoles[i] {
+			reverseXYs(hole)
+			rings = append(rings, hole)
+		}
+		poly, err := NewPolygonXY(rings)
+		if err != nil {
+			return nil, fmt.Errorf("overlay produced an invalid polygon: %v", err)
+		}
+		polys[i] = poly
+	}
+	return polys, nil
+}
+
+// splitSelfTouchingRing splits a closed ring into multiple rings wherever it
+// passes through the same vertex more than once. A ring traced around the
+// boundary of an area can touch itself (e.g. when a hole touches the exterior
+// ring), which isn't allowed in valid polygons. Each resultant ring is simple,
+// and retains the orientation of the part of the original ring it came from.
+func splitSelfTouchingRing(ring []XY) [][]XY {
+	var rings [][]XY
+	var stack []XY
+	seen := make(map[XY]int)
+	for _, pt := range ring[:len(ring)-1] {
+		if i, ok := seen[pt]; ok {
+			sub := append([]XY(nil), stack[i:]...)
+			rings = append(rings, append(sub, pt))
+			for _, removed := range stack[i+1:] {
+				delete(seen, removed)
+			}
+			stack = stack[:i+1]
+			continue
+		}
+		seen[pt] = len(stack)
+		stack = append(stack, pt)
+	}
+	return append(rings, append(stack, stack[0]))
+}
+
+// extractLines finds the linear part of the result. These are result edges
+// that don't bound any result faces. The edges are merged into the longest
+// possible line strings, only stopping at vertices that don't have exactly
+// two result edges.
+func (d *dcel) extractLines(op overlayOperator) ([]LineString, error) {
+	isResultLine := func(e *dcelHalfEdge) bool {
+		return d.edgeInResult(e.edge, op) &&
+			!d.faceInResult(e.face(), op) &&
+			!d.faceInResult(e.twin.face(), op)
+	}
+	degree := func(v *dcelVertex) int {
+		var n int
+		for _, e := range v.incident {
+			if isResultLine(e) {
+				n++
+			}
+		}
+		return n
+	}
+
+	var lines [][]XY
+	visited := make(map[*dcelEdge]bool)
+	trace := func(start *dcelHalfEdge) {
+		line := []XY{start.origin.coords}
+		e := start
+		for {
+			visited[e.edge] = true
+			v := e.dest()
+			line = append(line, v.coords)
+			if degree(v) != 2 {
+				break
+			}
+			var next *dcelHalfEdge
+			for _, candidate := range v.incident {
+				if isResultLine(candidate) && !visited[candidate.edge] {
+					next = candidate
+				}
+			}
+			if next == nil {
+				break // closed loop
+			}
+			e = next
+		}
+		lines = append(lines, line)
+	}
+
+	// Lines start at vertices that don't have exactly two result edges.
+	// Any remaining edges make up closed loops with no such vertex.
+	for _, v := range d.vertices {
+		if degree(v) == 2 {
+			continue
+		}
+		for _, e := range v.incident {
+			if isResultLine(e) && !visited[e.edge] {
+				trace(e)
+			}
+		}
+	}
+	for _, v := range d.vertices {
+		for _, e := range v.incident {
+			if isResultLine(e) && !visited[e.edge] {
+				trace(e)
+			}
+		}
+	}
+
+	result := make([]LineString, len(lines))
+	for i, line := range lines {
+		ls, err := NewLineStringXY(line)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = ls
+	}
+	return result, nil
+}
+
+// extractPoints finds the isolated points in the result, i.e. points that
+// aren't covered by the areal or linear parts of the result.
+func (d *dcel) extractPoints(op overlayOperator) []Point {
+	var pts []Point
+	for _, v := range d.vertices {
+		if !op(v.inSet[0], v.inSet[1]) {
+			continue
+		}
+		covered := false
+		for _, e := range v.incident {
+			if d.edgeInResult(e.edge, op) {
+				covered = true
+				break
+			}
+		}
+		if len(v.incident) == 0 {
+			covered = op(
+				d.inputs[0].pointInArea(v.coords),
+				d.inputs[1].pointInArea(v.coords),
+			)
+		}
+		if !covered {
+			pts = append(pts, NewPointXY(v.coords))
+		}
+	}
+	sort.Slice(pts, func(i, j int) bool {
+		return pts[i].XY().Less(pts[j].XY())
+	})
+	return pts
+}
+
+// overlayResult combines the areal, linear, and point parts of an overlay
+// result into a single geometry. The most specific geometry type possible is
+// used. If there are mixed dimension parts, a GeometryCollection is returned
+// with the parts ordered by decreasing dimension (which matches PostGIS).
+func overlayResult(polys []Polygon, lines []LineString, points []Point, emptyDim int) (Geometry, error) {
+	var parts []Geometry
+	switch len(polys) {
+	case 0:
+	case 1:
+		parts = append(parts, polys[0].AsGeometry())
+	default:
+		mp, err := NewMultiPolygon(polys)
+		if err != nil {
+			return Geometry{}, fmt.Errorf("overlay produced an invalid multipolygon: %v", err)
+		}
+		parts = append(parts, mp.AsGeometry())
+	}
+	switch len(lines) {
+	case 0:
+	case 1:
+		parts = append(parts, lineStringOrLine(lines[0]))
+	default:
+		parts = append(parts, NewMultiLineString(lines).AsGeometry())
+	}
+	switch len(points) {
+	case 0:
+	case 1:
+		parts = append(parts, points[0].AsGeometry())
+	default:
+		parts = append(parts, NewMultiPoint(points).AsGeometry())
+	}
+
+	switch len(parts) {
+	case 0:
+		switch emptyDim {
+		case -1:
+			return NewGeometryCollection(nil).AsGeometry(), nil
+		case 0:
+			return NewEmptyPoint().AsGeometry(), nil
+		case 1:
+			return NewEmptyLineString().AsGeometry(), nil
+		default:
+			return NewEmptyPolygon().AsGeometry(), nil
+		}
+	case 1:
+		return parts[0], nil
+	default:
+		var all []Geometry
+		for _, part := range parts {
+			all = append(all, explodeCollection(part)...)
+		}
+		return NewGeometryCollection(all).AsGeometry(), nil
+	}
+}
+
+// lineStringOrLine converts a LineString to a Line if it consists of just two
+// points. This is consistent with the way that geometries are unmarshalled.
+func lineStringOrLine(ls LineString) Geometry {
+	if ls.NumPoints() == 2 {
+		ln, err := NewLineC(ls.coords[0], ls.coords[1])
+		if err == nil {
+			return ln.AsGeometry()
+		}
+	}
+	return ls.AsGeometry()
+}
+
+// explodeCollection breaks a multi geometry down into its parts. Non-multi
+// geometries are returned as-is.
+func explodeCollection(g Geometry) []Geometry {
+	var parts []Geometry
+	switch g.tag {
+	case multiPointTag:
+		mp := g.AsMultiPoint()
+		for i := 0; i < mp.NumPoints(); i++ {
+			parts = append(parts, mp.PointN(i).AsGeometry())
+		}
+	case multiLineStringTag:
+		mls := g.AsMultiLineString()
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			parts = append(parts, lineStringOrLine(mls.LineStringN(i)))
+		}
+	case multiPolygonTag:
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			parts = append(parts, mp.PolygonN(i).AsGeometry())
+		}
+	default:
+		parts = append(parts, g)
+	}
+	return parts
+}
@@ -0,0 +1,250 @@
+package geom_test
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestOverlay(t *testing.T) {
+	for i, tt := range []struct {
+		in1, in2             string
+		union, diff, symDiff string
+	}{
+		// Empty inputs.
+		{
+			"GEOMETRYCOLLECTION EMPTY", "POINT EMPTY",
+			"POINT EMPTY", "GEOMETRYCOLLECTION EMPTY", "POINT EMPTY",
+		},
+		{
+			"POLYGON EMPTY", "LINESTRING EMPTY",
+			"POLYGON EMPTY", "POLYGON EMPTY", "POLYGON EMPTY",
+		},
+		{
+			"POINT(1 2)", "POLYGON EMPTY",
+			"POINT(1 2)", "POINT(1 2)", "POINT(1 2)",
+		},
+
+		// Point/Point
+		{
+			"POINT(1 2)", "POINT(1 2)",
+			"POINT(1 2)", "POINT EMPTY", "POINT EMPTY",
+		},
+		{
+			"POINT(1 2)", "POINT(3 4)",
+			"MULTIPOINT(1 2,3 4)", "POINT(1 2)", "MULTIPOINT(1 2,3 4)",
+		},
+
+		// Line/Line
+		{
+			"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)",
+			"MULTILINESTRING((0 0,1 1),(1 1,2 2),(0 2,1 1),(1 1,2 0))",
+			"LINESTRING(0 0,1 1,2 2)",
+			"MULTILINESTRING((0 0,1 1),(1 1,2 2),(0 2,1 1),(1 1,2 0))",
+		},
+		{
+			"LINESTRING(0 0,2 0)", "LINESTRING(1 0,3 0)",
+			"LINESTRING(0 0,1 0,2 0,3 0)",
+			"LINESTRING(0 0,1 0)",
+			"MULTILINESTRING((0 0,1 0),(2 0,3 0))",
+		},
+		{
+			"LINESTRING(0 0,1 0,1 1,0 1,0 0)", "LINESTRING(0 0,2 0)",
+			"MULTILINESTRING((1 0,2 0),(1 0,1 1,0 1,0 0,1 0))",
+			"LINESTRING(1 0,1 1,0 1,0 0)",
+			"LINESTRING(0 0,0 1,1 1,1 0,2 0)",
+		},
+
+		// Point/Polygon
+		{
+			"POLYGON((0 0,2 0,2 2,0 2,0 0))", "MULTIPOINT(1 1,3 3)",
+			"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 2,0 2,0 0)),POINT(3 3))",
+			"POLYGON((0 0,2 0,2 2,0 2,0 0))",
+			"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 2,0 2,0 0)),POINT(3 3))",
+		},
+
+		// Line/Polygon
+		{
+			"POLYGON((0 0,2 0,2 2,0 2,0 0))", "LINESTRING(-1 1,3 1)",
+			"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 1,2 2,0 2,0 1,0 0)),LINESTRING(-1 1,0 1),LINESTRING(2 1,3 1))",
+			"POLYGON((0 0,2 0,2 1,2 2,0 2,0 1,0 0))",
+			"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 1,2 2,0 2,0 1,0 0)),LINESTRING(-1 1,0 1),LINESTRING(2 1,3 1))",
+		},
+
+		// Polygon/Polygon
+		{
+			"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POLYGON((1 1,3 1,3 3,1 3,1 1))",
+			"POLYGON((0 0,2 0,2 1,3 1,3 3,1 3,1 2,0 2,0 0))",
+			"POLYGON((0 0,2 0,2 1,1 1,1 2,0 2,0 0))",
+			"MULTIPOLYGON(((0 0,2 0,2 1,1 1,1 2,0 2,0 0)),((2 1,3 1,3 3,1 3,1 2,2 2,2 1)))",
+		},
+		{
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((1 0,2 0,2 1,1 1,1 0))",
+			"POLYGON((0 0,1 0,2 0,2 1,1 1,0 1,0 0))",
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))",
+			"POLYGON((0 0,1 0,2 0,2 1,1 1,0 1,0 0))",
+		},
+		{
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((1 1,2 1,2 2,1 2,1 1))",
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((1 1,2 1,2 2,1 2,1 1)))",
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))",
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((1 1,2 1,2 2,1 2,1 1)))",
+		},
+		{
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((2 2,3 2,3 3,2 3,2 2))",
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((2 2,3 2,3 3,2 3,2 2)))",
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))",
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((2 2,3 2,3 3,2 3,2 2)))",
+		},
+		{
+			"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POLYGON((1 1,2 1,2 2,1 2,1 1))",
+			"POLYGON((0 0,4 0,4 4,0 4,0 0))",
+			"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,2 1,2 2,1 2,1 1))",
+			"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,2 1,2 2,1 2,1 1))",
+		},
+		{
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((0 0,1 0,1 1,0 1,0 0))",
+			"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON EMPTY", "POLYGON EMPTY",
+		},
+		{
+			"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,3 1,3 3,1 3,1 1))", "POLYGON((2 2,5 2,5 5,2 5,2 2))",
+			"POLYGON((0 0,4 0,4 2,5 2,5 5,2 5,2 4,0 4,0 0),(1 1,3 1,3 2,2 2,2 3,1 3,1 1))",
+			"POLYGON((0 0,4 0,4 2,3 2,3 1,1 1,1 3,2 3,2 4,0 4,0 0))",
+			"MULTIPOLYGON(((0 0,4 0,4 2,3 2,3 1,1 1,1 3,2 3,2 4,0 4,0 0)),((4 2,5 2,5 5,2 5,2 4,4 4,4 2)),((2 2,3 2,3 3,2 3,2 2)))",
+		},
+
+		// Rings that touch themselves are split, so that the results are
+		// valid.
+		{
+			"POLYGON((0 0,4 0,4 4,0 4,0 0))", "POLYGON((2 0,3 1,2 2,1 1,2 0))",
+			"POLYGON((0 0,2 0,4 0,4 4,0 4,0 0))",
+			"POLYGON((0 0,2 0,4 0,4 4,0 4,0 0),(2 0,3 1,2 2,1 1,2 0))",
+			"POLYGON((0 0,2 0,4 0,4 4,0 4,0 0),(2 0,3 1,2 2,1 1,2 0))",
+		},
+		{
+			"POLYGON((0 0,4 0,4 4,0 4,0 0))", "MULTIPOLYGON(((2 0,3 1,2 2,1 1,2 0)),((2 2,3 3,2 4,1 3,2 2)))",
+			"POLYGON((0 0,2 0,4 0,4 4,2 4,0 4,0 0))",
+			"MULTIPOLYGON(((0 0,2 0,1 1,2 2,1 3,2 4,0 4,0 0)),((2 0,4 0,4 4,2 4,3 3,2 2,3 1,2 0)))",
+			"MULTIPOLYGON(((0 0,2 0,1 1,2 2,1 3,2 4,0 4,0 0)),((2 0,4 0,4 4,2 4,3 3,2 2,3 1,2 0)))",
+		},
+		{
+			"POLYGON((0 0,3 0,3 3,0 3,0 0))", "POLYGON((3 0,6 0,6 3,3 3,5 2,5 1,3 0))",
+			"MULTIPOLYGON(((0 0,3 0,3 3,0 3,0 0)),((3 0,6 0,6 3,3 3,5 2,5 1,3 0)))",
+			"POLYGON((0 0,3 0,3 3,0 3,0 0))",
+			"MULTIPOLYGON(((0 0,3 0,3 3,0 3,0 0)),((3 0,6 0,6 3,3 3,5 2,5 1,3 0)))",
+		},
+
+		// Overlapping parts within a single input are merged.
+		{
+			"GEOMETRYCOLLECTION(POLYGON((0 0,2 0,2 2,0 2,0 0)),POLYGON((1 0,3 0,3 2,1 2,1 0)))", "POINT EMPTY",
+			"POLYGON((0 0,1 0,2 0,3 0,3 2,2 2,1 2,0 2,0 0))",
+			"POLYGON((0 0,1 0,2 0,3 0,3 2,2 2,1 2,0 2,0 0))",
+			"POLYGON((0 0,1 0,2 0,3 0,3 2,2 2,1 2,0 2,0 0))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.in1)
+			g2 := geomFromWKT(t, tt.in2)
+			for _, op := range []struct {
+				name string
+				fn   func(Geometry, Geometry) (Geometry, error)
+				want string
+			}{
+				{"union", Geometry.Union, tt.union},
+				{"difference", Geometry.Difference, tt.diff},
+				{"symmetric_difference", Geometry.SymmetricDifference, tt.symDiff},
+			} {
+				t.Run(op.name, func(t *testing.T) {
+					got, err := op.fn(g1, g2)
+					expectNoErr(t, err)
+					if !got.EqualsExact(geomFromWKT(t, op.want), IgnoreOrder) {
+						t.Errorf("\ninput1: %s\ninput2: %s\nwant:   %v\ngot:    %v", tt.in1, tt.in2, op.want, got.AsText())
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestOverlayCommutative(t *testing.T) {
+	for i, tt := range []struct {
+		in1, in2 string
+	}{
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POLYGON((1 1,3 1,3 3,1 3,1 1))"},
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "LINESTRING(-1 1,3 1)"},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)"},
+		{"POINT(1 2)", "MULTIPOINT(1 2,3 4)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.in1)
+			g2 := geomFromWKT(t, tt.in2)
+			for _, fn := range []func(Geometry, Geometry) (Geometry, error){
+				Geometry.Union,
+				Geometry.SymmetricDifference,
+			} {
+				forward, err := fn(g1, g2)
+				expectNoErr(t, err)
+				reverse, err := fn(g2, g1)
+				expectNoErr(t, err)
+				if !forward.EqualsExact(reverse, IgnoreOrder) {
+					t.Errorf("not commutative:\nforward: %v\nreverse: %v", forward.AsText(), reverse.AsText())
+				}
+			}
+		})
+	}
+}
+
+func TestOverlayDropsZM(t *testing.T) {
+	g1 := geomFromWKT(t, "POINT Z (1 2 3)")
+	g2 := geomFromWKT(t, "POINT M (3 4 5)")
+	got, err := g1.Union(g2)
+	expectNoErr(t, err)
+	expectGeomEq(t, got, geomFromWKT(t, "MULTIPOINT(1 2,3 4)"))
+}
+
+func TestOverlayRandomPolygons(t *testing.T) {
+	// The vertices of the polygons are random floats, so the polygon edges
+	// intersect at points that can't be represented exactly. The results
+	// must still be valid, and their areas must be consistent with each
+	// other.
+	rnd := rand.New(rand.NewSource(0))
+	randomPolygon := func() Geometry {
+		n := 3 + rnd.Intn(10)
+		c := XY{X: rnd.Float64() * 10, Y: rnd.Float64() * 10}
+		ring := make([]XY, n+1)
+		for i := 0; i < n; i++ {
+			angle := 2 * math.Pi * float64(i) / float64(n)
+			r := 1 + rnd.Float64()*5
+			ring[i] = c.Add(XY{X: r * math.Cos(angle), Y: r * math.Sin(angle)})
+		}
+		ring[n] = ring[0]
+		poly, err := NewPolygonXY([][]XY{ring})
+		expectNoErr(t, err)
+		return poly.AsGeometry()
+	}
+	for i := 0; i < 200; i++ {
+		g1, g2 := randomPolygon(), randomPolygon()
+		union, err := g1.Union(g2)
+		expectNoErr(t, err)
+		inter, err := g1.Intersection(g2)
+		expectNoErr(t, err)
+		diff, err := g1.Difference(g2)
+		expectNoErr(t, err)
+		symDiff, err := g1.SymmetricDifference(g2)
+		expectNoErr(t, err)
+
+		const eps = 1e-9
+		if got, want := union.Area(), g1.Area()+g2.Area()-inter.Area(); math.Abs(got-want) > eps {
+			t.Errorf("union area %v doesn't match %v:\ng1: %v\ng2: %v", got, want, g1.AsText(), g2.AsText())
+		}
+		if got, want := diff.Area(), g1.Area()-inter.Area(); math.Abs(got-want) > eps {
+			t.Errorf("difference area %v doesn't match %v:\ng1: %v\ng2: %v", got, want, g1.AsText(), g2.AsText())
+		}
+		if got, want := symDiff.Area(), union.Area()-inter.Area(); math.Abs(got-want) > eps {
+			t.Errorf("symmetric difference area %v doesn't match %v:\ng1: %v\ng2: %v", got, want, g1.AsText(), g2.AsText())
+		}
+	}
+}
@@ -1,7 +1,5 @@
 package geom
 
-import "math"
-
 type side int
 
 const (
@@ -13,51 +11,44 @@ const (
 // pointRingSide checks the side of a ring that a point is on. It assumes that
 // the input ring is actually a ring (i.e. closed and simple).
 func pointRingSide(pt XY, ring LineString) side {
-	ptg := NewPointC(Coordinates{pt})
-	// find max x coordinate
-	// TODO: should be able to use envelope for this
-	maxX := ring.LineN(0).StartPoint().XY().X
+	var inside bool
 	for i := 0; i < ring.NumLines(); i++ {
 		ln := ring.LineN(i)
-		maxX = math.Max(maxX, ln.EndPoint().XY().X)
-		if hasIntersectionPointWithLine(ptg, ln) {
+		crosses, onBoundary := rayCrossing(pt, ln.a.XY, ln.b.XY)
+		if onBoundary {
 			return boundary
 		}
+		if crosses {
+			inside = !inside
+		}
 	}
-	if pt.X > maxX {
-		return exterior
+	if inside {
+		return interior
 	}
+	return exterior
+}
 
-	ray, err := NewLineC(Coordinates{pt}, Coordinates{XY{maxX + 1, pt.Y}})
-	if err != nil {
-		// Cannot occur because X coordinates are different.
-		panic(err)
+// rayCrossing checks if the line segment from a to b crosses the ray cast
+// from pt in the positive X direction. Segments are treated as half open in
+// the Y direction, so that a ray passing through a vertex shared by two
+// segments is only counted once (if the ring actually crosses the ray at
+// that vertex). It also checks if pt lies on the segment. The calculation is
+// exact (it doesn't suffer from floating point rounding errors).
+func rayCrossing(pt, a, b XY) (crosses bool, onSegment bool) {
+	if xyOnSegment(pt, a, b) {
+		return false, true
 	}
-
-	var count int
-	for i := 0; i < ring.NumLines(); i++ {
-		seg := ring.LineN(i)
-		inter := intersectLineWithLineNoAlloc(seg, ray)
-		if inter.empty {
-			continue
-		}
-		if inter.ptA != inter.ptB {
-			continue
-		}
-		if inter.ptA == seg.a.XY || inter.ptA == seg.b.XY {
-			otherY := seg.a.Y
-			if inter.ptA == seg.a.XY {
-				otherY = seg.b.Y
-			}
-			if otherY < pt.Y {
-				count++
-			}
-		} else {
-			count++
-		}
+	if (a.Y > pt.Y) == (b.Y > pt.Y) {
+		return false, false
 	}
-	if count%2 == 1 {
-		return interior
+	o := orient2d(a, b, pt)
+	if b.Y > a.Y {
+		return o > 0, false
 	}
-	return exterior
+	return o < 0, false
+}
+
+// xyOnSegment checks if a point lies on the line segment from a to b.
+func xyOnSegment(pt, a, b XY) bool {
+	return orientation(a, b, pt) == collinear && onSegment(a, b, pt)
 }
@@ -0,0 +1,324 @@
+package geom
+
+import (
+	"fmt"
+)
+
+// Relate calculates the DE-9IM matrix between two geometries. The matrix is
+// returned as a 9 character string, in the same format as the PostGIS
+// ST_Relate function.
+//
+// The rows of the matrix correspond to the interior, boundary, and exterior
+// of the first geometry, and the columns correspond to the interior,
+// boundary, and exterior of the second geometry. Each entry is the dimension
+// of the intersection between the corresponding parts of the two geometries
+// ('0', '1', or '2'), or 'F' if they don't intersect.
+//
+// Z and M values are ignored. An error is returned if the geometries can't
+// be overlaid due to numerical precision issues.
+func Relate(a, b Geometry) (string, error) {
+	d, err := newDCEL(a.Force2D(), b.Force2D())
+	if err != nil {
+		return "", err
+	}
+	return d.relate().String(), nil
+}
+
+// RelateMatch checks if a DE-9IM matrix matches a pattern. The pattern is a 9
+// character string, with each character being one of:
+//
+// 'T' to match any non-empty intersection ('0', '1', or '2'),
+//
+// 'F' to match an empty intersection,
+//
+// '0', '1', or '2' to match an intersection of exactly that dimension,
+//
+// '*' to match anything.
+//
+// An error is returned if either the matrix or pattern is malformed.
+func RelateMatch(matrix, pattern string) (bool, error) {
+	if err := checkMatrix(matrix, "F012"); err != nil {
+		return false, fmt.Errorf("invalid DE-9IM matrix: %v", err)
+	}
+	if err := checkMatrix(pattern, "TF012*"); err != nil {
+		return false, fmt.Errorf("invalid DE-9IM pattern: %v", err)
+	}
+	return relateMatch(matrix, pattern), nil
+}
+
+func checkMatrix(m string, allowed string) error {
+	if len(m) != 9 {
+		return fmt.Errorf("must have 9 characters but has %d", len(m))
+	}
+	for _, c := range m {
+		var ok bool
+		for _, a := range allowed {
+			ok = ok || c == a
+		}
+		if !ok {
+			return fmt.Errorf("invalid character %q", c)
+		}
+	}
+	return nil
+}
+
+func relateMatch(matrix, pattern string) bool {
+	for i := 0; i < 9; i++ {
+		switch p, m := pattern[i], matrix[i]; p {
+		case '*':
+		case 'T':
+			if m == 'F' {
+				return false
+			}
+		default:
+			if m != p {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// relateMatchAny checks if a geometry pair match any of the given patterns.
+func relateMatchAny(a, b Geometry, patterns ...string) (bool, error) {
+	m, err := Relate(a, b)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range patterns {
+		if relateMatch(m, p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Disjoint checks if two geometries have no points in common.
+func Disjoint(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b, "FF*FF****")
+}
+
+// Contains checks if no points of b lie in the exterior of a, and at least
+// one point of the interior of b lies in the interior of a.
+func Contains(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b, "T*****FF*")
+}
+
+// Within checks if no points of a lie in the exterior of b, and at least one
+// point of the interior of a lies in the interior of b.
+func Within(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b, "T*F**F***")
+}
+
+// Covers checks if no points of b lie in the exterior of a, and the two
+// geometries have at least one point in common.
+func Covers(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b,
+		"T*****FF*",
+		"*T****FF*",
+		"***T**FF*",
+		"****T*FF*",
+	)
+}
+
+// CoveredBy checks if no points of a lie in the exterior of b, and the two
+// geometries have at least one point in common.
+func CoveredBy(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b,
+		"T*F**F***",
+		"*TF**F***",
+		"**FT*F***",
+		"**F*TF***",
+	)
+}
+
+// Touches checks if two geometries have at least one point in common, but
+// their interiors don't intersect.
+func Touches(a, b Geometry) (bool, error) {
+	return relateMatchAny(a, b,
+		"FT*******",
+		"F**T*****",
+		"F***T****",
+	)
+}
+
+// Crosses checks if two geometries have some (but not all) interior points
+// in common, and the dimension of the intersection of their interiors is less
+// than the maximum dimension of the two geometries. It's only defined for
+// Point/Line, Point/Area, Line/Area, and Line/Line geometry pairs, and is
+// false for all other pairs.
+func Crosses(a, b Geometry) (bool, error) {
+	dimA, dimB := overlayDimension(a), overlayDimension(b)
+	switch {
+	case dimA < dimB && dimA >= 0:
+		return relateMatchAny(a, b, "T*T******")
+	case dimA > dimB && dimB >= 0:
+		return relateMatchAny(a, b, "T*****T**")
+	case dimA == 1 && dimB == 1:
+		return relateMatchAny(a, b, "0********")
+	default:
+		return false, nil
+	}
+}
+
+// Overlaps checks if two geometries of the same dimension have some (but not
+// all) points in common, and the intersection of their interiors has the same
+// dimension as the geometries themselves. It's false for geometries of
+// different dimensions.
+func Overlaps(a, b Geometry) (bool, error) {
+	dimA, dimB := overlayDimension(a), overlayDimension(b)
+	switch {
+	case dimA != dimB:
+		return false, nil
+	case dimA == 0 || dimA == 2:
+		return relateMatchAny(a, b, "T*T***T**")
+	case dimA == 1:
+		return relateMatchAny(a, b, "1*T***T**")
+	default:
+		return false, nil
+	}
+}
+
+// location is a part of a geometry (its interior, boundary, or exterior).
+type location int
+
+const (
+	locInterior location = iota
+	locBoundary
+	locExterior
+)
+
+// matrix is a DE-9IM matrix. Each entry is the dimension of the intersection,
+// or -1 for an empty intersection.
+type matrix [3][3]int
+
+func (m matrix) String() string {
+	var buf [9]byte
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			buf[3*i+j] = "F012"[m[i][j]+1]
+		}
+	}
+	return string(buf[:])
+}
+
+func (m *matrix) add(locA, locB location, dim int) {
+	if dim > m[locA][locB] {
+		m[locA][locB] = dim
+	}
+}
+
+// relate calculates the DE-9IM matrix between the dcel's two inputs. Each
+// vertex, edge, and face of the dcel contributes to the matrix entry
+// corresponding to its location relative to each input.
+func (d *dcel) relate() matrix {
+	var m matrix
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = -1
+		}
+	}
+
+	// The unbounded face is always in the exterior of both inputs.
+	m.add(locExterior, locExterior, 2)
+	for _, f := range d.faces[1:] {
+		m.add(faceLocation(f, 0), faceLocation(f, 1), 2)
+	}
+	for _, e := range d.edges {
+		m.add(edgeLocation(e, 0), edgeLocation(e, 1), 1)
+	}
+	bounds := [2]map[XY]bool{d.lineBoundary(0), d.lineBoundary(1)}
+	for _, v := range d.vertices {
+		m.add(
+			d.vertexLocation(v, 0, bounds[0]),
+			d.vertexLocation(v, 1, bounds[1]),
+			0,
+		)
+	}
+	return m
+}
+
+func faceLocation(f *dcelFace, input int) location {
+	if f.inArea[input] {
+		return locInterior
+	}
+	return locExterior
+}
+
+func edgeLocation(e *dcelEdge, input int) location {
+	left := e.halfEdge.face().inArea[input]
+	right := e.halfEdge.twin.face().inArea[input]
+	switch {
+	case left && right:
+		return locInterior
+	case left || right:
+		return locBoundary
+	case e.linework[input]:
+		return locInterior
+	default:
+		return locExterior
+	}
+}
+
+// vertexLocation finds the location of a vertex relative to one of the
+// inputs. Areal parts of the input take precedence over linear parts, which
+// take precedence over point parts.
+func (d *dcel) vertexLocation(v *dcelVertex, input int, lineBound map[XY]bool) location {
+	if len(v.incident) == 0 {
+		switch {
+		case d.inputs[input].pointInArea(v.coords):
+			return locInterior
+		case v.explicit[input]:
+			return locInterior
+		default:
+			return locExterior
+		}
+	}
+
+	allInArea := true
+	for _, e := range v.incident {
+		allInArea = allInArea && e.face().inArea[input]
+	}
+	if allInArea {
+		return locInterior
+	}
+
+	var onLine bool
+	for _, e := range v.incident {
+		switch edgeLocation(e.edge, input) {
+		case locBoundary:
+			return locBoundary
+		case locInterior:
+			onLine = true
+		}
+	}
+	switch {
+	case lineBound[v.coords]:
+		return locBoundary
+	case onLine || v.explicit[input]:
+		return locInterior
+	default:
+		return locExterior
+	}
+}
+
+// lineBoundary finds the boundary of the linear parts of an input. This uses
+// the "mod 2" rule, where points that are the endpoint of an odd number of
+// lines are in the boundary.
+func (d *dcel) lineBoundary(input int) map[XY]bool {
+	counts := make(map[XY]int)
+	for _, ls := range d.inputs[input].lines {
+		if len(ls) < 2 || ls[0] == ls[len(ls)-1] {
+			continue
+		}
+		counts[d.nodes.find(ls[0])]++
+		counts[d.nodes.find(ls[len(ls)-1])]++
+	}
+	bound := make(map[XY]bool)
+	for xy, n := range counts {
+		if n%2 == 1 {
+			bound[xy] = true
+		}
+	}
+	return bound
+}
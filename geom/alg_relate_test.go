@@ -0,0 +1,160 @@
+package geom_test
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestRelate(t *testing.T) {
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+		want       string
+	}{
+		{"POINT(0 0)", "POINT(0 0)", "0FFFFFFF2"},
+		{"POINT(0 0)", "POINT(1 1)", "FF0FFF0F2"},
+		{"POINT(1 0)", "LINESTRING(0 0,2 0)", "0FFFFF102"},
+		{"POINT(0 0)", "LINESTRING(0 0,2 0)", "F0FFFF102"},
+		{"POINT EMPTY", "POINT(1 1)", "FFFFFF0F2"},
+		{"MULTIPOINT(0 0,1 0,5 5)", "LINESTRING(0 0,2 0)", "000FFF102"},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)", "0F1FF0102"},
+		{"LINESTRING(0 0,2 0)", "LINESTRING(1 0,3 0)", "1010F0102"},
+		{"LINESTRING(0 0,1 0,1 1,0 0)", "POINT(0 0)", "0F1FFFFF2"},
+		{"MULTILINESTRING((0 0,1 0),(1 0,2 0))", "POINT(1 0)", "0F1FF0FF2"},
+		{"LINESTRING(-1 1,3 1)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "101FF0212"},
+		{"LINESTRING(0 1,1 1)", "POLYGON((0 0,2 0,2 2,0 2,0 0))", "1FF00F212"},
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POINT(1 1)", "0F2FF1FF2"},
+		{"POLYGON((0 0,2 0,2 2,0 2,0 0))", "POLYGON((1 1,3 1,3 3,1 3,1 1))", "212101212"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((1 0,2 0,2 1,1 1,1 0))", "FF2F11212"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((0 0,1 0,1 1,0 1,0 0))", "2FFF1FFF2"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,3 1,3 3,1 3,1 1))", "POINT(2 2)", "FF2FF10F2"},
+		{"POINT Z (0 0 1)", "POINT M (0 0 2)", "0FFFFFFF2"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.wkt1)
+			g2 := geomFromWKT(t, tt.wkt2)
+			t.Run("forward", func(t *testing.T) {
+				got, err := Relate(g1, g2)
+				expectNoErr(t, err)
+				expectStringEq(t, got, tt.want)
+			})
+			t.Run("reversed", func(t *testing.T) {
+				got, err := Relate(g2, g1)
+				expectNoErr(t, err)
+				expectStringEq(t, got, transposeMatrix(tt.want))
+			})
+		})
+	}
+}
+
+func transposeMatrix(m string) string {
+	return string([]byte{m[0], m[3], m[6], m[1], m[4], m[7], m[2], m[5], m[8]})
+}
+
+func TestRelateMatch(t *testing.T) {
+	for i, tt := range []struct {
+		matrix, pattern string
+		want            bool
+	}{
+		{"0FFFFFFF2", "*********", true},
+		{"0FFFFFFF2", "0FFFFFFF2", true},
+		{"0FFFFFFF2", "TFFFFFFFT", true},
+		{"0FFFFFFF2", "1FFFFFFF2", false},
+		{"0FFFFFFF2", "FFFFFFFF2", false},
+		{"FF0FFF0F2", "FF*FF****", true},
+		{"212101212", "T*T***T**", true},
+		{"212101212", "FF*FF****", false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := RelateMatch(tt.matrix, tt.pattern)
+			expectNoErr(t, err)
+			expectBoolEq(t, got, tt.want)
+		})
+	}
+}
+
+func TestRelateMatchInvalid(t *testing.T) {
+	for i, tt := range []struct {
+		matrix, pattern string
+	}{
+		{"0FFFFFFF", "*********"},
+		{"0FFFFFFF2", "**********"},
+		{"0FFFFFFFT", "*********"},
+		{"0FFFFFFF*", "*********"},
+		{"0FFFFFFF2", "********3"},
+		{"0FFFFFFF2", "********t"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := RelateMatch(tt.matrix, tt.pattern)
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestRelatePredicates(t *testing.T) {
+	const (
+		square  = "POLYGON((0 0,2 0,2 2,0 2,0 0))"
+		inside  = "POLYGON((0.5 0.5,1.5 0.5,1.5 1.5,0.5 1.5,0.5 0.5))"
+		overlap = "POLYGON((1 1,3 1,3 3,1 3,1 1))"
+		touch   = "POLYGON((2 0,3 0,3 2,2 2,2 0))"
+		far     = "POLYGON((5 5,6 5,6 6,5 6,5 5))"
+	)
+	for i, tt := range []struct {
+		wkt1, wkt2 string
+
+		disjoint, contains, within, covers, coveredBy bool
+		touches, crosses, overlaps                    bool
+	}{
+		{wkt1: square, wkt2: inside, contains: true, covers: true},
+		{wkt1: inside, wkt2: square, within: true, coveredBy: true},
+		{wkt1: square, wkt2: square, contains: true, within: true, covers: true, coveredBy: true},
+		{wkt1: square, wkt2: overlap, overlaps: true},
+		{wkt1: square, wkt2: touch, touches: true},
+		{wkt1: square, wkt2: far, disjoint: true},
+		{wkt1: square, wkt2: "POINT(1 1)", contains: true, covers: true},
+		{wkt1: square, wkt2: "POINT(2 1)", covers: true, touches: true},
+		{wkt1: "POINT(2 1)", wkt2: square, coveredBy: true, touches: true},
+		{wkt1: square, wkt2: "LINESTRING(1 1,3 1)", crosses: true},
+		{wkt1: "LINESTRING(1 1,3 1)", wkt2: square, crosses: true},
+		{wkt1: square, wkt2: "LINESTRING(0 0,2 0)", covers: true, touches: true},
+		{wkt1: "LINESTRING(0 0,2 2)", wkt2: "LINESTRING(0 2,2 0)", crosses: true},
+		{wkt1: "LINESTRING(0 0,2 0)", wkt2: "LINESTRING(1 0,3 0)", overlaps: true},
+		{wkt1: "LINESTRING(0 0,2 0)", wkt2: "LINESTRING(2 0,3 0)", touches: true},
+		{wkt1: "MULTIPOINT(0 0,1 1)", wkt2: "MULTIPOINT(1 1,2 2)", overlaps: true},
+		{wkt1: "MULTIPOINT(1 0,5 5)", wkt2: "LINESTRING(0 0,2 0)", crosses: true},
+		{wkt1: "POINT(0 0)", wkt2: "POINT EMPTY", disjoint: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g1 := geomFromWKT(t, tt.wkt1)
+			g2 := geomFromWKT(t, tt.wkt2)
+			for _, pred := range []struct {
+				name   string
+				fn     func(a, b Geometry) (bool, error)
+				method func(a, b Geometry) (bool, error)
+				want   bool
+			}{
+				{"Disjoint", Disjoint, Geometry.Disjoint, tt.disjoint},
+				{"Contains", Contains, Geometry.Contains, tt.contains},
+				{"Within", Within, Geometry.Within, tt.within},
+				{"Covers", Covers, Geometry.Covers, tt.covers},
+				{"CoveredBy", CoveredBy, Geometry.CoveredBy, tt.coveredBy},
+				{"Touches", Touches, Geometry.Touches, tt.touches},
+				{"Crosses", Crosses, Geometry.Crosses, tt.crosses},
+				{"Overlaps", Overlaps, Geometry.Overlaps, tt.overlaps},
+			} {
+				t.Run(pred.name, func(t *testing.T) {
+					got, err := pred.fn(g1, g2)
+					expectNoErr(t, err)
+					expectBoolEq(t, got, pred.want)
+
+					got, err = pred.method(g1, g2)
+					expectNoErr(t, err)
+					expectBoolEq(t, got, pred.want)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package geom
+
+import "math"
+
+// The predicates in this file are based on "Adaptive Precision
+// Floating-Point Arithmetic and Fast Robust Geometric Predicates" by Jonathan
+// Richard Shewchuk. Each predicate is first evaluated using regular floating
+// point arithmetic. If the magnitude of the result is large enough compared
+// to a bound on the rounding error, then its sign is guaranteed to be
+// correct. Otherwise, the predicate is re-evaluated exactly using floating
+// point expansions (sums of non-overlapping floating point numbers). This
+// means that the predicates always give the correct sign, and are only
+// slower than the naive implementation for nearly degenerate inputs.
+
+var (
+	// epsilon is half of the machine epsilon, i.e. the largest relative
+	// rounding error of a single floating point operation.
+	epsilon = math.Ldexp(1, -53)
+
+	// splitter is used to split a float64 into two halves, each having at
+	// most 26 significant bits.
+	splitter = math.Ldexp(1, 27) + 1
+
+	orient2dErrBound = (3 + 16*epsilon) * epsilon
+	incircleErrBound = (10 + 96*epsilon) * epsilon
+)
+
+// orient2d gives a positive value if the points a, b, and c are in
+// counter-clockwise order, a negative value if they are in clockwise order,
+// and zero if they are collinear. The sign of the result is always correct,
+// however the magnitude is only approximate.
+func orient2d(a, b, c XY) float64 {
+	detLeft := (a.X - c.X) * (b.Y - c.Y)
+	detRight := (a.Y - c.Y) * (b.X - c.X)
+	det := detLeft - detRight
+
+	var detSum float64
+	switch {
+	case detLeft > 0:
+		if detRight <= 0 {
+			return det
+		}
+		detSum = detLeft + detRight
+	case detLeft < 0:
+		if detRight >= 0 {
+			return det
+		}
+		detSum = -detLeft - detRight
+	default:
+		return det
+	}
+	if math.Abs(det) >= orient2dErrBound*detSum {
+		return det
+	}
+	return orient2dExact(a, b, c)
+}
+
+// orient2dExact calculates the sign of the orientation determinant exactly.
+func orient2dExact(a, b, c XY) float64 {
+	// det = ax*by - ax*cy - bx*ay + bx*cy + cx*ay - cx*by
+	var det expansion
+	det = det.add(twoProduct(a.X, b.Y))
+	det = det.add(twoProduct(-a.X, c.Y))
+	det = det.add(twoProduct(-b.X, a.Y))
+	det = det.add(twoProduct(b.X, c.Y))
+	det = det.add(twoProduct(c.X, a.Y))
+	det = det.add(twoProduct(-c.X, b.Y))
+	return det.approx()
+}
+
+// incircle gives a positive value if the point d lies inside the circle
+// passing through a, b, and c, a negative value if it lies outside the
+// circle, and zero if the four points are cocircular. The points a, b, and c
+// must be in counter-clockwise order, otherwise the sign of the result is
+// reversed. The sign of the result is always correct, however the magnitude
+// is only approximate.
+func incircle(a, b, c, d XY) float64 {
+	adx, ady := a.X-d.X, a.Y-d.Y
+	bdx, bdy := b.X-d.X, b.Y-d.Y
+	cdx, cdy := c.X-d.X, c.Y-d.Y
+
+	bdxcdy, cdxbdy := bdx*cdy, cdx*bdy
+	alift := adx*adx + ady*ady
+	cdxady, adxcdy := cdx*ady, adx*cdy
+	blift := bdx*bdx + bdy*bdy
+	adxbdy, bdxady := adx*bdy, bdx*ady
+	clift := cdx*cdx + cdy*cdy
+
+	det := alift*(bdxcdy-cdxbdy) + blift*(cdxady-adxcdy) + clift*(adxbdy-bdxady)
+	permanent := (math.Abs(bdxcdy)+math.Abs(cdxbdy))*alift +
+		(math.Abs(cdxady)+math.Abs(adxcdy))*blift +
+		(math.Abs(adxbdy)+math.Abs(bdxady))*clift
+	if math.Abs(det) > incircleErrBound*permanent {
+		return det
+	}
+	return incircleExact(a, b, c, d)
+}
+
+// incircleExact calculates the sign of the incircle determinant exactly. The
+// determinant is expanded along its lifted column (x^2 + y^2), with each
+// minor being a 3x3 orientation determinant.
+func incircleExact(a, b, c, d XY) float64 {
+	minor := func(p, q, r XY) expansion {
+		var det expansion
+		det = det.add(twoProduct(p.X, q.Y))
+		det = det.add(twoProduct(-p.X, r.Y))
+		det = det.add(twoProduct(-q.X, p.Y))
+		det = det.add(twoProduct(q.X, r.Y))
+		det = det.add(twoProduct(r.X, p.Y))
+		det = det.add(twoProduct(-r.X, q.Y))
+		return det
+	}
+	lift := func(p XY, m expansion) expansion {
+		return m.scale(p.X).scale(p.X).add(m.scale(p.Y).scale(p.Y))
+	}
+
+	var det expansion
+	det = det.add(lift(a, minor(b, c, d)))
+	det = det.add(lift(b, minor(a, c, d)).negate())
+	det = det.add(lift(c, minor(a, b, d)))
+	det = det.add(lift(d, minor(a, b, c)).negate())
+	return det.approx()
+}
+
+// expansion is an arbitrary precision number, represented as the sum of its
+// components. The components are non-overlapping and ordered by increasing
+// magnitude, and zero components are omitted.
+type expansion []float64
+
+// add calculates the exact sum of two expansions.
+func (e expansion) add(f expansion) expansion {
+	sum := append(expansion(nil), e...)
+	for _, c := range f {
+		sum = sum.grow(c)
+	}
+	return sum
+}
+
+// grow calculates the exact sum of an expansion and a single float64.
+func (e expansion) grow(b float64) expansion {
+	result := make(expansion, 0, len(e)+1)
+	q := b
+	for _, c := range e {
+		var hi, lo float64
+		hi, lo = twoSum(q, c)
+		q = hi
+		if lo != 0 {
+			result = append(result, lo)
+		}
+	}
+	if q != 0 {
+		result = append(result, q)
+	}
+	return result
+}
+
+// scale calculates the exact product of an expansion and a single float64.
+func (e expansion) scale(b float64) expansion {
+	var result expansion
+	for _, c := range e {
+		result = result.add(twoProduct(c, b))
+	}
+	return result
+}
+
+func (e expansion) negate() expansion {
+	neg := make(expansion, len(e))
+	for i, c := range e {
+		neg[i] = -c
+	}
+	return neg
+}
+
+// approx gives an approximation of the expansion's value. Its sign is
+// always exact, since the largest component dominates the others.
+func (e expansion) approx() float64 {
+	var sum float64
+	for _, c := range e {
+		sum += c
+	}
+	if len(e) > 0 && (sum > 0) != (e[len(e)-1] > 0) {
+		return e[len(e)-1]
+	}
+	return sum
+}
+
+// twoSum calculates a+b exactly, giving the result as a rounded sum and
+// the rounding error.
+func twoSum(a, b float64) (float64, float64) {
+	x := a + b
+	bVirt := x - a
+	aVirt := x - bVirt
+	bRound := b - bVirt
+	aRound := a - aVirt
+	return x, aRound + bRound
+}
+
+// twoProduct calculates a*b exactly, giving the result as an expansion with
+// (at most) two components.
+func twoProduct(a, b float64) expansion {
+	x := a * b
+	aHi, aLo := split(a)
+	bHi, bLo := split(b)
+	err1 := x - aHi*bHi
+	err2 := err1 - aLo*bHi
+	err3 := err2 - aHi*bLo
+	y := aLo*bLo - err3
+	var result expansion
+	if y != 0 {
+		result = append(result, y)
+	}
+	if x != 0 {
+		result = append(result, x)
+	}
+	return result
+}
+
+// split splits a float64 into two halves with non-overlapping bits, each of
+// which has at most 26 significant bits.
+func split(a float64) (float64, float64) {
+	c := splitter * a
+	aBig := c - a
+	hi := c - aBig
+	return hi, a - hi
+}
@@ -0,0 +1,174 @@
+package geom
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func ratDet(rows [][]*big.Rat) *big.Rat {
+	if len(rows) == 1 {
+		return rows[0][0]
+	}
+	det := new(big.Rat)
+	for col := range rows[0] {
+		var minor [][]*big.Rat
+		for _, row := range rows[1:] {
+			var r []*big.Rat
+			r = append(r, row[:col]...)
+			r = append(r, row[col+1:]...)
+			minor = append(minor, r)
+		}
+		term := new(big.Rat).Mul(rows[0][col], ratDet(minor))
+		if col%2 == 0 {
+			det.Add(det, term)
+		} else {
+			det.Sub(det, term)
+		}
+	}
+	return det
+}
+
+func rat(f float64) *big.Rat {
+	return new(big.Rat).SetFloat64(f)
+}
+
+func exactOrient2d(a, b, c XY) int {
+	one := big.NewRat(1, 1)
+	return ratDet([][]*big.Rat{
+		{rat(a.X), rat(a.Y), one},
+		{rat(b.X), rat(b.Y), one},
+		{rat(c.X), rat(c.Y), one},
+	}).Sign()
+}
+
+func exactIncircle(a, b, c, d XY) int {
+	one := big.NewRat(1, 1)
+	row := func(p XY) []*big.Rat {
+		lift := new(big.Rat).Add(
+			new(big.Rat).Mul(rat(p.X), rat(p.X)),
+			new(big.Rat).Mul(rat(p.Y), rat(p.Y)),
+		)
+		return []*big.Rat{rat(p.X), rat(p.Y), lift, one}
+	}
+	return ratDet([][]*big.Rat{row(a), row(b), row(c), row(d)}).Sign()
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func TestOrient2dNearlyCollinear(t *testing.T) {
+	// This is the classic example from "Classroom Examples of Robustness
+	// Problems in Geometric Computations" (Kettner et al.), where the naive
+	// calculation gives inconsistent results.
+	b := XY{12, 12}
+	c := XY{24, 24}
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 64; j++ {
+			a := XY{
+				0.5 + float64(i)*math.Ldexp(1, -53),
+				0.5 + float64(j)*math.Ldexp(1, -53),
+			}
+			want := exactOrient2d(a, b, c)
+			for _, got := range []float64{
+				orient2d(a, b, c),
+				orient2d(b, c, a),
+				orient2d(c, a, b),
+				-orient2d(b, a, c),
+			} {
+				if sign(got) != want {
+					t.Fatalf("a=%v: got sign %v want %v", a, sign(got), want)
+				}
+			}
+		}
+	}
+}
+
+func TestOrient2dRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 10000; i++ {
+		a := XY{rnd.Float64(), rnd.Float64()}
+		b := XY{rnd.Float64(), rnd.Float64()}
+		// Place c very close to the line through a and b.
+		tt := rnd.Float64()*3 - 1
+		c := a.Add(b.Sub(a).Scale(tt))
+		c.X += float64(rnd.Intn(3)-1) * math.Ldexp(1, -52)
+		if got, want := sign(orient2d(a, b, c)), exactOrient2d(a, b, c); got != want {
+			t.Fatalf("a=%v b=%v c=%v: got sign %v want %v", a, b, c, got, want)
+		}
+	}
+}
+
+func TestIncircle(t *testing.T) {
+	for _, tt := range []struct {
+		a, b, c, d XY
+		want       int
+	}{
+		{XY{0, 0}, XY{1, 0}, XY{0, 1}, XY{0.5, 0.5}, 1},
+		{XY{0, 0}, XY{1, 0}, XY{0, 1}, XY{1, 1}, 0},
+		{XY{0, 0}, XY{1, 0}, XY{0, 1}, XY{2, 2}, -1},
+		{XY{0, 0}, XY{0, 1}, XY{1, 0}, XY{0.5, 0.5}, -1},
+	} {
+		if got := sign(incircle(tt.a, tt.b, tt.c, tt.d)); got != tt.want {
+			t.Errorf("incircle(%v, %v, %v, %v): got %v want %v", tt.a, tt.b, tt.c, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestIncircleNearlyCocircular(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 2000; i++ {
+		// Points on (or very near) the unit circle.
+		pt := func() XY {
+			theta := rnd.Float64() * 2 * math.Pi
+			return XY{math.Cos(theta), math.Sin(theta)}
+		}
+		a, b, c, d := pt(), pt(), pt(), pt()
+		if got, want := sign(incircle(a, b, c, d)), exactIncircle(a, b, c, d); got != want {
+			t.Fatalf("incircle(%v, %v, %v, %v): got %v want %v", a, b, c, d, got, want)
+		}
+	}
+	for i := 0; i < 2000; i++ {
+		// Integer points on a circle of radius 5, with small perturbations.
+		pts := []XY{{5, 0}, {3, 4}, {0, 5}, {-4, 3}, {-5, 0}, {-3, -4}, {4, -3}}
+		rnd.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+		d := pts[3]
+		d.Y += float64(rnd.Intn(3)-1) * math.Ldexp(1, -50)
+		if got, want := sign(incircle(pts[0], pts[1], pts[2], d)), exactIncircle(pts[0], pts[1], pts[2], d); got != want {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+}
+
+func TestExpansionArithmetic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		a := rnd.NormFloat64() * math.Ldexp(1, rnd.Intn(100)-50)
+		b := rnd.NormFloat64() * math.Ldexp(1, rnd.Intn(100)-50)
+
+		want := new(big.Rat).Mul(rat(a), rat(b))
+		got := new(big.Rat)
+		for _, c := range twoProduct(a, b) {
+			got.Add(got, rat(c))
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("twoProduct(%v, %v) inexact", a, b)
+		}
+
+		hi, lo := twoSum(a, b)
+		want = new(big.Rat).Add(rat(a), rat(b))
+		got = new(big.Rat).Add(rat(hi), rat(lo))
+		if got.Cmp(want) != 0 {
+			t.Fatalf("twoSum(%v, %v) inexact", a, b)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package geom
+
+import (
+	"container/heap"
+	"math"
+)
+
+// simplify simplifies each LineString and Polygon ring in a geometry
+// (including those nested within multi geometries and GeometryCollections).
+// The simplifyFn is given all of the coordinate sequences at once (so that it
+// can consider the interaction between sequences if it needs to). Sequences
+// that collapse (LineStrings with fewer than 2 distinct points or rings with
+// fewer than 4 points) are removed from the result.
+func simplify(g Geometry, simplifyFn func([][]Coordinates) [][]Coordinates, opts []ConstructorOption) (Geometry, error) {
+	seqs := simplifyFn(collectSequences(nil, g))
+	next := func() []Coordinates {
+		seq := seqs[0]
+		seqs = seqs[1:]
+		return seq
+	}
+	result, err := rebuildSimplified(g, next, opts)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return result.WithSRID(g.SRID()), nil
+}
+
+// collectSequences appends the coordinates of each LineString and Polygon
+// ring in the geometry to seqs. Consecutive duplicate points are removed.
+func collectSequences(seqs [][]Coordinates, g Geometry) [][]Coordinates {
+	switch g.tag {
+	case emptySetTag, pointTag, multiPointTag, lineTag:
+	case lineStringTag:
+		seqs = append(seqs, dedupCoordinates(g.AsLineString().Coordinates()))
+	case multiLineStringTag:
+		mls := g.AsMultiLineString()
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			seqs = collectSequences(seqs, mls.LineStringN(i).AsGeometry())
+		}
+	case polygonTag:
+		for _, r := range g.AsPolygon().rings() {
+			seqs = collectSequences(seqs, r.AsGeometry())
+		}
+	case multiPolygonTag:
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			seqs = collectSequences(seqs, mp.PolygonN(i).AsGeometry())
+		}
+	case geometryCollectionTag:
+		gc := g.AsGeometryCollection()
+		for i := 0; i < gc.NumGeometries(); i++ {
+			seqs = collectSequences(seqs, gc.GeometryN(i))
+		}
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+	return seqs
+}
+
+// rebuildSimplified rebuilds a geometry with the same structure as g, but
+// with its LineStrings and Polygon rings replaced by simplified coordinate
+// sequences (given in the same order that collectSequences produced them).
+func rebuildSimplified(g Geometry, next func() []Coordinates, opts []ConstructorOption) (Geometry, error) {
+	switch g.tag {
+	case emptySetTag, pointTag, multiPointTag, lineTag:
+		return g, nil
+	case lineStringTag:
+		ls, ok, err := rebuildLineString(next(), opts)
+		if err != nil {
+			return Geometry{}, err
+		}
+		if !ok {
+			return NewEmptyLineString(opts...).ForceCoordinatesType(g.CoordinatesType()).AsGeometry(), nil
+		}
+		return ls.AsGeometry(), nil
+	case multiLineStringTag:
+		mls := g.AsMultiLineString()
+		var lss []LineString
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			ls, ok, err := rebuildLineString(next(), opts)
+			if err != nil {
+				return Geometry{}, err
+			}
+			if ok {
+				lss = append(lss, ls)
+			}
+		}
+		return NewMultiLineString(lss, opts...).ForceCoordinatesType(g.CoordinatesType()).AsGeometry(), nil
+	case polygonTag:
+		poly, ok, err := rebuildPolygon(g.AsPolygon(), next, opts)
+		if err != nil {
+			return Geometry{}, err
+		}
+		if !ok {
+			return NewEmptyPolygon(opts...).ForceCoordinatesType(g.CoordinatesType()).AsGeometry(), nil
+		}
+		return poly.AsGeometry(), nil
+	case multiPolygonTag:
+		mp := g.AsMultiPolygon()
+		var polys []Polygon
+		for i := 0; i < mp.NumPolygons(); i++ {
+			poly, ok, err := rebuildPolygon(mp.PolygonN(i), next, opts)
+			if err != nil {
+				return Geometry{}, err
+			}
+			if ok {
+				polys = append(polys, poly)
+			}
+		}
+		result, err := NewMultiPolygon(polys, opts...)
+		if err != nil {
+			return Geometry{}, err
+		}
+		return result.ForceCoordinatesType(g.CoordinatesType()).AsGeometry(), nil
+	case geometryCollectionTag:
+		gc := g.AsGeometryCollection()
+		geoms := make([]Geometry, gc.NumGeometries())
+		for i := range geoms {
+			var err error
+			geoms[i], err = rebuildSimplified(gc.GeometryN(i), next, opts)
+			if err != nil {
+				return Geometry{}, err
+			}
+		}
+		return NewGeometryCollection(geoms, opts...).AsGeometry(), nil
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+// rebuildLineString creates a LineString from simplified coordinates. It
+// returns false if the LineString has collapsed.
+func rebuildLineString(coords []Coordinates, opts []ConstructorOption) (LineString, bool, error) {
+	if len(dedupCoordinates(coords)) < 2 {
+		return LineString{}, false, nil
+	}
+	ls, err := NewLineStringC(coords, opts...)
+	return ls, err == nil, err
+}
+
+// rebuildPolygon creates a Polygon from simplified rings. It returns false if
+// the Polygon's outer ring has collapsed. Any inner rings that have collapsed
+// are omitted.
+func rebuildPolygon(orig Polygon, next func() []Coordinates, opts []ConstructorOption) (Polygon, bool, error) {
+	var rings []LineString
+	for i := 0; i < 1+orig.NumInteriorRings(); i++ {
+		coords := next()
+		if len(dedupCoordinates(coords)) < 4 {
+			if i == 0 {
+				// Consume the remaining rings (they're all discarded
+				// along with the outer ring).
+				for j := 0; j < orig.NumInteriorRings(); j++ {
+					next()
+				}
+				return Polygon{}, false, nil
+			}
+			continue
+		}
+		ring, err := NewLineStringC(coords, opts...)
+		if err != nil {
+			return Polygon{}, false, err
+		}
+		rings = append(rings, ring)
+	}
+	poly, err := NewPolygon(rings[0], rings[1:], opts...)
+	return poly, err == nil, err
+}
+
+// dedupCoordinates removes consecutive coordinates that have the same XY
+// values.
+func dedupCoordinates(coords []Coordinates) []Coordinates {
+	var deduped []Coordinates
+	for _, c := range coords {
+		if len(deduped) == 0 || deduped[len(deduped)-1].XY != c.XY {
+			deduped = append(deduped, c)
+		}
+	}
+	return deduped
+}
+
+// furthestFromSegment finds the coordinate strictly between indices i and j
+// that is furthest from the segment between coords i and j.
+func furthestFromSegment(coords []Coordinates, i, j int) (int, float64) {
+	k, dist := -1, -1.0
+	for m := i + 1; m < j; m++ {
+		if d := distanceToSegment(coords[m].XY, coords[i].XY, coords[j].XY); d > dist {
+			k, dist = m, d
+		}
+	}
+	return k, dist
+}
+
+// douglasPeucker simplifies a sequence of coordinates using the
+// Ramer-Douglas-Peucker algorithm.
+func douglasPeucker(coords []Coordinates, tolerance float64) []Coordinates {
+	if len(coords) <= 2 {
+		return coords
+	}
+	keep := make([]bool, len(coords))
+	keep[0] = true
+	keep[len(coords)-1] = true
+	stack := [][2]int{{0, len(coords) - 1}}
+	for len(stack) > 0 {
+		i, j := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		k, dist := furthestFromSegment(coords, i, j)
+		if k != -1 && dist > tolerance {
+			keep[k] = true
+			stack = append(stack, [2]int{i, k}, [2]int{k, j})
+		}
+	}
+	return keptCoordinates(coords, keep)
+}
+
+func keptCoordinates(coords []Coordinates, keep []bool) []Coordinates {
+	var kept []Coordinates
+	for i, c := range coords {
+		if keep[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// visvalingamWhyatt simplifies a sequence of coordinates using the
+// Visvalingam-Whyatt algorithm. The first and last coordinates are always
+// retained.
+func visvalingamWhyatt(coords []Coordinates, areaThreshold float64) []Coordinates {
+	n := len(coords)
+	if n <= 2 {
+		return coords
+	}
+	prev := make([]int, n)
+	next := make([]int, n)
+	for i := range coords {
+		prev[i] = i - 1
+		next[i] = i + 1
+	}
+	area := func(i int) float64 {
+		a := coords[prev[i]].XY
+		b := coords[i].XY
+		c := coords[next[i]].XY
+		return math.Abs(b.Sub(a).Cross(c.Sub(a))) / 2
+	}
+
+	areas := make([]float64, n)
+	h := &vwHeap{}
+	for i := 1; i+1 < n; i++ {
+		areas[i] = area(i)
+		heap.Push(h, vwItem{i, areas[i]})
+	}
+	keep := make([]bool, n)
+	for i := range keep {
+		keep[i] = true
+	}
+	for h.Len() > 0 {
+		item := heap.Pop(h).(vwItem)
+		if !keep[item.idx] || item.area != areas[item.idx] {
+			continue // stale entry
+		}
+		if item.area >= areaThreshold {
+			break
+		}
+		keep[item.idx] = false
+		p, q := prev[item.idx], next[item.idx]
+		next[p] = q
+		prev[q] = p
+		for _, nb := range [2]int{p, q} {
+			if nb != 0 && nb != n-1 {
+				areas[nb] = area(nb)
+				heap.Push(h, vwItem{nb, areas[nb]})
+			}
+		}
+	}
+	return keptCoordinates(coords, keep)
+}
+
+type vwItem struct {
+	idx  int
+	area float64
+}
+
+// vwHeap is a min heap of vwItems, ordered by area (and then index, so that
+// results are deterministic).
+type vwHeap []vwItem
+
+func (h vwHeap) Len() int { return len(h) }
+func (h vwHeap) Less(i, j int) bool {
+	if h[i].area != h[j].area {
+		return h[i].area < h[j].area
+	}
+	return h[i].idx < h[j].idx
+}
+func (h vwHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *vwHeap) Push(x interface{}) { *h = append(*h, x.(vwItem)) }
+func (h *vwHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// topologySimplifier simplifies a set of coordinate sequences using the
+// Ramer-Douglas-Peucker algorithm, but only removes a section of points if
+// doing so doesn't cause any sequence to cross itself or any other sequence,
+// doesn't cause any sequence to jump to the other side of another sequence,
+// and doesn't cause a closed sequence to collapse.
+type topologySimplifier struct {
+	seqs [][]Coordinates
+	keep [][]bool
+	kept []int
+	tol  float64
+}
+
+func simplifyPreserveTopology(seqs [][]Coordinates, tolerance float64) [][]Coordinates {
+	s := topologySimplifier{
+		seqs: seqs,
+		keep: make([][]bool, len(seqs)),
+		kept: make([]int, len(seqs)),
+		tol:  tolerance,
+	}
+	for i, seq := range seqs {
+		s.keep[i] = make([]bool, len(seq))
+		for j := range seq {
+			s.keep[i][j] = true
+		}
+		s.kept[i] = len(seq)
+	}
+	for i := range seqs {
+		s.simplifySequence(i)
+	}
+	result := make([][]Coordinates, len(seqs))
+	for i, seq := range seqs {
+		result[i] = keptCoordinates(seq, s.keep[i])
+	}
+	return result
+}
+
+func (s *topologySimplifier) simplifySequence(idx int) {
+	seq := s.seqs[idx]
+	n := len(seq)
+	if n <= 2 {
+		return
+	}
+
+	var stack [][2]int
+	if seq[0].XY == seq[n-1].XY {
+		// Closed sequences are split at the point furthest from the start
+		// point, since the start point and end point are the same.
+		if n <= 4 {
+			return
+		}
+		k, _ := furthestFromSegment(seq, 0, n-1)
+		stack = [][2]int{{0, k}, {k, n - 1}}
+	} else {
+		stack = [][2]int{{0, n - 1}}
+	}
+
+	for len(stack) > 0 {
+		i, j := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		k, dist := furthestFromSegment(seq, i, j)
+		if k == -1 {
+			continue
+		}
+		if dist <= s.tol && s.canShortcut(idx, i, j) {
+			for m := i + 1; m < j; m++ {
+				s.keep[idx][m] = false
+			}
+			s.kept[idx] -= j - i - 1
+			continue
+		}
+		stack = append(stack, [2]int{i, k}, [2]int{k, j})
+	}
+}
+
+// canShortcut checks if the points strictly between indices i and j of a
+// sequence can be removed without changing the topology.
+func (s *topologySimplifier) canShortcut(idx, i, j int) bool {
+	seq := s.seqs[idx]
+	closed := seq[0].XY == seq[len(seq)-1].XY
+	if closed && s.kept[idx]-(j-i-1) < 4 {
+		return false
+	}
+
+	a, b := seq[i].XY, seq[j].XY
+	shortcut := Line{Coordinates{XY: a}, Coordinates{XY: b}}
+	shortcutEnv := NewEnvelope(a, b)
+	for t := range s.seqs {
+		crosses := s.forEachSegment(t, func(u, v int) bool {
+			if t == idx && u >= i && v <= j {
+				return false // part of the section being replaced
+			}
+			c, d := s.seqs[t][u].XY, s.seqs[t][v].XY
+			if !NewEnvelope(c, d).Intersects(shortcutEnv) {
+				return false
+			}
+			inter := intersectLineWithLineNoAlloc(shortcut, Line{Coordinates{XY: c}, Coordinates{XY: d}})
+			if inter.empty {
+				return false
+			}
+			return inter.ptA != inter.ptB || (inter.ptA != a && inter.ptA != b)
+		})
+		if crosses {
+			return false
+		}
+	}
+
+	// The shortcut doesn't cross anything, but other sequences may be
+	// enclosed by the region between the original section and the shortcut
+	// (in which case they would swap sides). Because nothing crosses the
+	// region's boundary, testing a single point of each sequence is enough.
+	region := make([]XY, 0, j-i+2)
+	for m := i; m <= j; m++ {
+		region = append(region, seq[m].XY)
+	}
+	region = append(region, a)
+	for t := range s.seqs {
+		for m, c := range s.seqs[t] {
+			if !s.keep[t][m] || (t == idx && m >= i && m <= j) || c.XY == a || c.XY == b {
+				continue
+			}
+			if pointInRingXY(c.XY, region) {
+				return false
+			}
+			break
+		}
+	}
+	return true
+}
+
+// forEachSegment calls fn for each segment (defined by the indices of its
+// endpoints) in the current state of a sequence, stopping early if fn returns
+// true.
+func (s *topologySimplifier) forEachSegment(idx int, fn func(u, v int) bool) bool {
+	prev := -1
+	for m := range s.seqs[idx] {
+		if !s.keep[idx][m] {
+			continue
+		}
+		if prev != -1 && fn(prev, m) {
+			return true
+		}
+		prev = m
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package geom_test
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		tol  float64
+		want string
+	}{
+		{"POINT(1 2)", 1, "POINT(1 2)"},
+		{"MULTIPOINT(0 0,0.1 0.1)", 1, "MULTIPOINT(0 0,0.1 0.1)"},
+		{"LINESTRING(0 0,1 1)", 1, "LINESTRING(0 0,1 1)"},
+		{"LINESTRING(0 0,1 0.5,2 0)", 1, "LINESTRING(0 0,2 0)"},
+		{"LINESTRING(0 0,1 0.5,2 0)", 0.4, "LINESTRING(0 0,1 0.5,2 0)"},
+		{"LINESTRING(0 0,1 0,2 0,3 0)", 0, "LINESTRING(0 0,3 0)"},
+		{"LINESTRING(0 0,1 0.1,2 -0.1,3 5,4 6.1,5 7)", 0.5, "LINESTRING(0 0,2 -0.1,3 5,5 7)"},
+		{"LINESTRING Z (0 0 1,1 0.1 2,2 0 3)", 1, "LINESTRING Z (0 0 1,2 0 3)"},
+		{"LINESTRING(0 0,1 0.1,0 0)", 2, "LINESTRING EMPTY"},
+		{"MULTILINESTRING((0 0,1 0.1,2 0),(5 5,6 6))", 1, "MULTILINESTRING((0 0,2 0),(5 5,6 6))"},
+		{"MULTILINESTRING((0 0,1 0.1,0 0))", 2, "MULTILINESTRING EMPTY"},
+		{"POLYGON((0 0,2 0,2 2,1 2.1,0 2,0 0))", 0.5, "POLYGON((0 0,2 0,2 2,0 2,0 0))"},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", 2, "POLYGON EMPTY"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(1 1,1.1 1,1.1 1.1,1 1.1,1 1))", 0.5, "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+		{
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((5 5,15 5,15 15,5 15,5 5)))", 2,
+			"MULTIPOLYGON(((5 5,15 5,15 15,5 15,5 5)))",
+		},
+		{
+			"GEOMETRYCOLLECTION(POINT(0 0),LINESTRING(0 0,1 0.1,2 0))", 1,
+			"GEOMETRYCOLLECTION(POINT(0 0),LINESTRING(0 0,2 0))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := geomFromWKT(t, tt.wkt).Simplify(tt.tol)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestSimplifyInvalidResult(t *testing.T) {
+	// The hole is close to the outer ring, so it ends up outside the
+	// simplified outer ring.
+	_, err := geomFromWKT(t, "POLYGON((0 0,5 -1,10 0,10 10,0 10,0 0),(4 -0.5,6 -0.5,5 3,4 -0.5))").Simplify(1.5)
+	if err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestSimplifyVW(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		area float64
+		want string
+	}{
+		{"LINESTRING(0 0,1 1)", 1, "LINESTRING(0 0,1 1)"},
+		{"LINESTRING(0 0,1 1,2 0)", 0.5, "LINESTRING(0 0,1 1,2 0)"},
+		{"LINESTRING(0 0,1 1,2 0)", 1.5, "LINESTRING(0 0,2 0)"},
+		{"LINESTRING(0 0,1 0.1,2 0,3 2,4 0)", 1, "LINESTRING(0 0,2 0,3 2,4 0)"},
+		{"LINESTRING(0 0,1 0.1,2 0,3 2,4 0)", 5, "LINESTRING(0 0,4 0)"},
+		{"POLYGON((0 0,4 0,4 4,2 4.1,0 4,0 0))", 1, "POLYGON((0 0,4 0,4 4,0 4,0 0))"},
+		{"POLYGON((0 0,4 0,4 4,0 4,0 0))", 100, "POLYGON EMPTY"},
+		{"MULTILINESTRING((0 0,1 0.1,2 0),(0 5,1 5.1,0 5))", 1, "MULTILINESTRING((0 0,2 0))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := geomFromWKT(t, tt.wkt).SimplifyVW(tt.area)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestSimplifyPreserveTopology(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		tol  float64
+		want string
+	}{
+		{"LINESTRING(0 0,1 0.5,2 0)", 1, "LINESTRING(0 0,2 0)"},
+		{"POLYGON((0 0,2 0,2 2,1 2.1,0 2,0 0))", 0.5, "POLYGON((0 0,2 0,2 2,0 2,0 0))"},
+
+		// Rings are not allowed to collapse.
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", 2, "POLYGON((0 0,1 0,1 1,0 0))"},
+		{"POLYGON((0 0,1 0,1 1,0 0))", 2, "POLYGON((0 0,1 0,1 1,0 0))"},
+
+		// The hole would end up outside of the outer ring if the outer ring
+		// was simplified naively.
+		{
+			"POLYGON((0 0,5 -1,10 0,10 10,0 10,0 0),(4 -0.5,6 -0.5,5 3,4 -0.5))", 2,
+			"POLYGON((0 0,5 -1,10 0,10 10,0 10,0 0),(4 -0.5,6 -0.5,5 3,4 -0.5))",
+		},
+
+		// The second line would be crossed by the first line if the first
+		// line was simplified naively.
+		{
+			"MULTILINESTRING((0 0,5 1,10 0),(5 0.5,5 0.8))", 2,
+			"MULTILINESTRING((0 0,5 1,10 0),(5 0.5,5 0.8))",
+		},
+		{
+			"MULTILINESTRING((0 0,5 1,10 0),(5 5,5 6))", 2,
+			"MULTILINESTRING((0 0,10 0),(5 5,5 6))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := geomFromWKT(t, tt.wkt).SimplifyPreserveTopology(tt.tol)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestSimplifyRetainsSRID(t *testing.T) {
+	g := geomFromWKT(t, "LINESTRING(0 0,1 0.1,2 0)").WithSRID(4326)
+	got, err := g.Simplify(1)
+	expectNoErr(t, err)
+	expectIntEq(t, got.SRID(), 4326)
+}
@@ -741,3 +741,80 @@ func TestReverse(t *testing.T) {
 		})
 	}
 }
+
+func TestCoordinatesType(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		want CoordinatesType
+	}{
+		{"POINT(1 2)", DimXY},
+		{"POINT Z (1 2 3)", DimXYZ},
+		{"POINT M EMPTY", DimXYM},
+		{"LINESTRING ZM (1 2 3 4,5 6 7 8)", DimXYZM},
+		{"LINESTRING Z (1 2 3,5 6 7,8 9 10)", DimXYZ},
+		{"POLYGON M ((0 0 1,1 0 1,0 1 1,0 0 1))", DimXYM},
+		{"MULTIPOINT Z EMPTY", DimXYZ},
+		{"MULTILINESTRING M ((1 2 3,4 5 6))", DimXYM},
+		{"MULTIPOLYGON ZM EMPTY", DimXYZM},
+		{"GEOMETRYCOLLECTION Z (POINT Z (1 2 3))", DimXYZ},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := geomFromWKT(t, tt.wkt).CoordinatesType()
+			if got != tt.want {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoordinatesTypeDimension(t *testing.T) {
+	for _, tt := range []struct {
+		ctype   CoordinatesType
+		dim     int
+		is3D    bool
+		measure bool
+	}{
+		{DimXY, 2, false, false},
+		{DimXYZ, 3, true, false},
+		{DimXYM, 3, false, true},
+		{DimXYZM, 4, true, true},
+	} {
+		t.Run(tt.ctype.String(), func(t *testing.T) {
+			expectIntEq(t, tt.ctype.Dimension(), tt.dim)
+			expectBoolEq(t, tt.ctype.Is3D(), tt.is3D)
+			expectBoolEq(t, tt.ctype.IsMeasured(), tt.measure)
+		})
+	}
+}
+
+func TestForceCoordinatesType(t *testing.T) {
+	for i, tt := range []struct {
+		input string
+		ctype CoordinatesType
+		want  string
+	}{
+		{"POINT ZM (1 2 3 4)", DimXY, "POINT(1 2)"},
+		{"POINT ZM (1 2 3 4)", DimXYM, "POINT M (1 2 4)"},
+		{"POINT(1 2)", DimXYZ, "POINT Z (1 2 0)"},
+		{"POINT EMPTY", DimXYZM, "POINT ZM EMPTY"},
+		{"LINESTRING Z (1 2 3,4 5 6)", DimXYM, "LINESTRING M (1 2 0,4 5 0)"},
+		{"POLYGON M ((0 0 1,1 0 2,0 1 3,0 0 1))", DimXYZM, "POLYGON ZM ((0 0 0 1,1 0 0 2,0 1 0 3,0 0 0 1))"},
+		{"MULTIPOINT Z ((1 2 3))", DimXY, "MULTIPOINT((1 2))"},
+		{"MULTILINESTRING EMPTY", DimXYZ, "MULTILINESTRING Z EMPTY"},
+		{"MULTIPOLYGON Z (((0 0 1,1 0 2,0 1 3,0 0 1)))", DimXY, "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))"},
+		{"GEOMETRYCOLLECTION(POINT(1 2))", DimXYM, "GEOMETRYCOLLECTION M (POINT M (1 2 0))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := geomFromWKT(t, tt.input).ForceCoordinatesType(tt.ctype)
+			expectStringEq(t, got.AsText(), tt.want)
+		})
+	}
+}
+
+func TestCollectionLowestCommonCoordinatesType(t *testing.T) {
+	gc := NewGeometryCollection([]Geometry{
+		geomFromWKT(t, "POINT ZM (1 2 3 4)"),
+		geomFromWKT(t, "POINT M (5 6 7)"),
+	})
+	expectStringEq(t, gc.AsText(), "GEOMETRYCOLLECTION M (POINT M (1 2 4),POINT M (5 6 7))")
+}
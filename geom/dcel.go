@@ -0,0 +1,847 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// dcel is a Doubly Connected Edge List. It represents the planar subdivision
+// created by overlaying two geometries on top of each other. Each vertex,
+// edge, and face in the subdivision is labelled according to its relationship
+// with each of the two input geometries.
+type dcel struct {
+	vertices  []*dcelVertex
+	vertexMap map[XY]*dcelVertex
+	edges     []*dcelEdge
+	cycles    []*dcelCycle
+
+	// faces[0] is always the unbounded face.
+	faces []*dcelFace
+
+	inputs [2]overlayInput
+	nodes  *nodeSet
+}
+
+type dcelVertex struct {
+	coords XY
+
+	// incident holds the half edges that have the vertex as their origin.
+	// Once the dcel is built, they are sorted counter-clockwise by angle.
+	incident []*dcelHalfEdge
+
+	// explicit is true if the vertex is a point from the input geometry
+	// (rather than just a vertex of one of its lines or rings).
+	explicit [2]bool
+
+	// inSet is true if the vertex is in the point set of the input geometry.
+	inSet [2]bool
+
+	component int
+}
+
+type dcelHalfEdge struct {
+	origin *dcelVertex
+	twin   *dcelHalfEdge
+	next   *dcelHalfEdge
+	cycle  *dcelCycle
+	edge   *dcelEdge
+}
+
+func (e *dcelHalfEdge) dest() *dcelVertex {
+	return e.twin.origin
+}
+
+// face gives the face to the left of the half edge.
+func (e *dcelHalfEdge) face() *dcelFace {
+	return e.cycle.face
+}
+
+type dcelEdge struct {
+	// halfEdge is one of the two half edges making up the edge. The other can
+	// be found using its twin.
+	halfEdge *dcelHalfEdge
+
+	// linework is true if the edge came from a linear element or polygon
+	// ring in the input geometry.
+	linework [2]bool
+
+	// inSet is true if the edge is in the point set of the input geometry.
+	inSet [2]bool
+}
+
+// dcelCycle is a closed walk of half edges, found by repeatedly following
+// the next pointer.
+type dcelCycle struct {
+	first      *dcelHalfEdge
+	signedArea float64
+	face       *dcelFace
+}
+
+func (c *dcelCycle) halfEdges() []*dcelHalfEdge {
+	var edges []*dcelHalfEdge
+	e := c.first
+	for {
+		edges = append(edges, e)
+		e = e.next
+		if e == c.first {
+			return edges
+		}
+	}
+}
+
+func (c *dcelCycle) ring() []XY {
+	var ring []XY
+	for _, e := range c.halfEdges() {
+		ring = append(ring, e.origin.coords)
+	}
+	return append(ring, ring[0])
+}
+
+type dcelFace struct {
+	// outer is the cycle making up the outer boundary of the face. It's nil
+	// for the unbounded face.
+	outer *dcelCycle
+
+	// inner are the cycles making up the boundaries of any holes in the
+	// face.
+	inner []*dcelCycle
+
+	// inArea is true if the face is in the interior of the input geometry.
+	inArea [2]bool
+}
+
+func (f *dcelFace) cycles() []*dcelCycle {
+	if f.outer == nil {
+		return f.inner
+	}
+	return append([]*dcelCycle{f.outer}, f.inner...)
+}
+
+// newDCEL creates a dcel by overlaying two geometries and labelling the
+// resultant vertices, edges, and faces.
+func newDCEL(a, b Geometry) (*dcel, error) {
+	return newDCELFromInputs(newOverlayInput(a), newOverlayInput(b))
+}
+
+// newDCELFromInputs is like newDCEL, but accepts geometries that have already
+// been broken down into their constituent parts.
+func newDCELFromInputs(a, b overlayInput) (*dcel, error) {
+	d := &dcel{
+		vertexMap: make(map[XY]*dcelVertex),
+		inputs:    [2]overlayInput{a, b},
+	}
+	for i := range d.inputs {
+		d.inputs[i].findPolygonEnvelopes()
+	}
+	if err := d.addNodedSegments(); err != nil {
+		return nil, err
+	}
+	d.addPoints()
+	d.linkHalfEdges()
+	d.findCycles()
+	d.findComponents()
+	d.createFaces()
+	d.labelFaces()
+	d.labelEdges()
+	d.labelVertices()
+	return d, nil
+}
+
+type dcelSegment struct {
+	a, b  XY
+	input int
+}
+
+func (d *dcel) addNodedSegments() error {
+	var segs []dcelSegment
+	var pts []XY
+	for i, input := range d.inputs {
+		for _, ls := range append(input.lines, input.rings...) {
+			for j := 0; j+1 < len(ls); j++ {
+				if ls[j] != ls[j+1] {
+					segs = append(segs, dcelSegment{ls[j], ls[j+1], i})
+				}
+			}
+		}
+		pts = append(pts, input.points...)
+	}
+
+	d.nodes = newNodeSet(segs, pts)
+	segs, err := nodeSegments(segs, d.nodes)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segs {
+		d.addEdge(seg)
+	}
+	return nil
+}
+
+// maxNodingPasses limits the number of passes that nodeSegments makes. Each
+// pass is only needed to fix up problems caused by numerical precision
+// issues in the previous pass, so only a small number of passes are ever
+// needed in practice.
+const maxNodingPasses = 10
+
+// nodeSegments splits each segment at any points where it intersects other
+// segments, such that the resultant segments only intersect each other at
+// their endpoints.
+//
+// Intersection points can't be represented exactly using floating point
+// numbers, so the segments are split at the nearby node (rather than at the
+// intersection point itself). Splitting segments moves them slightly, which
+// can cause new intersections. So the process is repeated until no new
+// intersections are found. An error is returned if that doesn't happen
+// within maxNodingPasses passes.
+func nodeSegments(segs []dcelSegment, nodes *nodeSet) ([]dcelSegment, error) {
+	for pass := 0; pass < maxNodingPasses; pass++ {
+		segs = splitSegmentsAtNodes(segs, nodes)
+
+		// Only pairs of segments with intersecting envelopes can intersect,
+		// so an index is used to find candidate pairs.
+		indexed := make([]indexedSegment, len(segs))
+		for i, seg := range segs {
+			indexed[i] = indexedSegment{seg.a, seg.b, NewEnvelope(seg.a, seg.b)}
+		}
+		index := newSegmentIndex(indexed)
+		clean := true
+		for i := range segs {
+			lnI := Line{Coordinates{XY: segs[i].a}, Coordinates{XY: segs[i].b}}
+			index.search(indexed[i].env, func(j int) bool {
+				if j <= i {
+					return false
+				}
+				lnJ := Line{Coordinates{XY: segs[j].a}, Coordinates{XY: segs[j].b}}
+				inter := intersectLineWithLineNoAlloc(lnI, lnJ)
+				if inter.empty {
+					return false
+				}
+				for _, pt := range [2]XY{inter.ptA, inter.ptB} {
+					if !segs[i].hasEndpoint(pt) || !segs[j].hasEndpoint(pt) {
+						nodes.insert(pt)
+						clean = false
+					}
+				}
+				return false
+			})
+		}
+		if clean {
+			return segs, nil
+		}
+	}
+	return nil, fmt.Errorf("noding did not converge after %d passes", maxNodingPasses)
+}
+
+func (s dcelSegment) hasEndpoint(pt XY) bool {
+	return pt == s.a || pt == s.b
+}
+
+// splitSegmentsAtNodes splits each segment at each node that is close to it.
+// Each resultant segment has nodes as its endpoints.
+func splitSegmentsAtNodes(segs []dcelSegment, nodes *nodeSet) []dcelSegment {
+	sorted := nodes.sortedByX()
+	var split []dcelSegment
+	for _, seg := range segs {
+		a, b := nodes.find(seg.a), nodes.find(seg.b)
+		env := NewEnvelope(a, b)
+		lo := sort.Search(len(sorted), func(i int) bool {
+			return sorted[i].X >= env.min.X-nodes.tol
+		})
+		pts := []XY{a, b}
+		for _, pt := range sorted[lo:] {
+			if pt.X > env.max.X+nodes.tol {
+				break
+			}
+			if pt != a && pt != b && distanceToSegment(pt, a, b) <= nodes.tol {
+				pts = append(pts, pt)
+			}
+		}
+
+		dir := b.Sub(a)
+		sort.Slice(pts, func(i, j int) bool {
+			return pts[i].Sub(a).Dot(dir) < pts[j].Sub(a).Dot(dir)
+		})
+		for i := 0; i+1 < len(pts); i++ {
+			if pts[i] != pts[i+1] {
+				split = append(split, dcelSegment{pts[i], pts[i+1], seg.input})
+			}
+		}
+	}
+	return split
+}
+
+// distanceToSegment finds the distance between a point and a line segment.
+func distanceToSegment(pt, a, b XY) float64 {
+	return pt.Sub(closestPointOnSegment(pt, a, b)).Length()
+}
+
+// nodeSet is a set of nodes (points where segments are split). Points that
+// are added to the set are merged with any existing node that is within a
+// small tolerance.
+type nodeSet struct {
+	tol   float64
+	cells map[[2]int64][]XY
+	nodes []XY
+}
+
+// nodingTolerance is the tolerance used for merging nodes, relative to the
+// magnitude of the coordinates involved.
+const nodingTolerance = 1e-10
+
+// newNodeSet creates a nodeSet containing the endpoints of the segments and
+// the points. Its tolerance is based on the magnitude of the coordinates.
+func newNodeSet(segs []dcelSegment, pts []XY) *nodeSet {
+	var maxAbs float64
+	update := func(pt XY) {
+		maxAbs = math.Max(maxAbs, math.Max(math.Abs(pt.X), math.Abs(pt.Y)))
+	}
+	for _, seg := range segs {
+		update(seg.a)
+		update(seg.b)
+	}
+	for _, pt := range pts {
+		update(pt)
+	}
+
+	s := &nodeSet{
+		tol:   nodingTolerance * math.Max(1, maxAbs),
+		cells: make(map[[2]int64][]XY),
+	}
+	for _, seg := range segs {
+		s.insert(seg.a)
+		s.insert(seg.b)
+	}
+	for _, pt := range pts {
+		s.insert(pt)
+	}
+	return s
+}
+
+func (s *nodeSet) cell(pt XY) [2]int64 {
+	return [2]int64{
+		int64(math.Floor(pt.X / s.tol)),
+		int64(math.Floor(pt.Y / s.tol)),
+	}
+}
+
+// lookup finds the existing node that is within tolerance of pt.
+func (s *nodeSet) lookup(pt XY) (XY, bool) {
+	c := s.cell(pt)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, node := range s.cells[[2]int64{c[0] + dx, c[1] + dy}] {
+				if node.Sub(pt).Length() <= s.tol {
+					return node, true
+				}
+			}
+		}
+	}
+	return XY{}, false
+}
+
+// insert adds a point to the set (unless it's within tolerance of an existing
+// node).
+func (s *nodeSet) insert(pt XY) {
+	if _, ok := s.lookup(pt); ok {
+		return
+	}
+	c := s.cell(pt)
+	s.cells[c] = append(s.cells[c], pt)
+	s.nodes = append(s.nodes, pt)
+}
+
+// find gives the node that pt has been merged into. The point must have
+// previously been inserted.
+func (s *nodeSet) find(pt XY) XY {
+	node, ok := s.lookup(pt)
+	if !ok {
+		panic("point not in node set")
+	}
+	return node
+}
+
+func (s *nodeSet) sortedByX() []XY {
+	sorted := append([]XY(nil), s.nodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].X < sorted[j].X
+	})
+	return sorted
+}
+
+func (d *dcel) vertex(xy XY) *dcelVertex {
+	v, ok := d.vertexMap[xy]
+	if !ok {
+		v = &dcelVertex{coords: xy}
+		d.vertexMap[xy] = v
+		d.vertices = append(d.vertices, v)
+	}
+	return v
+}
+
+func (d *dcel) addEdge(seg dcelSegment) {
+	u := d.vertex(seg.a)
+	v := d.vertex(seg.b)
+	for _, e := range u.incident {
+		if e.dest() == v {
+			e.edge.linework[seg.input] = true
+			return
+		}
+	}
+
+	edge := &dcelEdge{}
+	edge.linework[seg.input] = true
+	fwd := &dcelHalfEdge{origin: u, edge: edge}
+	rev := &dcelHalfEdge{origin: v, edge: edge}
+	fwd.twin = rev
+	rev.twin = fwd
+	edge.halfEdge = fwd
+	u.incident = append(u.incident, fwd)
+	v.incident = append(v.incident, rev)
+	d.edges = append(d.edges, edge)
+}
+
+func (d *dcel) addPoints() {
+	for i, input := range d.inputs {
+		for _, pt := range input.points {
+			d.vertex(d.nodes.find(pt)).explicit[i] = true
+		}
+	}
+}
+
+// linkHalfEdges sorts the half edges around each vertex, and then uses that
+// ordering to populate the next pointers. Faces are always to the left of
+// their half edges.
+func (d *dcel) linkHalfEdges() {
+	for _, v := range d.vertices {
+		sortCounterClockwise(v.coords, v.incident)
+		n := len(v.incident)
+		for i, e := range v.incident {
+			// The next half edge after an incoming half edge is the
+			// outgoing half edge that is immediately clockwise from its
+			// twin.
+			e.twin.next = v.incident[(i-1+n)%n]
+		}
+	}
+}
+
+// sortCounterClockwise sorts half edges sharing the same origin by the angle
+// they make with the positive X axis.
+func sortCounterClockwise(origin XY, edges []*dcelHalfEdge) {
+	quadrant := func(v XY) int {
+		switch {
+		case v.X > 0 && v.Y >= 0:
+			return 0
+		case v.X <= 0 && v.Y > 0:
+			return 1
+		case v.X < 0 && v.Y <= 0:
+			return 2
+		default:
+			return 3
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		vi := edges[i].dest().coords.Sub(origin)
+		vj := edges[j].dest().coords.Sub(origin)
+		qi, qj := quadrant(vi), quadrant(vj)
+		if qi != qj {
+			return qi < qj
+		}
+		return orient2d(origin, edges[i].dest().coords, edges[j].dest().coords) > 0
+	})
+}
+
+func (d *dcel) findCycles() {
+	for _, edge := range d.edges {
+		for _, start := range [2]*dcelHalfEdge{edge.halfEdge, edge.halfEdge.twin} {
+			if start.cycle != nil {
+				continue
+			}
+			cycle := &dcelCycle{first: start}
+			e := start
+			for {
+				e.cycle = cycle
+				cycle.signedArea += e.origin.coords.Cross(e.dest().coords) / 2
+				e = e.next
+				if e == start {
+					break
+				}
+			}
+			d.cycles = append(d.cycles, cycle)
+		}
+	}
+}
+
+// findComponents finds the connected components of the graph formed by the
+// vertices and edges. Each vertex is labelled with its component number.
+func (d *dcel) findComponents() {
+	for _, v := range d.vertices {
+		v.component = -1
+	}
+	var n int
+	for _, v := range d.vertices {
+		if v.component != -1 {
+			continue
+		}
+		stack := []*dcelVertex{v}
+		v.component = n
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range u.incident {
+				if w := e.dest(); w.component == -1 {
+					w.component = n
+					stack = append(stack, w)
+				}
+			}
+		}
+		n++
+	}
+}
+
+// createFaces creates the faces of the subdivision. Each connected component
+// has exactly one cycle bounding it from the outside. Each other cycle bounds
+// a face from the outside. The outside cycle of each component is a hole in
+// the smallest face from another component that contains it (or the
+// unbounded face, if there is no such face).
+func (d *dcel) createFaces() {
+	d.faces = []*dcelFace{{}}
+
+	// The outside cycle of each component passes through the component's
+	// lowest (then leftmost) vertex. All edges at that vertex point into the
+	// upper half plane, so the outside is immediately clockwise from the
+	// first edge in counter-clockwise order (the cycles are to the left of
+	// their half edges, so that's the cycle of the first edge's twin).
+	lowest := make(map[int]*dcelVertex)
+	for _, v := range d.vertices {
+		if len(v.incident) == 0 {
+			continue
+		}
+		l, ok := lowest[v.component]
+		if !ok || v.coords.Y < l.coords.Y || (v.coords.Y == l.coords.Y && v.coords.X < l.coords.X) {
+			lowest[v.component] = v
+		}
+	}
+	outside := make(map[int]*dcelCycle)
+	for comp, v := range lowest {
+		outside[comp] = v.incident[0].twin.cycle
+	}
+
+	type bounded struct {
+		face *dcelFace
+		ring []XY
+		comp int
+	}
+	var boundeds []bounded
+	for _, c := range d.cycles {
+		comp := c.first.origin.component
+		if outside[comp] == c {
+			continue
+		}
+		f := &dcelFace{outer: c}
+		c.face = f
+		d.faces = append(d.faces, f)
+		boundeds = append(boundeds, bounded{f, c.ring(), comp})
+	}
+
+	for _, c := range d.cycles {
+		comp := c.first.origin.component
+		if outside[comp] != c {
+			continue
+		}
+		container := d.faces[0]
+		pt := c.first.origin.coords
+		for _, b := range boundeds {
+			if b.comp == comp || !pointInRingXY(pt, b.ring) {
+				continue
+			}
+			if container.outer == nil || b.face.outer.signedArea < container.outer.signedArea {
+				container = b.face
+			}
+		}
+		c.face = container
+		container.inner = append(container.inner, c)
+	}
+}
+
+// labelFaces finds whether or not each bounded face is in the interior of
+// each input geometry. This is done by finding a point strictly inside the
+// face, and then checking if that point is inside each input geometry.
+func (d *dcel) labelFaces() {
+	for _, f := range d.faces[1:] {
+		pt, ok := f.interiorPoint()
+		if !ok {
+			continue
+		}
+		for i, input := range d.inputs {
+			f.inArea[i] = input.pointInArea(pt)
+		}
+	}
+}
+
+// interiorPoint finds a point that is strictly inside a bounded face. It does
+// this by intersecting a horizontal scan line with the boundary of the face,
+// and choosing the midpoint of the widest span that is inside the face.
+func (f *dcelFace) interiorPoint() (XY, bool) {
+	var boundary []*dcelHalfEdge
+	for _, c := range f.cycles() {
+		for _, e := range c.halfEdges() {
+			// Edges with the same face on both sides are dangling, so don't
+			// separate the face from anything else.
+			if e.twin.face() != f {
+				boundary = append(boundary, e)
+			}
+		}
+	}
+
+	var ys []float64
+	for _, e := range boundary {
+		ys = append(ys, e.origin.coords.Y)
+	}
+	sort.Float64s(ys)
+	var bestY, bestGap float64
+	for i := 0; i+1 < len(ys); i++ {
+		if gap := ys[i+1] - ys[i]; gap > bestGap {
+			bestGap = gap
+			bestY = (ys[i] + ys[i+1]) / 2
+		}
+	}
+	if bestGap == 0 {
+		return XY{}, false
+	}
+
+	var xs []float64
+	for _, e := range boundary {
+		a, b := e.origin.coords, e.dest().coords
+		if (a.Y < bestY) == (b.Y < bestY) {
+			continue
+		}
+		t := (bestY - a.Y) / (b.Y - a.Y)
+		xs = append(xs, a.X+t*(b.X-a.X))
+	}
+	sort.Float64s(xs)
+	var bestX, bestWidth float64
+	for i := 0; i+1 < len(xs); i += 2 {
+		if width := xs[i+1] - xs[i]; width > bestWidth {
+			bestWidth = width
+			bestX = (xs[i] + xs[i+1]) / 2
+		}
+	}
+	if bestWidth == 0 {
+		return XY{}, false
+	}
+	return XY{bestX, bestY}, true
+}
+
+func (d *dcel) labelEdges() {
+	for _, edge := range d.edges {
+		left := edge.halfEdge.face()
+		right := edge.halfEdge.twin.face()
+		for i := range d.inputs {
+			edge.inSet[i] = edge.linework[i] || left.inArea[i] || right.inArea[i]
+		}
+	}
+}
+
+func (d *dcel) labelVertices() {
+	for _, v := range d.vertices {
+		for i, input := range d.inputs {
+			v.inSet[i] = v.explicit[i]
+			for _, e := range v.incident {
+				v.inSet[i] = v.inSet[i] || e.edge.inSet[i]
+			}
+			if len(v.incident) == 0 {
+				v.inSet[i] = v.inSet[i] || input.pointInArea(v.coords)
+			}
+		}
+	}
+}
+
+// pointInRingXY checks if a point is strictly inside a closed ring. The
+// result is undefined if the point is on the ring's boundary.
+func pointInRingXY(pt XY, ring []XY) bool {
+	var inside bool
+	for i := 0; i+1 < len(ring); i++ {
+		if crosses, _ := rayCrossing(pt, ring[i], ring[i+1]); crosses {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// overlayInput is an input geometry to an overlay operation, broken down into
+// its constituent parts.
+type overlayInput struct {
+	points []XY
+	lines  [][]XY
+	rings  [][]XY
+	polys  []Polygon
+
+	// polyEnvs are the envelopes of each polygon. They're populated when the
+	// dcel is created, and allow pointInArea to skip most polygons.
+	polyEnvs []Envelope
+
+	// evenOdd causes the area of each polygon to be found using the even-odd
+	// rule over all of its rings. This gives a sensible area for polygons
+	// that are invalid.
+	evenOdd bool
+}
+
+func newOverlayInput(g Geometry) overlayInput {
+	var input overlayInput
+	input.add(g)
+	return input
+}
+
+func (o *overlayInput) add(g Geometry) {
+	switch g.tag {
+	case emptySetTag:
+	case pointTag:
+		o.points = append(o.points, g.AsPoint().XY())
+	case multiPointTag:
+		mp := g.AsMultiPoint()
+		for i := 0; i < mp.NumPoints(); i++ {
+			o.points = append(o.points, mp.PointN(i).XY())
+		}
+	case lineTag, lineStringTag, multiLineStringTag:
+		mls := toMultiLineString(g)
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			o.lines = append(o.lines, lineStringXYs(mls.LineStringN(i)))
+		}
+	case polygonTag:
+		o.addPolygon(g.AsPolygon())
+	case multiPolygonTag:
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			o.addPolygon(mp.PolygonN(i))
+		}
+	case geometryCollectionTag:
+		gc := g.AsGeometryCollection()
+		for i := 0; i < gc.NumGeometries(); i++ {
+			o.add(gc.GeometryN(i))
+		}
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+func (o *overlayInput) addPolygon(p Polygon) {
+	o.polys = append(o.polys, p)
+	for _, r := range p.rings() {
+		o.rings = append(o.rings, lineStringXYs(r))
+	}
+}
+
+func (o *overlayInput) findPolygonEnvelopes() {
+	o.polyEnvs = make([]Envelope, len(o.polys))
+	for i, p := range o.polys {
+		// All rings are included (rather than just the exterior ring),
+		// since holes may be outside of the exterior ring when the even-odd
+		// rule is used. Empty polygons get the zero envelope, which is
+		// harmless since they never contain any points.
+		var env Envelope
+		var ok bool
+		for _, r := range p.rings() {
+			if rEnv, rOK := r.Envelope(); rOK {
+				if ok {
+					env = env.ExpandToIncludeEnvelope(rEnv)
+				} else {
+					env, ok = rEnv, true
+				}
+			}
+		}
+		o.polyEnvs[i] = env
+	}
+}
+
+// pointInArea checks if a point is strictly inside any of the input's
+// polygons.
+func (o *overlayInput) pointInArea(pt XY) bool {
+	for i, p := range o.polys {
+		if !o.polyEnvs[i].Contains(pt) {
+			continue
+		}
+		if o.evenOdd {
+			if pointInPolygonEvenOdd(pt, p) {
+				return true
+			}
+		} else if pointPolygonSide(pt, p) == interior {
+			return true
+		}
+	}
+	return false
+}
+
+func pointPolygonSide(pt XY, p Polygon) side {
+	s := pointRingSide(pt, p.ExteriorRing())
+	if s != interior {
+		return s
+	}
+	for i := 0; i < p.NumInteriorRings(); i++ {
+		switch pointRingSide(pt, p.InteriorRingN(i)) {
+		case interior:
+			return exterior
+		case boundary:
+			return boundary
+		}
+	}
+	return interior
+}
+
+func lineStringXYs(ls LineString) []XY {
+	xys := make([]XY, ls.NumPoints())
+	for i := range xys {
+		xys[i] = ls.PointN(i).XY()
+	}
+	return xys
+}
+
+func toMultiLineString(g Geometry) MultiLineString {
+	switch g.tag {
+	case lineTag:
+		ln := g.AsLine()
+		ls := LineString{
+			coords:   []Coordinates{ln.a, ln.b},
+			distinct: []int{0, 1},
+		}
+		return NewMultiLineString([]LineString{ls})
+	case lineStringTag:
+		return NewMultiLineString([]LineString{g.AsLineString()})
+	case multiLineStringTag:
+		return g.AsMultiLineString()
+	default:
+		panic("not a linear geometry: " + g.tag.String())
+	}
+}
+
+// signedAreaXY calculates the signed area of a closed ring using the Shoelace
+// Formula. The area is positive if the ring is counter-clockwise.
+func signedAreaXY(ring []XY) float64 {
+	var sum float64
+	for i := 0; i+1 < len(ring); i++ {
+		sum += ring[i].Cross(ring[i+1])
+	}
+	return sum / 2
+}
+
+// ringIsCCW checks if a closed ring that doesn't touch itself is
+// counter-clockwise. The orientation at the ring's lowest (then leftmost)
+// vertex is the same as the orientation of the whole ring, since that vertex
+// is on the ring's convex hull.
+func ringIsCCW(ring []XY) bool {
+	n := len(ring) - 1
+	low := 0
+	for i := 1; i < n; i++ {
+		if ring[i].Y < ring[low].Y || (ring[i].Y == ring[low].Y && ring[i].X < ring[low].X) {
+			low = i
+		}
+	}
+	return orient2d(ring[(low-1+n)%n], ring[low], ring[low+1]) > 0
+}
+
+// reverseXYs reverses a slice of XYs in place.
+func reverseXYs(xys []XY) {
+	for i, j := 0, len(xys)-1; i < j; i, j = i+1, j-1 {
+		xys[i], xys[j] = xys[j], xys[i]
+	}
+}
@@ -0,0 +1,58 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNodeSegmentsRandom(t *testing.T) {
+	// After noding, segments may only touch each other at their endpoints.
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 100; i++ {
+		var segs []dcelSegment
+		for j := 0; j < 20; j++ {
+			a := XY{rnd.Float64(), rnd.Float64()}
+			b := XY{rnd.Float64(), rnd.Float64()}
+			segs = append(segs, dcelSegment{a, b, j % 2})
+		}
+		noded, err := nodeSegments(segs, newNodeSet(segs, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range noded {
+			for k := j + 1; k < len(noded); k++ {
+				lnJ := Line{Coordinates{XY: noded[j].a}, Coordinates{XY: noded[j].b}}
+				lnK := Line{Coordinates{XY: noded[k].a}, Coordinates{XY: noded[k].b}}
+				inter := intersectLineWithLineNoAlloc(lnJ, lnK)
+				if inter.empty {
+					continue
+				}
+				for _, pt := range [2]XY{inter.ptA, inter.ptB} {
+					if !noded[j].hasEndpoint(pt) || !noded[k].hasEndpoint(pt) {
+						t.Fatalf("segments %v and %v intersect at %v", noded[j], noded[k], pt)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRingIsCCW(t *testing.T) {
+	// The triangles are far from the origin, which causes the Shoelace
+	// Formula to lose too much precision to give the orientation.
+	const o = 1e9
+	for _, tt := range []struct {
+		ring []XY
+		want bool
+	}{
+		{[]XY{{0, 0}, {1, 0}, {0, 1}, {0, 0}}, true},
+		{[]XY{{0, 0}, {0, 1}, {1, 0}, {0, 0}}, false},
+		{[]XY{{o, o}, {o + 1, o}, {o, o + 1}, {o, o}}, true},
+		{[]XY{{o, o}, {o, o + 1}, {o + 1, o}, {o, o}}, false},
+		{[]XY{{o + 1, o}, {o + 1, o + 1}, {o, o + 1}, {o, o}, {o + 1, o}}, true},
+	} {
+		if got := ringIsCCW(tt.ring); got != tt.want {
+			t.Errorf("ringIsCCW(%v): got %v want %v", tt.ring, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package geom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GeoJSONFeatureReader reads the features of a GeoJSON FeatureCollection
+// from an io.Reader, one feature at a time. Unlike unmarshalling into a
+// GeoJSONFeatureCollection, the whole FeatureCollection is never held in
+// memory at once.
+type GeoJSONFeatureReader struct {
+	dec         *json.Decoder
+	started     bool
+	inFeatures  bool
+	sawType     bool
+	sawFeatures bool
+	err         error
+}
+
+// NewGeoJSONFeatureReader creates a GeoJSONFeatureReader that reads a
+// GeoJSON FeatureCollection from r.
+func NewGeoJSONFeatureReader(r io.Reader) *GeoJSONFeatureReader {
+	return &GeoJSONFeatureReader{dec: json.NewDecoder(r)}
+}
+
+// Read reads the next feature. It returns io.EOF once all features have been
+// read (and the rest of the FeatureCollection has been checked for
+// correctness, including that there's nothing but whitespace after it). A
+// null features field is treated the same as an empty one. Once Read returns an error, subsequent calls return the same
+// error.
+//
+// The FeatureCollection's type field is checked when it's encountered. If it
+// appears after the features field, then features may be returned before the
+// error is detected.
+func (r *GeoJSONFeatureReader) Read() (GeoJSONFeature, error) {
+	if r.err != nil {
+		return GeoJSONFeature{}, r.err
+	}
+	f, err := r.read()
+	if err != nil {
+		r.err = err
+	}
+	return f, err
+}
+
+func (r *GeoJSONFeatureReader) read() (GeoJSONFeature, error) {
+	if !r.started {
+		if err := r.expectDelim('{'); err != nil {
+			return GeoJSONFeature{}, err
+		}
+		r.started = true
+	}
+	for {
+		if r.inFeatures {
+			if r.dec.More() {
+				var f GeoJSONFeature
+				if err := r.dec.Decode(&f); err != nil {
+					return GeoJSONFeature{}, fmt.Errorf("features: %v", err)
+				}
+				return f, nil
+			}
+			if err := r.expectDelim(']'); err != nil {
+				return GeoJSONFeature{}, err
+			}
+			r.inFeatures = false
+			continue
+		}
+
+		if !r.dec.More() {
+			if err := r.expectDelim('}'); err != nil {
+				return GeoJSONFeature{}, err
+			}
+			if !r.sawType {
+				return GeoJSONFeature{}, errors.New("feature collection type field missing or empty")
+			}
+			if _, err := r.dec.Token(); err != io.EOF {
+				return GeoJSONFeature{}, errors.New("unexpected data after feature collection")
+			}
+			return GeoJSONFeature{}, io.EOF
+		}
+
+		tok, err := r.dec.Token()
+		if err != nil {
+			return GeoJSONFeature{}, err
+		}
+		switch key := tok.(string); key {
+		case "type":
+			var typ string
+			if err := r.dec.Decode(&typ); err != nil {
+				return GeoJSONFeature{}, err
+			}
+			if typ == "" {
+				return GeoJSONFeature{}, errors.New("feature collection type field missing or empty")
+			}
+			if typ != "FeatureCollection" {
+				return GeoJSONFeature{}, fmt.Errorf("type field not set to FeatureCollection: '%s'", typ)
+			}
+			r.sawType = true
+		case "features":
+			if r.sawFeatures {
+				return GeoJSONFeature{}, errors.New("duplicate features field")
+			}
+			r.sawFeatures = true
+			tok, err := r.dec.Token()
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return GeoJSONFeature{}, fmt.Errorf("features: %v", err)
+			}
+			if tok == nil {
+				continue // null is treated the same as an empty array
+			}
+			if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+				return GeoJSONFeature{}, fmt.Errorf("features: expected '[' but got '%v'", tok)
+			}
+			r.inFeatures = true
+		default:
+			// Skip over any foreign members (e.g. bbox).
+			var skip json.RawMessage
+			if err := r.dec.Decode(&skip); err != nil {
+				return GeoJSONFeature{}, err
+			}
+		}
+	}
+}
+
+func (r *GeoJSONFeatureReader) expectDelim(want json.Delim) error {
+	tok, err := r.dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return fmt.Errorf("expected '%v' but got '%v'", want, tok)
+	}
+	return nil
+}
+
+// GeoJSONFeatureWriter writes GeoJSON features to an io.Writer as a single
+// GeoJSON FeatureCollection, one feature at a time. Unlike marshalling a
+// GeoJSONFeatureCollection, the whole FeatureCollection is never held in
+// memory at once.
+//
+// Close must be called once all features have been written to complete the
+// FeatureCollection.
+type GeoJSONFeatureWriter struct {
+	w       io.Writer
+	started bool
+	closed  bool
+	err     error
+}
+
+// NewGeoJSONFeatureWriter creates a GeoJSONFeatureWriter that writes a
+// GeoJSON FeatureCollection to w.
+func NewGeoJSONFeatureWriter(w io.Writer) *GeoJSONFeatureWriter {
+	return &GeoJSONFeatureWriter{w: w}
+}
+
+// Write writes a single feature. Once Write returns an error, subsequent
+// calls return the same error.
+func (w *GeoJSONFeatureWriter) Write(f GeoJSONFeature) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return errors.New("write to closed GeoJSONFeatureWriter")
+	}
+	buf, err := f.MarshalJSON()
+	if err != nil {
+		w.err = err
+		return err
+	}
+	sep := ","
+	if !w.started {
+		sep = `{"type":"FeatureCollection","features":[`
+		w.started = true
+	}
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.w.Write(buf); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Close completes the FeatureCollection. It doesn't close the underlying
+// io.Writer.
+func (w *GeoJSONFeatureWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	tail := "]}"
+	if !w.started {
+		tail = `{"type":"FeatureCollection","features":[]}`
+	}
+	if _, err := io.WriteString(w.w, tail); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package geom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func readAllGeoJSONFeatures(r *GeoJSONFeatureReader) ([]GeoJSONFeature, error) {
+	var fs []GeoJSONFeature
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			return fs, nil
+		}
+		if err != nil {
+			return fs, err
+		}
+		fs = append(fs, f)
+	}
+}
+
+func TestGeoJSONFeatureReaderValid(t *testing.T) {
+	for i, tt := range []struct {
+		input string
+		ids   []interface{}
+	}{
+		{
+			input: `{"type":"FeatureCollection","features":[]}`,
+		},
+		{
+			input: `{"type":"FeatureCollection"}`,
+		},
+		{
+			input: `{"type":"FeatureCollection","features":null}`,
+		},
+		{
+			input: "  {\"type\":\"FeatureCollection\",\"features\":[]} \n\t ",
+		},
+		{
+			input: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[1,2]}},
+				{"type":"Feature","id":2,"geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]}}
+			]}`,
+			ids: []interface{}{1.0, 2.0},
+		},
+		{
+			// Type after features, and foreign members.
+			input: `{"bbox":[1,2,3,4],"features":[
+				{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]}}
+			],"foo":{"bar":[1,2,3]},"type":"FeatureCollection"}`,
+			ids: []interface{}{"a"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fs, err := readAllGeoJSONFeatures(NewGeoJSONFeatureReader(strings.NewReader(tt.input)))
+			expectNoErr(t, err)
+			expectIntEq(t, len(fs), len(tt.ids))
+			for j, f := range fs {
+				expectBoolEq(t, f.ID == tt.ids[j], true)
+			}
+
+			// The result should be the same as unmarshalling the whole
+			// FeatureCollection.
+			var fc GeoJSONFeatureCollection
+			expectNoErr(t, json.Unmarshal([]byte(tt.input), &fc))
+			expectIntEq(t, len(fs), len(fc))
+			for j := range fs {
+				expectGeomEq(t, fs[j].Geometry, fc[j].Geometry)
+			}
+		})
+	}
+}
+
+func TestGeoJSONFeatureReaderInvalid(t *testing.T) {
+	for i, tt := range []struct {
+		input       string
+		errFragment string
+	}{
+		{
+			input:       `{"type":"Foo","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}]}`,
+			errFragment: "type field not set to FeatureCollection",
+		},
+		{
+			input:       `{"features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}]}`,
+			errFragment: "feature collection type field missing or empty",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[{"type":"Foo","geometry":{"type":"Point","coordinates":[1,2]}}]}`,
+			errFragment: "type field not set to Feature",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[{"type":"Feature"}]}`,
+			errFragment: "geometry field missing or empty",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":{}}`,
+			errFragment: "features",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}`,
+			errFragment: "unexpected",
+		},
+		{
+			input:       `[]`,
+			errFragment: "expected '{'",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[]}{}`,
+			errFragment: "unexpected data after feature collection",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[]} x`,
+			errFragment: "unexpected data after feature collection",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":[]}]`,
+			errFragment: "unexpected data after feature collection",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":"foo"}`,
+			errFragment: "features: expected '['",
+		},
+		{
+			input:       `{"type":"FeatureCollection","features":`,
+			errFragment: "unexpected EOF",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			r := NewGeoJSONFeatureReader(strings.NewReader(tt.input))
+			_, err := readAllGeoJSONFeatures(r)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errFragment) {
+				t.Errorf("expected to contain '%s' but got '%s'", tt.errFragment, err.Error())
+			}
+
+			// The error should be sticky.
+			_, err2 := r.Read()
+			expectBoolEq(t, err2 == err, true)
+		})
+	}
+}
+
+func TestGeoJSONFeatureWriter(t *testing.T) {
+	for i, fc := range []GeoJSONFeatureCollection{
+		nil,
+		{{Geometry: geomFromWKT(t, "POINT(1 2)")}},
+		{
+			{Geometry: geomFromWKT(t, "POINT(1 2)"), ID: "myid"},
+			{Geometry: geomFromWKT(t, "LINESTRING(1 2,3 4)"), Properties: map[string]interface{}{"foo": "bar"}},
+			{Geometry: geomFromWKT(t, "POLYGON((0 0,1 0,0 1,0 0))"), ID: 3},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewGeoJSONFeatureWriter(&buf)
+			for _, f := range fc {
+				expectNoErr(t, w.Write(f))
+			}
+			expectNoErr(t, w.Close())
+
+			// The output should be the same as marshalling the whole
+			// FeatureCollection.
+			want, err := json.Marshal(fc)
+			expectNoErr(t, err)
+			expectStringEq(t, buf.String(), string(want))
+
+			// The output should be readable by GeoJSONFeatureReader.
+			got, err := readAllGeoJSONFeatures(NewGeoJSONFeatureReader(&buf))
+			expectNoErr(t, err)
+			expectIntEq(t, len(got), len(fc))
+			for j := range got {
+				expectGeomEq(t, got[j].Geometry, fc[j].Geometry)
+			}
+		})
+	}
+}
+
+func TestGeoJSONFeatureWriterAfterClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewGeoJSONFeatureWriter(&buf)
+	expectNoErr(t, w.Close())
+	expectNoErr(t, w.Close())
+	if err := w.Write(GeoJSONFeature{Geometry: geomFromWKT(t, "POINT(1 2)")}); err == nil {
+		t.Error("expected error but got nil")
+	}
+	expectStringEq(t, buf.String(), `{"type":"FeatureCollection","features":[]}`)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestGeoJSONFeatureWriterStickyError(t *testing.T) {
+	w := NewGeoJSONFeatureWriter(failingWriter{})
+	f := GeoJSONFeature{Geometry: geomFromWKT(t, "POINT(1 2)")}
+	err := w.Write(f)
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	expectBoolEq(t, w.Write(f) == err, true)
+	expectBoolEq(t, w.Close() == err, true)
+	expectStringEq(t, err.Error(), "write failed")
+}
@@ -127,7 +127,14 @@ func oneDimFloat64sToCoordinates(fs []float64) (Coordinates, error) {
 			return Coordinates{}, errors.New("coordinate is NaN or inf")
 		}
 	}
-	return Coordinates{XY{fs[0], fs[1]}}, nil
+	switch len(fs) {
+	case 2:
+		return Coordinates{XY: XY{fs[0], fs[1]}}, nil
+	case 3:
+		return NewXYZCoordinates(fs[0], fs[1], fs[2]), nil
+	default:
+		return NewXYZMCoordinates(fs[0], fs[1], fs[2], fs[3]), nil
+	}
 }
 
 func twoDimFloat64sToCoordinates(outer [][]float64) ([]Coordinates, error) {
@@ -139,6 +146,11 @@ func twoDimFloat64sToCoordinates(outer [][]float64) ([]Coordinates, error) {
 		}
 		coords = append(coords, cs)
 	}
+	normaliseCoordinatesTypes(func(fn func(*Coordinates)) {
+		for i := range coords {
+			fn(&coords[i])
+		}
+	})
 	return coords, nil
 }
 
@@ -151,6 +163,13 @@ func threeDimFloat64sToCoordinates(outer [][][]float64) ([][]Coordinates, error)
 		}
 		coords = append(coords, cs)
 	}
+	normaliseCoordinatesTypes(func(fn func(*Coordinates)) {
+		for i := range coords {
+			for j := range coords[i] {
+				fn(&coords[i][j])
+			}
+		}
+	})
 	return coords, nil
 }
 
@@ -163,9 +182,32 @@ func fourDimFloat64sToCoordinates(outer [][][][]float64) ([][][]Coordinates, err
 		}
 		coords = append(coords, cs)
 	}
+	normaliseCoordinatesTypes(func(fn func(*Coordinates)) {
+		for i := range coords {
+			for j := range coords[i] {
+				for k := range coords[i][j] {
+					fn(&coords[i][j][k])
+				}
+			}
+		}
+	})
 	return coords, nil
 }
 
+// normaliseCoordinatesTypes ensures that all coordinates visited by walk have
+// the same coordinates type. GeoJSON positions may have differing numbers of
+// values (even within a single geometry), in which case the lowest common
+// coordinates type is used.
+func normaliseCoordinatesTypes(walk func(func(*Coordinates))) {
+	ctype := DimXYZM
+	walk(func(c *Coordinates) {
+		ctype = commonCoordinatesType(ctype, c.Type)
+	})
+	walk(func(c *Coordinates) {
+		*c = c.ForceCoordinatesType(ctype)
+	})
+}
+
 func marshalGeoJSON(geomType string, coordinates interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteString(`{"type":"`)
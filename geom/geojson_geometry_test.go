@@ -119,7 +119,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// POINTZ(1 2 3)
 			geojson: `{"type":"Point","coordinates":[1,2,3]}`,
-			wkt:     "POINT(1 2)",
+			wkt:     "POINTZ(1 2 3)",
 		},
 		{
 			// POINTM(1 2 3)
@@ -129,7 +129,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// POINTZM(1 2 3 4)
 			geojson: `{"type":"Point","coordinates":[1,2,3]}`,
-			wkt:     "POINT(1 2)",
+			wkt:     "POINTZ(1 2 3)",
 		},
 		{
 			// LINESTRING EMPTY
@@ -159,7 +159,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// LINESTRINGZ(1 2 3,4 5 6)
 			geojson: `{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]}`,
-			wkt:     "LINESTRING(1 2,4 5)",
+			wkt:     "LINESTRINGZ(1 2 3,4 5 6)",
 		},
 		{
 			// LINESTRINGM(1 2 3,4 5 6)
@@ -169,7 +169,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// LINESTRINGZM(1 2 3 4,5 6 7 8)
 			geojson: `{"type":"LineString","coordinates":[[1,2,3],[5,6,7]]}`,
-			wkt:     "LINESTRING(1 2,5 6)",
+			wkt:     "LINESTRINGZ(1 2 3,5 6 7)",
 		},
 		{
 			// LINESTRING(1 2,3 4,5 6)
@@ -179,7 +179,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// LINESTRINGZ(1 2 3,3 4 5,5 6 7)
 			geojson: `{"type":"LineString","coordinates":[[1,2,3],[3,4,5],[5,6,7]]}`,
-			wkt:     "LINESTRING(1 2,3 4,5 6)",
+			wkt:     "LINESTRINGZ(1 2 3,3 4 5,5 6 7)",
 		},
 		{
 			// LINESTRINGM(1 2 3,3 4 5,5 6 7)
@@ -189,7 +189,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// LINESTRINGZM(1 2 3 4,3 4 5 6,5 6 7 8)
 			geojson: `{"type":"LineString","coordinates":[[1,2,3],[3,4,5],[5,6,7]]}`,
-			wkt:     "LINESTRING(1 2,3 4,5 6)",
+			wkt:     "LINESTRINGZ(1 2 3,3 4 5,5 6 7)",
 		},
 		{
 			// POLYGON EMPTY
@@ -219,7 +219,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// POLYGONZ((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))
 			geojson: `{"type":"Polygon","coordinates":[[[0,0,9],[4,0,9],[0,4,9],[0,0,9]],[[1,1,9],[2,1,9],[1,2,9],[1,1,9]]]}`,
-			wkt:     "POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			wkt:     "POLYGONZ((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))",
 		},
 		{
 			// POLYGONM((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))
@@ -229,7 +229,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// POLYGONZM((0 0 9 9,4 0 9 9,0 4 9 9,0 0 9 9),(1 1 9 9,2 1 9 9,1 2 9 9,1 1 9 9))
 			geojson: `{"type":"Polygon","coordinates":[[[0,0,9],[4,0,9],[0,4,9],[0,0,9]],[[1,1,9],[2,1,9],[1,2,9],[1,1,9]]]}`,
-			wkt:     "POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			wkt:     "POLYGONZ((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))",
 		},
 		{
 			// MULTIPOINT EMPTY
@@ -259,7 +259,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOINTZ(1 2 3)
 			geojson: `{"type":"MultiPoint","coordinates":[[1,2,3]]}`,
-			wkt:     "MULTIPOINT(1 2)",
+			wkt:     "MULTIPOINTZ(1 2 3)",
 		},
 		{
 			// MULTIPOINTM(1 2 3)
@@ -269,7 +269,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOINTZM(1 2 3 4)
 			geojson: `{"type":"MultiPoint","coordinates":[[1,2,3]]}`,
-			wkt:     "MULTIPOINT(1 2)",
+			wkt:     "MULTIPOINTZ(1 2 3)",
 		},
 		{
 			// MULTIPOINT(1 2,3 4)
@@ -279,7 +279,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOINTZ(1 2 3,3 4 5)
 			geojson: `{"type":"MultiPoint","coordinates":[[1,2,3],[3,4,5]]}`,
-			wkt:     "MULTIPOINT(1 2,3 4)",
+			wkt:     "MULTIPOINTZ(1 2 3,3 4 5)",
 		},
 		{
 			// MULTIPOINTM(1 2 3,3 4 5)
@@ -289,7 +289,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOINTZM(1 2 3 4,3 4 5 6)
 			geojson: `{"type":"MultiPoint","coordinates":[[1,2,3],[3,4,5]]}`,
-			wkt:     "MULTIPOINT(1 2,3 4)",
+			wkt:     "MULTIPOINTZ(1 2 3,3 4 5)",
 		},
 		{
 			// MULTILINESTRING EMPTY
@@ -319,7 +319,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZ((0 1 8,2 3 8,4 5 8))
 			geojson: `{"type":"MultiLineString","coordinates":[[[0,1,8],[2,3,8],[4,5,8]]]}`,
-			wkt:     "MULTILINESTRING((0 1,2 3,4 5))",
+			wkt:     "MULTILINESTRINGZ((0 1 8,2 3 8,4 5 8))",
 		},
 		{
 			// MULTILINESTRINGM((0 1 8,2 3 8,4 5 8))
@@ -329,7 +329,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZM((0 1 8 9,2 3 8 9,4 5 8 9))
 			geojson: `{"type":"MultiLineString","coordinates":[[[0,1,8],[2,3,8],[4,5,8]]]}`,
-			wkt:     "MULTILINESTRING((0 1,2 3,4 5))",
+			wkt:     "MULTILINESTRINGZ((0 1 8,2 3 8,4 5 8))",
 		},
 		{
 			// MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))
@@ -339,7 +339,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZ((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))
 			geojson: `{"type":"MultiLineString","coordinates":[[[0,1,9],[2,3,9]],[[4,5,9],[6,7,9],[8,9,9]]]}`,
-			wkt:     "MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))",
+			wkt:     "MULTILINESTRINGZ((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))",
 		},
 		{
 			// MULTILINESTRINGM((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))
@@ -349,7 +349,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZM((0 1 9 9,2 3 9 9),(4 5 9 9,6 7 9 9,8 9 9 9))
 			geojson: `{"type":"MultiLineString","coordinates":[[[0,1,9],[2,3,9]],[[4,5,9],[6,7,9],[8,9,9]]]}`,
-			wkt:     "MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))",
+			wkt:     "MULTILINESTRINGZ((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))",
 		},
 		{
 			// MULTIPOLYGON EMPTY
@@ -379,7 +379,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOLYGONZ(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))
 			geojson: `{"type":"MultiPolygon","coordinates":[[[[0,0,9],[1,0,9],[0,1,9],[0,0,9]]],[[[1,0,9],[2,0,9],[1,1,9],[1,0,9]]]]}`,
-			wkt:     "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
+			wkt:     "MULTIPOLYGONZ(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))",
 		},
 		{
 			// MULTIPOLYGONM(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))
@@ -389,7 +389,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// MULTIPOLYGONZM(((0 0 8 9,1 0 8 9,0 1 8 9,0 0 8 9)),((1 0 8 9,2 0 8 9,1 1 8 9,1 0 8 9)))
 			geojson: `{"type":"MultiPolygon","coordinates":[[[[0,0,8],[1,0,8],[0,1,8],[0,0,8]]],[[[1,0,8],[2,0,8],[1,1,8],[1,0,8]]]]}`,
-			wkt:     "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
+			wkt:     "MULTIPOLYGONZ(((0 0 8,1 0 8,0 1 8,0 0 8)),((1 0 8,2 0 8,1 1 8,1 0 8)))",
 		},
 		{
 			// GEOMETRYCOLLECTION EMPTY
@@ -419,7 +419,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// GEOMETRYCOLLECTIONZ(POINTZ(1 2 3),POINTZ(3 4 5))
 			geojson: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]},{"type":"Point","coordinates":[3,4,5]}]}`,
-			wkt:     "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
+			wkt:     "GEOMETRYCOLLECTIONZ(POINTZ(1 2 3),POINTZ(3 4 5))",
 		},
 		{
 			// GEOMETRYCOLLECTIONM(POINTM(1 2 3),POINTM(3 4 5))
@@ -429,7 +429,7 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 		{
 			// GEOMETRYCOLLECTIONZM(POINTZM(1 2 3 4),POINTZM(3 4 5 5))
 			geojson: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]},{"type":"Point","coordinates":[3,4,5]}]}`,
-			wkt:     "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
+			wkt:     "GEOMETRYCOLLECTIONZ(POINTZ(1 2 3),POINTZ(3 4 5))",
 		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -441,6 +441,37 @@ func TestGeoJSONUnmarshalValid(t *testing.T) {
 	}
 }
 
+func TestGeoJSONUnmarshalZM(t *testing.T) {
+	for i, tt := range []struct {
+		geojson string
+		wkt     string
+	}{
+		{
+			geojson: `{"type":"Point","coordinates":[1,2,3,4]}`,
+			wkt:     "POINT ZM (1 2 3 4)",
+		},
+		{
+			geojson: `{"type":"LineString","coordinates":[[1,2,3,4],[5,6,7,8]]}`,
+			wkt:     "LINESTRING ZM (1 2 3 4,5 6 7 8)",
+		},
+		{
+			// Mixed dimensions use the lowest common coordinates type.
+			geojson: `{"type":"LineString","coordinates":[[1,2,3],[4,5]]}`,
+			wkt:     "LINESTRING(1 2,4 5)",
+		},
+		{
+			geojson: `{"type":"Polygon","coordinates":[[[0,0,1,2],[1,0,3],[0,1,4,5],[0,0,1,2]]]}`,
+			wkt:     "POLYGON Z ((0 0 1,1 0 3,0 1 4,0 0 1))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := UnmarshalGeoJSON([]byte(tt.geojson))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.wkt))
+		})
+	}
+}
+
 func TestGeoJSONMarshal(t *testing.T) {
 	// Test cases are from:
 	/*
@@ -538,6 +569,18 @@ func TestGeoJSONMarshal(t *testing.T) {
 			wkt:  "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
 			want: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[3,4]}]}`,
 		},
+		{
+			wkt:  "POINT Z (1 2 3)",
+			want: `{"type":"Point","coordinates":[1,2,3]}`,
+		},
+		{
+			wkt:  "POINT M (1 2 3)",
+			want: `{"type":"Point","coordinates":[1,2]}`,
+		},
+		{
+			wkt:  "LINESTRING ZM (1 2 3 4,5 6 7 8)",
+			want: `{"type":"LineString","coordinates":[[1,2,3,4],[5,6,7,8]]}`,
+		},
 	} {
 		t.Run(tt.wkt, func(t *testing.T) {
 			geom := geomFromWKT(t, tt.wkt)
@@ -0,0 +1,677 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gmlNamespace is the namespace of GML 3.2 elements.
+const gmlNamespace = "http://www.opengis.net/gml/3.2"
+
+// MarshalGML returns the GML 3.2 representation of the geometry.
+//
+// Points are written as gml:Point elements (with a gml:pos), LineStrings as
+// gml:LineString elements (with a gml:posList), and Polygons as gml:Polygon
+// elements (with gml:exterior and gml:interior rings). MultiPoints,
+// MultiLineStrings, MultiPolygons, and GeometryCollections are written as
+// gml:MultiPoint, gml:MultiCurve, gml:MultiSurface, and gml:MultiGeometry
+// elements respectively. Empty geometries are written without any positions.
+//
+// The outermost element declares the gml namespace prefix. If the geometry
+// has an SRID, then it's written as the srsName (in the form "EPSG:4326").
+// Z values are written as a third ordinate (and the srsDimension is set to
+// 3), and M values are dropped.
+func MarshalGML(g Geometry) ([]byte, error) {
+	return xml.Marshal(GMLGeometry{g})
+}
+
+// UnmarshalGML parses a GML geometry element, and returns the corresponding
+// Geometry. An element that contains a geometry element (such as a GML
+// feature property) may also be parsed, in which case the geometry of the
+// element is returned.
+//
+// The supported elements are gml:Point, gml:LineString, gml:Curve (made up
+// of gml:LineStringSegments), gml:Polygon, gml:Surface (made up of
+// gml:PolygonPatches), gml:MultiPoint, gml:MultiCurve, gml:MultiSurface, and
+// gml:MultiGeometry (along with the older gml:MultiLineString and
+// gml:MultiPolygon elements). Positions may be given using either gml:posList
+// or gml:pos elements, and may have 2 or 3 dimensions (as indicated by the
+// srsDimension attribute). Elements are matched by name, regardless of their
+// namespace.
+//
+// If the srsName of the outermost geometry refers to an EPSG code, then the
+// code is set as the SRID of the returned Geometry. When the srsName is in
+// URN or HTTP URI form (e.g. "urn:ogc:def:crs:EPSG::4326" or
+// "http://www.opengis.net/def/crs/EPSG/0/4326", as used by WFS 2.0), the
+// ordinates are in the axis order defined by the EPSG database. For common
+// geographic coordinate reference systems (such as WGS 84, ETRS89, and NAD83)
+// that order is latitude then longitude, so the X and Y values are swapped.
+// Ordinates are otherwise read in the order that they appear (the short
+// "EPSG:4326" form conventionally uses longitude then latitude).
+func UnmarshalGML(input []byte, opts ...ConstructorOption) (Geometry, error) {
+	d := xml.NewDecoder(bytes.NewReader(input))
+	start, err := xmlFirstElement(d)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return decodeGML(d, start, opts)
+}
+
+// GMLGeometry is a Geometry that is encoded as GML 3.2. It implements the
+// xml.Marshaler and xml.Unmarshaler interfaces, so may be used with
+// xml.Encoder and xml.Decoder to stream geometries to and from larger GML
+// documents (such as WFS responses).
+type GMLGeometry struct {
+	Geometry Geometry
+}
+
+// MarshalXML implements the xml.Marshaler interface by writing the geometry
+// in the same way as MarshalGML. The name of the start element is ignored
+// (the name of the written element depends on the type of the geometry).
+func (g GMLGeometry) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	attrs := []xml.Attr{{Name: xml.Name{Local: "xmlns:gml"}, Value: gmlNamespace}}
+	if srid := g.Geometry.SRID(); srid != 0 {
+		attrs = append(attrs, xml.Attr{
+			Name:  xml.Name{Local: "srsName"},
+			Value: "EPSG:" + strconv.Itoa(srid),
+		})
+	}
+	if g.Geometry.CoordinatesType().Is3D() {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "srsDimension"}, Value: "3"})
+	}
+	w := xmlWriter{e: e}
+	writeGMLGeometry(&w, g.Geometry, attrs)
+	return w.err
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface by parsing the
+// element in the same way as UnmarshalGML.
+//
+// It constructs the resultant geometry with no ConstructionOptions. If
+// ConstructionOptions are needed, then UnmarshalGML should be used instead.
+func (g *GMLGeometry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	parsed, err := decodeGML(d, start, nil)
+	if err != nil {
+		return err
+	}
+	g.Geometry = parsed
+	return nil
+}
+
+// writeGMLGeometry writes a geometry element. The attributes are added to
+// the geometry element (but not to any elements nested within it).
+func writeGMLGeometry(w *xmlWriter, g Geometry, attrs []xml.Attr) {
+	switch {
+	case g.IsEmptySet():
+		var name string
+		switch g.Dimension() {
+		case 0:
+			name = "gml:Point"
+		case 1:
+			name = "gml:LineString"
+		default:
+			name = "gml:Polygon"
+		}
+		w.start(name, attrs...)
+		w.end(name)
+	case g.IsPoint():
+		w.start("gml:Point", attrs...)
+		writeGMLPositions(w, "gml:pos", []Coordinates{g.AsPoint().Coordinates()})
+		w.end("gml:Point")
+	case g.IsLine():
+		w.start("gml:LineString", attrs...)
+		writeGMLPositions(w, "gml:posList", g.AsLine().Coordinates())
+		w.end("gml:LineString")
+	case g.IsLineString():
+		w.start("gml:LineString", attrs...)
+		writeGMLPositions(w, "gml:posList", g.AsLineString().Coordinates())
+		w.end("gml:LineString")
+	case g.IsPolygon():
+		writeGMLPolygon(w, g.AsPolygon(), attrs)
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		w.start("gml:MultiPoint", attrs...)
+		for i := 0; i < mp.NumPoints(); i++ {
+			w.start("gml:pointMember")
+			writeGMLGeometry(w, mp.PointN(i).AsGeometry(), nil)
+			w.end("gml:pointMember")
+		}
+		w.end("gml:MultiPoint")
+	case g.IsMultiLineString():
+		mls := g.AsMultiLineString()
+		w.start("gml:MultiCurve", attrs...)
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			w.start("gml:curveMember")
+			writeGMLGeometry(w, mls.LineStringN(i).AsGeometry(), nil)
+			w.end("gml:curveMember")
+		}
+		w.end("gml:MultiCurve")
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		w.start("gml:MultiSurface", attrs...)
+		for i := 0; i < mp.NumPolygons(); i++ {
+			w.start("gml:surfaceMember")
+			writeGMLPolygon(w, mp.PolygonN(i), nil)
+			w.end("gml:surfaceMember")
+		}
+		w.end("gml:MultiSurface")
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		w.start("gml:MultiGeometry", attrs...)
+		for i := 0; i < gc.NumGeometries(); i++ {
+			w.start("gml:geometryMember")
+			writeGMLGeometry(w, gc.GeometryN(i), nil)
+			w.end("gml:geometryMember")
+		}
+		w.end("gml:MultiGeometry")
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+func writeGMLPolygon(w *xmlWriter, p Polygon, attrs []xml.Attr) {
+	w.start("gml:Polygon", attrs...)
+	for i, ring := range p.Coordinates() {
+		boundary := "gml:interior"
+		if i == 0 {
+			boundary = "gml:exterior"
+		}
+		w.start(boundary)
+		w.start("gml:LinearRing")
+		writeGMLPositions(w, "gml:posList", ring)
+		w.end("gml:LinearRing")
+		w.end(boundary)
+	}
+	w.end("gml:Polygon")
+}
+
+// writeGMLPositions writes a gml:pos or gml:posList element, which consists
+// of space separated ordinates.
+func writeGMLPositions(w *xmlWriter, name string, coords []Coordinates) {
+	var buf []byte
+	for i, c := range coords {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = appendFloat(buf, c.X)
+		buf = append(buf, ' ')
+		buf = appendFloat(buf, c.Y)
+		if c.Type.Is3D() {
+			buf = append(buf, ' ')
+			buf = appendFloat(buf, c.Z)
+		}
+	}
+	w.textElement(name, buf)
+}
+
+func isGMLGeometry(name string) bool {
+	switch name {
+	case "Point", "LineString", "Curve", "Polygon", "Surface",
+		"MultiPoint", "MultiCurve", "MultiLineString",
+		"MultiSurface", "MultiPolygon", "MultiGeometry":
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeGML decodes the element that has just been started, which is either
+// a geometry element or an element containing a geometry element.
+func decodeGML(d *xml.Decoder, start xml.StartElement, opts []ConstructorOption) (Geometry, error) {
+	p := gmlParser{d: d, opts: opts}
+	if isGMLGeometry(start.Name.Local) {
+		return p.parseRoot(start)
+	}
+	var g Geometry
+	var found bool
+	err := xmlForEachChild(d, func(child xml.StartElement) error {
+		if found || !isGMLGeometry(child.Name.Local) {
+			return d.Skip()
+		}
+		var err error
+		g, err = p.parseRoot(child)
+		found = true
+		return err
+	})
+	if err != nil {
+		return Geometry{}, err
+	}
+	if !found {
+		return Geometry{}, fmt.Errorf("no GML geometry found in %s element", start.Name.Local)
+	}
+	return g, nil
+}
+
+type gmlParser struct {
+	d    *xml.Decoder
+	opts []ConstructorOption
+}
+
+// parseRoot parses the outermost geometry element, setting the SRID of the
+// geometry from its srsName.
+func (p *gmlParser) parseRoot(start xml.StartElement) (Geometry, error) {
+	g, err := p.parseGeometry(start, 0)
+	if err != nil {
+		return Geometry{}, err
+	}
+	if srsName, ok := xmlAttr(start, "srsName"); ok {
+		if srid, ok := gmlSRID(srsName); ok {
+			g = g.WithSRID(srid)
+			if gmlLatLon(srsName, srid) {
+				swap := func(xy XY) XY { return XY{xy.Y, xy.X} }
+				if g, err = g.TransformXY(swap, p.opts...); err != nil {
+					return Geometry{}, err
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// gmlSRID extracts the EPSG code from a srsName. The srsName may be in any
+// of the common forms, e.g. "EPSG:4326", "urn:ogc:def:crs:EPSG::4326",
+// "http://www.opengis.net/def/crs/EPSG/0/4326", or
+// "http://www.opengis.net/gml/srs/epsg.xml#4326".
+func gmlSRID(srsName string) (int, bool) {
+	if !strings.Contains(strings.ToUpper(srsName), "EPSG") {
+		return 0, false
+	}
+	i := strings.LastIndexAny(srsName, ":/#")
+	srid, err := strconv.Atoi(srsName[i+1:])
+	return srid, err == nil
+}
+
+// gmlLatLonSRIDs are the EPSG codes of common geographic coordinate reference
+// systems that have latitude as their first axis.
+var gmlLatLonSRIDs = map[int]bool{
+	4148: true, // Hartebeesthoek94
+	4167: true, // NZGD2000
+	4230: true, // ED50
+	4258: true, // ETRS89
+	4267: true, // NAD27
+	4269: true, // NAD83
+	4277: true, // OSGB36
+	4283: true, // GDA94
+	4314: true, // DHDN
+	4322: true, // WGS 72
+	4326: true, // WGS 84
+	4490: true, // CGCS2000
+	4612: true, // JGD2000
+	4617: true, // NAD83(CSRS)
+	4619: true, // SWEREF99
+	4674: true, // SIRGAS 2000
+	4979: true, // WGS 84 (3D)
+	6668: true, // JGD2011
+	7844: true, // GDA2020
+}
+
+// gmlLatLon checks if the ordinates of a geometry with the given srsName (and
+// the SRID extracted from it) are in latitude/longitude order. Only the URN
+// and HTTP URI forms of srsName follow the axis order of the EPSG database.
+func gmlLatLon(srsName string, srid int) bool {
+	lower := strings.ToLower(srsName)
+	epsgAxisOrder := strings.HasPrefix(lower, "urn:") || strings.Contains(lower, "/def/crs/")
+	return epsgAxisOrder && gmlLatLonSRIDs[srid]
+}
+
+// srsDimension gives the srsDimension of an element, which is inherited from
+// its parent if it's not specified. A dimension of 0 indicates that the
+// dimension isn't known.
+func srsDimension(start xml.StartElement, inherited int) (int, error) {
+	attr, ok := xmlAttr(start, "srsDimension")
+	if !ok {
+		return inherited, nil
+	}
+	dim, err := strconv.Atoi(attr)
+	if err != nil || dim < 2 || dim > 3 {
+		return 0, fmt.Errorf("unsupported GML srsDimension: %q", attr)
+	}
+	return dim, nil
+}
+
+func (p *gmlParser) parseGeometry(start xml.StartElement, dim int) (Geometry, error) {
+	dim, err := srsDimension(start, dim)
+	if err != nil {
+		return Geometry{}, err
+	}
+	switch name := start.Name.Local; name {
+	case "Point":
+		coords, err := p.parsePositions(dim)
+		if err != nil {
+			return Geometry{}, err
+		}
+		switch len(coords) {
+		case 0:
+			return NewEmptyPoint(p.opts...).AsGeometry(), nil
+		case 1:
+			return NewPointC(coords[0], p.opts...).AsGeometry(), nil
+		default:
+			return Geometry{}, fmt.Errorf("GML Point has %d positions", len(coords))
+		}
+	case "LineString":
+		coords, err := p.parsePositions(dim)
+		if err != nil {
+			return Geometry{}, err
+		}
+		return p.newLineString(coords)
+	case "Curve":
+		coords, err := p.parseCurveSegments(dim)
+		if err != nil {
+			return Geometry{}, err
+		}
+		return p.newLineString(coords)
+	case "Polygon":
+		return p.parsePolygon(dim)
+	case "Surface":
+		return p.parseSurfacePatches(dim)
+	case "MultiPoint", "MultiCurve", "MultiLineString", "MultiSurface", "MultiPolygon", "MultiGeometry":
+		geoms, err := p.parseMembers(dim)
+		if err != nil {
+			return Geometry{}, err
+		}
+		return p.newMulti(name, geoms)
+	default:
+		return Geometry{}, fmt.Errorf("unknown GML geometry: %s", name)
+	}
+}
+
+func (p *gmlParser) newLineString(coords []Coordinates) (Geometry, error) {
+	switch len(coords) {
+	case 0:
+		return NewEmptyLineString(p.opts...).AsGeometry(), nil
+	case 2:
+		ln, err := NewLineC(coords[0], coords[1], p.opts...)
+		return ln.AsGeometry(), err
+	default:
+		ls, err := NewLineStringC(coords, p.opts...)
+		return ls.AsGeometry(), err
+	}
+}
+
+// parseMembers parses the geometries within the member elements (e.g.
+// gml:pointMember or gml:surfaceMembers) of a multi geometry.
+func (p *gmlParser) parseMembers(dim int) ([]Geometry, error) {
+	var geoms []Geometry
+	err := xmlForEachChild(p.d, func(member xml.StartElement) error {
+		if !strings.HasSuffix(member.Name.Local, "Member") && !strings.HasSuffix(member.Name.Local, "Members") {
+			return p.d.Skip()
+		}
+		return xmlForEachChild(p.d, func(child xml.StartElement) error {
+			if !isGMLGeometry(child.Name.Local) {
+				return p.d.Skip()
+			}
+			g, err := p.parseGeometry(child, dim)
+			if err != nil {
+				return err
+			}
+			geoms = append(geoms, g)
+			return nil
+		})
+	})
+	return geoms, err
+}
+
+func (p *gmlParser) newMulti(name string, geoms []Geometry) (Geometry, error) {
+	switch name {
+	case "MultiPoint":
+		pts := make([]Point, len(geoms))
+		for i, g := range geoms {
+			if !g.IsPoint() {
+				return Geometry{}, errors.New("GML MultiPoint member is not a non-empty Point")
+			}
+			pts[i] = g.AsPoint()
+		}
+		return NewMultiPoint(pts, p.opts...).AsGeometry(), nil
+	case "MultiCurve", "MultiLineString":
+		var lss []LineString
+		for _, g := range geoms {
+			switch {
+			case g.IsLine():
+				lss = append(lss, g.AsLine().AsLineString())
+			case g.IsLineString():
+				lss = append(lss, g.AsLineString())
+			case g.IsEmptySet() && g.Dimension() == 1:
+			default:
+				return Geometry{}, fmt.Errorf("GML %s member is not a LineString", name)
+			}
+		}
+		return NewMultiLineString(lss, p.opts...).AsGeometry(), nil
+	case "MultiSurface", "MultiPolygon":
+		var polys []Polygon
+		for _, g := range geoms {
+			switch {
+			case g.IsPolygon():
+				polys = append(polys, g.AsPolygon())
+			case g.IsMultiPolygon():
+				// Surfaces made up of multiple patches.
+				mp := g.AsMultiPolygon()
+				for i := 0; i < mp.NumPolygons(); i++ {
+					polys = append(polys, mp.PolygonN(i))
+				}
+			case g.IsEmptySet() && g.Dimension() == 2:
+			default:
+				return Geometry{}, fmt.Errorf("GML %s member is not a Polygon", name)
+			}
+		}
+		mp, err := NewMultiPolygon(polys, p.opts...)
+		return mp.AsGeometry(), err
+	default:
+		return NewGeometryCollection(geoms, p.opts...).AsGeometry(), nil
+	}
+}
+
+func (p *gmlParser) parsePolygon(dim int) (Geometry, error) {
+	rings, err := p.parseRings(dim)
+	if err != nil {
+		return Geometry{}, err
+	}
+	if len(rings) == 0 {
+		return NewEmptyPolygon(p.opts...).AsGeometry(), nil
+	}
+	poly, err := NewPolygonC(rings, p.opts...)
+	return poly.AsGeometry(), err
+}
+
+// parseRings parses the rings of a gml:Polygon or gml:PolygonPatch (the
+// exterior ring is first).
+func (p *gmlParser) parseRings(dim int) ([][]Coordinates, error) {
+	var exterior []Coordinates
+	var interiors [][]Coordinates
+	err := xmlForEachChild(p.d, func(child xml.StartElement) error {
+		boundary := child.Name.Local
+		if boundary != "exterior" && boundary != "interior" {
+			return p.d.Skip()
+		}
+		return xmlForEachChild(p.d, func(child xml.StartElement) error {
+			if child.Name.Local != "LinearRing" {
+				return p.d.Skip()
+			}
+			ringDim, err := srsDimension(child, dim)
+			if err != nil {
+				return err
+			}
+			ring, err := p.parsePositions(ringDim)
+			if err != nil {
+				return err
+			}
+			if boundary == "exterior" {
+				if exterior != nil {
+					return errors.New("GML Polygon has multiple exterior rings")
+				}
+				exterior = ring
+			} else {
+				interiors = append(interiors, ring)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if exterior == nil {
+		if len(interiors) > 0 {
+			return nil, errors.New("GML Polygon has interior rings but no exterior ring")
+		}
+		return nil, nil
+	}
+	return append([][]Coordinates{exterior}, interiors...), nil
+}
+
+// parseCurveSegments parses the gml:LineStringSegments of a gml:Curve,
+// joining them into a single sequence of positions.
+func (p *gmlParser) parseCurveSegments(dim int) ([]Coordinates, error) {
+	var coords []Coordinates
+	err := xmlForEachChild(p.d, func(child xml.StartElement) error {
+		if child.Name.Local != "segments" {
+			return p.d.Skip()
+		}
+		return xmlForEachChild(p.d, func(segment xml.StartElement) error {
+			if segment.Name.Local != "LineStringSegment" {
+				return fmt.Errorf("unsupported GML curve segment: %s", segment.Name.Local)
+			}
+			segDim, err := srsDimension(segment, dim)
+			if err != nil {
+				return err
+			}
+			segCoords, err := p.parsePositions(segDim)
+			if err != nil {
+				return err
+			}
+			// Consecutive segments share their end and start positions.
+			if len(coords) > 0 && len(segCoords) > 0 && coords[len(coords)-1] == segCoords[0] {
+				segCoords = segCoords[1:]
+			}
+			coords = append(coords, segCoords...)
+			return nil
+		})
+	})
+	return coords, err
+}
+
+// parseSurfacePatches parses the gml:PolygonPatches of a gml:Surface. A
+// Polygon is returned if there is a single patch, and a MultiPolygon is
+// returned otherwise.
+func (p *gmlParser) parseSurfacePatches(dim int) (Geometry, error) {
+	var polys []Polygon
+	err := xmlForEachChild(p.d, func(child xml.StartElement) error {
+		if child.Name.Local != "patches" {
+			return p.d.Skip()
+		}
+		return xmlForEachChild(p.d, func(patch xml.StartElement) error {
+			if patch.Name.Local != "PolygonPatch" {
+				return fmt.Errorf("unsupported GML surface patch: %s", patch.Name.Local)
+			}
+			patchDim, err := srsDimension(patch, dim)
+			if err != nil {
+				return err
+			}
+			g, err := p.parsePolygon(patchDim)
+			if err != nil {
+				return err
+			}
+			if g.IsPolygon() {
+				polys = append(polys, g.AsPolygon())
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return Geometry{}, err
+	}
+	switch len(polys) {
+	case 0:
+		return NewEmptyPolygon(p.opts...).AsGeometry(), nil
+	case 1:
+		return polys[0].AsGeometry(), nil
+	default:
+		mp, err := NewMultiPolygon(polys, p.opts...)
+		return mp.AsGeometry(), err
+	}
+}
+
+// parsePositions parses the gml:posList or gml:pos elements within the
+// element that has just been started.
+func (p *gmlParser) parsePositions(dim int) ([]Coordinates, error) {
+	var coords []Coordinates
+	err := xmlForEachChild(p.d, func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "posList":
+			listDim, err := srsDimension(child, dim)
+			if err != nil {
+				return err
+			}
+			if listDim == 0 {
+				listDim = 2
+			}
+			text, err := xmlText(p.d, child)
+			if err != nil {
+				return err
+			}
+			list, err := parseGMLOrdinates(text, listDim)
+			coords = append(coords, list...)
+			return err
+		case "pos":
+			posDim, err := srsDimension(child, dim)
+			if err != nil {
+				return err
+			}
+			text, err := xmlText(p.d, child)
+			if err != nil {
+				return err
+			}
+			if posDim == 0 {
+				// The dimension of a single position is implied by its
+				// number of ordinates.
+				posDim = len(strings.Fields(text))
+			}
+			pos, err := parseGMLOrdinates(text, posDim)
+			if err != nil {
+				return err
+			}
+			if len(pos) > 1 {
+				return fmt.Errorf("GML pos has multiple positions: %q", text)
+			}
+			coords = append(coords, pos...)
+			return nil
+		default:
+			return p.d.Skip()
+		}
+	})
+	return coords, err
+}
+
+// parseGMLOrdinates parses space separated ordinates, where each position has
+// the given number of ordinates (2 or 3).
+func parseGMLOrdinates(text string, dim int) ([]Coordinates, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if dim < 2 || dim > 3 {
+		return nil, fmt.Errorf("GML position has %d ordinates", dim)
+	}
+	if len(fields)%dim != 0 {
+		return nil, fmt.Errorf("GML positions have %d ordinates, which isn't a multiple of %d", len(fields), dim)
+	}
+	ctype := DimXY
+	if dim == 3 {
+		ctype = DimXYZ
+	}
+	coords := make([]Coordinates, len(fields)/dim)
+	for i := range coords {
+		var vals [3]float64
+		for j := 0; j < dim; j++ {
+			f, err := strconv.ParseFloat(fields[i*dim+j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GML ordinate: %q", fields[i*dim+j])
+			}
+			vals[j] = f
+		}
+		coords[i] = Coordinates{XY: XY{vals[0], vals[1]}, Z: vals[2], Type: ctype}
+	}
+	return coords, nil
+}
@@ -0,0 +1,264 @@
+package geom_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestGMLMarshal(t *testing.T) {
+	const ns = `xmlns:gml="http://www.opengis.net/gml/3.2"`
+	for i, tt := range []struct {
+		wkt  string
+		srid int
+		want string
+	}{
+		{"POINT EMPTY", 0, `<gml:Point ` + ns + `></gml:Point>`},
+		{"POINT(1 2)", 0, `<gml:Point ` + ns + `><gml:pos>1 2</gml:pos></gml:Point>`},
+		{"POINT(1 2)", 4326, `<gml:Point ` + ns + ` srsName="EPSG:4326"><gml:pos>1 2</gml:pos></gml:Point>`},
+		{"POINT Z (1 2 3)", 0, `<gml:Point ` + ns + ` srsDimension="3"><gml:pos>1 2 3</gml:pos></gml:Point>`},
+		{"POINT M (1 2 3)", 0, `<gml:Point ` + ns + `><gml:pos>1 2</gml:pos></gml:Point>`},
+		{"LINESTRING(1 2,3.5 -4)", 0, `<gml:LineString ` + ns + `><gml:posList>1 2 3.5 -4</gml:posList></gml:LineString>`},
+		{
+			"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))", 0,
+			`<gml:Polygon ` + ns + `>` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 4 0 0 4 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>1 1 2 1 1 2 1 1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			"MULTIPOINT((1 2),(3 4))", 0,
+			`<gml:MultiPoint ` + ns + `>` +
+				`<gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+				`<gml:pointMember><gml:Point><gml:pos>3 4</gml:pos></gml:Point></gml:pointMember>` +
+				`</gml:MultiPoint>`,
+		},
+		{
+			"MULTILINESTRING Z ((1 2 3,4 5 6))", 0,
+			`<gml:MultiCurve ` + ns + ` srsDimension="3">` +
+				`<gml:curveMember><gml:LineString><gml:posList>1 2 3 4 5 6</gml:posList></gml:LineString></gml:curveMember>` +
+				`</gml:MultiCurve>`,
+		},
+		{
+			"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))", 27700,
+			`<gml:MultiSurface ` + ns + ` srsName="EPSG:27700">` +
+				`<gml:surfaceMember><gml:Polygon>` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 0 1 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`</gml:Polygon></gml:surfaceMember>` +
+				`</gml:MultiSurface>`,
+		},
+		{"MULTIPOLYGON EMPTY", 0, `<gml:MultiSurface ` + ns + `></gml:MultiSurface>`},
+		{
+			"GEOMETRYCOLLECTION(POINT(1 2))", 0,
+			`<gml:MultiGeometry ` + ns + `>` +
+				`<gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>` +
+				`</gml:MultiGeometry>`,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := MarshalGML(geomFromWKT(t, tt.wkt).WithSRID(tt.srid))
+			expectNoErr(t, err)
+			expectStringEq(t, string(got), tt.want)
+		})
+	}
+}
+
+func TestGMLUnmarshal(t *testing.T) {
+	for i, tt := range []struct {
+		gml  string
+		want string
+		srid int
+	}{
+		{`<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 2</gml:pos></gml:Point>`, "POINT(1 2)", 0},
+		{`<Point><pos>1 2 3</pos></Point>`, "POINT Z (1 2 3)", 0},
+		{`<Point srsDimension="3"><pos>1 2 3</pos></Point>`, "POINT Z (1 2 3)", 0},
+		{`<Point></Point>`, "POINT EMPTY", 0},
+		{`<Point srsName="EPSG:4326"><pos>1 2</pos></Point>`, "POINT(1 2)", 4326},
+		{`<Point srsName="urn:ogc:def:crs:EPSG::3857"><pos>1 2</pos></Point>`, "POINT(1 2)", 3857},
+		{`<Point srsName="http://www.opengis.net/def/crs/EPSG/0/28355"><pos>1 2</pos></Point>`, "POINT(1 2)", 28355},
+		{`<Point srsName="http://www.opengis.net/gml/srs/epsg.xml#4283"><pos>1 2</pos></Point>`, "POINT(1 2)", 4283},
+		{`<Point srsName="urn:ogc:def:crs:OGC:1.3:CRS84"><pos>1 2</pos></Point>`, "POINT(1 2)", 0},
+
+		// The URN and HTTP URI forms of srsName use the EPSG axis order,
+		// which is latitude then longitude for geographic CRSs.
+		{`<Point srsName="urn:ogc:def:crs:EPSG::4326"><pos>-33.86 151.21</pos></Point>`, "POINT(151.21 -33.86)", 4326},
+		{`<Point srsName="urn:x-ogc:def:crs:EPSG:4258"><pos>52.5 13.4</pos></Point>`, "POINT(13.4 52.5)", 4258},
+		{`<Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><pos>-33.86 151.21</pos></Point>`, "POINT(151.21 -33.86)", 4326},
+		{`<Point srsName="urn:ogc:def:crs:EPSG::4979" srsDimension="3"><pos>-33.86 151.21 10</pos></Point>`, "POINT Z (151.21 -33.86 10)", 4979},
+		{`<Point srsName="http://www.opengis.net/gml/srs/epsg.xml#4326"><pos>151.21 -33.86</pos></Point>`, "POINT(151.21 -33.86)", 4326},
+		{`<LineString><posList>1 2 3 4</posList></LineString>`, "LINESTRING(1 2,3 4)", 0},
+		{`<LineString><posList srsDimension="3">1 2 3 4 5 6</posList></LineString>`, "LINESTRING Z (1 2 3,4 5 6)", 0},
+		{`<LineString srsDimension="3"><posList>1 2 3 4 5 6 7 8 9</posList></LineString>`, "LINESTRING Z (1 2 3,4 5 6,7 8 9)", 0},
+		{`<LineString><pos>1 2</pos><pos>3 4</pos><pos>5 6</pos></LineString>`, "LINESTRING(1 2,3 4,5 6)", 0},
+		{
+			`<Curve><segments>` +
+				`<LineStringSegment><posList>0 0 1 1</posList></LineStringSegment>` +
+				`<LineStringSegment><posList>1 1 2 0</posList></LineStringSegment>` +
+				`</segments></Curve>`,
+			"LINESTRING(0 0,1 1,2 0)", 0,
+		},
+		{
+			`<gml:Polygon gml:id="p1" xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:exterior><gml:LinearRing><gml:posList>0 0 4 0 0 4 0 0</gml:posList></gml:LinearRing></gml:exterior>
+				<gml:interior><gml:LinearRing><gml:posList>1 1 2 1 1 2 1 1</gml:posList></gml:LinearRing></gml:interior>
+			</gml:Polygon>`,
+			"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))", 0,
+		},
+		{`<Polygon></Polygon>`, "POLYGON EMPTY", 0},
+		{
+			`<Surface><patches><PolygonPatch><exterior><LinearRing><posList>0 0 1 0 0 1 0 0</posList></LinearRing></exterior></PolygonPatch></patches></Surface>`,
+			"POLYGON((0 0,1 0,0 1,0 0))", 0,
+		},
+		{
+			`<MultiPoint><pointMember><Point><pos>1 2</pos></Point></pointMember><pointMembers><Point><pos>3 4</pos></Point><Point><pos>5 6</pos></Point></pointMembers></MultiPoint>`,
+			"MULTIPOINT((1 2),(3 4),(5 6))", 0,
+		},
+		{
+			`<MultiCurve srsDimension="3"><curveMember><LineString><posList>1 2 3 4 5 6</posList></LineString></curveMember></MultiCurve>`,
+			"MULTILINESTRING Z ((1 2 3,4 5 6))", 0,
+		},
+		{
+			`<MultiLineString><lineStringMember><LineString><posList>1 2 3 4</posList></LineString></lineStringMember></MultiLineString>`,
+			"MULTILINESTRING((1 2,3 4))", 0,
+		},
+		{
+			`<gml:MultiSurface srsName="urn:ogc:def:crs:EPSG::4326" xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:surfaceMember>
+					<gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 0 1 0 0</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon>
+				</gml:surfaceMember>
+				<gml:surfaceMember>
+					<gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>2 2 3 2 2 3 2 2</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon>
+				</gml:surfaceMember>
+			</gml:MultiSurface>`,
+			"MULTIPOLYGON(((0 0,0 1,1 0,0 0)),((2 2,2 3,3 2,2 2)))", 4326,
+		},
+		{`<MultiSurface></MultiSurface>`, "MULTIPOLYGON EMPTY", 0},
+		{
+			`<MultiGeometry><geometryMember><Point><pos>1 2</pos></Point></geometryMember><geometryMember><LineString><posList>1 2 3 4</posList></LineString></geometryMember></MultiGeometry>`,
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4))", 0,
+		},
+		{
+			`<app:location xmlns:app="http://example.com/app"><Point srsName="EPSG:4326"><pos>1 2</pos></Point></app:location>`,
+			"POINT(1 2)", 4326,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := UnmarshalGML([]byte(tt.gml))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+			expectIntEq(t, got.SRID(), tt.srid)
+		})
+	}
+}
+
+func TestGMLRoundTrip(t *testing.T) {
+	for i, wkt := range []string{
+		"POINT EMPTY",
+		"POINT(1 2)",
+		"POINT Z (1 2 3)",
+		"LINESTRING EMPTY",
+		"LINESTRING(1 2,3 4)",
+		"LINESTRING Z (1 2 3,4 5 6,7 8 9)",
+		"POLYGON EMPTY",
+		"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+		"MULTIPOINT EMPTY",
+		"MULTIPOINT((1 2),(3 4))",
+		"MULTILINESTRING EMPTY",
+		"MULTILINESTRING((1 2,3 4),(5 6,7 8,9 10))",
+		"MULTIPOLYGON EMPTY",
+		"MULTIPOLYGON Z (((0 0 1,1 0 1,0 1 1,0 0 1)),((2 2 1,3 2 1,2 3 1,2 2 1)))",
+		"GEOMETRYCOLLECTION EMPTY",
+		"GEOMETRYCOLLECTION(POINT(1 2),MULTIPOINT((3 4)),GEOMETRYCOLLECTION(LINESTRING(3 4,5 6)))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			want := geomFromWKT(t, wkt).WithSRID(4326)
+			buf, err := MarshalGML(want)
+			expectNoErr(t, err)
+			got, err := UnmarshalGML(buf)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, want)
+			expectIntEq(t, got.SRID(), 4326)
+		})
+	}
+}
+
+func TestGMLUnmarshalInvalid(t *testing.T) {
+	for i, gml := range []string{
+		"",
+		"<Feature><name>no geometry</name></Feature>",
+		"<Point><pos>1</pos></Point>",
+		"<Point><pos>1 2 3 4</pos></Point>",
+		"<Point><pos>1 x</pos></Point>",
+		`<Point srsDimension="4"><pos>1 2 3 4</pos></Point>`,
+		`<Point srsDimension="3"><pos>1 2 3 4 5 6</pos></Point>`,
+		"<LineString><posList>1 2 3</posList></LineString>",
+		"<LineString><posList>1 2</posList></LineString>",
+		"<Polygon><exterior><LinearRing><posList>0 0 1 0 0 1</posList></LinearRing></exterior></Polygon>",
+		"<Polygon><interior><LinearRing><posList>0 0 1 0 0 1 0 0</posList></LinearRing></interior></Polygon>",
+		"<MultiPoint><pointMember><LineString><posList>1 2 3 4</posList></LineString></pointMember></MultiPoint>",
+		"<MultiSurface><surfaceMember><Point><pos>1 2</pos></Point></surfaceMember></MultiSurface>",
+		"<Curve><segments><Arc><posList>0 0 1 1 2 0</posList></Arc></segments></Curve>",
+		"<Point><pos>1 2</pos>",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := UnmarshalGML([]byte(gml))
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestGMLGeometryStructField(t *testing.T) {
+	type feature struct {
+		Name     string      `xml:"name"`
+		Geometry GMLGeometry `xml:"geometry"`
+	}
+	type collection struct {
+		Members []feature `xml:"member>road"`
+	}
+
+	wfs := `<wfs:FeatureCollection
+		xmlns:wfs="http://www.opengis.net/wfs/2.0"
+		xmlns:gml="http://www.opengis.net/gml/3.2"
+		xmlns:app="http://example.com/app">
+	<wfs:member>
+		<app:road gml:id="road.1">
+			<app:name>Main St</app:name>
+			<app:geometry>
+				<gml:LineString srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="2">
+					<gml:posList>-33.8688 151.2093 -33.8700 151.2100 -33.8712 151.2110</gml:posList>
+				</gml:LineString>
+			</app:geometry>
+		</app:road>
+	</wfs:member>
+	<wfs:member>
+		<app:road gml:id="road.2">
+			<app:name>High St</app:name>
+			<app:geometry>
+				<gml:Curve><gml:segments>
+					<gml:LineStringSegment><gml:posList>0 0 1 1</gml:posList></gml:LineStringSegment>
+				</gml:segments></gml:Curve>
+			</app:geometry>
+		</app:road>
+	</wfs:member>
+</wfs:FeatureCollection>`
+
+	var c collection
+	expectNoErr(t, xml.NewDecoder(bytes.NewReader([]byte(wfs))).Decode(&c))
+	expectIntEq(t, len(c.Members), 2)
+	expectStringEq(t, c.Members[0].Name, "Main St")
+	expectGeomEq(t, c.Members[0].Geometry.Geometry, geomFromWKT(t, "LINESTRING(151.2093 -33.8688,151.21 -33.87,151.211 -33.8712)"))
+	expectIntEq(t, c.Members[0].Geometry.Geometry.SRID(), 4326)
+	expectStringEq(t, c.Members[1].Name, "High St")
+	expectGeomEq(t, c.Members[1].Geometry.Geometry, geomFromWKT(t, "LINESTRING(0 0,1 1)"))
+
+	buf, err := xml.Marshal(GMLGeometry{geomFromWKT(t, "POINT(1 2)")})
+	expectNoErr(t, err)
+	got, err := UnmarshalGML(buf)
+	expectNoErr(t, err)
+	expectGeomEq(t, got, geomFromWKT(t, "POINT(1 2)"))
+}
@@ -0,0 +1,168 @@
+package geom
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// GeoPackage binary header constants.
+const (
+	gpkgMagic0  = 'G'
+	gpkgMagic1  = 'P'
+	gpkgVersion = 0
+
+	gpkgFlagLittleEndian = 0x01
+	gpkgFlagEnvelopeMask = 0x0e
+	gpkgFlagEmpty        = 0x10
+	gpkgFlagExtended     = 0x20
+
+	// gpkgEnvelopeXY is the envelope contents indicator for an envelope
+	// that only contains X and Y ranges.
+	gpkgEnvelopeXY = 1
+)
+
+// UnmarshalGPKG reads the GeoPackage binary format (the format used by
+// geometry columns in GeoPackage files), and returns the corresponding
+// Geometry. The GeoPackage binary format consists of a header (containing the
+// SRID and an optional envelope) followed by WKB. The SRID in the header is
+// set on the returned Geometry.
+//
+// Extended GeoPackage geometries (which are used by GeoPackage extensions
+// for geometry types not supported by WKB) are not supported.
+func UnmarshalGPKG(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return Geometry{}, fmt.Errorf("reading GeoPackage header: %v", err)
+	}
+	if hdr[0] != gpkgMagic0 || hdr[1] != gpkgMagic1 {
+		return Geometry{}, errors.New("invalid GeoPackage magic number")
+	}
+	if hdr[2] != gpkgVersion {
+		return Geometry{}, fmt.Errorf("unsupported GeoPackage binary version: %d", hdr[2])
+	}
+	flags := hdr[3]
+	if flags&gpkgFlagExtended != 0 {
+		return Geometry{}, errors.New("extended GeoPackage geometries are not supported")
+	}
+
+	var bo binary.ByteOrder = binary.BigEndian
+	if flags&gpkgFlagLittleEndian != 0 {
+		bo = binary.LittleEndian
+	}
+	srid := int(int32(bo.Uint32(hdr[4:])))
+
+	// The envelope is redundant (it can be calculated from the WKB), so it's
+	// skipped.
+	var envLen int64
+	switch ind := (flags & gpkgFlagEnvelopeMask) >> 1; ind {
+	case 0:
+	case 1:
+		envLen = 4 * 8
+	case 2, 3:
+		envLen = 6 * 8
+	case 4:
+		envLen = 8 * 8
+	default:
+		return Geometry{}, fmt.Errorf("invalid GeoPackage envelope contents indicator: %d", ind)
+	}
+	if _, err := io.CopyN(ioutil.Discard, r, envLen); err != nil {
+		return Geometry{}, fmt.Errorf("reading GeoPackage envelope: %v", err)
+	}
+
+	g, err := UnmarshalWKB(r, opts...)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return g.WithSRID(srid), nil
+}
+
+// AsGPKG writes the GeoPackage binary representation of the geometry to the
+// writer. The header contains the geometry's SRID and its XY envelope (unless
+// the geometry is empty). The header is followed by the geometry's WKB.
+func (g Geometry) AsGPKG(w io.Writer) error {
+	env, hasEnv := g.Envelope()
+	flags := byte(gpkgFlagLittleEndian)
+	if hasEnv {
+		flags |= gpkgEnvelopeXY << 1
+	}
+	if g.IsEmpty() {
+		flags |= gpkgFlagEmpty
+	}
+
+	hdr := make([]byte, 8, 8+4*8)
+	hdr[0] = gpkgMagic0
+	hdr[1] = gpkgMagic1
+	hdr[2] = gpkgVersion
+	hdr[3] = flags
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(int32(g.SRID())))
+	if hasEnv {
+		min, max := env.Min(), env.Max()
+		for _, f := range []float64{min.X, max.X, min.Y, max.Y} {
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+			hdr = append(hdr, buf[:]...)
+		}
+	}
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	return g.AsBinary(w)
+}
+
+// GPKGGeometry represents a Geometry stored in a GeoPackage geometry column
+// (which may be NULL). It implements the database/sql.Scanner and
+// database/sql.Valuer interfaces, so may be used as a scan destination or
+// query argument in SQL queries against GeoPackage files (using any SQLite
+// database/sql driver).
+type GPKGGeometry struct {
+	Geometry Geometry
+	Valid    bool // Valid is true iff Geometry is not NULL
+}
+
+// Scan implements the database/sql.Scanner interface by parsing the src value
+// as the GeoPackage binary format.
+//
+// It constructs the resultant geometry with no ConstructionOptions. If
+// ConstructionOptions are needed, then the value should be scanned into a byte
+// slice and then UnmarshalGPKG called manually (passing in the
+// ConstructionOptions as desired).
+func (g *GPKGGeometry) Scan(src interface{}) error {
+	var buf []byte
+	switch src := src.(type) {
+	case nil:
+		g.Geometry = Geometry{}
+		g.Valid = false
+		return nil
+	case []byte:
+		buf = src
+	case string:
+		buf = []byte(src)
+	default:
+		return fmt.Errorf("unsupported src type in Scan: %T", src)
+	}
+	unmarshalled, err := UnmarshalGPKG(bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	g.Geometry = unmarshalled
+	g.Valid = true
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface by returning the
+// GeoPackage binary representation of the Geometry (or NULL if Valid is
+// false).
+func (g GPKGGeometry) Value() (driver.Value, error) {
+	if !g.Valid {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	err := g.Geometry.AsGPKG(&buf)
+	return buf.Bytes(), err
+}
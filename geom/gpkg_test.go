@@ -0,0 +1,188 @@
+package geom_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestGPKGRoundTrip(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		srid int
+	}{
+		{"POINT(1 2)", 0},
+		{"POINT(1 2)", 4326},
+		{"POINT Z(1 2 3)", 4326},
+		{"POINT M(1 2 3)", 3857},
+		{"POINT ZM(1 2 3 4)", -1},
+		{"POINT EMPTY", 4326},
+		{"LINESTRING(1 2,3 4,5 6)", 4326},
+		{"POLYGON((0 0,1 0,0 1,0 0))", 4326},
+		{"MULTIPOINT((1 2),(3 4))", 4326},
+		{"MULTILINESTRING((0 0,1 1),(2 2,3 3))", 4326},
+		{"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))", 4326},
+		{"MULTIPOLYGON EMPTY", 4326},
+		{"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(3 4,5 6))", 4326},
+		{"GEOMETRYCOLLECTION EMPTY", 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt).WithSRID(tt.srid)
+			var buf bytes.Buffer
+			expectNoErr(t, g.AsGPKG(&buf))
+			got, err := UnmarshalGPKG(&buf)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, g)
+			expectIntEq(t, got.SRID(), tt.srid)
+		})
+	}
+}
+
+func TestAsGPKGHeader(t *testing.T) {
+	for i, tt := range []struct {
+		wkt     string
+		srid    int
+		wantHex string
+	}{
+		{
+			wkt:  "POINT(1 2)",
+			srid: 4326,
+			wantHex: "47500003e6100000" +
+				"000000000000f03f000000000000f03f" + // min and max X
+				"00000000000000400000000000000040" + // min and max Y
+				"0101000000000000000000f03f0000000000000040",
+		},
+		{
+			wkt:  "POINT EMPTY",
+			srid: 4326,
+			wantHex: "47500011e6100000" +
+				"0101000000010000000000f87f010000000000f87f",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			expectNoErr(t, geomFromWKT(t, tt.wkt).WithSRID(tt.srid).AsGPKG(&buf))
+			expectStringEq(t, hex.EncodeToString(buf.Bytes()), tt.wantHex)
+		})
+	}
+}
+
+func TestUnmarshalGPKG(t *testing.T) {
+	for i, tt := range []struct {
+		hex  string
+		wkt  string
+		srid int
+	}{
+		{
+			// Big endian header without an envelope, and big endian WKB.
+			hex:  "47500000000010e6" + "00000000013ff00000000000004000000000000000",
+			wkt:  "POINT(1 2)",
+			srid: 4326,
+		},
+		{
+			// Envelope with X, Y, and Z ranges.
+			hex: "47500005e6100000" +
+				"000000000000f03f000000000000f03f" +
+				"00000000000000400000000000000040" +
+				"00000000000008400000000000000840" +
+				"01e9030000000000000000f03f00000000000000400000000000000840",
+			wkt:  "POINT Z(1 2 3)",
+			srid: 4326,
+		},
+		{
+			// Envelope with X, Y, Z, and M ranges.
+			hex: "47500009ffffffff" +
+				"000000000000f03f000000000000f03f" +
+				"00000000000000400000000000000040" +
+				"00000000000008400000000000000840" +
+				"00000000000010400000000000001040" +
+				"01b90b0000000000000000f03f000000000000004000000000000008400000000000001040",
+			wkt:  "POINT ZM(1 2 3 4)",
+			srid: -1,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf, err := hex.DecodeString(tt.hex)
+			expectNoErr(t, err)
+			got, err := UnmarshalGPKG(bytes.NewReader(buf))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.wkt))
+			expectIntEq(t, got.SRID(), tt.srid)
+		})
+	}
+}
+
+func TestUnmarshalGPKGInvalid(t *testing.T) {
+	for i, hexStr := range []string{
+		"",
+		"4750",
+		"47510001e6100000" + "0101000000000000000000f03f0000000000000040", // bad magic
+		"47500101e6100000" + "0101000000000000000000f03f0000000000000040", // bad version
+		"47500021e6100000" + "0101000000000000000000f03f0000000000000040", // extended
+		"4750000be6100000" + "0101000000000000000000f03f0000000000000040", // bad envelope indicator
+		"47500003e6100000" + "000000000000f03f",                           // truncated envelope
+		"47500001e6100000" + "0101000000000000000000f03f",                 // truncated WKB
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf, err := hex.DecodeString(hexStr)
+			expectNoErr(t, err)
+			if _, err := UnmarshalGPKG(bytes.NewReader(buf)); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestGPKGGeometryScanner(t *testing.T) {
+	t.Run("null", func(t *testing.T) {
+		gg := GPKGGeometry{Valid: true}
+		expectNoErr(t, gg.Scan(nil))
+		expectBoolEq(t, gg.Valid, false)
+	})
+	t.Run("not null", func(t *testing.T) {
+		var buf bytes.Buffer
+		g := geomFromWKT(t, "POINT(1 2)").WithSRID(4326)
+		expectNoErr(t, g.AsGPKG(&buf))
+		for _, src := range []interface{}{buf.Bytes(), buf.String()} {
+			var gg GPKGGeometry
+			expectNoErr(t, gg.Scan(src))
+			expectBoolEq(t, gg.Valid, true)
+			expectGeomEq(t, gg.Geometry, g)
+			expectIntEq(t, gg.Geometry.SRID(), 4326)
+		}
+	})
+	t.Run("WKB", func(t *testing.T) {
+		var buf bytes.Buffer
+		expectNoErr(t, geomFromWKT(t, "POINT(1 2)").AsBinary(&buf))
+		var gg GPKGGeometry
+		if err := gg.Scan(buf.Bytes()); err == nil {
+			t.Error("expected error but got nil")
+		}
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		var gg GPKGGeometry
+		if err := gg.Scan(123); err == nil {
+			t.Error("expected error but got nil")
+		}
+	})
+}
+
+func TestGPKGGeometryValuer(t *testing.T) {
+	t.Run("null", func(t *testing.T) {
+		val, err := GPKGGeometry{}.Value()
+		expectNoErr(t, err)
+		expectBoolEq(t, val == nil, true)
+	})
+	t.Run("not null", func(t *testing.T) {
+		g := geomFromWKT(t, "LINESTRING(1 2,3 4)").WithSRID(4326)
+		val, err := GPKGGeometry{Geometry: g, Valid: true}.Value()
+		expectNoErr(t, err)
+		got, err := UnmarshalGPKG(bytes.NewReader(val.([]byte)))
+		expectNoErr(t, err)
+		expectGeomEq(t, got, g)
+		expectIntEq(t, got.SRID(), 4326)
+	})
+}
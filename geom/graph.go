@@ -1,5 +1,7 @@
 package geom
 
+import "sort"
+
 // graph is an adjacency list representing an undirected simple graph.
 type graph map[int]map[int]struct{}
 
@@ -27,33 +29,54 @@ func (g graph) addEdge(u, v int) {
 }
 
 func (g graph) hasCycle() bool {
+	_, _, ok := g.findCycle()
+	return ok
+}
+
+// findCycle finds an edge (u, v) that is part of a cycle. It returns false if
+// the graph doesn't contain any cycles. Vertices are visited in ascending
+// order, so the result is deterministic.
+func (g graph) findCycle() (int, int, bool) {
 	unvisited := make(map[int]struct{})
 	for v := range g {
 		unvisited[v] = struct{}{}
 	}
-	for v := range unvisited {
+	vertices := make([]int, 0, len(g))
+	for v := range g {
+		vertices = append(vertices, v)
+	}
+	sort.Ints(vertices)
+	for _, v := range vertices {
+		if _, ok := unvisited[v]; !ok {
+			continue
+		}
 		visited := make(map[int]struct{})
-		if g.dfsHasCycle(-1, v, visited, unvisited) {
-			return true
+		if u, w, ok := g.dfsFindCycle(-1, v, visited, unvisited); ok {
+			return u, w, true
 		}
 	}
-	return false
+	return 0, 0, false
 }
 
-func (g graph) dfsHasCycle(parent, v int, visited, unvisited map[int]struct{}) bool {
+func (g graph) dfsFindCycle(parent, v int, visited, unvisited map[int]struct{}) (int, int, bool) {
 	visited[v] = struct{}{}
 	delete(unvisited, v)
-	for neighbour := range g[v] {
+	neighbours := make([]int, 0, len(g[v]))
+	for n := range g[v] {
+		neighbours = append(neighbours, n)
+	}
+	sort.Ints(neighbours)
+	for _, neighbour := range neighbours {
 		if neighbour == parent {
 			continue
 		}
 		if _, have := visited[neighbour]; have {
-			return true
+			return v, neighbour, true
 		}
-		if g.dfsHasCycle(v, neighbour, visited, unvisited) {
-			return true
+		if u, w, ok := g.dfsFindCycle(v, neighbour, visited, unvisited); ok {
+			return u, w, true
 		}
 	}
 	delete(visited, v)
-	return false
+	return 0, 0, false
 }
@@ -0,0 +1,483 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MarshalKML returns the KML representation of the geometry, as a Point,
+// LineString, Polygon, or MultiGeometry element.
+//
+// KML coordinates are longitude, latitude, and (optionally) altitude, so X
+// and Y values are written as longitude and latitude, Z values are written as
+// altitude, and M values are dropped. Multi geometries and
+// GeometryCollections are written as MultiGeometry elements, and empty
+// geometries are written without any coordinates. The elements are written
+// without a namespace, so that they inherit the namespace of the document
+// they are written into (usually "http://www.opengis.net/kml/2.2").
+func MarshalKML(g Geometry) ([]byte, error) {
+	return xml.Marshal(KMLGeometry{g})
+}
+
+// UnmarshalKML parses a KML Point, LineString, LinearRing, Polygon, or
+// MultiGeometry element, and returns the corresponding Geometry. An element
+// that contains geometry elements (such as a Placemark, or a whole kml
+// document) may also be parsed, in which case the first geometry found
+// within the element is returned. To read every Placemark in a document,
+// KMLPlacemark can be used with an xml.Decoder.
+//
+// LinearRings are returned as LineStrings. MultiGeometries are returned as
+// MultiPoints, MultiLineStrings, or MultiPolygons if all of their members
+// are non-empty and of the corresponding type, and as GeometryCollections
+// otherwise. Altitude values are returned as Z values (if every coordinate
+// in a Point, LineString, LinearRing, or Polygon has an altitude). If only
+// some members of a MultiGeometry have altitudes, then the Z values are
+// dropped from all of its members. Elements in geometries that aren't
+// related to coordinates (such as altitudeMode) are ignored.
+func UnmarshalKML(input []byte, opts ...ConstructorOption) (Geometry, error) {
+	d := xml.NewDecoder(bytes.NewReader(input))
+	start, err := xmlFirstElement(d)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return decodeKML(d, start, opts)
+}
+
+// KMLGeometry is a Geometry that is encoded as KML. It implements the
+// xml.Marshaler and xml.Unmarshaler interfaces, so may be used with
+// xml.Encoder and xml.Decoder to stream geometries to and from larger KML
+// documents.
+type KMLGeometry struct {
+	Geometry Geometry
+}
+
+// MarshalXML implements the xml.Marshaler interface by writing the geometry
+// in the same way as MarshalKML. The name of the start element is ignored
+// (the name of the written element depends on the type of the geometry).
+func (g KMLGeometry) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	w := xmlWriter{e: e}
+	writeKMLGeometry(&w, g.Geometry)
+	return w.err
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface by parsing the
+// element in the same way as UnmarshalKML.
+//
+// It constructs the resultant geometry with no ConstructionOptions. If
+// ConstructionOptions are needed, then UnmarshalKML should be used instead.
+func (g *KMLGeometry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	parsed, err := decodeKML(d, start, nil)
+	if err != nil {
+		return err
+	}
+	g.Geometry = parsed
+	return nil
+}
+
+// KMLPlacemark is a KML Placemark element, consisting of a geometry along
+// with its name and description. It implements the xml.Marshaler and
+// xml.Unmarshaler interfaces. Any other elements within the Placemark are
+// ignored when it's unmarshalled.
+type KMLPlacemark struct {
+	ID          string
+	Name        string
+	Description string
+
+	// Geometry is an empty GeometryCollection if the Placemark doesn't
+	// have a geometry.
+	Geometry Geometry
+}
+
+// MarshalXML implements the xml.Marshaler interface by writing a Placemark
+// element. The ID, name, and description are omitted if they are empty. The
+// name of the start element is ignored.
+func (p KMLPlacemark) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	w := xmlWriter{e: e}
+	var attrs []xml.Attr
+	if p.ID != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "id"}, Value: p.ID})
+	}
+	w.start("Placemark", attrs...)
+	if p.Name != "" {
+		w.textElement("name", []byte(p.Name))
+	}
+	if p.Description != "" {
+		w.textElement("description", []byte(p.Description))
+	}
+	writeKMLGeometry(&w, p.Geometry)
+	w.end("Placemark")
+	return w.err
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface by parsing a
+// Placemark element. The geometry is parsed in the same way as UnmarshalKML,
+// and is constructed with no ConstructionOptions.
+func (p *KMLPlacemark) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var placemark KMLPlacemark
+	placemark.ID, _ = xmlAttr(start, "id")
+	var haveGeom bool
+	if err := xmlForEachChild(d, func(child xml.StartElement) error {
+		var err error
+		switch {
+		case child.Name.Local == "name":
+			placemark.Name, err = xmlText(d, child)
+		case child.Name.Local == "description":
+			placemark.Description, err = xmlText(d, child)
+		case isKMLGeometry(child.Name.Local) && !haveGeom:
+			placemark.Geometry, err = decodeKMLGeometry(d, child, nil)
+			haveGeom = true
+		default:
+			err = d.Skip()
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	*p = placemark
+	return nil
+}
+
+func writeKMLGeometry(w *xmlWriter, g Geometry) {
+	switch {
+	case g.IsEmptySet():
+		switch g.Dimension() {
+		case 0:
+			w.start("Point")
+			w.end("Point")
+		case 1:
+			w.start("LineString")
+			w.end("LineString")
+		default:
+			w.start("Polygon")
+			w.end("Polygon")
+		}
+	case g.IsPoint():
+		c := g.AsPoint().Coordinates()
+		w.start("Point")
+		writeKMLCoordinates(w, []Coordinates{c})
+		w.end("Point")
+	case g.IsLine():
+		w.start("LineString")
+		writeKMLCoordinates(w, g.AsLine().Coordinates())
+		w.end("LineString")
+	case g.IsLineString():
+		w.start("LineString")
+		writeKMLCoordinates(w, g.AsLineString().Coordinates())
+		w.end("LineString")
+	case g.IsPolygon():
+		writeKMLPolygon(w, g.AsPolygon())
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		w.start("MultiGeometry")
+		for i := 0; i < mp.NumPoints(); i++ {
+			writeKMLGeometry(w, mp.PointN(i).AsGeometry())
+		}
+		w.end("MultiGeometry")
+	case g.IsMultiLineString():
+		mls := g.AsMultiLineString()
+		w.start("MultiGeometry")
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			writeKMLGeometry(w, mls.LineStringN(i).AsGeometry())
+		}
+		w.end("MultiGeometry")
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		w.start("MultiGeometry")
+		for i := 0; i < mp.NumPolygons(); i++ {
+			writeKMLPolygon(w, mp.PolygonN(i))
+		}
+		w.end("MultiGeometry")
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		w.start("MultiGeometry")
+		for i := 0; i < gc.NumGeometries(); i++ {
+			writeKMLGeometry(w, gc.GeometryN(i))
+		}
+		w.end("MultiGeometry")
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+func writeKMLPolygon(w *xmlWriter, p Polygon) {
+	w.start("Polygon")
+	for i, ring := range p.Coordinates() {
+		boundary := "innerBoundaryIs"
+		if i == 0 {
+			boundary = "outerBoundaryIs"
+		}
+		w.start(boundary)
+		w.start("LinearRing")
+		writeKMLCoordinates(w, ring)
+		w.end("LinearRing")
+		w.end(boundary)
+	}
+	w.end("Polygon")
+}
+
+// writeKMLCoordinates writes a coordinates element, which consists of
+// space separated longitude,latitude[,altitude] tuples.
+func writeKMLCoordinates(w *xmlWriter, coords []Coordinates) {
+	var buf []byte
+	for i, c := range coords {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = appendFloat(buf, c.X)
+		buf = append(buf, ',')
+		buf = appendFloat(buf, c.Y)
+		if c.Type.Is3D() {
+			buf = append(buf, ',')
+			buf = appendFloat(buf, c.Z)
+		}
+	}
+	w.textElement("coordinates", buf)
+}
+
+func isKMLGeometry(name string) bool {
+	switch name {
+	case "Point", "LineString", "LinearRing", "Polygon", "MultiGeometry":
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeKML decodes the element that has just been started, which is either
+// a geometry element or an element containing a geometry element (possibly
+// nested within other elements).
+func decodeKML(d *xml.Decoder, start xml.StartElement, opts []ConstructorOption) (Geometry, error) {
+	g, found, err := decodeFirstKMLGeometry(d, start, opts)
+	if err != nil {
+		return Geometry{}, err
+	}
+	if !found {
+		return Geometry{}, fmt.Errorf("no KML geometry found in %s element", start.Name.Local)
+	}
+	return g, nil
+}
+
+// decodeFirstKMLGeometry decodes the first geometry element out of the
+// element that has just been started and its descendants. The rest of the
+// element is skipped.
+func decodeFirstKMLGeometry(d *xml.Decoder, start xml.StartElement, opts []ConstructorOption) (Geometry, bool, error) {
+	if isKMLGeometry(start.Name.Local) {
+		g, err := decodeKMLGeometry(d, start, opts)
+		return g, true, err
+	}
+	var g Geometry
+	var found bool
+	err := xmlForEachChild(d, func(child xml.StartElement) error {
+		if found {
+			return d.Skip()
+		}
+		var err error
+		g, found, err = decodeFirstKMLGeometry(d, child, opts)
+		return err
+	})
+	return g, found, err
+}
+
+func decodeKMLGeometry(d *xml.Decoder, start xml.StartElement, opts []ConstructorOption) (Geometry, error) {
+	switch start.Name.Local {
+	case "Point":
+		coords, err := decodeKMLCoordinates(d)
+		if err != nil {
+			return Geometry{}, err
+		}
+		switch len(coords) {
+		case 0:
+			return NewEmptyPoint(opts...).AsGeometry(), nil
+		case 1:
+			return NewPointC(coords[0], opts...).AsGeometry(), nil
+		default:
+			return Geometry{}, fmt.Errorf("KML Point has %d coordinates", len(coords))
+		}
+	case "LineString", "LinearRing":
+		coords, err := decodeKMLCoordinates(d)
+		if err != nil {
+			return Geometry{}, err
+		}
+		switch len(coords) {
+		case 0:
+			return NewEmptyLineString(opts...).AsGeometry(), nil
+		case 2:
+			ln, err := NewLineC(coords[0], coords[1], opts...)
+			return ln.AsGeometry(), err
+		default:
+			ls, err := NewLineStringC(coords, opts...)
+			return ls.AsGeometry(), err
+		}
+	case "Polygon":
+		rings, err := decodeKMLRings(d)
+		if err != nil {
+			return Geometry{}, err
+		}
+		if len(rings) == 0 {
+			return NewEmptyPolygon(opts...).AsGeometry(), nil
+		}
+		poly, err := NewPolygonC(rings, opts...)
+		return poly.AsGeometry(), err
+	case "MultiGeometry":
+		var geoms []Geometry
+		if err := xmlForEachChild(d, func(child xml.StartElement) error {
+			if !isKMLGeometry(child.Name.Local) {
+				return d.Skip()
+			}
+			g, err := decodeKMLGeometry(d, child, opts)
+			geoms = append(geoms, g)
+			return err
+		}); err != nil {
+			return Geometry{}, err
+		}
+		return newMultiGeometry(geoms, opts)
+	default:
+		return Geometry{}, fmt.Errorf("unknown KML geometry: %s", start.Name.Local)
+	}
+}
+
+// decodeKMLRings decodes the rings of a Polygon (the outer ring is first).
+func decodeKMLRings(d *xml.Decoder) ([][]Coordinates, error) {
+	var outer []Coordinates
+	var inners [][]Coordinates
+	err := xmlForEachChild(d, func(child xml.StartElement) error {
+		name := child.Name.Local
+		if name != "outerBoundaryIs" && name != "innerBoundaryIs" {
+			return d.Skip()
+		}
+		return xmlForEachChild(d, func(child xml.StartElement) error {
+			if child.Name.Local != "LinearRing" {
+				return d.Skip()
+			}
+			ring, err := decodeKMLCoordinates(d)
+			if err != nil {
+				return err
+			}
+			if name == "outerBoundaryIs" {
+				if outer != nil {
+					return errors.New("KML Polygon has multiple outer boundaries")
+				}
+				outer = ring
+			} else {
+				inners = append(inners, ring)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if outer == nil {
+		if len(inners) > 0 {
+			return nil, errors.New("KML Polygon has inner boundaries but no outer boundary")
+		}
+		return nil, nil
+	}
+	return append([][]Coordinates{outer}, inners...), nil
+}
+
+// decodeKMLCoordinates decodes the coordinates element within the geometry
+// element that has just been started.
+func decodeKMLCoordinates(d *xml.Decoder) ([]Coordinates, error) {
+	var coords []Coordinates
+	err := xmlForEachChild(d, func(child xml.StartElement) error {
+		if child.Name.Local != "coordinates" {
+			return d.Skip()
+		}
+		text, err := xmlText(d, child)
+		if err != nil {
+			return err
+		}
+		coords, err = parseKMLCoordinates(text)
+		return err
+	})
+	return coords, err
+}
+
+// parseKMLCoordinates parses whitespace separated
+// longitude,latitude[,altitude] tuples. The coordinates are XYZ if every
+// tuple has an altitude, and XY otherwise.
+func parseKMLCoordinates(text string) ([]Coordinates, error) {
+	tuples := strings.Fields(text)
+	if len(tuples) == 0 {
+		return nil, nil
+	}
+	coords := make([]Coordinates, len(tuples))
+	ctype := DimXYZ
+	for i, tuple := range tuples {
+		parts := strings.Split(tuple, ",")
+		if len(parts) != 2 && len(parts) != 3 {
+			return nil, fmt.Errorf("invalid KML coordinates: %q", tuple)
+		}
+		var vals [3]float64
+		for j, part := range parts {
+			f, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid KML coordinates: %q", tuple)
+			}
+			vals[j] = f
+		}
+		if len(parts) == 2 {
+			ctype = DimXY
+		}
+		coords[i] = Coordinates{XY: XY{vals[0], vals[1]}, Z: vals[2], Type: DimXYZ}
+	}
+	for i := range coords {
+		coords[i].Type = ctype
+		if ctype == DimXY {
+			coords[i].Z = 0
+		}
+	}
+	return coords, nil
+}
+
+// newMultiGeometry creates a MultiPoint, MultiLineString, or MultiPolygon if
+// every geometry is a non-empty geometry of the corresponding type, and a
+// GeometryCollection otherwise.
+func newMultiGeometry(geoms []Geometry, opts []ConstructorOption) (Geometry, error) {
+	if len(geoms) == 0 {
+		return GeometryCollection{}.AsGeometry(), nil
+	}
+	var numPts, numLines, numPolys int
+	for _, g := range geoms {
+		switch {
+		case g.IsPoint():
+			numPts++
+		case g.IsLine(), g.IsLineString():
+			numLines++
+		case g.IsPolygon():
+			numPolys++
+		}
+	}
+	switch len(geoms) {
+	case numPts:
+		pts := make([]Point, len(geoms))
+		for i, g := range geoms {
+			pts[i] = g.AsPoint()
+		}
+		return NewMultiPoint(pts, opts...).AsGeometry(), nil
+	case numLines:
+		lss := make([]LineString, len(geoms))
+		for i, g := range geoms {
+			if g.IsLine() {
+				lss[i] = g.AsLine().AsLineString()
+			} else {
+				lss[i] = g.AsLineString()
+			}
+		}
+		return NewMultiLineString(lss, opts...).AsGeometry(), nil
+	case numPolys:
+		polys := make([]Polygon, len(geoms))
+		for i, g := range geoms {
+			polys[i] = g.AsPolygon()
+		}
+		mp, err := NewMultiPolygon(polys, opts...)
+		return mp.AsGeometry(), err
+	default:
+		return NewGeometryCollection(geoms, opts...).AsGeometry(), nil
+	}
+}
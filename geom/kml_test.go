@@ -0,0 +1,289 @@
+package geom_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestKMLMarshal(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		want string
+	}{
+		{"POINT EMPTY", "<Point></Point>"},
+		{"POINT(1 2)", "<Point><coordinates>1,2</coordinates></Point>"},
+		{"POINT Z (1 2 3)", "<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"POINT M (1 2 3)", "<Point><coordinates>1,2</coordinates></Point>"},
+		{"LINESTRING EMPTY", "<LineString></LineString>"},
+		{"LINESTRING(1 2,3.5 -4)", "<LineString><coordinates>1,2 3.5,-4</coordinates></LineString>"},
+		{"LINESTRING ZM (1 2 3 4,5 6 7 8,9 10 11 12)", "<LineString><coordinates>1,2,3 5,6,7 9,10,11</coordinates></LineString>"},
+		{"POLYGON EMPTY", "<Polygon></Polygon>"},
+		{
+			"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			"<Polygon>" +
+				"<outerBoundaryIs><LinearRing><coordinates>0,0 4,0 0,4 0,0</coordinates></LinearRing></outerBoundaryIs>" +
+				"<innerBoundaryIs><LinearRing><coordinates>1,1 2,1 1,2 1,1</coordinates></LinearRing></innerBoundaryIs>" +
+				"</Polygon>",
+		},
+		{"MULTIPOINT EMPTY", "<MultiGeometry></MultiGeometry>"},
+		{
+			"MULTIPOINT((1 2),(3 4))",
+			"<MultiGeometry><Point><coordinates>1,2</coordinates></Point><Point><coordinates>3,4</coordinates></Point></MultiGeometry>",
+		},
+		{
+			"MULTILINESTRING((1 2,3 4),(5 6,7 8))",
+			"<MultiGeometry>" +
+				"<LineString><coordinates>1,2 3,4</coordinates></LineString>" +
+				"<LineString><coordinates>5,6 7,8</coordinates></LineString>" +
+				"</MultiGeometry>",
+		},
+		{
+			"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))",
+			"<MultiGeometry><Polygon>" +
+				"<outerBoundaryIs><LinearRing><coordinates>0,0 1,0 0,1 0,0</coordinates></LinearRing></outerBoundaryIs>" +
+				"</Polygon></MultiGeometry>",
+		},
+		{
+			"GEOMETRYCOLLECTION(POINT(1 2),GEOMETRYCOLLECTION(LINESTRING(3 4,5 6)))",
+			"<MultiGeometry>" +
+				"<Point><coordinates>1,2</coordinates></Point>" +
+				"<MultiGeometry><LineString><coordinates>3,4 5,6</coordinates></LineString></MultiGeometry>" +
+				"</MultiGeometry>",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := MarshalKML(geomFromWKT(t, tt.wkt))
+			expectNoErr(t, err)
+			expectStringEq(t, string(got), tt.want)
+		})
+	}
+}
+
+func TestKMLUnmarshal(t *testing.T) {
+	for i, tt := range []struct {
+		kml  string
+		want string
+	}{
+		{"<Point><coordinates>1,2</coordinates></Point>", "POINT(1 2)"},
+		{"<Point><coordinates> 1,2,3 </coordinates></Point>", "POINT Z (1 2 3)"},
+		{"<Point></Point>", "POINT EMPTY"},
+		{"<Point><coordinates></coordinates></Point>", "POINT EMPTY"},
+		{
+			`<Point xmlns="http://www.opengis.net/kml/2.2">
+				<extrude>1</extrude>
+				<altitudeMode>relativeToGround</altitudeMode>
+				<coordinates>-122.0822035425683,37.42228990140251,0</coordinates>
+			</Point>`,
+			"POINT Z (-122.0822035425683 37.42228990140251 0)",
+		},
+		{"<LineString><coordinates>1,2 3,4</coordinates></LineString>", "LINESTRING(1 2,3 4)"},
+		{
+			"<LineString><tessellate>1</tessellate><coordinates>\n\t1,2,3\n\t4,5,6\n\t7,8,9\n</coordinates></LineString>",
+			"LINESTRING Z (1 2 3,4 5 6,7 8 9)",
+		},
+		{"<LineString><coordinates>1,2,3 4,5</coordinates></LineString>", "LINESTRING(1 2,4 5)"},
+		{"<LinearRing><coordinates>0,0 1,0 0,1 0,0</coordinates></LinearRing>", "LINESTRING(0 0,1 0,0 1,0 0)"},
+		{
+			`<Polygon>
+				<innerBoundaryIs><LinearRing><coordinates>1,1 2,1 1,2 1,1</coordinates></LinearRing></innerBoundaryIs>
+				<outerBoundaryIs><LinearRing><coordinates>0,0 4,0 0,4 0,0</coordinates></LinearRing></outerBoundaryIs>
+			</Polygon>`,
+			"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+		},
+		{"<Polygon></Polygon>", "POLYGON EMPTY"},
+		{
+			"<MultiGeometry><Point><coordinates>1,2</coordinates></Point><Point><coordinates>3,4,5</coordinates></Point></MultiGeometry>",
+			"MULTIPOINT((1 2),(3 4))",
+		},
+		{
+			"<MultiGeometry><LineString><coordinates>1,2 3,4</coordinates></LineString><LinearRing><coordinates>0,0 1,0 0,1 0,0</coordinates></LinearRing></MultiGeometry>",
+			"MULTILINESTRING((1 2,3 4),(0 0,1 0,0 1,0 0))",
+		},
+		{
+			"<MultiGeometry><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 0,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon></MultiGeometry>",
+			"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))",
+		},
+		{
+			"<MultiGeometry><Point><coordinates>1,2</coordinates></Point><LineString><coordinates>1,2 3,4</coordinates></LineString></MultiGeometry>",
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4))",
+		},
+		{
+			"<MultiGeometry><Point><coordinates>1,2</coordinates></Point><Point></Point></MultiGeometry>",
+			"GEOMETRYCOLLECTION(POINT(1 2),POINT EMPTY)",
+		},
+		{"<MultiGeometry></MultiGeometry>", "GEOMETRYCOLLECTION EMPTY"},
+		{
+			`<?xml version="1.0" encoding="UTF-8"?>
+			<Placemark id="p1">
+				<name>Office</name>
+				<Style><IconStyle><Icon><href>icon.png</href></Icon></IconStyle></Style>
+				<Point><coordinates>1,2</coordinates></Point>
+			</Placemark>`,
+			"POINT(1 2)",
+		},
+		{
+			`<?xml version="1.0" encoding="UTF-8"?>
+			<kml xmlns="http://www.opengis.net/kml/2.2">
+				<Document>
+					<name>Places</name>
+					<Style id="s1"><LineStyle><width>2</width></LineStyle></Style>
+					<Folder>
+						<name>Paths</name>
+						<Placemark>
+							<name>Path</name>
+							<styleUrl>#s1</styleUrl>
+							<LineString><coordinates>1,2,0 3,4,0</coordinates></LineString>
+						</Placemark>
+						<Placemark>
+							<name>Office</name>
+							<Point><coordinates>5,6</coordinates></Point>
+						</Placemark>
+					</Folder>
+				</Document>
+			</kml>`,
+			"LINESTRING Z (1 2 0,3 4 0)",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := UnmarshalKML([]byte(tt.kml))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want))
+		})
+	}
+}
+
+func TestKMLRoundTrip(t *testing.T) {
+	for i, wkt := range []string{
+		"POINT EMPTY",
+		"POINT(1 2)",
+		"POINT Z (1 2 3)",
+		"LINESTRING EMPTY",
+		"LINESTRING(1 2,3 4)",
+		"LINESTRING Z (1 2 3,4 5 6,7 8 9)",
+		"POLYGON EMPTY",
+		"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+		"MULTIPOINT((1 2),(3 4))",
+		"MULTILINESTRING((1 2,3 4),(5 6,7 8,9 10))",
+		"MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((2 2,3 2,2 3,2 2)))",
+		"GEOMETRYCOLLECTION EMPTY",
+		"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(3 4,5 6))",
+		"GEOMETRYCOLLECTION Z (POINT Z (1 2 3),MULTIPOINT Z ((4 5 6)))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			want := geomFromWKT(t, wkt)
+			buf, err := MarshalKML(want)
+			expectNoErr(t, err)
+			got, err := UnmarshalKML(buf)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, want)
+		})
+	}
+}
+
+func TestKMLUnmarshalInvalid(t *testing.T) {
+	for i, kml := range []string{
+		"",
+		"not xml",
+		"<Placemark><name>no geometry</name></Placemark>",
+		"<kml><Document><Placemark><name>no geometry</name></Placemark></Document></kml>",
+		"<Point><coordinates>1</coordinates></Point>",
+		"<Point><coordinates>1,2,3,4</coordinates></Point>",
+		"<Point><coordinates>1,x</coordinates></Point>",
+		"<Point><coordinates>1,2 3,4</coordinates></Point>",
+		"<LineString><coordinates>1,2</coordinates></LineString>",
+		"<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 0,1</coordinates></LinearRing></outerBoundaryIs></Polygon>",
+		"<Polygon><innerBoundaryIs><LinearRing><coordinates>0,0 1,0 0,1 0,0</coordinates></LinearRing></innerBoundaryIs></Polygon>",
+		"<Point><coordinates>1,2</coordinates>",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := UnmarshalKML([]byte(kml))
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestKMLPlacemarkStreaming(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+	<Document>
+		<name>Export</name>
+		<Folder>
+			<Placemark id="a">
+				<name>First</name>
+				<description><![CDATA[<b>bold</b>]]></description>
+				<Point><coordinates>1,2,0</coordinates></Point>
+			</Placemark>
+			<Placemark>
+				<name>Second</name>
+				<LineString><coordinates>1,2 3,4 5,6</coordinates></LineString>
+			</Placemark>
+			<Placemark>
+				<name>No Geometry</name>
+			</Placemark>
+		</Folder>
+	</Document>
+</kml>`
+
+	var placemarks []KMLPlacemark
+	d := xml.NewDecoder(bytes.NewReader([]byte(doc)))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		expectNoErr(t, err)
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "Placemark" {
+			var p KMLPlacemark
+			expectNoErr(t, d.DecodeElement(&p, &start))
+			placemarks = append(placemarks, p)
+		}
+	}
+
+	expectIntEq(t, len(placemarks), 3)
+	expectStringEq(t, placemarks[0].ID, "a")
+	expectStringEq(t, placemarks[0].Name, "First")
+	expectStringEq(t, placemarks[0].Description, "<b>bold</b>")
+	expectGeomEq(t, placemarks[0].Geometry, geomFromWKT(t, "POINT Z (1 2 0)"))
+	expectStringEq(t, placemarks[1].ID, "")
+	expectStringEq(t, placemarks[1].Name, "Second")
+	expectGeomEq(t, placemarks[1].Geometry, geomFromWKT(t, "LINESTRING(1 2,3 4,5 6)"))
+	expectStringEq(t, placemarks[2].Name, "No Geometry")
+	expectGeomEq(t, placemarks[2].Geometry, geomFromWKT(t, "GEOMETRYCOLLECTION EMPTY"))
+
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	for _, p := range placemarks[:2] {
+		expectNoErr(t, e.Encode(p))
+	}
+	expectNoErr(t, e.Flush())
+	expectStringEq(t, buf.String(), ""+
+		`<Placemark id="a"><name>First</name><description>&lt;b&gt;bold&lt;/b&gt;</description>`+
+		`<Point><coordinates>1,2,0</coordinates></Point></Placemark>`+
+		`<Placemark><name>Second</name>`+
+		`<LineString><coordinates>1,2 3,4 5,6</coordinates></LineString></Placemark>`,
+	)
+}
+
+func TestKMLGeometryStructField(t *testing.T) {
+	type placemark struct {
+		XMLName xml.Name    `xml:"Placemark"`
+		Name    string      `xml:"name"`
+		Geom    KMLGeometry `xml:"Point"`
+	}
+	var p placemark
+	err := xml.Unmarshal([]byte(`<Placemark><name>x</name><Point><coordinates>1,2</coordinates></Point></Placemark>`), &p)
+	expectNoErr(t, err)
+	expectStringEq(t, p.Name, "x")
+	expectGeomEq(t, p.Geom.Geometry, geomFromWKT(t, "POINT(1 2)"))
+
+	buf, err := xml.Marshal(p)
+	expectNoErr(t, err)
+	expectStringEq(t, string(buf), `<Placemark><name>x</name><Point><coordinates>1,2</coordinates></Point></Placemark>`)
+}
@@ -0,0 +1,345 @@
+package geom
+
+import "math"
+
+// PreparedGeometry is a geometry that has been pre-processed so that spatial
+// predicates can be evaluated against it efficiently. It's useful when the
+// same geometry is tested against many other geometries (e.g. when testing
+// many points against the same set of polygons).
+//
+// Preparing a geometry has an up front cost, so isn't worthwhile if the
+// geometry is only going to be used a small number of times. Z and M values
+// are ignored.
+type PreparedGeometry struct {
+	geom     Geometry
+	input    overlayInput
+	dim      int
+	env      Envelope
+	nonEmpty bool
+
+	// index contains the points, line segments, and ring segments making up
+	// the geometry. kinds gives the kind of each indexed element, and polys
+	// gives the index of the polygon that each ring segment belongs to.
+	index segmentIndex
+	kinds []preparedKind
+	polys []int
+
+	// lineBound contains the points that make up the boundary of the linear
+	// parts of the geometry.
+	lineBound map[XY]bool
+}
+
+type preparedKind int
+
+const (
+	preparedPoint preparedKind = iota
+	preparedLine
+	preparedRing
+)
+
+// Prepare pre-processes a geometry, creating a PreparedGeometry.
+func Prepare(g Geometry) PreparedGeometry {
+	p := PreparedGeometry{
+		geom:  g,
+		input: newOverlayInput(g.Force2D()),
+		dim:   overlayDimension(g),
+	}
+	p.env, p.nonEmpty = g.Envelope()
+
+	var segs []indexedSegment
+	add := func(a, b XY, kind preparedKind, poly int) {
+		segs = append(segs, indexedSegment{a, b, NewEnvelope(a, b)})
+		p.kinds = append(p.kinds, kind)
+		p.polys = append(p.polys, poly)
+	}
+	for _, pt := range p.input.points {
+		add(pt, pt, preparedPoint, -1)
+	}
+	lineEnds := make(map[XY]int)
+	for _, ls := range p.input.lines {
+		for i := 0; i+1 < len(ls); i++ {
+			add(ls[i], ls[i+1], preparedLine, -1)
+		}
+		if len(ls) >= 2 && ls[0] != ls[len(ls)-1] {
+			lineEnds[ls[0]]++
+			lineEnds[ls[len(ls)-1]]++
+		}
+	}
+	for j, poly := range p.input.polys {
+		for _, r := range poly.rings() {
+			xys := lineStringXYs(r)
+			for i := 0; i+1 < len(xys); i++ {
+				add(xys[i], xys[i+1], preparedRing, j)
+			}
+		}
+	}
+	p.index = newSegmentIndex(segs)
+
+	p.lineBound = make(map[XY]bool)
+	for xy, n := range lineEnds {
+		if n%2 == 1 {
+			p.lineBound[xy] = true
+		}
+	}
+	return p
+}
+
+// Geometry gives the geometry that was prepared.
+func (p PreparedGeometry) Geometry() Geometry {
+	return p.geom
+}
+
+// locate finds the location of a point relative to the prepared geometry.
+func (p PreparedGeometry) locate(pt XY) location {
+	var onRing, onLine, onPoint bool
+	p.index.search(NewEnvelope(pt), func(i int) bool {
+		seg := p.index.segs[i]
+		if !xyOnSegment(pt, seg.a, seg.b) {
+			return false
+		}
+		switch p.kinds[i] {
+		case preparedRing:
+			onRing = true
+		case preparedLine:
+			onLine = true
+		case preparedPoint:
+			onPoint = true
+		}
+		return onRing
+	})
+
+	switch {
+	case p.inArea(pt):
+		return locInterior
+	case onRing:
+		return locBoundary
+	case onLine && p.lineBound[pt]:
+		return locBoundary
+	case onLine || onPoint:
+		return locInterior
+	default:
+		return locExterior
+	}
+}
+
+// inArea checks if a point is in the interior of any of the polygons in the
+// prepared geometry. A ray is cast from the point in the positive X
+// direction, and the number of ring segments that it crosses is counted
+// separately for each polygon (so that polygons in a GeometryCollection may
+// overlap). Polygons that have the point on their boundary are ignored.
+func (p PreparedGeometry) inArea(pt XY) bool {
+	if len(p.input.polys) == 0 {
+		return false
+	}
+	inside := make([]bool, len(p.input.polys))
+	onBoundary := make([]bool, len(p.input.polys))
+	ray := NewEnvelope(pt, XY{math.Inf(+1), pt.Y})
+	p.index.search(ray, func(i int) bool {
+		if p.kinds[i] != preparedRing {
+			return false
+		}
+		seg := p.index.segs[i]
+		poly := p.polys[i]
+		if xyOnSegment(pt, seg.a, seg.b) {
+			onBoundary[poly] = true
+		} else if crosses, _ := rayCrossing(pt, seg.a, seg.b); crosses {
+			inside[poly] = !inside[poly]
+		}
+		return false
+	})
+	for i := range inside {
+		if inside[i] && !onBoundary[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentInteracts checks if a line segment (or point, if a and b are the
+// same) intersects with any of the indexed elements whose kind is accepted by
+// the filter.
+func (p PreparedGeometry) segmentInteracts(a, b XY, filter func(preparedKind) bool) bool {
+	return p.index.search(NewEnvelope(a, b), func(i int) bool {
+		if !filter(p.kinds[i]) {
+			return false
+		}
+		seg := p.index.segs[i]
+		return segmentsIntersect(a, b, seg.a, seg.b)
+	})
+}
+
+// Intersects checks if the prepared geometry intersects with another
+// geometry. It gives the same result as the Intersects method on Geometry.
+func (p PreparedGeometry) Intersects(g Geometry) bool {
+	env, ok := g.Envelope()
+	if !ok || !p.nonEmpty || !env.Intersects(p.env) {
+		return false
+	}
+	switch {
+	case g.IsPoint():
+		return p.locate(g.AsPoint().XY()) != locExterior
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		for i := 0; i < mp.NumPoints(); i++ {
+			if p.locate(mp.PointN(i).XY()) != locExterior {
+				return true
+			}
+		}
+		return false
+	}
+
+	other := newOverlayInput(g.Force2D())
+	for _, pt := range other.points {
+		if p.locate(pt) != locExterior {
+			return true
+		}
+	}
+	anyKind := func(preparedKind) bool { return true }
+	for _, ls := range append(other.lines, other.rings...) {
+		for i := 0; i+1 < len(ls); i++ {
+			if p.segmentInteracts(ls[i], ls[i+1], anyKind) {
+				return true
+			}
+		}
+	}
+
+	// No boundaries interact, but one geometry could be inside an area of
+	// the other. Testing a single point of each component is enough.
+	for _, ls := range append(other.lines, other.rings...) {
+		if len(ls) > 0 && p.inArea(ls[0]) {
+			return true
+		}
+	}
+	if _, ok := representativeInArea(p.input, other); ok {
+		return true
+	}
+	return false
+}
+
+// Contains checks if the prepared geometry contains another geometry. It
+// gives the same result as the Contains function.
+func (p PreparedGeometry) Contains(g Geometry) (bool, error) {
+	return p.covers(g, true)
+}
+
+// Covers checks if the prepared geometry covers another geometry. It gives
+// the same result as the Covers function.
+func (p PreparedGeometry) Covers(g Geometry) (bool, error) {
+	return p.covers(g, false)
+}
+
+// covers checks if the prepared geometry covers (or contains, if
+// needInterior is set) another geometry.
+func (p PreparedGeometry) covers(g Geometry, needInterior bool) (bool, error) {
+	env, ok := g.Envelope()
+	if !ok || !p.nonEmpty || !p.env.Covers(env) {
+		return false, nil
+	}
+	if overlayDimension(g) > p.dim {
+		return false, nil
+	}
+
+	switch {
+	case g.IsPoint():
+		loc := p.locate(g.AsPoint().XY())
+		return loc == locInterior || (!needInterior && loc == locBoundary), nil
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		var anyInterior bool
+		for i := 0; i < mp.NumPoints(); i++ {
+			switch p.locate(mp.PointN(i).XY()) {
+			case locExterior:
+				return false, nil
+			case locInterior:
+				anyInterior = true
+			}
+		}
+		return anyInterior || !needInterior, nil
+	}
+
+	// When the prepared geometry is purely areal, and the other geometry
+	// doesn't interact with its boundary at all, then each component of the
+	// other geometry is either entirely inside or entirely outside.
+	onlyAreal := len(p.input.points) == 0 && len(p.input.lines) == 0
+	if onlyAreal {
+		other := newOverlayInput(g.Force2D())
+		onRing := func(k preparedKind) bool { return k == preparedRing }
+		var interacts bool
+		for _, pt := range other.points {
+			interacts = interacts || p.segmentInteracts(pt, pt, onRing)
+		}
+		for _, ls := range append(other.lines, other.rings...) {
+			for i := 0; !interacts && i+1 < len(ls); i++ {
+				interacts = p.segmentInteracts(ls[i], ls[i+1], onRing)
+			}
+		}
+		if !interacts {
+			// If any ring of the prepared geometry is inside the other
+			// geometry, then some of the other geometry must be outside of
+			// the prepared geometry.
+			if _, ok := representativeInArea(p.input, other); ok {
+				return false, nil
+			}
+			for _, pt := range other.points {
+				if !p.inArea(pt) {
+					return false, nil
+				}
+			}
+			for _, ls := range append(other.lines, other.rings...) {
+				if len(ls) > 0 && !p.inArea(ls[0]) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	}
+
+	if needInterior {
+		return Contains(p.geom, g)
+	}
+	return Covers(p.geom, g)
+}
+
+// Distance calculates the shortest distance (using the Euclidean metric)
+// between the prepared geometry and another geometry. It returns false if
+// either geometry is empty. It gives the same result as the Distance method
+// on Geometry.
+func (p PreparedGeometry) Distance(g Geometry) (float64, bool) {
+	if !p.nonEmpty || g.IsEmpty() {
+		return 0, false
+	}
+	other := newOverlayInput(g.Force2D())
+	elems := distanceElements(other)
+	if len(elems) == 0 {
+		return 0, false
+	}
+	if p.Intersects(g) {
+		return 0, true
+	}
+	best := math.Inf(+1)
+	for _, e := range elems {
+		best = p.index.nearest(e.env, func(i int) float64 {
+			seg := p.index.segs[i]
+			p1, p2 := closestPointsBetweenSegments(e.a, e.b, seg.a, seg.b)
+			return p1.Sub(p2).Length()
+		}, best)
+	}
+	return best, true
+}
+
+// segmentsIntersect checks if the line segment from a to b intersects with
+// the line segment from c to d. Either segment may be degenerate (i.e. a
+// point).
+func segmentsIntersect(a, b, c, d XY) bool {
+	switch {
+	case a == b:
+		return xyOnSegment(a, c, d)
+	case c == d:
+		return xyOnSegment(c, a, b)
+	default:
+		return !intersectLineWithLineNoAlloc(
+			Line{Coordinates{XY: a}, Coordinates{XY: b}},
+			Line{Coordinates{XY: c}, Coordinates{XY: d}},
+		).empty
+	}
+}
@@ -0,0 +1,194 @@
+package geom_test
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestPreparedGeometry(t *testing.T) {
+	const (
+		square   = "POLYGON((0 0,4 0,4 4,0 4,0 0))"
+		withHole = "POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,3 1,3 3,1 3,1 1))"
+		line     = "LINESTRING(0 0,2 0,2 2)"
+
+		// The polygons overlap in the square from (2 2) to (4 4).
+		overlapping = "GEOMETRYCOLLECTION(POLYGON((0 0,4 0,4 4,0 4,0 0)),POLYGON((2 2,6 2,6 6,2 6,2 2)))"
+	)
+	for i, tt := range []struct {
+		prep, other                  string
+		intersects, contains, covers bool
+		dist                         float64
+	}{
+		{square, "POINT(2 2)", true, true, true, 0},
+		{square, "POINT(4 2)", true, false, true, 0},
+		{square, "POINT(5 2)", false, false, false, 1},
+		{square, "POINT EMPTY", false, false, false, 0},
+		{square, "MULTIPOINT(1 1,4 4)", true, true, true, 0},
+		{square, "MULTIPOINT(0 0,4 4)", true, false, true, 0},
+		{square, "MULTIPOINT(1 1,5 5)", true, false, false, 0},
+		{square, "LINESTRING(1 1,2 2)", true, true, true, 0},
+		{square, "LINESTRING(0 0,4 0)", true, false, true, 0},
+		{square, "LINESTRING(1 1,5 1)", true, false, false, 0},
+		{square, "LINESTRING(5 0,5 4)", false, false, false, 1},
+		{square, "POLYGON((1 1,2 1,2 2,1 2,1 1))", true, true, true, 0},
+		{square, "POLYGON((0 0,4 0,4 4,0 4,0 0))", true, true, true, 0},
+		{square, "POLYGON((-1 -1,5 -1,5 5,-1 5,-1 -1))", true, false, false, 0},
+		{withHole, "POINT(2 2)", false, false, false, 1},
+		{withHole, "POINT(0.5 0.5)", true, true, true, 0},
+		{withHole, "POINT(1 2)", true, false, true, 0},
+		{withHole, "LINESTRING(0.5 0.5,3.5 0.5)", true, true, true, 0},
+		{withHole, "LINESTRING(0.5 2,3.5 2)", true, false, false, 0},
+		{withHole, "POLYGON((0.5 0.5,3.5 0.5,3.5 3.5,0.5 3.5,0.5 0.5))", true, false, false, 0},
+		{withHole, "POLYGON((1.5 1.5,2.5 1.5,2.5 2.5,1.5 2.5,1.5 1.5))", false, false, false, 0.5},
+		{line, "POINT(1 0)", true, true, true, 0},
+		{line, "POINT(0 0)", true, false, true, 0},
+		{line, "POINT(2 0)", true, true, true, 0},
+		{line, "POINT(1 1)", false, false, false, 1},
+		{line, "LINESTRING(0 0,2 0)", true, true, true, 0},
+		{line, "POLYGON((1 -1,3 -1,3 1,1 1,1 -1))", true, false, false, 0},
+		{"MULTIPOINT(0 0,1 1)", "POINT(1 1)", true, true, true, 0},
+		{"MULTIPOINT(0 0,1 1)", "POINT(1 2)", false, false, false, 1},
+		{"GEOMETRYCOLLECTION(POINT(10 10),POLYGON((0 0,1 0,1 1,0 1,0 0)))", "POINT(10 10)", true, true, true, 0},
+		{overlapping, "POINT(3 3)", true, true, true, 0},
+		{overlapping, "POINT(2 3)", true, true, true, 0},
+		{overlapping, "POINT(0 3)", true, false, true, 0},
+		{overlapping, "POINT(7 3)", false, false, false, 1},
+		{overlapping, "LINESTRING(2.5 3,3.5 3)", true, true, true, 0},
+		{overlapping, "LINESTRING(1 1,5 5)", true, true, true, 0},
+		{overlapping, "POLYGON((2.5 2.5,3.5 2.5,3.5 3.5,2.5 2.5))", true, true, true, 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.prep)
+			other := geomFromWKT(t, tt.other)
+			p := Prepare(g)
+			expectGeomEq(t, p.Geometry(), g)
+			expectBoolEq(t, p.Intersects(other), tt.intersects)
+			contains, err := p.Contains(other)
+			expectNoErr(t, err)
+			expectBoolEq(t, contains, tt.contains)
+			covers, err := p.Covers(other)
+			expectNoErr(t, err)
+			expectBoolEq(t, covers, tt.covers)
+			dist, ok := p.Distance(other)
+			expectBoolEq(t, ok, !other.IsEmpty())
+			if math.Abs(dist-tt.dist) > 1e-15 {
+				t.Errorf("want distance %v but got %v", tt.dist, dist)
+			}
+
+			// The unprepared geometry should give the same results.
+			expectBoolEq(t, g.Intersects(other), tt.intersects)
+			unpreparedDist, _ := g.Distance(other)
+			if math.Abs(unpreparedDist-tt.dist) > 1e-15 {
+				t.Errorf("want unprepared distance %v but got %v", tt.dist, unpreparedDist)
+			}
+		})
+	}
+}
+
+func TestPreparedGeometryMatchesUnprepared(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	randomXY := func() XY {
+		return XY{X: float64(rnd.Intn(7)), Y: float64(rnd.Intn(7))}
+	}
+	var randomGeom func() Geometry
+	randomGeom = func() Geometry {
+		switch rnd.Intn(5) {
+		case 0:
+			return NewPointXY(randomXY()).AsGeometry()
+		case 1:
+			return NewMultiPointXY([]XY{randomXY(), randomXY()}).AsGeometry()
+		case 2:
+			a, b := randomXY(), randomXY()
+			if a == b {
+				b.X++
+			}
+			ls, err := NewLineStringXY([]XY{a, b, b.Add(XY{X: 1})})
+			expectNoErr(t, err)
+			return ls.AsGeometry()
+		case 3:
+			// The polygons in a GeometryCollection may overlap.
+			return NewGeometryCollection([]Geometry{randomGeom(), randomGeom()}).AsGeometry()
+		default:
+			min := randomXY()
+			w, h := float64(1+rnd.Intn(4)), float64(1+rnd.Intn(4))
+			shell := []XY{min, min.Add(XY{X: w}), min.Add(XY{X: w, Y: h}), min.Add(XY{Y: h}), min}
+			rings := [][]XY{shell}
+			if w > 2 && h > 2 && rnd.Intn(2) == 0 {
+				c := min.Add(XY{X: 1, Y: 1})
+				rings = append(rings, []XY{c, c.Add(XY{X: 1}), c.Add(XY{X: 1, Y: 1}), c.Add(XY{Y: 1}), c})
+			}
+			poly, err := NewPolygonXY(rings)
+			expectNoErr(t, err)
+			return poly.AsGeometry()
+		}
+	}
+
+	for i := 0; i < 2000; i++ {
+		g1, g2 := randomGeom(), randomGeom()
+		p := Prepare(g1)
+		if got, want := p.Intersects(g2), g1.Intersects(g2); got != want {
+			t.Errorf("Intersects(%v, %v): got %v want %v", g1.AsText(), g2.AsText(), got, want)
+		}
+		for _, pred := range []struct {
+			name     string
+			prepared func(Geometry) (bool, error)
+			plain    func(a, b Geometry) (bool, error)
+		}{
+			{"Contains", p.Contains, Contains},
+			{"Covers", p.Covers, Covers},
+		} {
+			got, err := pred.prepared(g2)
+			expectNoErr(t, err)
+			want, err := pred.plain(g1, g2)
+			expectNoErr(t, err)
+			if got != want {
+				t.Errorf("%s(%v, %v): got %v want %v", pred.name, g1.AsText(), g2.AsText(), got, want)
+			}
+		}
+		gotDist, _ := p.Distance(g2)
+		wantDist, _ := g1.Distance(g2)
+		if math.Abs(gotDist-wantDist) > 1e-12 {
+			t.Errorf("Distance(%v, %v): got %v want %v", g1.AsText(), g2.AsText(), gotDist, wantDist)
+		}
+	}
+}
+
+func BenchmarkPreparedPointInPolygon(b *testing.B) {
+	for _, sz := range []int{10, 100, 1000, 10000} {
+		b.Run("n="+strconv.Itoa(sz), func(b *testing.B) {
+			ring := make([]XY, sz+1)
+			for i := 0; i < sz; i++ {
+				angle := 2 * math.Pi * float64(i) / float64(sz)
+				ring[i] = XY{X: math.Cos(angle), Y: math.Sin(angle)}
+			}
+			ring[sz] = ring[0]
+			poly, err := NewPolygonXY([][]XY{ring})
+			if err != nil {
+				b.Fatal(err)
+			}
+			pt := NewPointXY(XY{X: 0.5, Y: 0.1}).AsGeometry()
+
+			b.Run("prepared", func(b *testing.B) {
+				p := Prepare(poly.AsGeometry())
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if !p.Intersects(pt) {
+						b.Fatal("expected to intersect")
+					}
+				}
+			})
+			b.Run("unprepared", func(b *testing.B) {
+				g := poly.AsGeometry()
+				for i := 0; i < b.N; i++ {
+					if !g.Intersects(pt) {
+						b.Fatal("expected to intersect")
+					}
+				}
+			})
+		})
+	}
+}
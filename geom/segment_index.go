@@ -0,0 +1,120 @@
+package geom
+
+import "sort"
+
+// segmentIndex is a static bounding volume hierarchy over a set of line
+// segments (where points are represented as segments with equal endpoints).
+// It's used internally by the geom package to avoid scanning every segment
+// of a geometry for each query. (The rtree package can't be used here, since
+// it depends on the geom package).
+type segmentIndex struct {
+	segs []indexedSegment
+	root *segmentIndexNode
+}
+
+type indexedSegment struct {
+	a, b XY
+	env  Envelope
+}
+
+type segmentIndexNode struct {
+	env      Envelope
+	children [2]*segmentIndexNode
+	items    []int // only populated in leaf nodes
+}
+
+const segmentIndexLeafSize = 8
+
+func newSegmentIndex(segs []indexedSegment) segmentIndex {
+	if len(segs) == 0 {
+		return segmentIndex{}
+	}
+	ids := make([]int, len(segs))
+	for i := range ids {
+		ids[i] = i
+	}
+	return segmentIndex{segs, buildSegmentIndexNode(segs, ids)}
+}
+
+// buildSegmentIndexNode builds a node containing the given segments. Nodes
+// are split in half along the longer axis of their envelope.
+func buildSegmentIndexNode(segs []indexedSegment, ids []int) *segmentIndexNode {
+	env := segs[ids[0]].env
+	for _, id := range ids[1:] {
+		env = env.ExpandToIncludeEnvelope(segs[id].env)
+	}
+	if len(ids) <= segmentIndexLeafSize {
+		return &segmentIndexNode{env: env, items: ids}
+	}
+
+	key := func(id int) float64 { return segs[id].env.Center().X }
+	if env.Height() > env.Width() {
+		key = func(id int) float64 { return segs[id].env.Center().Y }
+	}
+	sort.Slice(ids, func(i, j int) bool { return key(ids[i]) < key(ids[j]) })
+	mid := len(ids) / 2
+	return &segmentIndexNode{
+		env: env,
+		children: [2]*segmentIndexNode{
+			buildSegmentIndexNode(segs, ids[:mid]),
+			buildSegmentIndexNode(segs, ids[mid:]),
+		},
+	}
+}
+
+// search calls fn for each segment whose envelope intersects with env. The
+// search stops early if fn returns true, in which case search also returns
+// true.
+func (s segmentIndex) search(env Envelope, fn func(i int) bool) bool {
+	if s.root == nil {
+		return false
+	}
+	return s.root.search(env, fn)
+}
+
+func (n *segmentIndexNode) search(env Envelope, fn func(i int) bool) bool {
+	if !n.env.Intersects(env) {
+		return false
+	}
+	if n.children[0] == nil {
+		for _, id := range n.items {
+			if fn(id) {
+				return true
+			}
+		}
+		return false
+	}
+	return n.children[0].search(env, fn) || n.children[1].search(env, fn)
+}
+
+// nearest finds the smallest distance (given by distFn) between an object
+// with the given envelope and the segments in the index. The search is pruned
+// using envelope distances, so distFn must never give a smaller distance than
+// the distance between the object's envelope and the segment's envelope.
+// Only distances smaller than best are considered.
+func (s segmentIndex) nearest(env Envelope, distFn func(i int) float64, best float64) float64 {
+	if s.root == nil {
+		return best
+	}
+	return s.root.nearest(env, distFn, best)
+}
+
+func (n *segmentIndexNode) nearest(env Envelope, distFn func(i int) float64, best float64) float64 {
+	if n.env.Distance(env) >= best {
+		return best
+	}
+	if n.children[0] == nil {
+		for _, id := range n.items {
+			if d := distFn(id); d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	first, second := n.children[0], n.children[1]
+	if second.env.Distance(env) < first.env.Distance(env) {
+		first, second = second, first
+	}
+	best = first.nearest(env, distFn, best)
+	return second.nearest(env, distFn, best)
+}
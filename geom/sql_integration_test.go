@@ -40,4 +40,22 @@ func TestIntegrationValuerScanner(t *testing.T) {
 		}
 		expectGeomEq(t, output, input)
 	})
+	t.Run("input has SRID", func(t *testing.T) {
+		input := input.WithSRID(4326)
+		var output Geometry
+		if err := db.QueryRow("SELECT ST_AsEWKB(ST_GeomFromEWKB($1))", input).Scan(&output); err != nil {
+			t.Fatal(err)
+		}
+		expectGeomEq(t, output, input)
+		expectIntEq(t, output.SRID(), 4326)
+	})
+	t.Run("output is hex encoded", func(t *testing.T) {
+		input := input.WithSRID(4326)
+		var output Geometry
+		if err := db.QueryRow("SELECT ST_GeomFromEWKB($1)", input).Scan(&output); err != nil {
+			t.Fatal(err)
+		}
+		expectGeomEq(t, output, input)
+		expectIntEq(t, output.SRID(), 4326)
+	})
 }
@@ -2,7 +2,9 @@ package geom_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"strconv"
+	"strings"
 	"testing"
 
 	. "github.com/peterstace/simplefeatures/geom"
@@ -40,6 +42,67 @@ func TestScanner(t *testing.T) {
 		g = Geometry{}
 		check(t, g.Scan([]byte(wkb.Bytes())))
 	})
+	t.Run("hex string", func(t *testing.T) {
+		g = Geometry{}
+		check(t, g.Scan(hex.EncodeToString(wkb.Bytes())))
+	})
+	t.Run("hex byte", func(t *testing.T) {
+		g = Geometry{}
+		check(t, g.Scan([]byte(hex.EncodeToString(wkb.Bytes()))))
+	})
+	t.Run("upper case hex string", func(t *testing.T) {
+		g = Geometry{}
+		check(t, g.Scan(strings.ToUpper(hex.EncodeToString(wkb.Bytes()))))
+	})
+}
+
+func TestScannerInvalidHex(t *testing.T) {
+	var g Geometry
+	if err := g.Scan("0101000000zz"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestScannerHexEWKB(t *testing.T) {
+	// SELECT ST_GeomFromEWKT('SRID=4326;POINT(1 2)')::text
+	const hexEWKB = "0101000020E6100000000000000000F03F0000000000000040"
+	var g Geometry
+	expectNoErr(t, g.Scan(hexEWKB))
+	expectGeomEq(t, g, geomFromWKT(t, "POINT(1 2)"))
+	expectIntEq(t, g.SRID(), 4326)
+}
+
+func TestNullGeometryScanner(t *testing.T) {
+	t.Run("null", func(t *testing.T) {
+		ng := NullGeometry{Valid: true}
+		expectNoErr(t, ng.Scan(nil))
+		expectBoolEq(t, ng.Valid, false)
+	})
+	t.Run("hex", func(t *testing.T) {
+		var ng NullGeometry
+		expectNoErr(t, ng.Scan("0101000000000000000000F03F0000000000000040"))
+		expectBoolEq(t, ng.Valid, true)
+		expectGeomEq(t, ng.Geometry, geomFromWKT(t, "POINT(1 2)"))
+	})
+}
+
+func TestValuerScannerSRID(t *testing.T) {
+	g := geomFromWKT(t, "POINT Z (1 2 3)").WithSRID(4326)
+	val, err := g.Value()
+	expectNoErr(t, err)
+	var scanned Geometry
+	expectNoErr(t, scanned.Scan(val))
+	expectGeomEq(t, scanned, g)
+	expectIntEq(t, scanned.SRID(), 4326)
+}
+
+func TestScannerEWKB(t *testing.T) {
+	// SELECT ST_AsEWKB(ST_GeomFromEWKT('SRID=4326;POINT(1 2)'))
+	ewkb := hexStringToBytes(t, "0101000020e6100000000000000000f03f0000000000000040")
+	var g Geometry
+	expectNoErr(t, g.Scan(ewkb))
+	expectGeomEq(t, g, geomFromWKT(t, "POINT(1 2)"))
+	expectIntEq(t, g.SRID(), 4326)
 }
 
 func TestValuerConcrete(t *testing.T) {
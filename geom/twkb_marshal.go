@@ -0,0 +1,401 @@
+package geom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TWKB metadata header flags.
+const (
+	twkbHasBBox     = 0x01
+	twkbHasSize     = 0x02
+	twkbHasIDList   = 0x04
+	twkbHasExtended = 0x08
+	twkbIsEmpty     = 0x10
+)
+
+// twkbMaxPrecision is the maximum number of decimal digits that can be
+// retained for each coordinate.
+const twkbMaxPrecision = 7
+
+// TWKBOption allows the behaviour of AsTWKB to be modified.
+type TWKBOption func(s *twkbOptionSet)
+
+type twkbOptionSet struct {
+	precZ, precM int
+	size, bbox   bool
+	ids          []int64
+}
+
+func newTWKBOptionSet(opts []TWKBOption) twkbOptionSet {
+	var s twkbOptionSet
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+// TWKBPrecisionZ sets the number of decimal digits retained for Z values (0
+// to 7). The default is 0.
+func TWKBPrecisionZ(p int) TWKBOption {
+	return func(s *twkbOptionSet) {
+		s.precZ = p
+	}
+}
+
+// TWKBPrecisionM sets the number of decimal digits retained for M values (0
+// to 7). The default is 0.
+func TWKBPrecisionM(p int) TWKBOption {
+	return func(s *twkbOptionSet) {
+		s.precM = p
+	}
+}
+
+// TWKBIncludeSize causes the size (in bytes) of each geometry to be included
+// in its header, which allows readers to skip over geometries.
+var TWKBIncludeSize = TWKBOption(
+	func(s *twkbOptionSet) {
+		s.size = true
+	},
+)
+
+// TWKBIncludeBBox causes the bounding box of each geometry to be included in
+// its header.
+var TWKBIncludeBBox = TWKBOption(
+	func(s *twkbOptionSet) {
+		s.bbox = true
+	},
+)
+
+// TWKBIDList sets the IDs of the members of a multi geometry or
+// GeometryCollection. There must be one ID per member.
+func TWKBIDList(ids []int64) TWKBOption {
+	return func(s *twkbOptionSet) {
+		s.ids = ids
+	}
+}
+
+// AsTWKB returns the Tiny Well Known Binary (TWKB) representation of a
+// geometry. TWKB is a compact format that stores coordinates as varint
+// encoded deltas from the previous coordinate. The precision is the number
+// of decimal digits retained for X and Y values (from -8 to 7, where negative
+// values round to powers of 10).
+//
+// Repeated points (after rounding to the precision) are omitted, except
+// where they're needed to retain the minimum number of points in a
+// LineString (2) or ring (4). This means that geometries may collapse when
+// rounded (e.g. a short LineString may become a LineString with two equal
+// points), or become invalid in other ways (e.g. a ring may self-intersect).
+// Such results can only be read back using the DisableAllValidations
+// constructor option. The encoding is compatible with PostGIS (see ST_AsTWKB
+// and ST_GeomFromTWKB).
+func AsTWKB(g Geometry, precisionXY int, opts ...TWKBOption) ([]byte, error) {
+	os := newTWKBOptionSet(opts)
+	if precisionXY < -8 || precisionXY > twkbMaxPrecision {
+		return nil, fmt.Errorf("XY precision out of range: %d", precisionXY)
+	}
+	if os.precZ < 0 || os.precZ > twkbMaxPrecision || os.precM < 0 || os.precM > twkbMaxPrecision {
+		return nil, errors.New("Z and M precision must be between 0 and 7")
+	}
+	m := twkbMarshaller{opts: os, precXY: precisionXY}
+	var ids []int64
+	if os.ids != nil {
+		n, ok := twkbNumMembers(g)
+		if !ok || n != len(os.ids) {
+			return nil, errors.New("TWKB ID list must have one ID per member of a multi geometry or GeometryCollection")
+		}
+		ids = os.ids
+	}
+	buf, _ := m.writeGeometry(nil, g, ids)
+	return buf, m.err
+}
+
+func twkbNumMembers(g Geometry) (int, bool) {
+	switch {
+	case g.IsMultiPoint():
+		return g.AsMultiPoint().NumPoints(), true
+	case g.IsMultiLineString():
+		return g.AsMultiLineString().NumLineStrings(), true
+	case g.IsMultiPolygon():
+		return g.AsMultiPolygon().NumPolygons(), true
+	case g.IsGeometryCollection():
+		return g.AsGeometryCollection().NumGeometries(), true
+	default:
+		return 0, false
+	}
+}
+
+type twkbMarshaller struct {
+	opts   twkbOptionSet
+	precXY int
+	err    error
+}
+
+func (m *twkbMarshaller) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// twkbGeomType gives the TWKB geometry type of a geometry. Empty sets are
+// typed according to their dimension.
+func twkbGeomType(g Geometry) uint32 {
+	switch {
+	case g.IsPoint():
+		return wkbGeomTypePoint
+	case g.IsLine(), g.IsLineString():
+		return wkbGeomTypeLineString
+	case g.IsPolygon():
+		return wkbGeomTypePolygon
+	case g.IsMultiPoint():
+		return wkbGeomTypeMultiPoint
+	case g.IsMultiLineString():
+		return wkbGeomTypeMultiLineString
+	case g.IsMultiPolygon():
+		return wkbGeomTypeMultiPolygon
+	case g.IsGeometryCollection():
+		return wkbGeomTypeGeometryCollection
+	default:
+		switch g.AsEmptySet().Dimension() {
+		case 0:
+			return wkbGeomTypePoint
+		case 1:
+			return wkbGeomTypeLineString
+		default:
+			return wkbGeomTypePolygon
+		}
+	}
+}
+
+// twkbBody accumulates the body of a single TWKB geometry (i.e. everything
+// after the header), along with the state needed for delta encoding.
+type twkbBody struct {
+	buf     []byte
+	factors []float64 // one per dimension
+	prev    [4]int64
+	min     [4]int64
+	max     [4]int64
+	any     bool // true once a point has been written
+}
+
+func (b *twkbBody) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	b.buf = append(b.buf, tmp[:n]...)
+}
+
+func (b *twkbBody) varint(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	b.buf = append(b.buf, tmp[:n]...)
+}
+
+func (b *twkbBody) expandBBox(vals []int64) {
+	for i, v := range vals {
+		if !b.any || v < b.min[i] {
+			b.min[i] = v
+		}
+		if !b.any || v > b.max[i] {
+			b.max[i] = v
+		}
+	}
+	b.any = true
+}
+
+// quantise converts coordinates to the integer values that are stored.
+func (m *twkbMarshaller) quantise(c Coordinates, factors []float64) []int64 {
+	vals := make([]int64, len(factors))
+	for i, f := range factors {
+		var v float64
+		switch i {
+		case 0:
+			v = c.X
+		case 1:
+			v = c.Y
+		case 2:
+			if c.Type.Is3D() {
+				v = c.Z
+			} else {
+				v = c.M
+			}
+		default:
+			v = c.M
+		}
+		q := math.Round(v * f)
+		if !(math.Abs(q) < 1<<62) { // also catches NaN
+			m.setErr(fmt.Errorf("coordinate can't be represented in TWKB: %v", v))
+			return vals
+		}
+		vals[i] = int64(q)
+	}
+	return vals
+}
+
+// writePoints writes a sequence of points. The number of points is written
+// first, unless it's implied (as it is for Points).
+func (m *twkbMarshaller) writePoints(b *twkbBody, coords []Coordinates, minPoints int, writeCount bool) {
+	quantised := make([][]int64, len(coords))
+	for i, c := range coords {
+		quantised[i] = m.quantise(c, b.factors)
+	}
+
+	// Points that are the same as the previous point (once quantised) are
+	// skipped, but only while more than the minimum number of points remain.
+	// Once that's no longer the case, all remaining points are kept (even
+	// if they're repeated). This matches the behaviour of PostGIS.
+	keep := make([]bool, len(quantised))
+	numKept := len(quantised)
+	for i, q := range quantised {
+		keep[i] = i == 0 || !int64sEqual(q, quantised[i-1]) || numKept <= minPoints
+		if !keep[i] {
+			numKept--
+		}
+	}
+
+	if writeCount {
+		b.uvarint(uint64(numKept))
+	}
+	for i, q := range quantised {
+		if !keep[i] {
+			continue
+		}
+		for j, v := range q {
+			b.varint(v - b.prev[j])
+			b.prev[j] = v
+		}
+		b.expandBBox(q)
+	}
+}
+
+func int64sEqual(a, b []int64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *twkbMarshaller) writeIDs(b *twkbBody, ids []int64) {
+	for _, id := range ids {
+		b.varint(id)
+	}
+}
+
+// writeGeometry appends a geometry (including its header). It also gives the
+// geometry's body, since the bounding box of a GeometryCollection includes
+// the bounding boxes of its children.
+func (m *twkbMarshaller) writeGeometry(dst []byte, g Geometry, ids []int64) ([]byte, *twkbBody) {
+	ctype := g.CoordinatesType()
+	b := &twkbBody{factors: []float64{math.Pow10(m.precXY), math.Pow10(m.precXY)}}
+	if ctype.Is3D() {
+		b.factors = append(b.factors, math.Pow10(m.opts.precZ))
+	}
+	if ctype.IsMeasured() {
+		b.factors = append(b.factors, math.Pow10(m.opts.precM))
+	}
+
+	switch {
+	case g.IsEmpty():
+	case g.IsPoint():
+		m.writePoints(b, []Coordinates{g.AsPoint().Coordinates()}, 1, false)
+	case g.IsLine():
+		m.writePoints(b, g.AsLine().Coordinates(), 2, true)
+	case g.IsLineString():
+		m.writePoints(b, g.AsLineString().Coordinates(), 2, true)
+	case g.IsPolygon():
+		m.writeRings(b, g.AsPolygon().Coordinates())
+	case g.IsMultiPoint():
+		coords := g.AsMultiPoint().Coordinates()
+		b.uvarint(uint64(len(coords)))
+		m.writeIDs(b, ids)
+		for _, c := range coords {
+			m.writePoints(b, []Coordinates{c}, 1, false)
+		}
+	case g.IsMultiLineString():
+		lss := g.AsMultiLineString().Coordinates()
+		b.uvarint(uint64(len(lss)))
+		m.writeIDs(b, ids)
+		for _, ls := range lss {
+			m.writePoints(b, ls, 2, true)
+		}
+	case g.IsMultiPolygon():
+		polys := g.AsMultiPolygon().Coordinates()
+		b.uvarint(uint64(len(polys)))
+		m.writeIDs(b, ids)
+		for _, rings := range polys {
+			m.writeRings(b, rings)
+		}
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		n := gc.NumGeometries()
+		b.uvarint(uint64(n))
+		m.writeIDs(b, ids)
+		for i := 0; i < n; i++ {
+			var child *twkbBody
+			b.buf, child = m.writeGeometry(b.buf, gc.GeometryN(i), nil)
+			if child.any {
+				b.expandBBox(child.min[:len(b.factors)])
+				b.expandBBox(child.max[:len(b.factors)])
+			}
+		}
+	}
+
+	metadata := byte(0)
+	if g.IsEmpty() {
+		metadata |= twkbIsEmpty
+	}
+	if m.opts.bbox && !g.IsEmpty() {
+		metadata |= twkbHasBBox
+	}
+	if m.opts.size {
+		metadata |= twkbHasSize
+	}
+	if len(ids) > 0 && !g.IsEmpty() {
+		metadata |= twkbHasIDList
+	}
+	if ctype != DimXY {
+		metadata |= twkbHasExtended
+	}
+
+	typeAndPrec := byte(twkbGeomType(g)) | byte(zigzag(int64(m.precXY)))<<4
+	dst = append(dst, typeAndPrec, metadata)
+	if ctype != DimXY {
+		ext := byte(0)
+		if ctype.Is3D() {
+			ext |= 0x01 | byte(m.opts.precZ)<<2
+		}
+		if ctype.IsMeasured() {
+			ext |= 0x02 | byte(m.opts.precM)<<5
+		}
+		dst = append(dst, ext)
+	}
+
+	var rest twkbBody
+	if metadata&twkbHasBBox != 0 {
+		for i := range b.factors {
+			rest.varint(b.min[i])
+			rest.varint(b.max[i] - b.min[i])
+		}
+	}
+	rest.buf = append(rest.buf, b.buf...)
+	if m.opts.size {
+		var tmp [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(tmp[:], uint64(len(rest.buf)))
+		dst = append(dst, tmp[:n]...)
+	}
+	return append(dst, rest.buf...), b
+}
+
+func (m *twkbMarshaller) writeRings(b *twkbBody, rings [][]Coordinates) {
+	b.uvarint(uint64(len(rings)))
+	for _, r := range rings {
+		m.writePoints(b, r, 4, true)
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
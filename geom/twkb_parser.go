@@ -0,0 +1,293 @@
+package geom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// UnmarshalTWKB reads the Tiny Well Known Binary (TWKB), and returns the
+// corresponding Geometry. Any ID list is ignored (UnmarshalTWKBWithIDs can
+// be used to read the ID list).
+func UnmarshalTWKB(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
+	g, _, err := UnmarshalTWKBWithIDs(r, opts...)
+	return g, err
+}
+
+// UnmarshalTWKBWithIDs reads the Tiny Well Known Binary (TWKB), and returns
+// the corresponding Geometry along with the IDs of its members (if the TWKB
+// contains an ID list).
+//
+// Only the bytes making up the TWKB are read from the reader, so it may be
+// used to read a sequence of TWKB geometries.
+func UnmarshalTWKBWithIDs(r io.Reader, opts ...ConstructorOption) (Geometry, []int64, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &twkbByteReader{r: r}
+	}
+	p := twkbParser{r: br, opts: opts}
+	g := p.parse()
+	if p.err != nil {
+		return Geometry{}, nil, p.err
+	}
+	return g, p.ids, nil
+}
+
+// twkbByteReader reads one byte at a time from an io.Reader.
+type twkbByteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (r *twkbByteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(r.r, r.buf[:])
+	return r.buf[0], err
+}
+
+type twkbParser struct {
+	err      error
+	r        io.ByteReader
+	opts     []ConstructorOption
+	geomType uint32
+	ctype    CoordinatesType
+	factors  []float64 // one per dimension
+	prev     [4]int64
+	ids      []int64
+}
+
+func (p *twkbParser) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *twkbParser) readByte() byte {
+	if p.err != nil {
+		return 0
+	}
+	b, err := p.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	p.setErr(err)
+	return b
+}
+
+func (p *twkbParser) readUvarint() uint64 {
+	if p.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(p.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	p.setErr(err)
+	return v
+}
+
+func (p *twkbParser) readVarint() int64 {
+	if p.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(p.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	p.setErr(err)
+	return v
+}
+
+func (p *twkbParser) parse() Geometry {
+	typeAndPrec := p.readByte()
+	metadata := p.readByte()
+	if p.err != nil {
+		return Geometry{}
+	}
+	p.geomType = uint32(typeAndPrec & 0x0f)
+	precXY := unzigzag(uint64(typeAndPrec >> 4))
+	p.factors = []float64{math.Pow10(int(precXY)), math.Pow10(int(precXY))}
+
+	p.ctype = DimXY
+	if metadata&twkbHasExtended != 0 {
+		ext := p.readByte()
+		if ext&0x01 != 0 {
+			p.ctype |= DimXYZ
+			p.factors = append(p.factors, math.Pow10(int(ext>>2&0x07)))
+		}
+		if ext&0x02 != 0 {
+			p.ctype |= DimXYM
+			p.factors = append(p.factors, math.Pow10(int(ext>>5&0x07)))
+		}
+	}
+	if metadata&twkbHasSize != 0 {
+		p.readUvarint()
+	}
+	if metadata&twkbIsEmpty != 0 {
+		return p.emptyGeometry()
+	}
+	if metadata&twkbHasBBox != 0 {
+		for range p.factors {
+			p.readVarint() // min
+			p.readVarint() // delta
+		}
+	}
+	if p.err != nil {
+		return Geometry{}
+	}
+
+	g := p.parseBody(metadata&twkbHasIDList != 0)
+	if p.err != nil {
+		return Geometry{}
+	}
+	return g
+}
+
+func (p *twkbParser) emptyGeometry() Geometry {
+	var g Geometry
+	switch p.geomType {
+	case wkbGeomTypePoint:
+		g = NewEmptyPoint(p.opts...).AsGeometry()
+	case wkbGeomTypeLineString:
+		g = NewEmptyLineString(p.opts...).AsGeometry()
+	case wkbGeomTypePolygon:
+		g = NewEmptyPolygon(p.opts...).AsGeometry()
+	case wkbGeomTypeMultiPoint:
+		g = MultiPoint{}.AsGeometry()
+	case wkbGeomTypeMultiLineString:
+		g = MultiLineString{}.AsGeometry()
+	case wkbGeomTypeMultiPolygon:
+		g = MultiPolygon{}.AsGeometry()
+	case wkbGeomTypeGeometryCollection:
+		g = GeometryCollection{}.AsGeometry()
+	default:
+		p.setErr(fmt.Errorf("unknown geometry type: %d", p.geomType))
+		return Geometry{}
+	}
+	return g.ForceCoordinatesType(p.ctype)
+}
+
+func (p *twkbParser) parseBody(hasIDs bool) Geometry {
+	switch p.geomType {
+	case wkbGeomTypePoint:
+		return NewPointC(p.parsePoint(), p.opts...).AsGeometry()
+	case wkbGeomTypeLineString:
+		coords := p.parsePoints()
+		if len(coords) == 2 {
+			ln, err := NewLineC(coords[0], coords[1], p.opts...)
+			p.setErr(err)
+			return ln.AsGeometry()
+		}
+		ls, err := NewLineStringC(coords, p.opts...)
+		p.setErr(err)
+		return ls.AsGeometry()
+	case wkbGeomTypePolygon:
+		poly, err := NewPolygonC(p.parseRings(), p.opts...)
+		p.setErr(err)
+		return poly.AsGeometry()
+	case wkbGeomTypeMultiPoint:
+		n := p.parseCount(hasIDs)
+		var coords []Coordinates
+		for i := uint64(0); i < n && p.err == nil; i++ {
+			coords = append(coords, p.parsePoint())
+		}
+		return NewMultiPointC(coords, p.opts...).AsGeometry()
+	case wkbGeomTypeMultiLineString:
+		n := p.parseCount(hasIDs)
+		var coords [][]Coordinates
+		for i := uint64(0); i < n && p.err == nil; i++ {
+			coords = append(coords, p.parsePoints())
+		}
+		mls, err := NewMultiLineStringC(coords, p.opts...)
+		p.setErr(err)
+		return mls.AsGeometry()
+	case wkbGeomTypeMultiPolygon:
+		n := p.parseCount(hasIDs)
+		var coords [][][]Coordinates
+		for i := uint64(0); i < n && p.err == nil; i++ {
+			coords = append(coords, p.parseRings())
+		}
+		mp, err := NewMultiPolygonC(coords, p.opts...)
+		p.setErr(err)
+		return mp.AsGeometry()
+	case wkbGeomTypeGeometryCollection:
+		n := p.parseCount(hasIDs)
+		var geoms []Geometry
+		for i := uint64(0); i < n && p.err == nil; i++ {
+			geoms = append(geoms, p.parseChild())
+		}
+		return NewGeometryCollection(geoms, p.opts...).AsGeometry()
+	default:
+		p.setErr(fmt.Errorf("unknown geometry type: %d", p.geomType))
+		return Geometry{}
+	}
+}
+
+// parseCount parses the number of members of a multi geometry or
+// GeometryCollection, followed by the ID list (if there is one).
+func (p *twkbParser) parseCount(hasIDs bool) uint64 {
+	n := p.readUvarint()
+	if hasIDs {
+		for i := uint64(0); i < n && p.err == nil; i++ {
+			p.ids = append(p.ids, p.readVarint())
+		}
+	}
+	return n
+}
+
+func (p *twkbParser) parsePoint() Coordinates {
+	var vals [4]float64
+	for i, f := range p.factors {
+		p.prev[i] += p.readVarint()
+		vals[i] = float64(p.prev[i]) / f
+	}
+	c := Coordinates{XY: XY{vals[0], vals[1]}, Type: p.ctype}
+	switch p.ctype {
+	case DimXYZ:
+		c.Z = vals[2]
+	case DimXYM:
+		c.M = vals[2]
+	case DimXYZM:
+		c.Z = vals[2]
+		c.M = vals[3]
+	}
+	return c
+}
+
+func (p *twkbParser) parsePoints() []Coordinates {
+	n := p.readUvarint()
+	var coords []Coordinates
+	for i := uint64(0); i < n && p.err == nil; i++ {
+		coords = append(coords, p.parsePoint())
+	}
+	return coords
+}
+
+func (p *twkbParser) parseRings() [][]Coordinates {
+	n := p.readUvarint()
+	var rings [][]Coordinates
+	for i := uint64(0); i < n && p.err == nil; i++ {
+		rings = append(rings, p.parsePoints())
+	}
+	return rings
+}
+
+// parseChild parses a geometry that is nested inside a GeometryCollection.
+// It has its own header, and its coordinates type must match that of the
+// parent.
+func (p *twkbParser) parseChild() Geometry {
+	if p.err != nil {
+		return Geometry{}
+	}
+	child := twkbParser{r: p.r, opts: p.opts}
+	g := child.parse()
+	p.setErr(child.err)
+	if child.err == nil && child.ctype != p.ctype {
+		p.setErr(errMixedCoordinatesTypes)
+	}
+	return g
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,265 @@
+package geom_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	. "github.com/peterstace/simplefeatures/geom"
+)
+
+func TestTWKBMarshalKnownValues(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		prec int
+		opts []TWKBOption
+		want string
+	}{
+		// Examples from the PostGIS documentation for ST_AsTWKB.
+		{"POINT(1 1)", 0, nil, "01000202"},
+		{"LINESTRING(1 1,5 5)", 0, nil, "02000202020808"},
+		{"MULTIPOINT((0 0),(1 1))", 0, []TWKBOption{TWKBIDList([]int64{1, 2})}, "040402020400000202"},
+
+		// Derived from the TWKB specification. The encodings are compared
+		// against PostGIS's ST_AsTWKB by CheckTWKB in the fuzztests package.
+
+		{"LINESTRING(1 1,5 5)", 0, []TWKBOption{TWKBIncludeSize, TWKBIncludeBBox}, "020309020802080202020808"},
+		{"POINT(12.34 -5.67)", 2, nil, "4100a413ed08"},
+		{"POINT(1234 -5678)", -2, nil, "31001871"},
+		{"POINT EMPTY", 0, nil, "0110"},
+		{"LINESTRING Z EMPTY", 0, nil, "021801"},
+		{"POINT Z (1 2 3)", 0, []TWKBOption{TWKBPrecisionZ(1)}, "01080502043c"},
+		{"POINT M (1 2 3)", 0, []TWKBOption{TWKBPrecisionM(1)}, "01082202043c"},
+		{"LINESTRING(0 0,0 0,1 1)", 0, nil, "02000200000202"},
+		{"GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(1 1,2 2))", 0, nil, "0700020100020202000202020202"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := AsTWKB(geomFromWKT(t, tt.wkt), tt.prec, tt.opts...)
+			expectNoErr(t, err)
+			expectStringEq(t, hex.EncodeToString(got), tt.want)
+		})
+	}
+}
+
+func TestTWKBRoundTrip(t *testing.T) {
+	for i, tt := range []struct {
+		wkt string
+	}{
+		{"POINT EMPTY"},
+		{"POINT Z EMPTY"},
+		{"POINT M EMPTY"},
+		{"POINT ZM EMPTY"},
+		{"POINT(1 2)"},
+		{"POINT(-1.5 2.25)"},
+		{"POINT Z (1 2 3)"},
+		{"POINT M (1 2 3)"},
+		{"POINT ZM (1 2 3 4)"},
+		{"LINESTRING EMPTY"},
+		{"LINESTRING(1 2,3 4)"},
+		{"LINESTRING(1 2,3 4,5 6)"},
+		{"LINESTRING ZM (1 2 3 4,5 6 7 8,9 10 11 12)"},
+		{"POLYGON EMPTY"},
+		{"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))"},
+		{"MULTIPOINT EMPTY"},
+		{"MULTIPOINT((1 2),(-3 -4))"},
+		{"MULTIPOINT Z ((1 2 3),(4 5 6))"},
+		{"MULTILINESTRING EMPTY"},
+		{"MULTILINESTRING((1 2,3 4,5 6),(7 8,9 10))"},
+		{"MULTIPOLYGON EMPTY"},
+		{"MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((2 2,3 2,2 3,2 2)))"},
+		{"GEOMETRYCOLLECTION EMPTY"},
+		{"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4),POLYGON((0 0,1 0,0 1,0 0)))"},
+		{"GEOMETRYCOLLECTION M (POINT M (1 2 3),MULTIPOINT M ((4 5 6)))"},
+		{"GEOMETRYCOLLECTION(GEOMETRYCOLLECTION(POINT(1 2)),MULTIPOINT((3 4)))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			for _, opts := range [][]TWKBOption{
+				nil,
+				{TWKBIncludeSize, TWKBIncludeBBox},
+			} {
+				want := geomFromWKT(t, tt.wkt)
+				buf, err := AsTWKB(want, 2, append(opts, TWKBPrecisionZ(2), TWKBPrecisionM(2))...)
+				expectNoErr(t, err)
+				got, err := UnmarshalTWKB(bytes.NewReader(buf))
+				expectNoErr(t, err)
+				expectGeomEq(t, got, want)
+			}
+		})
+	}
+}
+
+func TestTWKBPrecision(t *testing.T) {
+	for i, tt := range []struct {
+		input string
+		prec  int
+		opts  []TWKBOption
+		want  string
+	}{
+		{"POINT(1.23456 -1.23456)", 0, nil, "POINT(1 -1)"},
+		{"POINT(1.23456 -1.23456)", 3, nil, "POINT(1.235 -1.235)"},
+		{"POINT(1234.5 -6789.1)", -2, nil, "POINT(1200 -6800)"},
+		{"POINT Z (1 2 3.456)", 0, []TWKBOption{TWKBPrecisionZ(1)}, "POINT Z (1 2 3.5)"},
+		{"POINT M (1 2 3.456)", 0, []TWKBOption{TWKBPrecisionM(2)}, "POINT M (1 2 3.46)"},
+		{"LINESTRING(0 0,0.1 0.1,1 1)", 0, nil, "LINESTRING(0 0,1 1)"},
+		{"LINESTRING(0 0,0.1 0.1,1 1,1.1 1.1)", 0, nil, "LINESTRING(0 0,1 1)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf, err := AsTWKB(geomFromWKT(t, tt.input), tt.prec, tt.opts...)
+			expectNoErr(t, err)
+			got, err := UnmarshalTWKB(bytes.NewReader(buf))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, geomFromWKT(t, tt.want), Tolerance(1e-9))
+		})
+	}
+}
+
+func TestTWKBCollapsedByRounding(t *testing.T) {
+	// Repeated points are kept where they're needed for the minimum number
+	// of points in a LineString or ring, which matches PostGIS.
+	for i, tt := range []struct {
+		wkt  string
+		prec int
+		want string
+	}{
+		{"LINESTRING(0 0,0.1 0.1)", 0, "LINESTRING(0 0,0 0)"},
+		{"LINESTRING(0 0,0.1 0.1,0.2 0.2)", 0, "LINESTRING(0 0,0 0)"},
+		{"LINESTRING(0 0,0.1 0,5 0,5.1 0,5.2 0)", 0, "LINESTRING(0 0,5 0)"},
+		{"LINESTRING Z (0 0 0,0.1 0.1 10)", 0, "LINESTRING Z (0 0 0,0 0 10)"},
+		{"POLYGON((0 0,0.3 0,0 0.3,0 0))", 0, "POLYGON((0 0,0 0,0 0,0 0))"},
+		{"POLYGON((0 0,1 0,1 0.4,0 0))", 0, "POLYGON((0 0,1 0,1 0,0 0))"},
+		{
+			"MULTIPOLYGON(((0 0,10 0,0 10,0 0)),((20 20,20.1 20,20 20.1,20 20)))", 0,
+			"MULTIPOLYGON(((0 0,10 0,0 10,0 0)),((20 20,20 20,20 20,20 20)))",
+		},
+		{
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(50 50,51 51))", -2,
+			"GEOMETRYCOLLECTION(POINT(0 0),LINESTRING(100 100,100 100))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf, err := AsTWKB(geomFromWKT(t, tt.wkt), tt.prec)
+			expectNoErr(t, err)
+			got, err := UnmarshalTWKB(bytes.NewReader(buf), DisableAllValidations)
+			expectNoErr(t, err)
+			expectStringEq(t, got.AsText(), tt.want)
+		})
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		buf, err := AsTWKB(geomFromWKT(t, "LINESTRING(0 0,0.1 0.1)"), 0)
+		expectNoErr(t, err)
+		expectStringEq(t, hex.EncodeToString(buf), "02000200000000")
+	})
+}
+
+func TestTWKBInvalidAfterRounding(t *testing.T) {
+	// The ring has enough distinct points after rounding, but they're
+	// collinear (so the Polygon is invalid).
+	g := geomFromWKT(t, "POLYGON((0 0,2 0,1 0.4,0 0))")
+	buf, err := AsTWKB(g, 0)
+	expectNoErr(t, err)
+
+	_, err = UnmarshalTWKB(bytes.NewReader(buf))
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	got, err := UnmarshalTWKB(bytes.NewReader(buf), DisableAllValidations)
+	expectNoErr(t, err)
+	expectStringEq(t, got.AsText(), "POLYGON((0 0,2 0,1 0,0 0))")
+}
+
+func TestTWKBIDList(t *testing.T) {
+	for i, tt := range []struct {
+		wkt string
+		ids []int64
+	}{
+		{"MULTIPOINT((0 0),(1 1))", []int64{1, 2}},
+		{"MULTILINESTRING((0 0,1 1),(2 2,3 3),(4 4,5 5))", []int64{-5, 0, 1 << 40}},
+		{"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))", []int64{42}},
+		{"GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))", []int64{7, 8}},
+		{"MULTIPOINT((0 0),(1 1))", nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			want := geomFromWKT(t, tt.wkt)
+			buf, err := AsTWKB(want, 0, TWKBIDList(tt.ids))
+			expectNoErr(t, err)
+			got, ids, err := UnmarshalTWKBWithIDs(bytes.NewReader(buf))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, want)
+			expectIntEq(t, len(ids), len(tt.ids))
+			for j := range ids {
+				if ids[j] != tt.ids[j] {
+					t.Errorf("id %d: got=%d want=%d", j, ids[j], tt.ids[j])
+				}
+			}
+		})
+	}
+}
+
+func TestTWKBUnmarshalSequence(t *testing.T) {
+	var buf []byte
+	wkts := []string{"POINT(1 2)", "LINESTRING(1 2,3 4,5 6)", "POLYGON EMPTY"}
+	for _, wkt := range wkts {
+		twkb, err := AsTWKB(geomFromWKT(t, wkt), 0)
+		expectNoErr(t, err)
+		buf = append(buf, twkb...)
+	}
+
+	// Hide the io.ByteReader implementation, to check that the parser
+	// doesn't read past the end of each geometry.
+	r := struct{ *bytes.Reader }{bytes.NewReader(buf)}
+	for _, wkt := range wkts {
+		g, err := UnmarshalTWKB(r)
+		expectNoErr(t, err)
+		expectGeomEq(t, g, geomFromWKT(t, wkt))
+	}
+	expectIntEq(t, r.Len(), 0)
+}
+
+func TestTWKBMarshalInvalid(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		prec int
+		opts []TWKBOption
+	}{
+		{"POINT(1 2)", 8, nil},
+		{"POINT(1 2)", -9, nil},
+		{"POINT Z (1 2 3)", 0, []TWKBOption{TWKBPrecisionZ(8)}},
+		{"POINT M (1 2 3)", 0, []TWKBOption{TWKBPrecisionM(-1)}},
+		{"POINT(1 2)", 0, []TWKBOption{TWKBIDList([]int64{1})}},
+		{"MULTIPOINT((1 2),(3 4))", 0, []TWKBOption{TWKBIDList([]int64{1})}},
+		{"POINT(1e300 2)", 0, nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := AsTWKB(geomFromWKT(t, tt.wkt), tt.prec, tt.opts...)
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestTWKBUnmarshalInvalid(t *testing.T) {
+	for i, hexStr := range []string{
+		"",                     // no header
+		"01",                   // no metadata
+		"0100",                 // missing coordinates
+		"010002",               // missing Y
+		"0800",                 // unknown geometry type
+		"0200020202",           // missing second point
+		"03000103000002000100", // ring with too few points
+		"070001010801020406",   // child has different coordinates type
+		"040401",               // missing ID list
+		"01000280",             // truncated varint
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf, err := hex.DecodeString(hexStr)
+			expectNoErr(t, err)
+			_, err = UnmarshalTWKB(bytes.NewReader(buf))
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
@@ -1,14 +1,115 @@
 package geom
 
+import "errors"
+
+// CoordinatesType controls the dimensionality and type of data used to encode
+// a point location. At minimum, a point location is defined by X and Y
+// coordinates. It may optionally include a Z value, representing height. It
+// may also optionally include an M value, traditionally representing an
+// arbitrary user defined measurement associated with each point location.
+type CoordinatesType byte
+
+const (
+	// DimXY coordinates only contain X and Y values.
+	DimXY CoordinatesType = iota
+
+	// DimXYZ coordinates contain X, Y, and Z (height) values.
+	DimXYZ
+
+	// DimXYM coordinates contain X, Y, and M (measure) values.
+	DimXYM
+
+	// DimXYZM coordinates contain X, Y, Z (height), and M (measure) values.
+	DimXYZM
+)
+
+// String gives a string representation of a CoordinatesType.
+func (t CoordinatesType) String() string {
+	if t > DimXYZM {
+		return "invalid"
+	}
+	return [...]string{"XY", "XYZ", "XYM", "XYZM"}[t]
+}
+
+// Dimension returns the number of float64 coordinates required to encode a
+// point location using the CoordinatesType.
+func (t CoordinatesType) Dimension() int {
+	return 2 + int(t&DimXYZ) + int((t&DimXYM)>>1)
+}
+
+// Is3D returns true iff the CoordinatesType includes a Z value.
+func (t CoordinatesType) Is3D() bool {
+	return t&DimXYZ != 0
+}
+
+// IsMeasured returns true iff the CoordinatesType includes an M value.
+func (t CoordinatesType) IsMeasured() bool {
+	return t&DimXYM != 0
+}
+
+// commonCoordinatesType gives the highest CoordinatesType that doesn't
+// include any values that are missing from either of a or b.
+func commonCoordinatesType(a, b CoordinatesType) CoordinatesType {
+	return a & b
+}
+
+var errMixedCoordinatesTypes = errors.New("mixed coordinate types")
+
+// Coordinates represents a point location. Coordinates values may be
+// constructed manually using the type definition directly. Alternatively, one
+// of the New(XYZM)Coordinates constructor functions can be used.
 type Coordinates struct {
+	// XY represents the XY position of the point location.
 	XY
-	// TODO: Put optional Z and M here.
+
+	// Z represents the height of the location. Its value is zero
+	// for non-3D coordinate types.
+	Z float64
+
+	// M represents a user defined measure associated with the
+	// location. Its value is zero for non-measure coordinate
+	// types.
+	M float64
+
+	// Type indicates the coordinates type, and therefore whether
+	// or not Z and M are populated.
+	Type CoordinatesType
+}
+
+// NewXYZCoordinates creates a new 3D Coordinates value.
+func NewXYZCoordinates(x, y, z float64) Coordinates {
+	return Coordinates{XY: XY{x, y}, Z: z, Type: DimXYZ}
+}
+
+// NewXYMCoordinates creates a new measured Coordinates value.
+func NewXYMCoordinates(x, y, m float64) Coordinates {
+	return Coordinates{XY: XY{x, y}, M: m, Type: DimXYM}
+}
+
+// NewXYZMCoordinates creates a new 3D measured Coordinates value.
+func NewXYZMCoordinates(x, y, z, m float64) Coordinates {
+	return Coordinates{XY: XY{x, y}, Z: z, M: m, Type: DimXYZM}
+}
+
+// ForceCoordinatesType returns a copy of the Coordinates with a new
+// CoordinatesType. Any Z or M values not supported by the new type are
+// dropped, and any Z or M values that are introduced by the new type are set
+// to zero.
+func (c Coordinates) ForceCoordinatesType(newCType CoordinatesType) Coordinates {
+	if !newCType.Is3D() {
+		c.Z = 0
+	}
+	if !newCType.IsMeasured() {
+		c.M = 0
+	}
+	c.Type = newCType
+	return c
 }
 
 func oneDimXYToCoords(pts []XY) []Coordinates {
 	coords := make([]Coordinates, len(pts))
 	for i, pt := range pts {
-		coords[i] = Coordinates{pt}
+		coords[i] = Coordinates{XY: pt}
 	}
 	return coords
 }
@@ -29,16 +130,43 @@ func threeDimXYToCoords(xys [][][]XY) [][][]Coordinates {
 	return coords
 }
 
+// coordinatesTypeOf finds the coordinates type of a slice of Coordinates. An
+// error is returned if the coordinates types are mixed.
+func coordinatesTypeOf(coords []Coordinates) (CoordinatesType, error) {
+	if len(coords) == 0 {
+		return DimXY, nil
+	}
+	ctype := coords[0].Type
+	for _, c := range coords[1:] {
+		if c.Type != ctype {
+			return 0, errMixedCoordinatesTypes
+		}
+	}
+	return ctype, nil
+}
+
 type OptionalCoordinates struct {
 	Empty bool
 	Value Coordinates
 }
 
+// MarshalJSON gives the GeoJSON position for the Coordinates. Z values are
+// included for 3D coordinates. GeoJSON doesn't have a way to represent
+// measures for 2D coordinates, so M values are only included if the
+// coordinates also have a Z value.
 func (c Coordinates) MarshalJSON() ([]byte, error) {
 	buf := []byte{'['}
 	buf = appendFloat(buf, c.XY.X)
 	buf = append(buf, ',')
 	buf = appendFloat(buf, c.XY.Y)
+	if c.Type.Is3D() {
+		buf = append(buf, ',')
+		buf = appendFloat(buf, c.Z)
+		if c.Type.IsMeasured() {
+			buf = append(buf, ',')
+			buf = appendFloat(buf, c.M)
+		}
+	}
 	buf = append(buf, ']')
 	return buf, nil
 }
@@ -46,3 +174,20 @@ func (c Coordinates) MarshalJSON() ([]byte, error) {
 func (c Coordinates) Equals(other Coordinates) bool {
 	return c.XY.Equals(other.XY)
 }
+
+// appendWKT appends the WKT representation of the coordinates (without any
+// surrounding parenthesis) to dst.
+func (c Coordinates) appendWKT(dst []byte) []byte {
+	dst = appendFloat(dst, c.X)
+	dst = append(dst, ' ')
+	dst = appendFloat(dst, c.Y)
+	if c.Type.Is3D() {
+		dst = append(dst, ' ')
+		dst = appendFloat(dst, c.Z)
+	}
+	if c.Type.IsMeasured() {
+		dst = append(dst, ' ')
+		dst = appendFloat(dst, c.M)
+	}
+	return dst
+}
@@ -11,35 +11,37 @@ import (
 
 // EmptySet is a 0-dimensional geometry that represents the empty pointset.
 type EmptySet struct {
-	wkt       string
+	wktType   string
 	wkbType   uint32
 	jsonType  string
 	dimension int
+	ctype     CoordinatesType
 }
 
 func NewEmptyPoint(opts ...ConstructorOption) EmptySet {
-	return EmptySet{"POINT EMPTY", wkbGeomTypePoint, "Point", 0}
+	return EmptySet{"POINT", wkbGeomTypePoint, "Point", 0, DimXY}
 }
 
 func NewEmptyLineString(opts ...ConstructorOption) EmptySet {
-	return EmptySet{"LINESTRING EMPTY", wkbGeomTypeLineString, "LineString", 1}
+	return EmptySet{"LINESTRING", wkbGeomTypeLineString, "LineString", 1, DimXY}
 }
 
 func NewEmptyPolygon(opts ...ConstructorOption) EmptySet {
-	return EmptySet{"POLYGON EMPTY", wkbGeomTypePolygon, "Polygon", 2}
+	return EmptySet{"POLYGON", wkbGeomTypePolygon, "Polygon", 2, DimXY}
 }
 
 // AsGeometry converts this EmptySet into a Geometry.
 func (e EmptySet) AsGeometry() Geometry {
-	return Geometry{emptySetTag, unsafe.Pointer(&e)}
+	return Geometry{tag: emptySetTag, ptr: unsafe.Pointer(&e)}
 }
 
 func (e EmptySet) AsText() string {
-	return e.wkt
+	return string(e.AppendWKT(nil))
 }
 
 func (e EmptySet) AppendWKT(dst []byte) []byte {
-	return append(dst, e.wkt...)
+	dst = appendWKTHeader(dst, e.wktType, e.ctype)
+	return appendWKTEmpty(dst, e.ctype)
 }
 
 func (e EmptySet) IsSimple() bool {
@@ -50,6 +52,24 @@ func (e EmptySet) Intersection(g Geometry) (Geometry, error) {
 	return intersection(e.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (e EmptySet) Union(g Geometry) (Geometry, error) {
+	return union(e.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (e EmptySet) Difference(g Geometry) (Geometry, error) {
+	return difference(e.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (e EmptySet) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(e.AsGeometry(), g)
+}
+
 func (e EmptySet) Intersects(g Geometry) bool {
 	return hasIntersection(e.AsGeometry(), g)
 }
@@ -78,18 +98,23 @@ func (e EmptySet) Value() (driver.Value, error) {
 
 func (e EmptySet) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	e.writeWKB(marsh)
+	return marsh.err
+}
+
+func (e EmptySet) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(e.wkbType)
+	marsh.writeGeomType(e.wkbType, e.ctype)
 	switch e.wkbType {
 	case wkbGeomTypePoint:
-		marsh.writeFloat64(math.NaN())
-		marsh.writeFloat64(math.NaN())
+		for i := 0; i < e.ctype.Dimension(); i++ {
+			marsh.writeFloat64(math.NaN())
+		}
 	case wkbGeomTypeLineString, wkbGeomTypePolygon:
 		marsh.writeCount(0)
 	default:
 		marsh.setErr(errors.New("unknown empty geometry type (this shouldn't ever happen)"))
 	}
-	return marsh.err
 }
 
 // ConvexHull returns the convex hull of this geometry. The convex hull of an
@@ -111,7 +136,9 @@ func (e EmptySet) TransformXY(fn func(XY) XY, opts ...ConstructorOption) (Geomet
 // EqualsExact checks if this EmptySet is exactly equal to another geometry
 // by checking if the other geometry is an empty set of the same type.
 func (e EmptySet) EqualsExact(other Geometry, opts ...EqualsExactOption) bool {
-	return other.IsEmptySet() && e.wkbType == other.AsEmptySet().wkbType
+	return other.IsEmptySet() &&
+		e.wkbType == other.AsEmptySet().wkbType &&
+		e.ctype == other.AsEmptySet().ctype
 }
 
 // IsValid checks if this EmptySet is valid. However, this is no constraints on
@@ -124,3 +151,20 @@ func (e EmptySet) IsValid() bool {
 func (e EmptySet) Reverse() EmptySet {
 	return e
 }
+
+// CoordinatesType returns the CoordinatesType of the EmptySet.
+func (e EmptySet) CoordinatesType() CoordinatesType {
+	return e.ctype
+}
+
+// ForceCoordinatesType returns a new EmptySet with a different
+// CoordinatesType.
+func (e EmptySet) ForceCoordinatesType(newCType CoordinatesType) EmptySet {
+	e.ctype = newCType
+	return e
+}
+
+// Force2D returns a copy of the EmptySet with Z and M values removed.
+func (e EmptySet) Force2D() EmptySet {
+	return e.ForceCoordinatesType(DimXY)
+}
@@ -17,21 +17,39 @@ import (
 // 2. It must contain zero or more geometries.
 type GeometryCollection struct {
 	geoms []Geometry
+	ctype CoordinatesType
 }
 
 // NewGeometryCollection creates a potentially heterogenous collection of
-// geometries. There are no constraints on the collection.
+// geometries. There are no constraints on the collection. The CoordinatesType
+// of the GeometryCollection is the lowest common CoordinatesType of its
+// constituent geometries.
 func NewGeometryCollection(geoms []Geometry, opts ...ConstructorOption) GeometryCollection {
 	if len(geoms) == 0 {
 		// Store empty geoms as nil to make testing easier.
-		geoms = nil
+		return GeometryCollection{}
 	}
-	return GeometryCollection{geoms}
+	ctype := DimXYZM
+	for _, g := range geoms {
+		ctype = commonCoordinatesType(ctype, g.CoordinatesType())
+	}
+	for i, g := range geoms {
+		if g.CoordinatesType() != ctype {
+			forced := make([]Geometry, len(geoms))
+			copy(forced, geoms[:i])
+			for j := i; j < len(geoms); j++ {
+				forced[j] = geoms[j].ForceCoordinatesType(ctype)
+			}
+			geoms = forced
+			break
+		}
+	}
+	return GeometryCollection{geoms, ctype}
 }
 
 // AsGeometry converts this GeometryCollection into a Geometry.
 func (c GeometryCollection) AsGeometry() Geometry {
-	return Geometry{geometryCollectionTag, unsafe.Pointer(&c)}
+	return Geometry{tag: geometryCollectionTag, ptr: unsafe.Pointer(&c)}
 }
 
 // NumGeometries gives the number of Geomety elements is the GeometryCollection.
@@ -49,9 +67,9 @@ func (c GeometryCollection) AsText() string {
 }
 
 func (c GeometryCollection) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("GEOMETRYCOLLECTION")...)
+	dst = appendWKTHeader(dst, "GEOMETRYCOLLECTION", c.ctype)
 	if len(c.geoms) == 0 {
-		return append(dst, []byte(" EMPTY")...)
+		return appendWKTEmpty(dst, c.ctype)
 	}
 	dst = append(dst, '(')
 	for i, geom := range c.geoms {
@@ -67,6 +85,24 @@ func (c GeometryCollection) Intersection(g Geometry) (Geometry, error) {
 	return intersection(c.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (c GeometryCollection) Union(g Geometry) (Geometry, error) {
+	return union(c.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (c GeometryCollection) Difference(g Geometry) (Geometry, error) {
+	return difference(c.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (c GeometryCollection) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(c.AsGeometry(), g)
+}
+
 func (c GeometryCollection) Intersects(g Geometry) bool {
 	return hasIntersection(c.AsGeometry(), g)
 }
@@ -127,7 +163,7 @@ func (c GeometryCollection) Boundary() GeometryCollection {
 			bounds = append(bounds, bound)
 		}
 	}
-	return NewGeometryCollection(bounds)
+	return NewGeometryCollection(bounds).ForceCoordinatesType(c.ctype)
 }
 
 func (c GeometryCollection) Value() (driver.Value, error) {
@@ -138,15 +174,19 @@ func (c GeometryCollection) Value() (driver.Value, error) {
 
 func (c GeometryCollection) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	c.writeWKB(marsh)
+	return marsh.err
+}
+
+func (c GeometryCollection) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeGeometryCollection)
+	marsh.writeGeomType(wkbGeomTypeGeometryCollection, c.ctype)
 	n := c.NumGeometries()
 	marsh.writeCount(n)
 	for i := 0; i < n; i++ {
 		geom := c.GeometryN(i)
-		marsh.setErr(geom.AsBinary(w))
+		geom.writeWKB(marsh)
 	}
-	return marsh.err
 }
 
 func (c GeometryCollection) ConvexHull() Geometry {
@@ -178,7 +218,7 @@ func (c GeometryCollection) TransformXY(fn func(XY) XY, opts ...ConstructorOptio
 			return Geometry{}, err
 		}
 	}
-	return NewGeometryCollection(transformed).AsGeometry(), nil
+	return NewGeometryCollection(transformed).ForceCoordinatesType(c.ctype).AsGeometry(), nil
 }
 
 // EqualsExact checks if this GeometryCollection is exactly equal to another GeometryCollection.
@@ -210,13 +250,13 @@ func (c GeometryCollection) Reverse() GeometryCollection {
 		}
 	}
 	if numEmpty == c.NumGeometries() {
-		return NewGeometryCollection(geoms)
+		return NewGeometryCollection(geoms).ForceCoordinatesType(c.ctype)
 	}
 	for n := 0; n < c.NumGeometries(); n++ {
 		rev := c.GeometryN(n).Reverse()
 		geoms = append(geoms, rev)
 	}
-	return NewGeometryCollection(geoms)
+	return NewGeometryCollection(geoms).ForceCoordinatesType(c.ctype)
 }
 
 // Length of a GeometryCollection is the sum of the lengths of its parts.
@@ -371,3 +411,29 @@ func (c GeometryCollection) computeCentroid() (highestDim, numPoints int, sumLen
 	valid = true
 	return
 }
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the GeometryCollection.
+func (c GeometryCollection) CoordinatesType() CoordinatesType {
+	return c.ctype
+}
+
+// ForceCoordinatesType returns a new GeometryCollection with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0.
+func (c GeometryCollection) ForceCoordinatesType(newCType CoordinatesType) GeometryCollection {
+	var geoms []Geometry
+	if len(c.geoms) > 0 {
+		geoms = make([]Geometry, len(c.geoms))
+		for i, g := range c.geoms {
+			geoms[i] = g.ForceCoordinatesType(newCType)
+		}
+	}
+	return GeometryCollection{geoms, newCType}
+}
+
+// Force2D returns a copy of the GeometryCollection with Z and M values
+// removed.
+func (c GeometryCollection) Force2D() GeometryCollection {
+	return c.ForceCoordinatesType(DimXY)
+}
@@ -20,20 +20,25 @@ type Line struct {
 
 // NewLineC creates a line segment given the Coordinates of its two endpoints.
 func NewLineC(a, b Coordinates, opts ...ConstructorOption) (Line, error) {
-	if doCheapValidations(opts) && a.XY.Equals(b.XY) {
-		return Line{}, fmt.Errorf("line endpoints must be distinct: %v", a.XY)
+	if doCheapValidations(opts) {
+		if a.XY.Equals(b.XY) {
+			return Line{}, validationErrorAt(ReasonTooFewPoints, a.XY)
+		}
+		if a.Type != b.Type {
+			return Line{}, errMixedCoordinatesTypes
+		}
 	}
 	return Line{a, b}, nil
 }
 
 // NewLineXY creates a line segment given the XYs of its two endpoints.
 func NewLineXY(a, b XY, opts ...ConstructorOption) (Line, error) {
-	return NewLineC(Coordinates{a}, Coordinates{b}, opts...)
+	return NewLineC(Coordinates{XY: a}, Coordinates{XY: b}, opts...)
 }
 
 // AsGeometry converts this Line into a Geometry.
 func (n Line) AsGeometry() Geometry {
-	return Geometry{lineTag, unsafe.Pointer(&n)}
+	return Geometry{tag: lineTag, ptr: unsafe.Pointer(&n)}
 }
 
 // StartPoint gives the first point of the line.
@@ -69,14 +74,11 @@ func (n Line) AsText() string {
 }
 
 func (n Line) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("LINESTRING(")...)
-	dst = appendFloat(dst, n.a.X)
-	dst = append(dst, ' ')
-	dst = appendFloat(dst, n.a.Y)
+	dst = appendWKTHeader(dst, "LINESTRING", n.CoordinatesType())
+	dst = append(dst, '(')
+	dst = n.a.appendWKT(dst)
 	dst = append(dst, ',')
-	dst = appendFloat(dst, n.b.X)
-	dst = append(dst, ' ')
-	dst = appendFloat(dst, n.b.Y)
+	dst = n.b.appendWKT(dst)
 	return append(dst, ')')
 }
 
@@ -88,6 +90,24 @@ func (n Line) Intersection(g Geometry) (Geometry, error) {
 	return intersection(n.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (n Line) Union(g Geometry) (Geometry, error) {
+	return union(n.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (n Line) Difference(g Geometry) (Geometry, error) {
+	return difference(n.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (n Line) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(n.AsGeometry(), g)
+}
+
 func (n Line) Intersects(g Geometry) bool {
 	return hasIntersection(n.AsGeometry(), g)
 }
@@ -102,8 +122,8 @@ func (n Line) Envelope() (Envelope, bool) {
 
 func (n Line) Boundary() MultiPoint {
 	return NewMultiPoint([]Point{
-		NewPointXY(n.a.XY),
-		NewPointXY(n.b.XY),
+		NewPointC(n.a),
+		NewPointC(n.b),
 	})
 }
 
@@ -115,14 +135,16 @@ func (n Line) Value() (driver.Value, error) {
 
 func (n Line) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	n.writeWKB(marsh)
+	return marsh.err
+}
+
+func (n Line) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeLineString)
+	marsh.writeGeomType(wkbGeomTypeLineString, n.CoordinatesType())
 	marsh.writeCount(2)
-	marsh.writeFloat64(n.StartPoint().XY().X)
-	marsh.writeFloat64(n.StartPoint().XY().Y)
-	marsh.writeFloat64(n.EndPoint().XY().X)
-	marsh.writeFloat64(n.EndPoint().XY().Y)
-	return marsh.err
+	marsh.writeCoordinates(n.a)
+	marsh.writeCoordinates(n.b)
 }
 
 func (n Line) ConvexHull() Geometry {
@@ -193,3 +215,23 @@ func (n Line) AsLineString() LineString {
 func (n Line) Reverse() Line {
 	return Line{n.b, n.a}
 }
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the Line.
+func (n Line) CoordinatesType() CoordinatesType {
+	return n.a.Type
+}
+
+// ForceCoordinatesType returns a new Line with a different CoordinatesType. If
+// a dimension is added, then new values are populated with 0.
+func (n Line) ForceCoordinatesType(newCType CoordinatesType) Line {
+	return Line{
+		n.a.ForceCoordinatesType(newCType),
+		n.b.ForceCoordinatesType(newCType),
+	}
+}
+
+// Force2D returns a copy of the Line with Z and M values removed.
+func (n Line) Force2D() Line {
+	return n.ForceCoordinatesType(DimXY)
+}
@@ -3,7 +3,6 @@ package geom
 import (
 	"bytes"
 	"database/sql/driver"
-	"errors"
 	"io"
 	"math"
 	"sort"
@@ -17,32 +16,41 @@ import (
 //
 // 1. It must contain at least 2 distinct points.
 type LineString struct {
-	// coords have been deduplicated such that no two consecutive coordinates
-	// are coincident. This allows quick calculation of Line segments.
+	// coords are the original points making up the LineString, and retain
+	// consecutive coincident points. This is so that information about the
+	// original points (including any Z and M values) is retained.
 	coords []Coordinates
 
-	// points are indexes into coords, and retain consecutive coincident
-	// points. This is so that information about the original points making up
-	// the LineString are retained.
-	points []int
+	// distinct are indexes into coords, and have been deduplicated such that
+	// no two consecutive indexed coordinates are coincident. This allows quick
+	// calculation of Line segments.
+	distinct []int
 }
 
 // NewLineStringC creates a line string from the coordinates defining its
-// points.
+// points. All coordinates must have the same CoordinatesType.
 func NewLineStringC(pts []Coordinates, opts ...ConstructorOption) (LineString, error) {
-	coords := make([]Coordinates, 0, len(pts)) // may not use full capacity
-	points := make([]int, len(pts))
+	coords := make([]Coordinates, len(pts))
+	copy(coords, pts)
+	distinct := make([]int, 0, len(pts)) // may not use full capacity
 
-	for i := range pts {
-		if len(coords) == 0 || pts[i].XY != coords[len(coords)-1].XY {
-			coords = append(coords, pts[i])
+	for i := range coords {
+		if len(distinct) == 0 || coords[i].XY != coords[distinct[len(distinct)-1]].XY {
+			distinct = append(distinct, i)
 		}
-		points[i] = len(coords) - 1
 	}
-	if doCheapValidations(opts) && len(coords) <= 1 {
-		return LineString{}, errors.New("LineString must contain at least two distinct points")
+	if doCheapValidations(opts) {
+		if len(distinct) == 0 {
+			return LineString{}, validationError(ReasonTooFewPoints)
+		}
+		if len(distinct) == 1 {
+			return LineString{}, validationErrorAt(ReasonTooFewPoints, coords[0].XY)
+		}
+		if _, err := coordinatesTypeOf(coords); err != nil {
+			return LineString{}, err
+		}
 	}
-	return LineString{coords, points}, nil
+	return LineString{coords, distinct}, nil
 }
 
 // NewLineStringXY creates a line string from the XYs defining its points.
@@ -52,32 +60,32 @@ func NewLineStringXY(pts []XY, opts ...ConstructorOption) (LineString, error) {
 
 // AsGeometry converts this LineString into a Geometry.
 func (s LineString) AsGeometry() Geometry {
-	return Geometry{lineStringTag, unsafe.Pointer(&s)}
+	return Geometry{tag: lineStringTag, ptr: unsafe.Pointer(&s)}
 }
 
 // StartPoint gives the first point of the line string.
 func (s LineString) StartPoint() Point {
-	return NewPointC(s.coords[s.points[0]])
+	return NewPointC(s.coords[0])
 }
 
 // EndPoint gives the last point of the line string.
 func (s LineString) EndPoint() Point {
-	return NewPointC(s.coords[s.points[len(s.points)-1]])
+	return NewPointC(s.coords[len(s.coords)-1])
 }
 
 // NumPoints gives the number of control points in the line string.
 func (s LineString) NumPoints() int {
-	return len(s.points)
+	return len(s.coords)
 }
 
 // PointN gives the nth (zero indexed) point in the line string. Panics if n is
 // out of range with respect to the number of points.
 func (s LineString) PointN(n int) Point {
-	return NewPointC(s.coords[s.points[n]])
+	return NewPointC(s.coords[n])
 }
 
 func (s LineString) NumLines() int {
-	return len(s.coords) - 1
+	return len(s.distinct) - 1
 }
 
 func (s LineString) LineN(n int) Line {
@@ -86,8 +94,8 @@ func (s LineString) LineN(n int) Line {
 	// constructor significantly speeds up the benchmarks.
 	//
 	// The two coordinates are guaranteed to not be coincident due to the way
-	// that the coords slice is constructed, so this is safe.
-	return Line{s.coords[n], s.coords[n+1]}
+	// that the distinct slice is constructed, so this is safe.
+	return Line{s.coords[s.distinct[n]], s.coords[s.distinct[n+1]]}
 }
 
 func (s LineString) AsText() string {
@@ -95,20 +103,17 @@ func (s LineString) AsText() string {
 }
 
 func (s LineString) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("LINESTRING")...)
+	dst = appendWKTHeader(dst, "LINESTRING", s.CoordinatesType())
 	return s.appendWKTBody(dst)
 }
 
 func (s LineString) appendWKTBody(dst []byte) []byte {
 	dst = append(dst, '(')
-	for i, ptIdx := range s.points {
+	for i, c := range s.coords {
 		if i > 0 {
 			dst = append(dst, ',')
 		}
-		c := s.coords[ptIdx]
-		dst = appendFloat(dst, c.X)
-		dst = append(dst, ' ')
-		dst = appendFloat(dst, c.Y)
+		dst = c.appendWKT(dst)
 	}
 	return append(dst, ')')
 }
@@ -122,6 +127,15 @@ type lineWithIndex struct {
 // through the same point twice (with the possible exception of the two
 // endpoints being coincident).
 func (s LineString) IsSimple() bool {
+	_, ok := s.selfIntersection()
+	return !ok
+}
+
+// selfIntersection finds a point at which the LineString intersects itself
+// (other than the permitted intersections between adjacent segments, and
+// between the endpoints of a closed LineString). It returns false if the
+// LineString is simple.
+func (s LineString) selfIntersection() (XY, bool) {
 	// A line sweep algorithm is used, where a vertical line is swept over X
 	// values (from lowest to highest). We only have consider line segments
 	// that have overlapping X values when performing pairwise intersection
@@ -153,9 +167,12 @@ func (s LineString) IsSimple() bool {
 			if !intersects {
 				continue
 			}
+			location := func() XY {
+				return intersectLineWithLineNoAlloc(s.LineN(current), s.LineN(other)).ptA
+			}
 			if dim >= 1 {
 				// Two overlapping line segments.
-				return false
+				return location(), true
 			}
 
 			// The dimension must be 1. Since the intersection is between two
@@ -174,17 +191,17 @@ func (s LineString) IsSimple() bool {
 				if s.IsClosed() {
 					continue
 				} else {
-					return false
+					return location(), true
 				}
 			}
 
 			// Any other point intersection (e.g. looping back on
 			// itself at a point) is disallowed for simple linestrings.
-			return false
+			return location(), true
 		}
 		active.push(current)
 	}
-	return true
+	return XY{}, false
 }
 
 func (s LineString) IsClosed() bool {
@@ -195,6 +212,24 @@ func (s LineString) Intersection(g Geometry) (Geometry, error) {
 	return intersection(s.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (s LineString) Union(g Geometry) (Geometry, error) {
+	return union(s.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (s LineString) Difference(g Geometry) (Geometry, error) {
+	return difference(s.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (s LineString) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(s.AsGeometry(), g)
+}
+
 func (s LineString) Intersects(g Geometry) bool {
 	return hasIntersection(s.AsGeometry(), g)
 }
@@ -220,7 +255,7 @@ func (s LineString) Boundary() MultiPoint {
 	if !s.IsClosed() {
 		pts = append(pts, s.StartPoint(), s.EndPoint())
 	}
-	return NewMultiPoint(pts)
+	return NewMultiPoint(pts).ForceCoordinatesType(s.CoordinatesType())
 }
 
 func (s LineString) Value() (driver.Value, error) {
@@ -231,15 +266,17 @@ func (s LineString) Value() (driver.Value, error) {
 
 func (s LineString) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	s.writeWKB(marsh)
+	return marsh.err
+}
+
+func (s LineString) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeLineString)
-	n := s.NumPoints()
-	marsh.writeCount(n)
-	for i := 0; i < n; i++ {
-		marsh.writeFloat64(s.PointN(i).XY().X)
-		marsh.writeFloat64(s.PointN(i).XY().Y)
+	marsh.writeGeomType(wkbGeomTypeLineString, s.CoordinatesType())
+	marsh.writeCount(len(s.coords))
+	for _, c := range s.coords {
+		marsh.writeCoordinates(c)
 	}
-	return marsh.err
 }
 
 func (s LineString) ConvexHull() Geometry {
@@ -252,11 +289,8 @@ func (s LineString) MarshalJSON() ([]byte, error) {
 
 // Coordinates returns the coordinates of each point along the LineString.
 func (s LineString) Coordinates() []Coordinates {
-	n := s.NumPoints()
-	coords := make([]Coordinates, n)
-	for i := range coords {
-		coords[i] = s.PointN(i).Coordinates()
-	}
+	coords := make([]Coordinates, len(s.coords))
+	copy(coords, s.coords)
 	return coords
 }
 
@@ -297,9 +331,11 @@ func (s LineString) IsRing() bool {
 // Length gives the length of the line string.
 func (s LineString) Length() float64 {
 	var sum float64
-	for i := 0; i+1 < len(s.coords); i++ {
-		dx := s.coords[i].X - s.coords[i+1].X
-		dy := s.coords[i].Y - s.coords[i+1].Y
+	n := s.NumLines()
+	for i := 0; i < n; i++ {
+		ln := s.LineN(i)
+		dx := ln.a.X - ln.b.X
+		dy := ln.a.Y - ln.b.Y
 		sum += math.Sqrt(dx*dx + dy*dy)
 	}
 	return sum
@@ -354,3 +390,28 @@ func (s LineString) Reverse() LineString {
 	}
 	return s2
 }
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the LineString.
+func (s LineString) CoordinatesType() CoordinatesType {
+	if len(s.coords) == 0 {
+		return DimXY
+	}
+	return s.coords[0].Type
+}
+
+// ForceCoordinatesType returns a new LineString with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0.
+func (s LineString) ForceCoordinatesType(newCType CoordinatesType) LineString {
+	coords := make([]Coordinates, len(s.coords))
+	for i, c := range s.coords {
+		coords[i] = c.ForceCoordinatesType(newCType)
+	}
+	return LineString{coords, s.distinct}
+}
+
+// Force2D returns a copy of the LineString with Z and M values removed.
+func (s LineString) Force2D() LineString {
+	return s.ForceCoordinatesType(DimXY)
+}
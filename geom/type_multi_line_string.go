@@ -14,12 +14,32 @@ import (
 // 1. It must be made of up zero or more valid LineStrings.
 type MultiLineString struct {
 	lines []LineString
+	ctype CoordinatesType
 }
 
 // NewMultiLineString creates a MultiLineString from its constintuent
-// LineStrings.
+// LineStrings. The CoordinatesType of the MultiLineString is the lowest common
+// CoordinatesType of its LineStrings.
 func NewMultiLineString(lines []LineString, opts ...ConstructorOption) MultiLineString {
-	return MultiLineString{lines}
+	if len(lines) == 0 {
+		return MultiLineString{}
+	}
+	ctype := DimXYZM
+	for _, ls := range lines {
+		ctype = commonCoordinatesType(ctype, ls.CoordinatesType())
+	}
+	for i, ls := range lines {
+		if ls.CoordinatesType() != ctype {
+			forced := make([]LineString, len(lines))
+			copy(forced, lines[:i])
+			for j := i; j < len(lines); j++ {
+				forced[j] = lines[j].ForceCoordinatesType(ctype)
+			}
+			lines = forced
+			break
+		}
+	}
+	return MultiLineString{lines, ctype}
 }
 
 // NewMultiLineStringC creates a MultiLineString from its coordinates. The
@@ -35,21 +55,25 @@ func NewMultiLineStringC(coords [][]Coordinates, opts ...ConstructorOption) (Mul
 		if err != nil {
 			return MultiLineString{}, err
 		}
+		if doCheapValidations(opts) && len(lines) > 0 &&
+			line.CoordinatesType() != lines[0].CoordinatesType() {
+			return MultiLineString{}, errMixedCoordinatesTypes
+		}
 		lines = append(lines, line)
 	}
-	return MultiLineString{lines}, nil
+	return NewMultiLineString(lines, opts...), nil
 }
 
 // NewMultiLineStringXY creates a MultiLineString from its XYs. The
 // first dimension of the XYs slice indicates the LineString, and the
 // second dimension indicates the XY within a LineString.
 func NewMultiLineStringXY(pts [][]XY, opts ...ConstructorOption) (MultiLineString, error) {
-	return NewMultiLineStringC(twoDimXYToCoords(pts))
+	return NewMultiLineStringC(twoDimXYToCoords(pts), opts...)
 }
 
 // AsGeometry converts this MultiLineString into a Geometry.
 func (m MultiLineString) AsGeometry() Geometry {
-	return Geometry{multiLineStringTag, unsafe.Pointer(&m)}
+	return Geometry{tag: multiLineStringTag, ptr: unsafe.Pointer(&m)}
 }
 
 // NumLineStrings gives the number of LineString elements in the
@@ -68,9 +92,9 @@ func (m MultiLineString) AsText() string {
 }
 
 func (m MultiLineString) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("MULTILINESTRING")...)
+	dst = appendWKTHeader(dst, "MULTILINESTRING", m.ctype)
 	if len(m.lines) == 0 {
-		return append(dst, []byte(" EMPTY")...)
+		return appendWKTEmpty(dst, m.ctype)
 	}
 	dst = append(dst, '(')
 	for i, line := range m.lines {
@@ -110,6 +134,24 @@ func (m MultiLineString) Intersection(g Geometry) (Geometry, error) {
 	return intersection(m.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (m MultiLineString) Union(g Geometry) (Geometry, error) {
+	return union(m.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (m MultiLineString) Difference(g Geometry) (Geometry, error) {
+	return difference(m.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (m MultiLineString) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(m.AsGeometry(), g)
+}
+
 func (m MultiLineString) Intersects(g Geometry) bool {
 	return hasIntersection(m.AsGeometry(), g)
 }
@@ -158,7 +200,7 @@ func (m MultiLineString) Boundary() MultiPoint {
 			bound = append(bound, pt)
 		}
 	}
-	return NewMultiPoint(bound)
+	return NewMultiPoint(bound).ForceCoordinatesType(m.ctype)
 }
 
 func (m MultiLineString) Value() (driver.Value, error) {
@@ -169,15 +211,19 @@ func (m MultiLineString) Value() (driver.Value, error) {
 
 func (m MultiLineString) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	m.writeWKB(marsh)
+	return marsh.err
+}
+
+func (m MultiLineString) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeMultiLineString)
+	marsh.writeGeomType(wkbGeomTypeMultiLineString, m.ctype)
 	n := m.NumLineStrings()
 	marsh.writeCount(n)
 	for i := 0; i < n; i++ {
 		ls := m.LineStringN(i)
-		marsh.setErr(ls.AsBinary(w))
+		ls.writeWKB(marsh)
 	}
-	return marsh.err
 }
 
 func (m MultiLineString) ConvexHull() Geometry {
@@ -208,7 +254,7 @@ func (m MultiLineString) TransformXY(fn func(XY) XY, opts ...ConstructorOption)
 	coords := m.Coordinates()
 	transform2dCoords(coords, fn)
 	mls, err := NewMultiLineStringC(coords, opts...)
-	return mls.AsGeometry(), err
+	return mls.ForceCoordinatesType(m.ctype).AsGeometry(), err
 }
 
 // EqualsExact checks if this MultiLineString is exactly equal to another MultiLineString.
@@ -262,5 +308,30 @@ func (m MultiLineString) Reverse() MultiLineString {
 	for i := 0; i < len(m.lines); i++ {
 		linestrings[i] = m.lines[i].Reverse()
 	}
-	return NewMultiLineString(linestrings)
+	return NewMultiLineString(linestrings).ForceCoordinatesType(m.ctype)
+}
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the MultiLineString.
+func (m MultiLineString) CoordinatesType() CoordinatesType {
+	return m.ctype
+}
+
+// ForceCoordinatesType returns a new MultiLineString with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0.
+func (m MultiLineString) ForceCoordinatesType(newCType CoordinatesType) MultiLineString {
+	var lines []LineString
+	if len(m.lines) > 0 {
+		lines = make([]LineString, len(m.lines))
+		for i, ls := range m.lines {
+			lines[i] = ls.ForceCoordinatesType(newCType)
+		}
+	}
+	return MultiLineString{lines, newCType}
+}
+
+// Force2D returns a copy of the MultiLineString with Z and M values removed.
+func (m MultiLineString) Force2D() MultiLineString {
+	return m.ForceCoordinatesType(DimXY)
 }
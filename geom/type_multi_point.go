@@ -14,11 +14,33 @@ import (
 //
 // 1. It must be made up of 0 or more valid Points.
 type MultiPoint struct {
-	pts []Point
+	pts   []Point
+	ctype CoordinatesType
 }
 
+// NewMultiPoint creates a MultiPoint from its constituent Points. The
+// CoordinatesType of the MultiPoint is the lowest common CoordinatesType of
+// its Points.
 func NewMultiPoint(pts []Point, opts ...ConstructorOption) MultiPoint {
-	return MultiPoint{pts}
+	if len(pts) == 0 {
+		return MultiPoint{}
+	}
+	ctype := DimXYZM
+	for _, p := range pts {
+		ctype = commonCoordinatesType(ctype, p.CoordinatesType())
+	}
+	for i, p := range pts {
+		if p.CoordinatesType() != ctype {
+			forced := make([]Point, len(pts))
+			copy(forced, pts[:i])
+			for j := i; j < len(pts); j++ {
+				forced[j] = pts[j].ForceCoordinatesType(ctype)
+			}
+			pts = forced
+			break
+		}
+	}
+	return MultiPoint{pts, ctype}
 }
 
 // NewMultiPointOC creates a new MultiPoint consisting of a Point for each
@@ -47,12 +69,12 @@ func NewMultiPointC(coords []Coordinates, opts ...ConstructorOption) MultiPoint
 
 // NewMultiPointXY creates a new MultiPoint consisting of a point for each XY.
 func NewMultiPointXY(pts []XY, opts ...ConstructorOption) MultiPoint {
-	return NewMultiPointC(oneDimXYToCoords(pts))
+	return NewMultiPointC(oneDimXYToCoords(pts), opts...)
 }
 
 // AsGeometry converts this MultiPoint into a Geometry.
 func (m MultiPoint) AsGeometry() Geometry {
-	return Geometry{multiPointTag, unsafe.Pointer(&m)}
+	return Geometry{tag: multiPointTag, ptr: unsafe.Pointer(&m)}
 }
 
 // NumPoints gives the number of element points making up the MultiPoint.
@@ -70,9 +92,9 @@ func (m MultiPoint) AsText() string {
 }
 
 func (m MultiPoint) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("MULTIPOINT")...)
+	dst = appendWKTHeader(dst, "MULTIPOINT", m.ctype)
 	if len(m.pts) == 0 {
-		return append(dst, []byte(" EMPTY")...)
+		return appendWKTEmpty(dst, m.ctype)
 	}
 	dst = append(dst, '(')
 	for i, pt := range m.pts {
@@ -100,6 +122,24 @@ func (m MultiPoint) Intersection(g Geometry) (Geometry, error) {
 	return intersection(m.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (m MultiPoint) Union(g Geometry) (Geometry, error) {
+	return union(m.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (m MultiPoint) Difference(g Geometry) (Geometry, error) {
+	return difference(m.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (m MultiPoint) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(m.AsGeometry(), g)
+}
+
 func (m MultiPoint) Intersects(g Geometry) bool {
 	return hasIntersection(m.AsGeometry(), g)
 }
@@ -124,7 +164,7 @@ func (m MultiPoint) Envelope() (Envelope, bool) {
 }
 
 func (m MultiPoint) Boundary() GeometryCollection {
-	return NewGeometryCollection(nil)
+	return NewGeometryCollection(nil).ForceCoordinatesType(m.ctype)
 }
 
 func (m MultiPoint) Value() (driver.Value, error) {
@@ -135,15 +175,19 @@ func (m MultiPoint) Value() (driver.Value, error) {
 
 func (m MultiPoint) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	m.writeWKB(marsh)
+	return marsh.err
+}
+
+func (m MultiPoint) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeMultiPoint)
+	marsh.writeGeomType(wkbGeomTypeMultiPoint, m.ctype)
 	n := m.NumPoints()
 	marsh.writeCount(n)
 	for i := 0; i < n; i++ {
 		pt := m.PointN(i)
-		marsh.setErr(pt.AsBinary(w))
+		pt.writeWKB(marsh)
 	}
-	return marsh.err
 }
 
 // ConvexHull finds the convex hull of the set of points. This may either be
@@ -170,7 +214,8 @@ func (m MultiPoint) Coordinates() []Coordinates {
 func (m MultiPoint) TransformXY(fn func(XY) XY, opts ...ConstructorOption) (Geometry, error) {
 	coords := m.Coordinates()
 	transform1dCoords(coords, fn)
-	return NewMultiPointC(coords, opts...).AsGeometry(), nil
+	mp := NewMultiPointC(coords, opts...).ForceCoordinatesType(m.ctype)
+	return mp.AsGeometry(), nil
 }
 
 // EqualsExact checks if this MultiPoint is exactly equal to another MultiPoint.
@@ -208,5 +253,30 @@ func (m MultiPoint) Reverse() MultiPoint {
 	for i := 0; i < len(m.pts); i++ {
 		coords[i] = m.pts[i].Coordinates()
 	}
-	return NewMultiPointC(coords)
+	return NewMultiPointC(coords).ForceCoordinatesType(m.ctype)
+}
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the MultiPoint.
+func (m MultiPoint) CoordinatesType() CoordinatesType {
+	return m.ctype
+}
+
+// ForceCoordinatesType returns a new MultiPoint with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0.
+func (m MultiPoint) ForceCoordinatesType(newCType CoordinatesType) MultiPoint {
+	var pts []Point
+	if len(m.pts) > 0 {
+		pts = make([]Point, len(m.pts))
+		for i, pt := range m.pts {
+			pts[i] = pt.ForceCoordinatesType(newCType)
+		}
+	}
+	return MultiPoint{pts, newCType}
+}
+
+// Force2D returns a copy of the MultiPoint with Z and M values removed.
+func (m MultiPoint) Force2D() MultiPoint {
+	return m.ForceCoordinatesType(DimXY)
 }
@@ -3,7 +3,6 @@ package geom
 import (
 	"bytes"
 	"database/sql/driver"
-	"errors"
 	"io"
 	"sort"
 	"unsafe"
@@ -20,13 +19,38 @@ import (
 // 3. The boundaries of any two polygons may touch only at a finite number of points.
 type MultiPolygon struct {
 	polys []Polygon
+	ctype CoordinatesType
 }
 
 // NewMultiPolygon creates a MultiPolygon from its constituent Polygons. It
-// gives an error if any of the MultiPolygon assertions are not maintained.
+// gives an error if any of the MultiPolygon assertions are not maintained, or
+// if the Polygons have mixed CoordinatesTypes.
 func NewMultiPolygon(polys []Polygon, opts ...ConstructorOption) (MultiPolygon, error) {
+	if len(polys) == 0 {
+		return MultiPolygon{}, nil
+	}
+	ctype := DimXYZM
+	for _, p := range polys {
+		ctype = commonCoordinatesType(ctype, p.CoordinatesType())
+	}
+	for i, p := range polys {
+		if p.CoordinatesType() == ctype {
+			continue
+		}
+		if doCheapValidations(opts) {
+			return MultiPolygon{}, errMixedCoordinatesTypes
+		}
+		forced := make([]Polygon, len(polys))
+		copy(forced, polys[:i])
+		for j := i; j < len(polys); j++ {
+			forced[j] = polys[j].ForceCoordinatesType(ctype)
+		}
+		polys = forced
+		break
+	}
+
 	if !doExpensiveValidations(opts) {
-		return MultiPolygon{polys}, nil
+		return MultiPolygon{polys, ctype}, nil
 	}
 
 	type interval struct {
@@ -36,7 +60,7 @@ func NewMultiPolygon(polys []Polygon, opts ...ConstructorOption) (MultiPolygon,
 	for i := range intervals {
 		env, ok := polys[i].Envelope()
 		if !ok {
-			return MultiPolygon{}, errors.New("polygon in multiploygon not allowed to be empty")
+			return MultiPolygon{}, validationError(ReasonEmptyPolygon)
 		}
 		intervals[i].minX = env.Min().X
 		intervals[i].maxX = env.Max().X
@@ -64,16 +88,16 @@ func NewMultiPolygon(polys []Polygon, opts ...ConstructorOption) (MultiPolygon,
 			bound2 := polys[j].Boundary()
 			inter := mustIntersection(bound1.AsGeometry(), bound2.AsGeometry())
 			if inter.Dimension() > 0 {
-				return MultiPolygon{}, errors.New("the boundaries of the polygon elements of multipolygons must only intersect at points")
+				return MultiPolygon{}, boundariesOverlapError(inter)
 			}
-			if polyInteriorsIntersect(polys[i], polys[j]) {
-				return MultiPolygon{}, errors.New("polygon interiors must not intersect")
+			if loc, ok := polyInteriorsIntersect(polys[i], polys[j]); ok {
+				return MultiPolygon{}, validationErrorAt(ReasonNestedShells, loc)
 			}
 		}
 		active.push(i)
 	}
 
-	return MultiPolygon{polys}, nil
+	return MultiPolygon{polys, ctype}, nil
 }
 
 // NewMultiPolygonC creates a new MultiPolygon from its constituent Coordinate values.
@@ -97,7 +121,9 @@ func NewMultiPolygonXY(pts [][][]XY, opts ...ConstructorOption) (MultiPolygon, e
 	return NewMultiPolygonC(threeDimXYToCoords(pts), opts...)
 }
 
-func polyInteriorsIntersect(p1, p2 Polygon) bool {
+// polyInteriorsIntersect checks if the interiors of two polygons intersect. If
+// they do, then a point in both interiors is returned.
+func polyInteriorsIntersect(p1, p2 Polygon) (XY, bool) {
 	// Run twice, swapping the order of the polygons each time.
 	for order := 0; order < 2; order++ {
 		p1, p2 = p2, p1
@@ -163,14 +189,49 @@ func polyInteriorsIntersect(p1, p2 Polygon) bool {
 		}
 
 		// Check to see if any of the points from the boundary from the first
-		// polygon are inside the second polygon.
+		// polygon are inside the second polygon. The smallest such point is
+		// returned, so that the result is deterministic.
+		var best XY
+		var found bool
 		for pt := range allPts {
-			if isPointInteriorToPolygon(pt, p2) {
-				return true
+			if (!found || pt.Less(best)) && isPointInteriorToPolygon(pt, p2) {
+				best, found = pt, true
 			}
 		}
+		if found {
+			return best, true
+		}
 	}
-	return false
+	return XY{}, false
+}
+
+// boundariesOverlapError creates a validation error for polygon boundaries
+// that overlap. The location of the error is the start of the first line
+// segment in the overlap.
+func boundariesOverlapError(overlap Geometry) ValidationError {
+	geoms := []Geometry{overlap}
+	if overlap.IsGeometryCollection() {
+		geoms = overlap.AsGeometryCollection().flatten()
+	}
+	var loc XY
+	var found bool
+	for _, g := range geoms {
+		if found {
+			break
+		}
+		switch {
+		case g.IsLine():
+			loc, found = g.AsLine().StartPoint().XY(), true
+		case g.IsLineString():
+			loc, found = g.AsLineString().StartPoint().XY(), true
+		case g.IsMultiLineString() && g.AsMultiLineString().NumLineStrings() > 0:
+			loc, found = g.AsMultiLineString().LineStringN(0).StartPoint().XY(), true
+		}
+	}
+	if !found {
+		return validationError(ReasonBoundariesOverlap)
+	}
+	return validationErrorAt(ReasonBoundariesOverlap, loc)
 }
 
 func isPointInteriorToPolygon(pt XY, poly Polygon) bool {
@@ -187,7 +248,7 @@ func isPointInteriorToPolygon(pt XY, poly Polygon) bool {
 
 // AsGeometry converts this MultiPolygon into a Geometry.
 func (m MultiPolygon) AsGeometry() Geometry {
-	return Geometry{multiPolygonTag, unsafe.Pointer(&m)}
+	return Geometry{tag: multiPolygonTag, ptr: unsafe.Pointer(&m)}
 }
 
 // NumPolygons gives the number of Polygon elements in the MultiPolygon.
@@ -205,9 +266,9 @@ func (m MultiPolygon) AsText() string {
 }
 
 func (m MultiPolygon) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("MULTIPOLYGON")...)
+	dst = appendWKTHeader(dst, "MULTIPOLYGON", m.ctype)
 	if len(m.polys) == 0 {
-		return append(dst, []byte(" EMPTY")...)
+		return appendWKTEmpty(dst, m.ctype)
 	}
 	dst = append(dst, '(')
 	for i, poly := range m.polys {
@@ -232,6 +293,24 @@ func (m MultiPolygon) Intersection(g Geometry) (Geometry, error) {
 	return intersection(m.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (m MultiPolygon) Union(g Geometry) (Geometry, error) {
+	return union(m.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (m MultiPolygon) Difference(g Geometry) (Geometry, error) {
+	return difference(m.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (m MultiPolygon) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(m.AsGeometry(), g)
+}
+
 func (m MultiPolygon) IsEmpty() bool {
 	return len(m.polys) == 0
 }
@@ -259,7 +338,7 @@ func (m MultiPolygon) Boundary() MultiLineString {
 			bounds = append(bounds, inner)
 		}
 	}
-	return NewMultiLineString(bounds)
+	return NewMultiLineString(bounds).ForceCoordinatesType(m.ctype)
 }
 
 func (m MultiPolygon) Value() (driver.Value, error) {
@@ -270,15 +349,19 @@ func (m MultiPolygon) Value() (driver.Value, error) {
 
 func (m MultiPolygon) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	m.writeWKB(marsh)
+	return marsh.err
+}
+
+func (m MultiPolygon) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypeMultiPolygon)
+	marsh.writeGeomType(wkbGeomTypeMultiPolygon, m.ctype)
 	n := m.NumPolygons()
 	marsh.writeCount(n)
 	for i := 0; i < n; i++ {
 		poly := m.PolygonN(i)
-		marsh.setErr(poly.AsBinary(w))
+		poly.writeWKB(marsh)
 	}
-	return marsh.err
 }
 
 func (m MultiPolygon) ConvexHull() Geometry {
@@ -313,7 +396,7 @@ func (m MultiPolygon) TransformXY(fn func(XY) XY, opts ...ConstructorOption) (Ge
 	coords := m.Coordinates()
 	transform3dCoords(coords, fn)
 	mp, err := NewMultiPolygonC(coords, opts...)
-	return mp.AsGeometry(), err
+	return mp.ForceCoordinatesType(m.ctype).AsGeometry(), err
 }
 
 // EqualsExact checks if this MultiPolygon is exactly equal to another MultiPolygon.
@@ -383,5 +466,30 @@ func (m MultiPolygon) Reverse() MultiPolygon {
 	if err != nil {
 		panic("Reverse of an existing MultiPolygon should not fail")
 	}
-	return m2
+	return m2.ForceCoordinatesType(m.ctype)
+}
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the MultiPolygon.
+func (m MultiPolygon) CoordinatesType() CoordinatesType {
+	return m.ctype
+}
+
+// ForceCoordinatesType returns a new MultiPolygon with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0.
+func (m MultiPolygon) ForceCoordinatesType(newCType CoordinatesType) MultiPolygon {
+	var polys []Polygon
+	if len(m.polys) > 0 {
+		polys = make([]Polygon, len(m.polys))
+		for i, p := range m.polys {
+			polys[i] = p.ForceCoordinatesType(newCType)
+		}
+	}
+	return MultiPolygon{polys, newCType}
+}
+
+// Force2D returns a copy of the MultiPolygon with Z and M values removed.
+func (m MultiPolygon) Force2D() MultiPolygon {
+	return m.ForceCoordinatesType(DimXY)
 }
@@ -33,7 +33,7 @@ func NewPointC(c Coordinates, _ ...ConstructorOption) Point {
 
 // AsGeometry converts this Point into a Geometry.
 func (p Point) AsGeometry() Geometry {
-	return Geometry{pointTag, unsafe.Pointer(&p)}
+	return Geometry{tag: pointTag, ptr: unsafe.Pointer(&p)}
 }
 
 // XY gives the XY location of the point.
@@ -51,15 +51,13 @@ func (p Point) AsText() string {
 }
 
 func (p Point) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("POINT")...)
+	dst = appendWKTHeader(dst, "POINT", p.CoordinatesType())
 	return p.appendWKTBody(dst)
 }
 
 func (p Point) appendWKTBody(dst []byte) []byte {
 	dst = append(dst, '(')
-	dst = appendFloat(dst, p.coords.X)
-	dst = append(dst, ' ')
-	dst = appendFloat(dst, p.coords.Y)
+	dst = p.coords.appendWKT(dst)
 	return append(dst, ')')
 }
 
@@ -71,6 +69,24 @@ func (p Point) Intersection(g Geometry) (Geometry, error) {
 	return intersection(p.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (p Point) Union(g Geometry) (Geometry, error) {
+	return union(p.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (p Point) Difference(g Geometry) (Geometry, error) {
+	return difference(p.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (p Point) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(p.AsGeometry(), g)
+}
+
 func (p Point) Intersects(g Geometry) bool {
 	return hasIntersection(p.AsGeometry(), g)
 }
@@ -84,7 +100,7 @@ func (p Point) Envelope() (Envelope, bool) {
 }
 
 func (p Point) Boundary() GeometryCollection {
-	return NewGeometryCollection(nil)
+	return NewGeometryCollection(nil).ForceCoordinatesType(p.CoordinatesType())
 }
 
 func (p Point) Value() (driver.Value, error) {
@@ -95,13 +111,16 @@ func (p Point) Value() (driver.Value, error) {
 
 func (p Point) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
-	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypePoint)
-	marsh.writeFloat64(p.coords.X)
-	marsh.writeFloat64(p.coords.Y)
+	p.writeWKB(marsh)
 	return marsh.err
 }
 
+func (p Point) writeWKB(marsh *wkbMarshaller) {
+	marsh.writeByteOrder()
+	marsh.writeGeomType(wkbGeomTypePoint, p.CoordinatesType())
+	marsh.writeCoordinates(p.coords)
+}
+
 // ConvexHull returns the convex hull of this Point, which is always the same
 // point.
 func (p Point) ConvexHull() Geometry {
@@ -122,7 +141,7 @@ func (p Point) TransformXY(fn func(XY) XY, opts ...ConstructorOption) (Geometry,
 // EqualsExact checks if this Point is exactly equal to another Point.
 func (p Point) EqualsExact(other Geometry, opts ...EqualsExactOption) bool {
 	return other.IsPoint() &&
-		newEqualsExactOptionSet(opts).eq(p.XY(), other.AsPoint().XY())
+		newEqualsExactOptionSet(opts).eq(p.coords, other.AsPoint().coords)
 }
 
 // IsValid checks if this Point is valid, but there is not way to indicate if
@@ -140,3 +159,19 @@ func (p Point) Centroid() Point {
 func (p Point) Reverse() Point {
 	return Point{p.coords}
 }
+
+// CoordinatesType returns the CoordinatesType used to represent the Point.
+func (p Point) CoordinatesType() CoordinatesType {
+	return p.coords.Type
+}
+
+// ForceCoordinatesType returns a new Point with a different CoordinatesType.
+// If a dimension is added, then new values are populated with 0.
+func (p Point) ForceCoordinatesType(newCType CoordinatesType) Point {
+	return Point{p.coords.ForceCoordinatesType(newCType)}
+}
+
+// Force2D returns a copy of the Point with Z and M values removed.
+func (p Point) Force2D() Point {
+	return p.ForceCoordinatesType(DimXY)
+}
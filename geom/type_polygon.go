@@ -3,7 +3,6 @@ package geom
 import (
 	"bytes"
 	"database/sql/driver"
-	"errors"
 	"io"
 	"math"
 	"sort"
@@ -61,7 +60,11 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 	for i := range intervals {
 		env, ok := ring(i).Envelope()
 		if !ok {
-			return Polygon{}, errors.New("polygon rings must not be empty")
+			ringIdx := i + 1
+			if i == len(holes) {
+				ringIdx = 0
+			}
+			return Polygon{}, validationErrorInRing(ReasonRingEmpty, ringIdx)
 		}
 		intervals[i].minX = env.Min().X
 		intervals[i].maxX = env.Max().X
@@ -70,10 +73,15 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 	for _, i := range rings {
 		r := ring(i)
 		if doCheapValidations(opts) && !r.IsClosed() {
-			return Polygon{}, errors.New("polygon rings must be closed")
+			return Polygon{}, validationErrorAt(ReasonRingNotClosed, r.StartPoint().XY())
+		}
+		if doCheapValidations(opts) && r.CoordinatesType() != outer.CoordinatesType() {
+			return Polygon{}, errMixedCoordinatesTypes
 		}
-		if doExpensiveValidations(opts) && !r.IsSimple() {
-			return Polygon{}, errors.New("polygon rings must be simple")
+		if doExpensiveValidations(opts) {
+			if loc, ok := r.selfIntersection(); ok {
+				return Polygon{}, validationErrorAt(ReasonRingSelfIntersection, loc)
+			}
 		}
 	}
 
@@ -103,16 +111,13 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 			otherRing := ring(other)
 			if current < len(holes) && other < len(holes) {
 				// Check is skipped if the outer ring is involved.
-				nestedFwd := pointRingSide(
-					currentRing.StartPoint().XY(),
-					otherRing,
-				) == interior
-				nestedRev := pointRingSide(
-					otherRing.StartPoint().XY(),
-					currentRing,
-				) == interior
-				if nestedFwd || nestedRev {
-					return Polygon{}, errors.New("polygon must not have nested rings")
+				currentStart := currentRing.StartPoint().XY()
+				otherStart := otherRing.StartPoint().XY()
+				if pointRingSide(currentStart, otherRing) == interior {
+					return Polygon{}, validationErrorAt(ReasonNestedHoles, currentStart)
+				}
+				if pointRingSide(otherStart, currentRing) == interior {
+					return Polygon{}, validationErrorAt(ReasonNestedHoles, otherStart)
 				}
 			}
 
@@ -125,7 +130,7 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 				continue
 			}
 			if ext.multiplePoints {
-				return Polygon{}, errors.New("polygon rings must not intersect at multiple points")
+				return Polygon{}, validationErrorAt(ReasonRingsIntersect, ext.singlePoint)
 			}
 
 			interVert, ok := interVerts[ext.singlePoint]
@@ -145,7 +150,7 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 		for i := 0; i < hole.NumPoints(); i++ {
 			pt := hole.PointN(i)
 			if pointRingSide(pt.XY(), outer) == exterior {
-				return Polygon{}, errors.New("hole must be inside outer ring")
+				return Polygon{}, validationErrorAt(ReasonHoleOutsideShell, pt.XY())
 			}
 		}
 	}
@@ -155,8 +160,17 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 	// intersection vertex and a ring vertex if the ring participates in that
 	// intersection. The interior of the polygon is connected iff the graph
 	// does not contain a cycle.
-	if graph.hasCycle() {
-		return Polygon{}, errors.New("polygon interiors must be connected")
+	if u, v, ok := graph.findCycle(); ok {
+		// The graph is bipartite, so exactly one of u and v is an
+		// intersection vertex.
+		if u < len(rings) {
+			u = v
+		}
+		for xy, interVert := range interVerts {
+			if interVert == u {
+				return Polygon{}, validationErrorAt(ReasonDisconnectedInterior, xy)
+			}
+		}
 	}
 
 	return Polygon{outer: outer, holes: holes}, nil
@@ -167,10 +181,13 @@ func NewPolygon(outer LineString, holes []LineString, opts ...ConstructorOption)
 // Coordinates slice is the position within the ring.
 func NewPolygonC(coords [][]Coordinates, opts ...ConstructorOption) (Polygon, error) {
 	if len(coords) == 0 {
-		return Polygon{}, errors.New("Polygon must have an outer ring")
+		return Polygon{}, validationError(ReasonMissingOuterRing)
 	}
 	rings := make([]LineString, len(coords))
 	for i := range rings {
+		if len(coords[i]) == 0 && doCheapValidations(opts) {
+			return Polygon{}, validationErrorInRing(ReasonRingEmpty, i)
+		}
 		var err error
 		rings[i], err = NewLineStringC(coords[i], opts...)
 		if err != nil {
@@ -189,7 +206,7 @@ func NewPolygonXY(pts [][]XY, opts ...ConstructorOption) (Polygon, error) {
 
 // AsGeometry converts this Polygon into a Geometry.
 func (p Polygon) AsGeometry() Geometry {
-	return Geometry{polygonTag, unsafe.Pointer(&p)}
+	return Geometry{tag: polygonTag, ptr: unsafe.Pointer(&p)}
 }
 
 // ExteriorRing gives the exterior ring of the polygon boundary.
@@ -214,7 +231,7 @@ func (p Polygon) AsText() string {
 }
 
 func (p Polygon) AppendWKT(dst []byte) []byte {
-	dst = append(dst, []byte("POLYGON")...)
+	dst = appendWKTHeader(dst, "POLYGON", p.CoordinatesType())
 	return p.appendWKTBody(dst)
 }
 
@@ -237,6 +254,24 @@ func (p Polygon) Intersection(g Geometry) (Geometry, error) {
 	return intersection(p.AsGeometry(), g)
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry.
+func (p Polygon) Union(g Geometry) (Geometry, error) {
+	return union(p.AsGeometry(), g)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+func (p Polygon) Difference(g Geometry) (Geometry, error) {
+	return difference(p.AsGeometry(), g)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+func (p Polygon) SymmetricDifference(g Geometry) (Geometry, error) {
+	return symmetricDifference(p.AsGeometry(), g)
+}
+
 func (p Polygon) Intersects(g Geometry) bool {
 	return hasIntersection(p.AsGeometry(), g)
 }
@@ -284,20 +319,21 @@ func (p Polygon) Value() (driver.Value, error) {
 
 func (p Polygon) AsBinary(w io.Writer) error {
 	marsh := newWKBMarshaller(w)
+	p.writeWKB(marsh)
+	return marsh.err
+}
+
+func (p Polygon) writeWKB(marsh *wkbMarshaller) {
 	marsh.writeByteOrder()
-	marsh.writeGeomType(wkbGeomTypePolygon)
+	marsh.writeGeomType(wkbGeomTypePolygon, p.CoordinatesType())
 	rings := p.rings()
 	marsh.writeCount(len(rings))
 	for _, ring := range rings {
-		numPts := ring.NumPoints()
-		marsh.writeCount(numPts)
-		for i := 0; i < numPts; i++ {
-			pt := ring.PointN(i)
-			marsh.writeFloat64(pt.XY().X)
-			marsh.writeFloat64(pt.XY().Y)
+		marsh.writeCount(len(ring.coords))
+		for _, c := range ring.coords {
+			marsh.writeCoordinates(c)
 		}
 	}
-	return marsh.err
 }
 
 // ConvexHull returns the convex hull of the Polygon, which is always another
@@ -456,3 +492,24 @@ func (p Polygon) Reverse() Polygon {
 	}
 	return p2
 }
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the Polygon.
+func (p Polygon) CoordinatesType() CoordinatesType {
+	return p.outer.CoordinatesType()
+}
+
+// ForceCoordinatesType returns a new Polygon with a different CoordinatesType.
+// If a dimension is added, then new values are populated with 0.
+func (p Polygon) ForceCoordinatesType(newCType CoordinatesType) Polygon {
+	holes := make([]LineString, len(p.holes))
+	for i, h := range p.holes {
+		holes[i] = h.ForceCoordinatesType(newCType)
+	}
+	return Polygon{p.outer.ForceCoordinatesType(newCType), holes}
+}
+
+// Force2D returns a copy of the Polygon with Z and M values removed.
+func (p Polygon) Force2D() Polygon {
+	return p.ForceCoordinatesType(DimXY)
+}
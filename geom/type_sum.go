@@ -3,17 +3,24 @@ package geom
 import (
 	"bytes"
 	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"strings"
+	"strconv"
 	"unsafe"
 )
 
 // Geometry is a single geometry of any type. It's zero value is valid and is
 // an empty GeometryCollection.
+//
+// A Geometry may optionally carry a Spatial Reference System Identifier
+// (SRID). The SRID is metadata only, and isn't used by any of the geometric
+// operations. The concrete geometry types don't carry an SRID, so it is lost
+// when converting a Geometry to one of them.
 type Geometry struct {
-	tag geometryTag
-	ptr unsafe.Pointer
+	tag  geometryTag
+	ptr  unsafe.Pointer
+	srid int32
 }
 
 type geometryTag int
@@ -81,6 +88,20 @@ func (g Geometry) check(tag geometryTag) {
 	}
 }
 
+// SRID returns the Spatial Reference System Identifier of the Geometry. A
+// value of 0 indicates that the SRID is unknown or unspecified.
+func (g Geometry) SRID() int {
+	return int(g.srid)
+}
+
+// WithSRID returns a copy of the Geometry with its SRID replaced by the
+// supplied value. A value of 0 indicates that the SRID is unknown or
+// unspecified.
+func (g Geometry) WithSRID(srid int) Geometry {
+	g.srid = int32(srid)
+	return g
+}
+
 // AsGeometryCollection returns the geometry as a GeometryCollection. It panics
 // if the geometry is not a GeometryCollection.
 func (g Geometry) AsGeometryCollection() GeometryCollection {
@@ -270,28 +291,113 @@ func (g Geometry) AsBinary(w io.Writer) error {
 	}
 }
 
+func (g Geometry) writeWKB(marsh *wkbMarshaller) {
+	switch g.tag {
+	case geometryCollectionTag:
+		g.AsGeometryCollection().writeWKB(marsh)
+	case emptySetTag:
+		g.AsEmptySet().writeWKB(marsh)
+	case pointTag:
+		g.AsPoint().writeWKB(marsh)
+	case lineTag:
+		g.AsLine().writeWKB(marsh)
+	case lineStringTag:
+		g.AsLineString().writeWKB(marsh)
+	case polygonTag:
+		g.AsPolygon().writeWKB(marsh)
+	case multiPointTag:
+		g.AsMultiPoint().writeWKB(marsh)
+	case multiLineStringTag:
+		g.AsMultiLineString().writeWKB(marsh)
+	case multiPolygonTag:
+		g.AsMultiPolygon().writeWKB(marsh)
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+// AsHexWKB returns the hex encoded WKB (Well Known Binary) representation of
+// the geometry. The SRID isn't included (see AsHexEWKB).
+func (g Geometry) AsHexWKB() string {
+	var buf bytes.Buffer
+	if err := g.AsBinary(&buf); err != nil {
+		// Writes to a bytes.Buffer never fail, so this can't happen.
+		panic(err)
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// AsEWKB writes the EWKB (Extended Well Known Binary) representation of the
+// geometry to the writer. EWKB is the binary format used by PostGIS. It
+// differs from WKB in that it includes the geometry's SRID (if it is
+// non-zero), and uses flags rather than ISO type codes to indicate the
+// presence of Z and M values.
+func (g Geometry) AsEWKB(w io.Writer) error {
+	marsh := newEWKBMarshaller(w, g.SRID())
+	g.writeWKB(marsh)
+	return marsh.err
+}
+
+// AsHexEWKB returns the hex encoded EWKB (Extended Well Known Binary)
+// representation of the geometry, which includes its SRID. This is the format
+// that PostGIS uses for geometry values that are selected without any
+// conversion function.
+func (g Geometry) AsHexEWKB() string {
+	var buf bytes.Buffer
+	if err := g.AsEWKB(&buf); err != nil {
+		// Writes to a bytes.Buffer never fail, so this can't happen.
+		panic(err)
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// AsEWKT returns the EWKT (Extended Well Known Text) representation of the
+// geometry. EWKT is the text format used by PostGIS. It is the same as WKT,
+// but is prefixed with the SRID (if it is non-zero), e.g.
+// "SRID=4326;POINT(1 2)".
+func (g Geometry) AsEWKT() string {
+	var dst []byte
+	if g.srid != 0 {
+		dst = append(dst, "SRID="...)
+		dst = strconv.AppendInt(dst, int64(g.srid), 10)
+		dst = append(dst, ';')
+	}
+	return string(g.appendWKT(dst))
+}
+
 // Value implements the database/sql/driver.Valuer interface by returning the
-// WKB (Well Known Binary) representation of this Geometry.
+// WKB (Well Known Binary) representation of this Geometry. If the Geometry has
+// a non-zero SRID, then EWKB (Extended Well Known Binary) is returned instead
+// so that the SRID is retained.
 func (g Geometry) Value() (driver.Value, error) {
 	var buf bytes.Buffer
-	err := g.AsBinary(&buf)
+	var err error
+	if g.srid != 0 {
+		err = g.AsEWKB(&buf)
+	} else {
+		err = g.AsBinary(&buf)
+	}
 	return buf.Bytes(), err
 }
 
 // Scan implements the database/sql.Scanner interface by parsing the src value
-// as WKB (Well Known Binary).
+// as either WKB (Well Known Binary) or EWKB (Extended Well Known Binary). Any
+// SRID present in the EWKB is retained by the Geometry.
+//
+// The src value may also be hex encoded, which is the format PostGIS uses
+// when geometry columns are selected without ST_AsBinary.
 //
 // It constructs the resultant geometry with no ConstructionOptions. If
 // ConstructionOptions are needed, then the value should be scanned into a byte
-// slice and then UnmarshalWKB called manually (passing in the
+// slice and then UnmarshalEWKB called manually (passing in the
 // ConstructionOptions as desired).
 func (g *Geometry) Scan(src interface{}) error {
-	var r io.Reader
+	var wkb []byte
 	switch src := src.(type) {
 	case []byte:
-		r = bytes.NewReader(src)
+		wkb = src
 	case string:
-		r = strings.NewReader(src)
+		wkb = []byte(src)
 	default:
 		// nil is specifically not supported. It _could_ map to an empty
 		// geometry, however then the caller wouldn't be able to differentiate
@@ -300,7 +406,17 @@ func (g *Geometry) Scan(src interface{}) error {
 		return fmt.Errorf("unsupported src type in Scan: %T", src)
 	}
 
-	unmarshalled, err := UnmarshalWKB(r)
+	// Binary WKB always starts with a 0x00 or 0x01 byte order marker,
+	// whereas hex encoded WKB always starts with an ASCII '0'.
+	if len(wkb) > 0 && wkb[0] == '0' {
+		decoded := make([]byte, hex.DecodedLen(len(wkb)))
+		if _, err := hex.Decode(decoded, wkb); err != nil {
+			return fmt.Errorf("invalid hex encoded WKB: %v", err)
+		}
+		wkb = decoded
+	}
+
+	unmarshalled, err := UnmarshalEWKB(bytes.NewReader(wkb))
 	if err != nil {
 		return err
 	}
@@ -513,17 +629,98 @@ func (g Geometry) IsValid() bool {
 	}
 }
 
+// IsValidReason gives the reason that the geometry is invalid, or nil if the
+// geometry is valid. Geometric problems (e.g. self intersecting rings) are
+// reported using a ValidationError, which also gives the location of the
+// problem.
+func (g Geometry) IsValidReason() error {
+	var err error
+	switch g.tag {
+	case geometryCollectionTag:
+		for _, child := range g.AsGeometryCollection().flatten() {
+			if err = child.IsValidReason(); err != nil {
+				break
+			}
+		}
+	case emptySetTag, pointTag, multiPointTag:
+		// These geometries are always valid.
+	case lineTag:
+		ln := g.AsLine()
+		_, err = NewLineC(ln.a, ln.b)
+	case lineStringTag:
+		_, err = NewLineStringC(g.AsLineString().Coordinates())
+	case polygonTag:
+		_, err = NewPolygonC(g.AsPolygon().Coordinates())
+	case multiLineStringTag:
+		_, err = NewMultiLineStringC(g.AsMultiLineString().Coordinates())
+	case multiPolygonTag:
+		_, err = NewMultiPolygonC(g.AsMultiPolygon().Coordinates())
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+	return err
+}
+
 // Intersects returns true if the intersection of this gemoetry with the
 // specified other geometry is not empty, or false if it is empty.
 func (g Geometry) Intersects(other Geometry) bool {
 	return hasIntersection(g, other)
 }
 
+// Disjoint checks if this geometry and another geometry have no points in
+// common. See the Disjoint function for details.
+func (g Geometry) Disjoint(other Geometry) (bool, error) {
+	return Disjoint(g, other)
+}
+
+// Contains checks if this geometry contains another geometry. See the
+// Contains function for details.
+func (g Geometry) Contains(other Geometry) (bool, error) {
+	return Contains(g, other)
+}
+
+// Within checks if this geometry is within another geometry. See the Within
+// function for details.
+func (g Geometry) Within(other Geometry) (bool, error) {
+	return Within(g, other)
+}
+
+// Covers checks if this geometry covers another geometry. See the Covers
+// function for details.
+func (g Geometry) Covers(other Geometry) (bool, error) {
+	return Covers(g, other)
+}
+
+// CoveredBy checks if this geometry is covered by another geometry. See the
+// CoveredBy function for details.
+func (g Geometry) CoveredBy(other Geometry) (bool, error) {
+	return CoveredBy(g, other)
+}
+
+// Touches checks if this geometry touches another geometry. See the Touches
+// function for details.
+func (g Geometry) Touches(other Geometry) (bool, error) {
+	return Touches(g, other)
+}
+
+// Crosses checks if this geometry crosses another geometry. See the Crosses
+// function for details.
+func (g Geometry) Crosses(other Geometry) (bool, error) {
+	return Crosses(g, other)
+}
+
+// Overlaps checks if this geometry overlaps another geometry. See the
+// Overlaps function for details.
+func (g Geometry) Overlaps(other Geometry) (bool, error) {
+	return Overlaps(g, other)
+}
+
 // Intersection returns a geometric object that represents the point set
-// intersection of this geometry with another geometry.
+// intersection of this geometry with another geometry. It is implemented for
+// all pairs of geometries.
 //
-// It is not implemented for all possible pairs of geometries, and returns an
-// error in those cases.
+// An error may be returned in pathological cases where numerical robustness
+// issues cause an invalid result to be produced.
 func (g Geometry) Intersection(other Geometry) (Geometry, error) {
 	result, err := intersection(g, other)
 	if err != nil {
@@ -532,35 +729,172 @@ func (g Geometry) Intersection(other Geometry) (Geometry, error) {
 	return result, nil
 }
 
+// Union returns a geometric object that represents the point set union of
+// this geometry with another geometry. The result is noded, so any points
+// where linework from the inputs crosses are included as vertices in the
+// result.
+//
+// Z and M values are not retained in the result. If the result is empty,
+// then an empty geometry with the highest dimension of the two inputs is
+// returned (which matches the behaviour of PostGIS).
+func (g Geometry) Union(other Geometry) (Geometry, error) {
+	return union(g, other)
+}
+
+// Difference returns a geometric object that represents the part of this
+// geometry that doesn't intersect with another geometry.
+//
+// Z and M values are not retained in the result. If the result is empty,
+// then an empty geometry with the dimension of this geometry is returned
+// (which matches the behaviour of PostGIS).
+func (g Geometry) Difference(other Geometry) (Geometry, error) {
+	return difference(g, other)
+}
+
+// SymmetricDifference returns a geometric object that represents the parts of
+// this geometry and another geometry that don't intersect with each other.
+//
+// Z and M values are not retained in the result. If the result is empty,
+// then an empty geometry with the highest dimension of the two inputs is
+// returned (which matches the behaviour of PostGIS).
+func (g Geometry) SymmetricDifference(other Geometry) (Geometry, error) {
+	return symmetricDifference(g, other)
+}
+
+// Buffer returns a geometric object that represents all points within the
+// given distance of this geometry. The result is always a Polygon or a
+// MultiPolygon (which is empty if the buffer doesn't contain any area).
+//
+// Negative distances shrink the areal parts of the geometry, and cause any
+// non-areal parts to be ignored. Options may be supplied to control the
+// number of segments used for curves, the style of the joins between
+// segments, and the style of the caps at the ends of lines. The defaults match
+// those used by PostGIS.
+//
+// Z and M values are not retained in the result.
+func (g Geometry) Buffer(distance float64, opts ...BufferOption) (Geometry, error) {
+	return buffer(g, distance, newBufferOptionSet(opts))
+}
+
+// Distance calculates the shortest distance (using the Euclidean metric)
+// between this geometry and another. It returns false if either geometry is
+// empty.
+func (g Geometry) Distance(other Geometry) (float64, bool) {
+	_, _, dist, ok := closestPoints(g, other, 0)
+	return dist, ok
+}
+
+// ClosestPoints finds a point on this geometry and a point on another geometry
+// such that the distance between the two points is the shortest distance
+// between the two geometries (the two points are the same if the geometries
+// intersect). It returns false if either geometry is empty.
+//
+// Z and M values are not retained in the resultant points.
+func (g Geometry) ClosestPoints(other Geometry) (Point, Point, bool) {
+	pt1, pt2, _, ok := closestPoints(g, other, 0)
+	if !ok {
+		return Point{}, Point{}, false
+	}
+	return NewPointXY(pt1), NewPointXY(pt2), true
+}
+
+// DWithin checks if this geometry and another geometry are within the given
+// distance of each other. It returns false if either geometry is empty. It's
+// faster than calculating the distance between the geometries, since it stops
+// as soon as any part of the geometries are found to be close enough to each
+// other.
+func (g Geometry) DWithin(other Geometry, distance float64) bool {
+	env1, ok1 := g.Envelope()
+	env2, ok2 := other.Envelope()
+	if !ok1 || !ok2 || env1.Distance(env2) > distance {
+		return false
+	}
+	_, _, dist, ok := closestPoints(g, other, distance)
+	return ok && dist <= distance
+}
+
+// Simplify simplifies the LineStrings and Polygon rings making up the
+// geometry using the Ramer-Douglas-Peucker algorithm. Points further than the
+// tolerance from the simplified geometry are retained. LineStrings and rings
+// that collapse are removed from the result (a Polygon is removed if its
+// outer ring collapses). Points and MultiPoints are not altered.
+//
+// The simplified result is validated using the usual constructor validations
+// (which can be controlled using ConstructorOptions). Because the algorithm
+// doesn't consider how rings interact with each other, simplified Polygons
+// and MultiPolygons may fail validation, in which case an error is returned.
+// SimplifyPreserveTopology should be used to avoid this. The SRID is
+// retained.
+func (g Geometry) Simplify(tolerance float64, opts ...ConstructorOption) (Geometry, error) {
+	return simplify(g, func(seqs [][]Coordinates) [][]Coordinates {
+		for i := range seqs {
+			seqs[i] = douglasPeucker(seqs[i], tolerance)
+		}
+		return seqs
+	}, opts)
+}
+
+// SimplifyVW simplifies the LineStrings and Polygon rings making up the
+// geometry using the Visvalingam-Whyatt algorithm. Points are repeatedly
+// removed while the triangle that they form with their neighbours has an
+// area less than the threshold. The end points of each LineString and ring
+// are always retained. Collapsed LineStrings and rings, and validation, are
+// treated in the same way as Simplify. The SRID is retained.
+func (g Geometry) SimplifyVW(areaThreshold float64, opts ...ConstructorOption) (Geometry, error) {
+	return simplify(g, func(seqs [][]Coordinates) [][]Coordinates {
+		for i := range seqs {
+			seqs[i] = visvalingamWhyatt(seqs[i], areaThreshold)
+		}
+		return seqs
+	}, opts)
+}
+
+// SimplifyPreserveTopology simplifies the geometry in the same way as
+// Simplify, except that points are only removed if doing so doesn't change
+// the topology of the geometry. LineStrings and rings are not allowed to cross
+// themselves or each other, or to move to the other side of each other, and
+// rings are not allowed to collapse. This means that the simplified result of
+// a valid Polygon or MultiPolygon is also valid. The SRID is retained.
+func (g Geometry) SimplifyPreserveTopology(tolerance float64, opts ...ConstructorOption) (Geometry, error) {
+	return simplify(g, func(seqs [][]Coordinates) [][]Coordinates {
+		return simplifyPreserveTopology(seqs, tolerance)
+	}, opts)
+}
+
 // TransformXY transforms this Geometry into another geometry according the
 // mapping provided by the XY function. Some classes of mappings (such as
 // affine transformations) will preserve the validity this Geometry in the
 // transformed Geometry, in which case no error will be returned. Other
 // types of transformations may result in a validation error if their
-// mapping results in an invalid Geometry.
+// mapping results in an invalid Geometry. The SRID is retained.
 func (g Geometry) TransformXY(fn func(XY) XY, opts ...ConstructorOption) (Geometry, error) {
+	var (
+		result Geometry
+		err    error
+	)
 	switch g.tag {
 	case geometryCollectionTag:
-		return g.AsGeometryCollection().TransformXY(fn, opts...)
+		result, err = g.AsGeometryCollection().TransformXY(fn, opts...)
 	case emptySetTag:
-		return g.AsEmptySet().TransformXY(fn, opts...)
+		result, err = g.AsEmptySet().TransformXY(fn, opts...)
 	case pointTag:
-		return g.AsPoint().TransformXY(fn, opts...)
+		result, err = g.AsPoint().TransformXY(fn, opts...)
 	case lineTag:
-		return g.AsLine().TransformXY(fn, opts...)
+		result, err = g.AsLine().TransformXY(fn, opts...)
 	case lineStringTag:
-		return g.AsLineString().TransformXY(fn, opts...)
+		result, err = g.AsLineString().TransformXY(fn, opts...)
 	case polygonTag:
-		return g.AsPolygon().TransformXY(fn, opts...)
+		result, err = g.AsPolygon().TransformXY(fn, opts...)
 	case multiPointTag:
-		return g.AsMultiPoint().TransformXY(fn, opts...)
+		result, err = g.AsMultiPoint().TransformXY(fn, opts...)
 	case multiLineStringTag:
-		return g.AsMultiLineString().TransformXY(fn, opts...)
+		result, err = g.AsMultiLineString().TransformXY(fn, opts...)
 	case multiPolygonTag:
-		return g.AsMultiPolygon().TransformXY(fn, opts...)
+		result, err = g.AsMultiPolygon().TransformXY(fn, opts...)
 	default:
 		panic("unknown geometry: " + g.tag.String())
 	}
+	return result.WithSRID(g.SRID()), err
 }
 
 // Length gives the length of a Line, LineString, or MultiLineString
@@ -677,28 +1011,93 @@ func (g Geometry) IsSimple() (isSimple bool, wellDefined bool) {
 
 // Reverse returns a new geometry containing coordinates listed in reverse order.
 // Multi component geometries do not reverse the order of their components,
-// but merely reverse each component's coordinates in place.
+// but merely reverse each component's coordinates in place. The SRID is
+// retained.
 func (g Geometry) Reverse() Geometry {
+	var result Geometry
 	switch g.tag {
 	case geometryCollectionTag:
-		return g.AsGeometryCollection().Reverse().AsGeometry()
+		result = g.AsGeometryCollection().Reverse().AsGeometry()
 	case emptySetTag:
-		return g.AsEmptySet().Reverse().AsGeometry()
+		result = g.AsEmptySet().Reverse().AsGeometry()
 	case pointTag:
-		return g.AsPoint().Reverse().AsGeometry()
+		result = g.AsPoint().Reverse().AsGeometry()
 	case lineTag:
-		return g.AsLine().Reverse().AsGeometry()
+		result = g.AsLine().Reverse().AsGeometry()
 	case lineStringTag:
-		return g.AsLineString().Reverse().AsGeometry()
+		result = g.AsLineString().Reverse().AsGeometry()
 	case polygonTag:
-		return g.AsPolygon().Reverse().AsGeometry()
+		result = g.AsPolygon().Reverse().AsGeometry()
 	case multiPointTag:
-		return g.AsMultiPoint().Reverse().AsGeometry()
+		result = g.AsMultiPoint().Reverse().AsGeometry()
 	case multiLineStringTag:
-		return g.AsMultiLineString().Reverse().AsGeometry()
+		result = g.AsMultiLineString().Reverse().AsGeometry()
 	case multiPolygonTag:
-		return g.AsMultiPolygon().Reverse().AsGeometry()
+		result = g.AsMultiPolygon().Reverse().AsGeometry()
 	default:
 		panic("unknown geometry: " + g.tag.String())
 	}
+	return result.WithSRID(g.SRID())
+}
+
+// CoordinatesType returns the CoordinatesType used to represent points making
+// up the geometry.
+func (g Geometry) CoordinatesType() CoordinatesType {
+	switch g.tag {
+	case geometryCollectionTag:
+		return g.AsGeometryCollection().CoordinatesType()
+	case emptySetTag:
+		return g.AsEmptySet().CoordinatesType()
+	case pointTag:
+		return g.AsPoint().CoordinatesType()
+	case lineTag:
+		return g.AsLine().CoordinatesType()
+	case lineStringTag:
+		return g.AsLineString().CoordinatesType()
+	case polygonTag:
+		return g.AsPolygon().CoordinatesType()
+	case multiPointTag:
+		return g.AsMultiPoint().CoordinatesType()
+	case multiLineStringTag:
+		return g.AsMultiLineString().CoordinatesType()
+	case multiPolygonTag:
+		return g.AsMultiPolygon().CoordinatesType()
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+}
+
+// ForceCoordinatesType returns a new Geometry with a different
+// CoordinatesType. If a dimension is added, then new values are populated
+// with 0. The SRID is retained.
+func (g Geometry) ForceCoordinatesType(newCType CoordinatesType) Geometry {
+	var result Geometry
+	switch g.tag {
+	case geometryCollectionTag:
+		result = g.AsGeometryCollection().ForceCoordinatesType(newCType).AsGeometry()
+	case emptySetTag:
+		result = g.AsEmptySet().ForceCoordinatesType(newCType).AsGeometry()
+	case pointTag:
+		result = g.AsPoint().ForceCoordinatesType(newCType).AsGeometry()
+	case lineTag:
+		result = g.AsLine().ForceCoordinatesType(newCType).AsGeometry()
+	case lineStringTag:
+		result = g.AsLineString().ForceCoordinatesType(newCType).AsGeometry()
+	case polygonTag:
+		result = g.AsPolygon().ForceCoordinatesType(newCType).AsGeometry()
+	case multiPointTag:
+		result = g.AsMultiPoint().ForceCoordinatesType(newCType).AsGeometry()
+	case multiLineStringTag:
+		result = g.AsMultiLineString().ForceCoordinatesType(newCType).AsGeometry()
+	case multiPolygonTag:
+		result = g.AsMultiPolygon().ForceCoordinatesType(newCType).AsGeometry()
+	default:
+		panic("unknown geometry: " + g.tag.String())
+	}
+	return result.WithSRID(g.SRID())
+}
+
+// Force2D returns a copy of the geometry with Z and M values removed.
+func (g Geometry) Force2D() Geometry {
+	return g.ForceCoordinatesType(DimXY)
 }
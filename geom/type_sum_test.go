@@ -37,3 +37,14 @@ func TestZeroGeometry(t *testing.T) {
 
 	expectIntEq(t, z.Dimension(), 0)
 }
+
+func TestSRIDRetained(t *testing.T) {
+	g := geomFromWKT(t, "LINESTRING Z (1 2 3,4 5 6)").WithSRID(4326)
+	expectIntEq(t, g.SRID(), 4326)
+	expectIntEq(t, g.Reverse().SRID(), 4326)
+	expectIntEq(t, g.Force2D().SRID(), 4326)
+	transformed, err := g.TransformXY(func(xy XY) XY { return xy })
+	expectNoErr(t, err)
+	expectIntEq(t, transformed.SRID(), 4326)
+	expectIntEq(t, g.WithSRID(0).SRID(), 0)
+}
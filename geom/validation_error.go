@@ -0,0 +1,129 @@
+package geom
+
+import "strconv"
+
+// ValidationReason describes why a geometry is invalid.
+type ValidationReason int
+
+const (
+	// ReasonTooFewPoints indicates that a Line or LineString doesn't have
+	// enough distinct points.
+	ReasonTooFewPoints ValidationReason = iota + 1
+
+	// ReasonRingEmpty indicates that a polygon ring is empty.
+	ReasonRingEmpty
+
+	// ReasonRingNotClosed indicates that a polygon ring doesn't start and
+	// end at the same point.
+	ReasonRingNotClosed
+
+	// ReasonRingSelfIntersection indicates that a polygon ring intersects
+	// with itself (i.e. isn't simple).
+	ReasonRingSelfIntersection
+
+	// ReasonNestedHoles indicates that a polygon hole is inside another hole.
+	ReasonNestedHoles
+
+	// ReasonRingsIntersect indicates that two rings of a polygon intersect at
+	// more than one point.
+	ReasonRingsIntersect
+
+	// ReasonHoleOutsideShell indicates that a polygon hole isn't inside the
+	// polygon's outer ring.
+	ReasonHoleOutsideShell
+
+	// ReasonDisconnectedInterior indicates that the rings of a polygon
+	// intersect in a way that splits its interior into multiple parts.
+	ReasonDisconnectedInterior
+
+	// ReasonMissingOuterRing indicates that a polygon has no outer ring.
+	ReasonMissingOuterRing
+
+	// ReasonEmptyPolygon indicates that a polygon in a multipolygon is empty.
+	ReasonEmptyPolygon
+
+	// ReasonBoundariesOverlap indicates that the boundaries of two polygons
+	// in a multipolygon intersect along a line.
+	ReasonBoundariesOverlap
+
+	// ReasonNestedShells indicates that the interiors of two polygons in a
+	// multipolygon intersect.
+	ReasonNestedShells
+)
+
+// String gives a description of the validation reason.
+func (r ValidationReason) String() string {
+	switch r {
+	case ReasonTooFewPoints:
+		return "too few distinct points"
+	case ReasonRingEmpty:
+		return "polygon rings must not be empty"
+	case ReasonRingNotClosed:
+		return "polygon rings must be closed"
+	case ReasonRingSelfIntersection:
+		return "polygon rings must be simple"
+	case ReasonNestedHoles:
+		return "polygon must not have nested rings"
+	case ReasonRingsIntersect:
+		return "polygon rings must not intersect at multiple points"
+	case ReasonHoleOutsideShell:
+		return "hole must be inside outer ring"
+	case ReasonDisconnectedInterior:
+		return "polygon interiors must be connected"
+	case ReasonMissingOuterRing:
+		return "polygon must have an outer ring"
+	case ReasonEmptyPolygon:
+		return "polygon in multipolygon not allowed to be empty"
+	case ReasonBoundariesOverlap:
+		return "the boundaries of the polygon elements of multipolygons must only intersect at points"
+	case ReasonNestedShells:
+		return "polygon interiors must not intersect"
+	default:
+		return "invalid validation reason"
+	}
+}
+
+// ValidationError is the error given by geometry constructors (and
+// IsValidReason) when a geometry is invalid. It's similar to the details given
+// by the PostGIS ST_IsValidDetail function.
+type ValidationError struct {
+	// Reason describes why the geometry is invalid.
+	Reason ValidationReason
+
+	// Location is the location of the problem (e.g. the point at which a
+	// ring self intersects). It's only populated if HasLocation is true.
+	Location    XY
+	HasLocation bool
+
+	// RingIndex identifies the polygon ring that has the problem (0 for the
+	// outer ring, and i+1 for the i'th interior ring). It's only populated
+	// if HasRingIndex is true.
+	RingIndex    int
+	HasRingIndex bool
+}
+
+func validationError(reason ValidationReason) ValidationError {
+	return ValidationError{Reason: reason}
+}
+
+func validationErrorAt(reason ValidationReason, loc XY) ValidationError {
+	return ValidationError{Reason: reason, Location: loc, HasLocation: true}
+}
+
+func validationErrorInRing(reason ValidationReason, ringIdx int) ValidationError {
+	return ValidationError{Reason: reason, RingIndex: ringIdx, HasRingIndex: true}
+}
+
+// Error gives the reason, location, and ring index of the validation error.
+func (e ValidationError) Error() string {
+	msg := e.Reason.String()
+	if e.HasLocation {
+		msg += " at POINT(" +
+			strconv.FormatFloat(e.Location.X, 'f', -1, 64) + " " +
+			strconv.FormatFloat(e.Location.Y, 'f', -1, 64) + ")"
+	}
+	if e.HasRingIndex {
+		msg += " (ring " + strconv.Itoa(e.RingIndex) + ")"
+	}
+	return msg
+}
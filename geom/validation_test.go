@@ -45,6 +45,39 @@ func TestLineStringValidation(t *testing.T) {
 	}
 }
 
+func TestMixedCoordinatesTypesValidation(t *testing.T) {
+	xyz := NewXYZCoordinates(1, 1, 1)
+	xym := NewXYMCoordinates(2, 2, 2)
+	t.Run("Line", func(t *testing.T) {
+		_, err := NewLineC(xyz, xym)
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("LineString", func(t *testing.T) {
+		_, err := NewLineStringC([]Coordinates{xyz, xym, xyz})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("Polygon", func(t *testing.T) {
+		outer := geomFromWKT(t, "LINESTRING Z (0 0 0,3 0 0,0 3 0,0 0 0)").AsLineString()
+		hole := geomFromWKT(t, "LINESTRING M (1 1 0,1 2 0,2 1 0,1 1 0)").AsLineString()
+		_, err := NewPolygon(outer, []LineString{hole})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("MultiPolygon", func(t *testing.T) {
+		p1 := geomFromWKT(t, "POLYGON Z ((0 0 0,1 0 0,0 1 0,0 0 0))").AsPolygon()
+		p2 := geomFromWKT(t, "POLYGON((2 2,3 2,2 3,2 2))").AsPolygon()
+		_, err := NewMultiPolygon([]Polygon{p1, p2})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
 func TestPolygonValidation(t *testing.T) {
 	for i, wkt := range []string{
 		"POLYGON((0 0,1 0,1 1,0 1,0 0))",
@@ -179,6 +212,106 @@ func TestMultiPolygonValidation(t *testing.T) {
 	}
 }
 
+func TestValidationErrorDetail(t *testing.T) {
+	for i, tt := range []struct {
+		wkt     string
+		reason  ValidationReason
+		loc     XY
+		noLoc   bool
+		ringIdx int // -1 if there's no ring index
+	}{
+		{"LINESTRING(1 2,1 2)", ReasonTooFewPoints, XY{1, 2}, false, -1},
+		{"POLYGON((0 0,1 1,0 1))", ReasonRingNotClosed, XY{0, 0}, false, -1},
+		{"POLYGON((0 0,1 1,0 1,1 0,0 0))", ReasonRingSelfIntersection, XY{0.5, 0.5}, false, -1},
+		{"POLYGON((0 0,3 0,3 3,0 3,0 0),(1 0,3 1,2 2,1 0))", ReasonRingsIntersect, XY{3, 1}, false, -1},
+		{"POLYGON((0 0,3 0,3 3,0 3,0 0),(4 0,7 0,7 3,4 3,4 0))", ReasonHoleOutsideShell, XY{4, 0}, false, -1},
+		{
+			"POLYGON((0 0,4 0,4 4,0 4,0 0),(2 0,3 1,2 2,1 1,2 0),(2 2,3 3,2 4,1 3,2 2))",
+			ReasonDisconnectedInterior, XY{2, 2}, false, -1,
+		},
+		{
+			"POLYGON((0 0,5 0,5 5,0 5,0 0),(1 1,4 1,4 4,1 4,1 1),(2 2,3 2,3 3,2 3,2 2))",
+			ReasonNestedHoles, XY{2, 2}, false, -1,
+		},
+		{
+			"MULTIPOLYGON(((0 0,0 1,1 1,1 0,0 0)),((1 0,1 1,2 1,2 0,1 0)))",
+			ReasonBoundariesOverlap, XY{1, 1}, false, -1,
+		},
+		{
+			"MULTIPOLYGON(((0 0,3 0,3 3,0 3,0 0)),((2 1,3 3,1 2,2 1)))",
+			ReasonNestedShells, XY{1, 2}, false, -1,
+		},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0),EMPTY)", ReasonRingEmpty, XY{}, true, 1},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0),(0.2 0.2,0.4 0.2,0.4 0.4,0.2 0.2),EMPTY)", ReasonRingEmpty, XY{}, true, 2},
+		{"POLYGON(EMPTY,(0 0,1 0,1 1,0 1,0 0))", ReasonRingEmpty, XY{}, true, 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			check := func(t *testing.T, err error) {
+				verr, ok := err.(ValidationError)
+				if !ok {
+					t.Fatalf("expected ValidationError but got %T: %v", err, err)
+				}
+				if verr.Reason != tt.reason {
+					t.Errorf("reason: got=%v want=%v", verr.Reason, tt.reason)
+				}
+				if verr.HasLocation != !tt.noLoc {
+					t.Errorf("has location: got=%v want=%v", verr.HasLocation, !tt.noLoc)
+				}
+				if verr.Location != tt.loc {
+					t.Errorf("location: got=%v want=%v", verr.Location, tt.loc)
+				}
+				if verr.HasRingIndex != (tt.ringIdx != -1) {
+					t.Errorf("has ring index: got=%v want=%v", verr.HasRingIndex, tt.ringIdx != -1)
+				}
+				if verr.HasRingIndex && verr.RingIndex != tt.ringIdx {
+					t.Errorf("ring index: got=%v want=%v", verr.RingIndex, tt.ringIdx)
+				}
+			}
+			t.Run("constructor", func(t *testing.T) {
+				_, err := UnmarshalWKT(strings.NewReader(tt.wkt))
+				check(t, err)
+			})
+			t.Run("IsValidReason", func(t *testing.T) {
+				g, err := UnmarshalWKT(strings.NewReader(tt.wkt), DisableAllValidations)
+				expectNoErr(t, err)
+				check(t, g.IsValidReason())
+			})
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := ValidationError{
+		Reason:      ReasonHoleOutsideShell,
+		Location:    XY{1.5, -2},
+		HasLocation: true,
+	}
+	expectStringEq(t, err.Error(), "hole must be inside outer ring at POINT(1.5 -2)")
+
+	err = ValidationError{
+		Reason:       ReasonRingEmpty,
+		RingIndex:    2,
+		HasRingIndex: true,
+	}
+	expectStringEq(t, err.Error(), "polygon rings must not be empty (ring 2)")
+}
+
+func TestIsValidReasonValid(t *testing.T) {
+	for i, wkt := range []string{
+		"POINT(1 2)",
+		"LINESTRING(0 0,1 1)",
+		"POLYGON((0 0,1 0,1 1,0 1,0 0))",
+		"GEOMETRYCOLLECTION(POINT(1 2),POLYGON((0 0,1 0,1 1,0 1,0 0)))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, wkt)
+			if err := g.IsValidReason(); err != nil {
+				t.Errorf("expected nil but got %v", err)
+			}
+		})
+	}
+}
+
 func BenchmarkPolygonSingleRingValidation(b *testing.B) {
 	for _, sz := range []int{10, 100, 1000, 10000} {
 		b.Run(fmt.Sprintf("n=%d", sz), func(b *testing.B) {
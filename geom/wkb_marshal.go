@@ -8,12 +8,21 @@ import (
 type wkbMarshaller struct {
 	w   io.Writer
 	err error
+
+	// extended is true when writing EWKB rather than ISO WKB. srid is only
+	// written for the root geometry, so is cleared once it's been written.
+	extended bool
+	srid     int
 }
 
 func newWKBMarshaller(w io.Writer) *wkbMarshaller {
 	return &wkbMarshaller{w: w}
 }
 
+func newEWKBMarshaller(w io.Writer, srid int) *wkbMarshaller {
+	return &wkbMarshaller{w: w, extended: true, srid: srid}
+}
+
 func (m *wkbMarshaller) setErr(err error) {
 	if m.err == nil {
 		m.err = err
@@ -36,14 +45,43 @@ func (m *wkbMarshaller) writeByteOrder() {
 	m.write(littleEndian)
 }
 
-func (m *wkbMarshaller) writeGeomType(geomType uint32) {
+func (m *wkbMarshaller) writeGeomType(geomType uint32, ctype CoordinatesType) {
+	if !m.extended {
+		m.write(geomType + 1000*uint32(ctype))
+		return
+	}
+
+	if ctype.Is3D() {
+		geomType |= ewkbZFlag
+	}
+	if ctype.IsMeasured() {
+		geomType |= ewkbMFlag
+	}
+	if m.srid != 0 {
+		geomType |= ewkbSRIDFlag
+	}
 	m.write(geomType)
+	if m.srid != 0 {
+		m.write(int32(m.srid))
+		m.srid = 0
+	}
 }
 
 func (m *wkbMarshaller) writeFloat64(f float64) {
 	m.write(f)
 }
 
+func (m *wkbMarshaller) writeCoordinates(c Coordinates) {
+	m.writeFloat64(c.X)
+	m.writeFloat64(c.Y)
+	if c.Type.Is3D() {
+		m.writeFloat64(c.Z)
+	}
+	if c.Type.IsMeasured() {
+		m.writeFloat64(c.M)
+	}
+}
+
 func (m *wkbMarshaller) writeCount(n int) {
 	m.write(uint32(n))
 }
@@ -1,42 +1,85 @@
 package geom
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 )
 
-// TODO: When 3D/measure are supported, we will need to check for consistent
-// coordinate types inside compound geometries.
-
 // UnmarshalWKB reads the Well Known Binary (WKB), and returns the
-// corresponding Geometry.
+// corresponding Geometry. The Z, M, and ZM variants of each geometry type are
+// supported (using the ISO WKB geometry type codes).
 func UnmarshalWKB(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
 	p := wkbParser{r: r, opts: opts}
-	p.parseByteOrder()
-	p.parseGeomType()
-	geom := p.parseGeomRoot()
+	geom := p.parse()
 	return geom, p.err
 }
 
-type coordType byte
+// UnmarshalEWKB reads the Extended Well Known Binary (EWKB) format used by
+// PostGIS, and returns the corresponding Geometry. If the EWKB includes an
+// SRID, then it is set on the returned Geometry.
+//
+// Regular WKB (including the ISO WKB geometry type codes for Z, M, and ZM
+// geometries) is a subset of EWKB, so is also accepted.
+func UnmarshalEWKB(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
+	p := wkbParser{r: r, opts: opts, extended: true}
+	geom := p.parse()
+	if p.err != nil {
+		return Geometry{}, p.err
+	}
+	return geom.WithSRID(p.srid), nil
+}
+
+// UnmarshalHexWKB parses hex encoded Well Known Binary (WKB), and returns the
+// corresponding Geometry. It's the inverse of AsHexWKB.
+func UnmarshalHexWKB(s string, opts ...ConstructorOption) (Geometry, error) {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return UnmarshalWKB(bytes.NewReader(buf), opts...)
+}
+
+// UnmarshalHexEWKB parses hex encoded Extended Well Known Binary (EWKB), and
+// returns the corresponding Geometry (with its SRID set). This is the format
+// that PostGIS uses for geometry values that are selected without any
+// conversion function. It's the inverse of AsHexEWKB.
+func UnmarshalHexEWKB(s string, opts ...ConstructorOption) (Geometry, error) {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return UnmarshalEWKB(bytes.NewReader(buf), opts...)
+}
 
+// EWKB geometry type flags, as used by PostGIS.
 const (
-	coordTypeXY coordType = 1 + iota
-	coordTypeXYZ
-	coordTypeXYM
-	coordTypeXYZM
+	ewkbZFlag    = uint32(0x80000000)
+	ewkbMFlag    = uint32(0x40000000)
+	ewkbSRIDFlag = uint32(0x20000000)
 )
 
 type wkbParser struct {
-	err       error
-	r         io.Reader
-	bo        binary.ByteOrder
-	geomType  uint32
-	coordType coordType
-	opts      []ConstructorOption
+	err      error
+	r        io.Reader
+	bo       binary.ByteOrder
+	geomType uint32
+	ctype    CoordinatesType
+	opts     []ConstructorOption
+
+	// extended is true when EWKB flags are allowed in the geometry type.
+	extended bool
+	srid     int
+}
+
+func (p *wkbParser) parse() Geometry {
+	p.parseByteOrder()
+	p.parseGeomType()
+	return p.parseGeomRoot()
 }
 
 func (p *wkbParser) setErr(err error) {
@@ -67,21 +110,41 @@ func (p *wkbParser) parseUint32() uint32 {
 
 func (p *wkbParser) parseGeomType() {
 	geomCode := p.parseUint32()
+	if p.extended && geomCode&(ewkbZFlag|ewkbMFlag|ewkbSRIDFlag) != 0 {
+		p.parseEWKBGeomType(geomCode)
+		return
+	}
 	p.geomType = geomCode % 1000
 	switch geomCode / 1000 {
 	case 0:
-		p.coordType = coordTypeXY
+		p.ctype = DimXY
 	case 1:
-		p.coordType = coordTypeXYZ
+		p.ctype = DimXYZ
 	case 2:
-		p.coordType = coordTypeXYM
+		p.ctype = DimXYM
 	case 3:
-		p.coordType = coordTypeXYZM
+		p.ctype = DimXYZM
 	default:
 		p.setErr(errors.New("cannot determine coordinate type"))
 	}
 }
 
+func (p *wkbParser) parseEWKBGeomType(geomCode uint32) {
+	p.geomType = geomCode &^ (ewkbZFlag | ewkbMFlag | ewkbSRIDFlag)
+	p.ctype = DimXY
+	if geomCode&ewkbZFlag != 0 {
+		p.ctype |= DimXYZ
+	}
+	if geomCode&ewkbMFlag != 0 {
+		p.ctype |= DimXYM
+	}
+	if geomCode&ewkbSRIDFlag != 0 {
+		var srid int32
+		p.read(&srid)
+		p.srid = int(srid)
+	}
+}
+
 const (
 	wkbGeomTypePoint              = uint32(1)
 	wkbGeomTypeLineString         = uint32(2)
@@ -93,6 +156,16 @@ const (
 )
 
 func (p *wkbParser) parseGeomRoot() Geometry {
+	geom := p.parseGeomBody()
+	if p.err != nil {
+		return Geometry{}
+	}
+	// Empty geometries don't contain any coordinates to imply the
+	// coordinates type, so it's explicitly applied to the geometry.
+	return geom.ForceCoordinatesType(p.ctype)
+}
+
+func (p *wkbParser) parseGeomBody() Geometry {
 	switch p.geomType {
 	case wkbGeomTypePoint:
 		coords := p.parsePoint()
@@ -155,13 +228,13 @@ func (p *wkbParser) parsePoint() OptionalCoordinates {
 	x := p.parseFloat64()
 	y := p.parseFloat64()
 	var z, m float64
-	switch p.coordType {
-	case coordTypeXY:
-	case coordTypeXYZ:
+	switch p.ctype {
+	case DimXY:
+	case DimXYZ:
 		z = p.parseFloat64()
-	case coordTypeXYM:
+	case DimXYM:
 		m = p.parseFloat64()
-	case coordTypeXYZM:
+	case DimXYZM:
 		z = p.parseFloat64()
 		m = p.parseFloat64()
 	default:
@@ -178,9 +251,12 @@ func (p *wkbParser) parsePoint() OptionalCoordinates {
 		return OptionalCoordinates{}
 	}
 
-	// Only XY is supported so far.
-	_, _ = z, m
-	return OptionalCoordinates{Value: Coordinates{XY{x, y}}}
+	return OptionalCoordinates{Value: Coordinates{
+		XY:   XY{x, y},
+		Z:    z,
+		M:    m,
+		Type: p.ctype,
+	}}
 }
 
 func (p *wkbParser) parseLineString() []Coordinates {
@@ -208,8 +284,7 @@ func (p *wkbParser) parseMultiPoint() MultiPoint {
 	n := p.parseUint32()
 	var pts []Point
 	for i := uint32(0); i < n; i++ {
-		geom, err := UnmarshalWKB(p.r)
-		p.setErr(err)
+		geom := p.parseChild()
 		if geom.IsEmpty() {
 			continue
 		}
@@ -225,8 +300,7 @@ func (p *wkbParser) parseMultiLineString() MultiLineString {
 	n := p.parseUint32()
 	var lss []LineString
 	for i := uint32(0); i < n; i++ {
-		geom, err := UnmarshalWKB(p.r)
-		p.setErr(err)
+		geom := p.parseChild()
 		if geom.IsEmpty() {
 			continue
 		}
@@ -251,8 +325,7 @@ func (p *wkbParser) parseMultiPolygon() MultiPolygon {
 	n := p.parseUint32()
 	var polys []Polygon
 	for i := uint32(0); i < n; i++ {
-		geom, err := UnmarshalWKB(p.r)
-		p.setErr(err)
+		geom := p.parseChild()
 		if geom.IsEmpty() {
 			continue
 		}
@@ -270,9 +343,26 @@ func (p *wkbParser) parseGeometryCollection() GeometryCollection {
 	n := p.parseUint32()
 	var geoms []Geometry
 	for i := uint32(0); i < n; i++ {
-		geom, err := UnmarshalWKB(p.r)
-		p.setErr(err)
+		geom := p.parseChild()
 		geoms = append(geoms, geom)
 	}
 	return NewGeometryCollection(geoms, p.opts...)
 }
+
+// parseChild parses a geometry that is nested inside a multi geometry or
+// geometry collection. Its coordinates type must match that of the parent.
+func (p *wkbParser) parseChild() Geometry {
+	if p.err != nil {
+		return Geometry{}
+	}
+	child := wkbParser{r: p.r, opts: p.opts, extended: p.extended}
+	geom := child.parse()
+	p.setErr(child.err)
+	if child.err == nil && child.srid != 0 {
+		p.setErr(errors.New("SRID is only allowed on the root geometry"))
+	}
+	if child.err == nil && geom.CoordinatesType() != p.ctype {
+		p.setErr(errMixedCoordinatesTypes)
+	}
+	return geom
+}
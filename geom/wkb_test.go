@@ -2,6 +2,8 @@ package geom_test
 
 import (
 	"bytes"
+	"encoding/hex"
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
@@ -116,17 +118,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// POINTZ EMPTY
 			wkb: "01e9030000000000000000f87f000000000000f87f000000000000f87f",
-			wkt: "POINT EMPTY",
+			wkt: "POINTZ EMPTY",
 		},
 		{
 			// POINTM EMPTY
 			wkb: "01d1070000000000000000f87f000000000000f87f000000000000f87f",
-			wkt: "POINT EMPTY",
+			wkt: "POINTM EMPTY",
 		},
 		{
 			// POINTZM EMPTY
 			wkb: "01b90b0000000000000000f87f000000000000f87f000000000000f87f000000000000f87f",
-			wkt: "POINT EMPTY",
+			wkt: "POINTZM EMPTY",
 		},
 		{
 			// POINT(1 2)
@@ -136,17 +138,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// POINTZ(1 2 3)
 			wkb: "01e9030000000000000000f03f00000000000000400000000000000840",
-			wkt: "POINT(1 2)",
+			wkt: "POINTZ(1 2 3)",
 		},
 		{
 			// POINTM(1 2 3)
 			wkb: "01d1070000000000000000f03f00000000000000400000000000000840",
-			wkt: "POINT(1 2)",
+			wkt: "POINTM(1 2 3)",
 		},
 		{
 			// POINTZM(1 2 3 4)
 			wkb: "01b90b0000000000000000f03f000000000000004000000000000008400000000000001040",
-			wkt: "POINT(1 2)",
+			wkt: "POINTZM(1 2 3 4)",
 		},
 		{
 			// LINESTRING EMPTY
@@ -156,17 +158,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// LINESTRINGZ EMPTY
 			wkb: "01ea03000000000000",
-			wkt: "LINESTRING EMPTY",
+			wkt: "LINESTRINGZ EMPTY",
 		},
 		{
 			// LINESTRINGM EMPTY
 			wkb: "01d207000000000000",
-			wkt: "LINESTRING EMPTY",
+			wkt: "LINESTRINGM EMPTY",
 		},
 		{
 			// LINESTRINGZM EMPTY
 			wkb: "01ba0b000000000000",
-			wkt: "LINESTRING EMPTY",
+			wkt: "LINESTRINGZM EMPTY",
 		},
 		{
 			// LINESTRING(1 2,3 4)
@@ -176,17 +178,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// LINESTRINGZ(1 2 3,4 5 6)
 			wkb: "01ea03000002000000000000000000f03f00000000000000400000000000000840000000000000104000000000000014400000000000001840",
-			wkt: "LINESTRING(1 2,4 5)",
+			wkt: "LINESTRINGZ(1 2 3,4 5 6)",
 		},
 		{
 			// LINESTRINGM(1 2 3,4 5 6)
 			wkb: "01d207000002000000000000000000f03f00000000000000400000000000000840000000000000104000000000000014400000000000001840",
-			wkt: "LINESTRING(1 2,4 5)",
+			wkt: "LINESTRINGM(1 2 3,4 5 6)",
 		},
 		{
 			// LINESTRINGZM(1 2 3 4,5 6 7 8)
 			wkb: "01ba0b000002000000000000000000f03f000000000000004000000000000008400000000000001040000000000000144000000000000018400000000000001c400000000000002040",
-			wkt: "LINESTRING(1 2,5 6)",
+			wkt: "LINESTRINGZM(1 2 3 4,5 6 7 8)",
 		},
 		{
 			// LINESTRING(1 2,3 4,5 6)
@@ -196,17 +198,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// LINESTRINGZ(1 2 3,3 4 5,5 6 7)
 			wkb: "01ea03000003000000000000000000f03f00000000000000400000000000000840000000000000084000000000000010400000000000001440000000000000144000000000000018400000000000001c40",
-			wkt: "LINESTRING(1 2,3 4,5 6)",
+			wkt: "LINESTRINGZ(1 2 3,3 4 5,5 6 7)",
 		},
 		{
 			// LINESTRINGM(1 2 3,3 4 5,5 6 7)
 			wkb: "01d207000003000000000000000000f03f00000000000000400000000000000840000000000000084000000000000010400000000000001440000000000000144000000000000018400000000000001c40",
-			wkt: "LINESTRING(1 2,3 4,5 6)",
+			wkt: "LINESTRINGM(1 2 3,3 4 5,5 6 7)",
 		},
 		{
 			// LINESTRINGZM(1 2 3 4,3 4 5 6,5 6 7 8)
 			wkb: "01ba0b000003000000000000000000f03f0000000000000040000000000000084000000000000010400000000000000840000000000000104000000000000014400000000000001840000000000000144000000000000018400000000000001c400000000000002040",
-			wkt: "LINESTRING(1 2,3 4,5 6)",
+			wkt: "LINESTRINGZM(1 2 3 4,3 4 5 6,5 6 7 8)",
 		},
 		{
 			// POLYGON EMPTY
@@ -216,17 +218,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// POLYGONZ EMPTY
 			wkb: "01eb03000000000000",
-			wkt: "POLYGON EMPTY",
+			wkt: "POLYGONZ EMPTY",
 		},
 		{
 			// POLYGONM EMPTY
 			wkb: "01d307000000000000",
-			wkt: "POLYGON EMPTY",
+			wkt: "POLYGONM EMPTY",
 		},
 		{
 			// POLYGONZM EMPTY
 			wkb: "01bb0b000000000000",
-			wkt: "POLYGON EMPTY",
+			wkt: "POLYGONZM EMPTY",
 		},
 		{
 			// POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))
@@ -236,17 +238,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// POLYGONZ((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))
 			wkb: "01eb030000020000000400000000000000000000000000000000000000000000000000224000000000000010400000000000000000000000000000224000000000000000000000000000001040000000000000224000000000000000000000000000000000000000000000224004000000000000000000f03f000000000000f03f00000000000022400000000000000040000000000000f03f0000000000002240000000000000f03f00000000000000400000000000002240000000000000f03f000000000000f03f0000000000002240",
-			wkt: "POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			wkt: "POLYGONZ((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))",
 		},
 		{
 			// POLYGONM((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))
 			wkb: "01d3070000020000000400000000000000000000000000000000000000000000000000224000000000000010400000000000000000000000000000224000000000000000000000000000001040000000000000224000000000000000000000000000000000000000000000224004000000000000000000f03f000000000000f03f00000000000022400000000000000040000000000000f03f0000000000002240000000000000f03f00000000000000400000000000002240000000000000f03f000000000000f03f0000000000002240",
-			wkt: "POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			wkt: "POLYGONM((0 0 9,4 0 9,0 4 9,0 0 9),(1 1 9,2 1 9,1 2 9,1 1 9))",
 		},
 		{
 			// POLYGONZM((0 0 9 9,4 0 9 9,0 4 9 9,0 0 9 9),(1 1 9 9,2 1 9 9,1 2 9 9,1 1 9 9))
 			wkb: "01bb0b00000200000004000000000000000000000000000000000000000000000000002240000000000000224000000000000010400000000000000000000000000000224000000000000022400000000000000000000000000000104000000000000022400000000000002240000000000000000000000000000000000000000000002240000000000000224004000000000000000000f03f000000000000f03f000000000000224000000000000022400000000000000040000000000000f03f00000000000022400000000000002240000000000000f03f000000000000004000000000000022400000000000002240000000000000f03f000000000000f03f00000000000022400000000000002240",
-			wkt: "POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+			wkt: "POLYGONZM((0 0 9 9,4 0 9 9,0 4 9 9,0 0 9 9),(1 1 9 9,2 1 9 9,1 2 9 9,1 1 9 9))",
 		},
 		{
 			// MULTIPOINT EMPTY
@@ -256,17 +258,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTIPOINTZ EMPTY
 			wkb: "01ec03000000000000",
-			wkt: "MULTIPOINT EMPTY",
+			wkt: "MULTIPOINTZ EMPTY",
 		},
 		{
 			// MULTIPOINTM EMPTY
 			wkb: "01d407000000000000",
-			wkt: "MULTIPOINT EMPTY",
+			wkt: "MULTIPOINTM EMPTY",
 		},
 		{
 			// MULTIPOINTZM EMPTY
 			wkb: "01bc0b000000000000",
-			wkt: "MULTIPOINT EMPTY",
+			wkt: "MULTIPOINTZM EMPTY",
 		},
 		{
 			// MULTIPOINT(1 2)
@@ -276,17 +278,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTIPOINTZ(1 2 3)
 			wkb: "01ec0300000100000001e9030000000000000000f03f00000000000000400000000000000840",
-			wkt: "MULTIPOINT(1 2)",
+			wkt: "MULTIPOINTZ(1 2 3)",
 		},
 		{
 			// MULTIPOINTM(1 2 3)
 			wkb: "01d40700000100000001d1070000000000000000f03f00000000000000400000000000000840",
-			wkt: "MULTIPOINT(1 2)",
+			wkt: "MULTIPOINTM(1 2 3)",
 		},
 		{
 			// MULTIPOINTZM(1 2 3 4)
 			wkb: "01bc0b00000100000001b90b0000000000000000f03f000000000000004000000000000008400000000000001040",
-			wkt: "MULTIPOINT(1 2)",
+			wkt: "MULTIPOINTZM(1 2 3 4)",
 		},
 		{
 			// MULTIPOINT(1 2,3 4)
@@ -296,17 +298,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTIPOINTZ(1 2 3,3 4 5)
 			wkb: "01ec0300000200000001e9030000000000000000f03f0000000000000040000000000000084001e9030000000000000000084000000000000010400000000000001440",
-			wkt: "MULTIPOINT(1 2,3 4)",
+			wkt: "MULTIPOINTZ(1 2 3,3 4 5)",
 		},
 		{
 			// MULTIPOINTM(1 2 3,3 4 5)
 			wkb: "01d40700000200000001d1070000000000000000f03f0000000000000040000000000000084001d1070000000000000000084000000000000010400000000000001440",
-			wkt: "MULTIPOINT(1 2,3 4)",
+			wkt: "MULTIPOINTM(1 2 3,3 4 5)",
 		},
 		{
 			// MULTIPOINTZM(1 2 3 4,3 4 5 6)
 			wkb: "01bc0b00000200000001b90b0000000000000000f03f00000000000000400000000000000840000000000000104001b90b00000000000000000840000000000000104000000000000014400000000000001840",
-			wkt: "MULTIPOINT(1 2,3 4)",
+			wkt: "MULTIPOINTZM(1 2 3 4,3 4 5 6)",
 		},
 		{
 			// MULTILINESTRING EMPTY
@@ -316,17 +318,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZ EMPTY
 			wkb: "01ed03000000000000",
-			wkt: "MULTILINESTRING EMPTY",
+			wkt: "MULTILINESTRINGZ EMPTY",
 		},
 		{
 			// MULTILINESTRINGM EMPTY
 			wkb: "01d507000000000000",
-			wkt: "MULTILINESTRING EMPTY",
+			wkt: "MULTILINESTRINGM EMPTY",
 		},
 		{
 			// MULTILINESTRINGZM EMPTY
 			wkb: "01bd0b000000000000",
-			wkt: "MULTILINESTRING EMPTY",
+			wkt: "MULTILINESTRINGZM EMPTY",
 		},
 		{
 			// MULTILINESTRING((0 1,2 3,4 5))
@@ -336,17 +338,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZ((0 1 8,2 3 8,4 5 8))
 			wkb: "01ed0300000100000001ea030000030000000000000000000000000000000000f03f0000000000002040000000000000004000000000000008400000000000002040000000000000104000000000000014400000000000002040",
-			wkt: "MULTILINESTRING((0 1,2 3,4 5))",
+			wkt: "MULTILINESTRINGZ((0 1 8,2 3 8,4 5 8))",
 		},
 		{
 			// MULTILINESTRINGM((0 1 8,2 3 8,4 5 8))
 			wkb: "01d50700000100000001d2070000030000000000000000000000000000000000f03f0000000000002040000000000000004000000000000008400000000000002040000000000000104000000000000014400000000000002040",
-			wkt: "MULTILINESTRING((0 1,2 3,4 5))",
+			wkt: "MULTILINESTRINGM((0 1 8,2 3 8,4 5 8))",
 		},
 		{
 			// MULTILINESTRINGZM((0 1 8 9,2 3 8 9,4 5 8 9))
 			wkb: "01bd0b00000100000001ba0b0000030000000000000000000000000000000000f03f0000000000002040000000000000224000000000000000400000000000000840000000000000204000000000000022400000000000001040000000000000144000000000000020400000000000002240",
-			wkt: "MULTILINESTRING((0 1,2 3,4 5))",
+			wkt: "MULTILINESTRINGZM((0 1 8 9,2 3 8 9,4 5 8 9))",
 		},
 		{
 			// MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))
@@ -356,17 +358,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTILINESTRINGZ((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))
 			wkb: "01ed0300000200000001ea030000020000000000000000000000000000000000f03f000000000000224000000000000000400000000000000840000000000000224001ea0300000300000000000000000010400000000000001440000000000000224000000000000018400000000000001c400000000000002240000000000000204000000000000022400000000000002240",
-			wkt: "MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))",
+			wkt: "MULTILINESTRINGZ((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))",
 		},
 		{
 			// MULTILINESTRINGM((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))
 			wkb: "01d50700000200000001d2070000020000000000000000000000000000000000f03f000000000000224000000000000000400000000000000840000000000000224001d20700000300000000000000000010400000000000001440000000000000224000000000000018400000000000001c400000000000002240000000000000204000000000000022400000000000002240",
-			wkt: "MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))",
+			wkt: "MULTILINESTRINGM((0 1 9,2 3 9),(4 5 9,6 7 9,8 9 9))",
 		},
 		{
 			// MULTILINESTRINGZM((0 1 9 9,2 3 9 9),(4 5 9 9,6 7 9 9,8 9 9 9))
 			wkb: "01bd0b00000200000001ba0b0000020000000000000000000000000000000000f03f00000000000022400000000000002240000000000000004000000000000008400000000000002240000000000000224001ba0b000003000000000000000000104000000000000014400000000000002240000000000000224000000000000018400000000000001c40000000000000224000000000000022400000000000002040000000000000224000000000000022400000000000002240",
-			wkt: "MULTILINESTRING((0 1,2 3),(4 5,6 7,8 9))",
+			wkt: "MULTILINESTRINGZM((0 1 9 9,2 3 9 9),(4 5 9 9,6 7 9 9,8 9 9 9))",
 		},
 		{
 			// MULTIPOLYGON EMPTY
@@ -376,17 +378,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTIPOLYGONZ EMPTY
 			wkb: "01ee03000000000000",
-			wkt: "MULTIPOLYGON EMPTY",
+			wkt: "MULTIPOLYGONZ EMPTY",
 		},
 		{
 			// MULTIPOLYGONM EMPTY
 			wkb: "01d607000000000000",
-			wkt: "MULTIPOLYGON EMPTY",
+			wkt: "MULTIPOLYGONM EMPTY",
 		},
 		{
 			// MULTIPOLYGONZM EMPTY
 			wkb: "01be0b000000000000",
-			wkt: "MULTIPOLYGON EMPTY",
+			wkt: "MULTIPOLYGONZM EMPTY",
 		},
 		{
 			// MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))
@@ -396,17 +398,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// MULTIPOLYGONZ(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))
 			wkb: "01ee0300000200000001eb0300000100000004000000000000000000000000000000000000000000000000002240000000000000f03f000000000000000000000000000022400000000000000000000000000000f03f000000000000224000000000000000000000000000000000000000000000224001eb0300000100000004000000000000000000f03f00000000000000000000000000002240000000000000004000000000000000000000000000002240000000000000f03f000000000000f03f0000000000002240000000000000f03f00000000000000000000000000002240",
-			wkt: "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
+			wkt: "MULTIPOLYGONZ(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))",
 		},
 		{
 			// MULTIPOLYGONM(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))
 			wkb: "01d60700000200000001d30700000100000004000000000000000000000000000000000000000000000000002240000000000000f03f000000000000000000000000000022400000000000000000000000000000f03f000000000000224000000000000000000000000000000000000000000000224001d30700000100000004000000000000000000f03f00000000000000000000000000002240000000000000004000000000000000000000000000002240000000000000f03f000000000000f03f0000000000002240000000000000f03f00000000000000000000000000002240",
-			wkt: "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
+			wkt: "MULTIPOLYGONM(((0 0 9,1 0 9,0 1 9,0 0 9)),((1 0 9,2 0 9,1 1 9,1 0 9)))",
 		},
 		{
 			// MULTIPOLYGONZM(((0 0 8 9,1 0 8 9,0 1 8 9,0 0 8 9)),((1 0 8 9,2 0 8 9,1 1 8 9,1 0 8 9)))
 			wkb: "01be0b00000200000001bb0b000001000000040000000000000000000000000000000000000000000000000020400000000000002240000000000000f03f0000000000000000000000000000204000000000000022400000000000000000000000000000f03f00000000000020400000000000002240000000000000000000000000000000000000000000002040000000000000224001bb0b00000100000004000000000000000000f03f0000000000000000000000000000204000000000000022400000000000000040000000000000000000000000000020400000000000002240000000000000f03f000000000000f03f00000000000020400000000000002240000000000000f03f000000000000000000000000000020400000000000002240",
-			wkt: "MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
+			wkt: "MULTIPOLYGONZM(((0 0 8 9,1 0 8 9,0 1 8 9,0 0 8 9)),((1 0 8 9,2 0 8 9,1 1 8 9,1 0 8 9)))",
 		},
 		{
 			// GEOMETRYCOLLECTION EMPTY
@@ -416,17 +418,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// GEOMETRYCOLLECTIONZ EMPTY
 			wkb: "01ef03000000000000",
-			wkt: "GEOMETRYCOLLECTION EMPTY",
+			wkt: "GEOMETRYCOLLECTIONZ EMPTY",
 		},
 		{
 			// GEOMETRYCOLLECTIONM EMPTY
 			wkb: "01d707000000000000",
-			wkt: "GEOMETRYCOLLECTION EMPTY",
+			wkt: "GEOMETRYCOLLECTIONM EMPTY",
 		},
 		{
 			// GEOMETRYCOLLECTIONZM EMPTY
 			wkb: "01bf0b000000000000",
-			wkt: "GEOMETRYCOLLECTION EMPTY",
+			wkt: "GEOMETRYCOLLECTIONZM EMPTY",
 		},
 		{
 			// GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))
@@ -436,17 +438,17 @@ func TestWKBParseValid(t *testing.T) {
 		{
 			// GEOMETRYCOLLECTIONZ(POINTZ(1 2 3),POINTZ(3 4 5))
 			wkb: "01ef0300000200000001e9030000000000000000f03f0000000000000040000000000000084001e9030000000000000000084000000000000010400000000000001440",
-			wkt: "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
+			wkt: "GEOMETRYCOLLECTIONZ(POINTZ(1 2 3),POINTZ(3 4 5))",
 		},
 		{
 			// GEOMETRYCOLLECTIONM(POINTM(1 2 3),POINTM(3 4 5))
 			wkb: "01d70700000200000001d1070000000000000000f03f0000000000000040000000000000084001d1070000000000000000084000000000000010400000000000001440",
-			wkt: "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
+			wkt: "GEOMETRYCOLLECTIONM(POINTM(1 2 3),POINTM(3 4 5))",
 		},
 		{
 			// GEOMETRYCOLLECTIONZM(POINTZM(1 2 3 4),POINTZM(3 4 5 5))
 			wkb: "01bf0b00000200000001b90b0000000000000000f03f00000000000000400000000000000840000000000000104001b90b00000000000000000840000000000000104000000000000014400000000000001440",
-			wkt: "GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))",
+			wkt: "GEOMETRYCOLLECTIONZM(POINTZM(1 2 3 4),POINTZM(3 4 5 5))",
 		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -469,6 +471,15 @@ func TestWKBParserInvalidGeometryType(t *testing.T) {
 	}
 }
 
+func TestWKBParserMixedCoordinatesTypes(t *testing.T) {
+	// MULTIPOINTZ containing a single POINTM.
+	const wkb = "01ec0300000100000001d1070000000000000000f03f00000000000000400000000000000840"
+	_, err := UnmarshalWKB(bytes.NewReader(hexStringToBytes(t, wkb)))
+	if err == nil {
+		t.Errorf("expected an error but got nil")
+	}
+}
+
 func TestWKBMarshalValid(t *testing.T) {
 	for i, wkt := range []string{
 		"POINT EMPTY",
@@ -488,6 +499,15 @@ func TestWKBMarshalValid(t *testing.T) {
 		"MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((1 0,2 0,1 1,1 0)))",
 		"GEOMETRYCOLLECTION EMPTY",
 		"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4))",
+		"POINT Z EMPTY",
+		"POINT ZM (1 2 3 4)",
+		"LINESTRING M (1 2 3,4 5 6)",
+		"LINESTRING Z (1 2 3,1 2 4,5 6 7)",
+		"POLYGON Z ((0 0 1,4 0 2,0 4 3,0 0 1))",
+		"MULTIPOINT M ((1 2 3))",
+		"MULTILINESTRING ZM EMPTY",
+		"MULTIPOLYGON Z (((0 0 1,1 0 2,0 1 3,0 0 1)))",
+		"GEOMETRYCOLLECTION M (POINT M (1 2 3),LINESTRING M (1 2 3,3 4 5))",
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			geom := geomFromWKT(t, wkt)
@@ -500,3 +520,182 @@ func TestWKBMarshalValid(t *testing.T) {
 		})
 	}
 }
+
+func TestEWKBParseValid(t *testing.T) {
+	for i, tt := range []struct {
+		ewkb string
+		wkt  string
+		srid int
+	}{
+		{
+			// SELECT ST_AsEWKB(ST_GeomFromEWKT('SRID=4326;POINT(1 2)'))
+			ewkb: "0101000020e6100000000000000000f03f0000000000000040",
+			wkt:  "POINT(1 2)",
+			srid: 4326,
+		},
+		{
+			// SELECT ST_AsEWKB(ST_GeomFromEWKT('POINT(1 2 3)'))
+			ewkb: "0101000080000000000000f03f00000000000000400000000000000840",
+			wkt:  "POINT Z (1 2 3)",
+		},
+		{
+			// SELECT ST_AsEWKB(ST_GeomFromEWKT('SRID=3857;POINTM(1 2 3)'))
+			ewkb: "0101000060110f0000000000000000f03f00000000000000400000000000000840",
+			wkt:  "POINT M (1 2 3)",
+			srid: 3857,
+		},
+		{
+			// SELECT ST_AsEWKB(ST_GeomFromEWKT('SRID=4326;MULTIPOINT(1 2 3 4)'))
+			ewkb: "01040000e0e61000000100000001010000c0000000000000f03f000000000000004000000000000008400000000000001040",
+			wkt:  "MULTIPOINT ZM (1 2 3 4)",
+			srid: 4326,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := UnmarshalEWKB(bytes.NewReader(hexStringToBytes(t, tt.ewkb)))
+			expectNoErr(t, err)
+			expectGeomEq(t, g, geomFromWKT(t, tt.wkt))
+			expectIntEq(t, g.SRID(), tt.srid)
+
+			var buf bytes.Buffer
+			expectNoErr(t, g.AsEWKB(&buf))
+			expectStringEq(t, hex.EncodeToString(buf.Bytes()), tt.ewkb)
+		})
+	}
+}
+
+func TestEWKBParseAcceptsWKB(t *testing.T) {
+	for i, wkt := range []string{
+		"POINT(1 2)",
+		"LINESTRING Z (1 2 3,4 5 6)",
+		"GEOMETRYCOLLECTION M (POINT M (1 2 3))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			want := geomFromWKT(t, wkt)
+			var buf bytes.Buffer
+			expectNoErr(t, want.AsBinary(&buf))
+			got, err := UnmarshalEWKB(&buf)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, want)
+			expectIntEq(t, got.SRID(), 0)
+		})
+	}
+}
+
+func TestEWKBParseInvalid(t *testing.T) {
+	for i, ewkb := range []string{
+		// SRID on nested geometry.
+		"01040000200100000001000000010100002001000000000000000000f03f0000000000000040",
+		// Mixed coordinate types.
+		"0104000080010000000101000000000000000000f03f0000000000000040",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := UnmarshalEWKB(bytes.NewReader(hexStringToBytes(t, ewkb)))
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestWKBParseRejectsEWKB(t *testing.T) {
+	ewkb := "0101000020e6100000000000000000f03f0000000000000040"
+	_, err := UnmarshalWKB(bytes.NewReader(hexStringToBytes(t, ewkb)))
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestEWKBMarshalUnmarshal(t *testing.T) {
+	for i, wkt := range []string{
+		"POINT EMPTY",
+		"POINT Z EMPTY",
+		"LINESTRING ZM (1 2 3 4,5 6 7 8)",
+		"POLYGON M ((0 0 1,4 0 2,0 4 3,0 0 1))",
+		"MULTILINESTRING Z ((0 1 2,3 4 5))",
+		"MULTIPOLYGON(((0 0,1 0,0 1,0 0)))",
+		"GEOMETRYCOLLECTION Z (POINT Z (1 2 3),MULTIPOINT Z ((4 5 6)))",
+	} {
+		for _, srid := range []int{0, 4326} {
+			t.Run(fmt.Sprintf("%d_%d", i, srid), func(t *testing.T) {
+				geom := geomFromWKT(t, wkt).WithSRID(srid)
+				var buf bytes.Buffer
+				expectNoErr(t, geom.AsEWKB(&buf))
+				readBackGeom, err := UnmarshalEWKB(&buf)
+				expectNoErr(t, err)
+				expectGeomEq(t, readBackGeom, geom)
+				expectIntEq(t, readBackGeom.SRID(), srid)
+			})
+		}
+	}
+}
+
+func TestHexWKB(t *testing.T) {
+	for i, tt := range []struct {
+		wkt string
+		hex string
+	}{
+		{"POINT(1 2)", "0101000000000000000000f03f0000000000000040"},
+		{"POINT Z (1 2 3)", "01e9030000000000000000f03f00000000000000400000000000000840"},
+		{"LINESTRING EMPTY", "010200000000000000"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt)
+			expectStringEq(t, g.AsHexWKB(), tt.hex)
+			got, err := UnmarshalHexWKB(tt.hex)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, g)
+			got, err = UnmarshalHexWKB(strings.ToUpper(tt.hex))
+			expectNoErr(t, err)
+			expectGeomEq(t, got, g)
+		})
+	}
+}
+
+func TestHexEWKB(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		srid int
+		hex  string
+	}{
+		// SELECT ST_GeomFromEWKT('SRID=4326;POINT(1 2)')
+		{"POINT(1 2)", 4326, "0101000020e6100000000000000000f03f0000000000000040"},
+		// SELECT ST_GeomFromEWKT('POINT(1 2 3)')
+		{"POINT Z (1 2 3)", 0, "0101000080000000000000f03f00000000000000400000000000000840"},
+		{"LINESTRING EMPTY", 0, "010200000000000000"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt).WithSRID(tt.srid)
+			expectStringEq(t, g.AsHexEWKB(), tt.hex)
+			got, err := UnmarshalHexEWKB(tt.hex)
+			expectNoErr(t, err)
+			expectGeomEq(t, got, g)
+			expectIntEq(t, got.SRID(), tt.srid)
+		})
+	}
+}
+
+func TestUnmarshalHexWKBRejectsEWKB(t *testing.T) {
+	_, err := UnmarshalHexWKB("0101000020e6100000000000000000f03f0000000000000040")
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestUnmarshalHexWKBInvalid(t *testing.T) {
+	for i, s := range []string{
+		"",
+		"0",
+		"0101000000zz",
+		"010100000000",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := UnmarshalHexWKB(s); err == nil {
+				t.Error("expected error")
+			}
+			if _, err := UnmarshalHexEWKB(s); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package geom
+
+// appendWKTHeader appends the geometry type name along with the Z, M, or ZM
+// qualifier for the coordinates type (if one is required). The output format
+// matches that of PostGIS, e.g. "POINT(" for XY, but "POINT Z (" for XYZ.
+func appendWKTHeader(dst []byte, geomType string, ctype CoordinatesType) []byte {
+	dst = append(dst, geomType...)
+	switch ctype {
+	case DimXYZ:
+		dst = append(dst, " Z "...)
+	case DimXYM:
+		dst = append(dst, " M "...)
+	case DimXYZM:
+		dst = append(dst, " ZM "...)
+	}
+	return dst
+}
+
+// appendWKTEmpty appends the EMPTY keyword after a header written by
+// appendWKTHeader.
+func appendWKTEmpty(dst []byte, ctype CoordinatesType) []byte {
+	if ctype == DimXY {
+		dst = append(dst, ' ')
+	}
+	return append(dst, "EMPTY"...)
+}
@@ -26,6 +26,22 @@ func UnmarshalWKT(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
 	return geom, nil
 }
 
+// UnmarshalEWKT parses an Extended Well Known Text (EWKT), and returns the
+// corresponding Geometry. EWKT is the text format used by PostGIS. It is the
+// same as WKT, but may optionally be prefixed with an SRID, e.g.
+// "SRID=4326;POINT(1 2)". If present, the SRID is set on the returned
+// Geometry.
+func UnmarshalEWKT(r io.Reader, opts ...ConstructorOption) (Geometry, error) {
+	p := newParser(r, opts)
+	srid := p.nextSRID()
+	geom := p.nextGeometryTaggedText()
+	p.checkEOF()
+	if p.err != nil {
+		return Geometry{}, p.err
+	}
+	return geom.WithSRID(srid), nil
+}
+
 func newParser(r io.Reader, opts []ConstructorOption) *parser {
 	return &parser{lexer: newWKTLexer(r), opts: opts}
 }
@@ -34,6 +50,13 @@ type parser struct {
 	lexer *wktLexer
 	opts  []ConstructorOption
 	err   error
+
+	// ctype is the coordinates type of the geometry currently being parsed.
+	// If ctypeKnown is false, then the coordinates type hasn't been
+	// explicitly specified and will be inferred from the number of values in
+	// the first point parsed.
+	ctype      CoordinatesType
+	ctypeKnown bool
 }
 
 func (p *parser) check(err error) {
@@ -71,8 +94,108 @@ func (p *parser) checkEOF() {
 	}
 }
 
+// nextSRID consumes an optional EWKT SRID prefix, returning the SRID (or 0 if
+// there is no prefix).
+func (p *parser) nextSRID() int {
+	if !strings.EqualFold(p.peekToken(), "SRID") {
+		return 0
+	}
+	p.nextToken()
+	if tok := p.nextToken(); tok != "=" {
+		p.errorf("expected '=' but encountered %v", tok)
+		return 0
+	}
+	var negative bool
+	tok := p.nextToken()
+	if tok == "-" {
+		negative = true
+		tok = p.nextToken()
+	}
+	srid, err := strconv.ParseInt(tok, 10, 32)
+	if err != nil {
+		p.errorf("invalid SRID: %v", tok)
+		return 0
+	}
+	if negative {
+		srid *= -1
+	}
+	if tok := p.nextToken(); tok != ";" {
+		p.errorf("expected ';' but encountered %v", tok)
+		return 0
+	}
+	return int(srid)
+}
+
 func (p *parser) nextGeometryTaggedText() Geometry {
-	switch tok := p.nextToken(); strings.ToUpper(tok) {
+	// Geometries may be nested (inside a geometry collection), so the
+	// coordinates type state is saved and restored.
+	oldCType, oldCTypeKnown := p.ctype, p.ctypeKnown
+	defer func() {
+		p.ctype, p.ctypeKnown = oldCType, oldCTypeKnown
+	}()
+
+	geomType := p.nextGeometryTag()
+	geom := p.nextGeometryText(geomType)
+	if p.err != nil {
+		return Geometry{}
+	}
+	if p.ctypeKnown {
+		// Required for empty geometries, which don't have any points to
+		// carry the coordinates type.
+		geom = geom.ForceCoordinatesType(p.ctype)
+	}
+	return geom
+}
+
+// nextGeometryTag consumes the geometry type and any coordinates type
+// qualifier. Both the SFA 1.2 form (e.g. "POINT Z") and the form used by some
+// other implementations (e.g. "POINTZ") are accepted. The geometry type is
+// returned, and the coordinates type (if given) is recorded in the parser. If
+// no coordinates type qualifier is given, then the coordinates type of any
+// parent geometry collection is retained.
+func (p *parser) nextGeometryTag() string {
+	tok := strings.ToUpper(p.nextToken())
+	for _, geomType := range []string{
+		"POINT",
+		"LINESTRING",
+		"POLYGON",
+		"MULTIPOINT",
+		"MULTILINESTRING",
+		"MULTIPOLYGON",
+		"GEOMETRYCOLLECTION",
+	} {
+		if !strings.HasPrefix(tok, geomType) {
+			continue
+		}
+		qualifier := tok[len(geomType):]
+		if qualifier == "" {
+			switch next := strings.ToUpper(p.peekToken()); next {
+			case "Z", "M", "ZM":
+				p.nextToken()
+				qualifier = next
+			}
+		}
+		switch qualifier {
+		case "":
+			return geomType
+		case "Z":
+			p.ctype = DimXYZ
+		case "M":
+			p.ctype = DimXYM
+		case "ZM":
+			p.ctype = DimXYZM
+		default:
+			continue
+		}
+		p.ctypeKnown = true
+		return geomType
+	}
+	p.errorf("unexpected token: %v", tok)
+	return ""
+}
+
+func (p *parser) nextGeometryText(geomType string) Geometry {
+	switch geomType {
 	case "POINT":
 		coords := p.nextPointText()
 		if coords.Empty {
@@ -119,7 +242,6 @@ func (p *parser) nextGeometryTaggedText() Geometry {
 	case "GEOMETRYCOLLECTION":
 		return p.nextGeometryCollectionText()
 	default:
-		p.errorf("unexpected token: %v", tok)
 		return Geometry{}
 	}
 }
@@ -148,10 +270,49 @@ func (p *parser) nextCommaOrRightParen() string {
 }
 
 func (p *parser) nextPoint() Coordinates {
-	// TODO: handle z, m, and zm points.
-	x := p.nextSignedNumericLiteral()
-	y := p.nextSignedNumericLiteral()
-	return Coordinates{XY{x, y}}
+	var c Coordinates
+	c.X = p.nextSignedNumericLiteral()
+	c.Y = p.nextSignedNumericLiteral()
+
+	var extra []float64
+	for p.err == nil && len(extra) < 2 {
+		if tok := p.peekToken(); tok == "," || tok == ")" {
+			break
+		}
+		extra = append(extra, p.nextSignedNumericLiteral())
+	}
+
+	if !p.ctypeKnown {
+		// When the coordinates type isn't explicitly given, it's inferred
+		// from the first point. Three values are interpreted as XYZ (rather
+		// than XYM), which matches the behaviour of PostGIS.
+		p.ctypeKnown = true
+		switch len(extra) {
+		case 0:
+			p.ctype = DimXY
+		case 1:
+			p.ctype = DimXYZ
+		case 2:
+			p.ctype = DimXYZM
+		}
+	}
+	if len(extra) != p.ctype.Dimension()-2 {
+		p.errorf("point has %d coordinates, but expected %d (for %s)",
+			2+len(extra), p.ctype.Dimension(), p.ctype)
+		return c
+	}
+
+	c.Type = p.ctype
+	switch p.ctype {
+	case DimXYZ:
+		c.Z = extra[0]
+	case DimXYM:
+		c.M = extra[0]
+	case DimXYZM:
+		c.Z = extra[0]
+		c.M = extra[1]
+	}
+	return c
 }
 
 func (p *parser) nextSignedNumericLiteral() float64 {
@@ -296,5 +457,13 @@ func (p *parser) nextGeometryCollectionText() Geometry {
 			break
 		}
 	}
+	for _, g := range geoms[1:] {
+		if g.CoordinatesType() != geoms[0].CoordinatesType() {
+			p.check(errMixedCoordinatesTypes)
+		}
+	}
+	if p.ctypeKnown && geoms[0].CoordinatesType() != p.ctype {
+		p.check(errMixedCoordinatesTypes)
+	}
 	return NewGeometryCollection(geoms, p.opts...).AsGeometry()
 }
@@ -18,6 +18,14 @@ func TestUnmarshalWKTValidGrammar(t *testing.T) {
 		{"lower case", "point (1 1)"},
 		{"no space between tag and coord", "point(1 1)"},
 		{"exponent", "point (1e3 1.5e2)"},
+		{"z tag", "POINT Z (1 2 3)"},
+		{"m tag", "POINT M (1 2 3)"},
+		{"zm tag", "POINT ZM (1 2 3 4)"},
+		{"joined z tag", "POINTZ (1 2 3)"},
+		{"lower case zm tag", "point zm (1 2 3 4)"},
+		{"implicit z", "POINT (1 2 3)"},
+		{"implicit zm", "POINT (1 2 3 4)"},
+		{"z empty", "LINESTRING Z EMPTY"},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := UnmarshalWKT(strings.NewReader(tt.wkt))
@@ -41,6 +49,15 @@ func TestUnmarshalWKTInvalidGrammar(t *testing.T) {
 
 		{"mixed empty", "LINESTRING(0 0, EMPTY, 2 2)"},
 		{"foo internal point", "LINESTRING(0 0, foo, 2 2)"},
+
+		{"too few z coords", "POINT Z (1 2)"},
+		{"too many z coords", "POINT Z (1 2 3 4)"},
+		{"too few zm coords", "POINT ZM (1 2 3)"},
+		{"too many coords", "POINT (1 2 3 4 5)"},
+		{"unknown dimension tag", "POINTQ (1 2)"},
+		{"mixed implicit dimensions", "LINESTRING(0 0,1 1 1)"},
+		{"mixed geometry collection dimensions", "GEOMETRYCOLLECTION(POINT(1 2),POINT Z (1 2 3))"},
+		{"geometry collection child dimension mismatch", "GEOMETRYCOLLECTION Z (POINT M (1 2 3))"},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := UnmarshalWKT(strings.NewReader(tt.wkt))
@@ -69,6 +86,14 @@ func TestMarshalUnmarshalWKT(t *testing.T) {
 		"MULTIPOLYGON(((30 20,45 40,10 40,30 20)),((15 5,40 10,10 20,5 10,15 5)))",
 		"MULTIPOLYGON(((40 40,20 45,45 30,40 40)),((20 35,10 30,10 10,30 5,45 20,20 35),(30 20,20 15,20 25,30 20)))",
 		"GEOMETRYCOLLECTION(POINT(4 6),LINESTRING(4 6,7 10))",
+		"POINT Z (1 2 3)",
+		"POINT M (1 2 3)",
+		"POINT ZM (1 2 3 4)",
+		"LINESTRING Z (30 10 1,10 30 2,40 40 3)",
+		"POLYGON M ((30 10 1,40 40 2,20 40 3,10 20 4,30 10 5))",
+		"MULTIPOINT ZM ((10 40 1 2),(40 30 3 4))",
+		"MULTILINESTRING Z EMPTY",
+		"GEOMETRYCOLLECTION Z (POINT Z (4 6 1),POINT Z EMPTY)",
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			g1 := geomFromWKT(t, wkt)
@@ -77,3 +102,94 @@ func TestMarshalUnmarshalWKT(t *testing.T) {
 		})
 	}
 }
+
+func TestAsTextZM(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		want string
+	}{
+		{"POINT Z (1 2 3)", "POINT Z (1 2 3)"},
+		{"POINTZ(1 2 3)", "POINT Z (1 2 3)"},
+		{"POINT M (1 2 3)", "POINT M (1 2 3)"},
+		{"POINT(1 2 3)", "POINT Z (1 2 3)"},
+		{"POINT(1 2 3 4)", "POINT ZM (1 2 3 4)"},
+		{"POINT Z EMPTY", "POINT Z EMPTY"},
+		{"POINTM EMPTY", "POINT M EMPTY"},
+		{"LINESTRING M (1 2 3,4 5 6)", "LINESTRING M (1 2 3,4 5 6)"},
+		{"LINESTRING(1 2 3,4 5 6,7 8 9)", "LINESTRING Z (1 2 3,4 5 6,7 8 9)"},
+		{"LINESTRING Z (0 0 1,0 0 2,1 1 3)", "LINESTRING Z (0 0 1,0 0 2,1 1 3)"},
+		{"POLYGON ZM ((0 0 1 2,1 0 3 4,0 1 5 6,0 0 1 2))", "POLYGON ZM ((0 0 1 2,1 0 3 4,0 1 5 6,0 0 1 2))"},
+		{"MULTIPOINT Z (1 2 3,4 5 6)", "MULTIPOINT Z ((1 2 3),(4 5 6))"},
+		{"MULTIPOINT M EMPTY", "MULTIPOINT M EMPTY"},
+		{"MULTILINESTRING ZM ((1 2 3 4,5 6 7 8))", "MULTILINESTRING ZM ((1 2 3 4,5 6 7 8))"},
+		{"MULTIPOLYGON Z (((0 0 1,1 0 1,0 1 1,0 0 1)))", "MULTIPOLYGON Z (((0 0 1,1 0 1,0 1 1,0 0 1)))"},
+		{"GEOMETRYCOLLECTION Z EMPTY", "GEOMETRYCOLLECTION Z EMPTY"},
+		{"GEOMETRYCOLLECTION Z (POINT EMPTY,POINT(1 2 3))", "GEOMETRYCOLLECTION Z (POINT Z EMPTY,POINT Z (1 2 3))"},
+		{"GEOMETRYCOLLECTION(POINT M (1 2 3))", "GEOMETRYCOLLECTION M (POINT M (1 2 3))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectStringEq(t, geomFromWKT(t, tt.wkt).AsText(), tt.want)
+		})
+	}
+}
+
+func TestUnmarshalEWKT(t *testing.T) {
+	for i, tt := range []struct {
+		ewkt string
+		wkt  string
+		srid int
+	}{
+		{"SRID=4326;POINT(1 2)", "POINT(1 2)", 4326},
+		{"srid=3857; LINESTRING Z (1 2 3,4 5 6)", "LINESTRING Z (1 2 3,4 5 6)", 3857},
+		{"SRID=0;POINT EMPTY", "POINT EMPTY", 0},
+		{"MULTIPOINT(1 2)", "MULTIPOINT(1 2)", 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := UnmarshalEWKT(strings.NewReader(tt.ewkt))
+			expectNoErr(t, err)
+			expectGeomEq(t, g, geomFromWKT(t, tt.wkt))
+			expectIntEq(t, g.SRID(), tt.srid)
+		})
+	}
+}
+
+func TestUnmarshalEWKTInvalid(t *testing.T) {
+	for _, ewkt := range []string{
+		"SRID=4326 POINT(1 2)",
+		"SRID 4326;POINT(1 2)",
+		"SRID=;POINT(1 2)",
+		"SRID=1.5;POINT(1 2)",
+		"SRID=4326;",
+	} {
+		t.Run(ewkt, func(t *testing.T) {
+			_, err := UnmarshalEWKT(strings.NewReader(ewkt))
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestUnmarshalWKTRejectsSRID(t *testing.T) {
+	_, err := UnmarshalWKT(strings.NewReader("SRID=4326;POINT(1 2)"))
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestAsEWKT(t *testing.T) {
+	for i, tt := range []struct {
+		wkt  string
+		srid int
+		want string
+	}{
+		{"POINT(1 2)", 4326, "SRID=4326;POINT(1 2)"},
+		{"POINT Z (1 2 3)", 3857, "SRID=3857;POINT Z (1 2 3)"},
+		{"LINESTRING(1 2,3 4)", 0, "LINESTRING(1 2,3 4)"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := geomFromWKT(t, tt.wkt).WithSRID(tt.srid)
+			expectStringEq(t, g.AsEWKT(), tt.want)
+		})
+	}
+}
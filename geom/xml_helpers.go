@@ -0,0 +1,93 @@
+package geom
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// xmlFirstElement reads tokens up to (and including) the first start element.
+func xmlFirstElement(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, errors.New("no XML element found")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// xmlForEachChild calls fn for each child element of the element that has
+// just been started. The function must consume the child element (up to and
+// including its end element). The end element of the parent is consumed
+// before xmlForEachChild returns.
+func xmlForEachChild(d *xml.Decoder, fn func(child xml.StartElement) error) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if err := fn(tok); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// xmlText reads the character data of an element that has just been
+// started, up to and including its end element.
+func xmlText(d *xml.Decoder, start xml.StartElement) (string, error) {
+	var s string
+	err := d.DecodeElement(&s, &start)
+	return s, err
+}
+
+// xmlAttr gives the value of an attribute (ignoring its namespace).
+func xmlAttr(start xml.StartElement, local string) (string, bool) {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// xmlWriter writes XML tokens, holding onto the first error encountered.
+type xmlWriter struct {
+	e   *xml.Encoder
+	err error
+}
+
+func (w *xmlWriter) start(name string, attrs ...xml.Attr) {
+	if w.err == nil {
+		w.err = w.e.EncodeToken(xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs})
+	}
+}
+
+func (w *xmlWriter) end(name string) {
+	if w.err == nil {
+		w.err = w.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (w *xmlWriter) text(buf []byte) {
+	if w.err == nil {
+		w.err = w.e.EncodeToken(xml.CharData(buf))
+	}
+}
+
+// textElement writes an element that only contains character data.
+func (w *xmlWriter) textElement(name string, buf []byte, attrs ...xml.Attr) {
+	w.start(name, attrs...)
+	w.text(buf)
+	w.end(name)
+}
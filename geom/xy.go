@@ -1,5 +1,7 @@
 package geom
 
+import "math"
+
 type XY struct {
 	X, Y float64
 }
@@ -41,6 +43,11 @@ func (w XY) Dot(o XY) float64 {
 	return w.X*o.X + w.Y*o.Y
 }
 
+// Length treats XY as a vector, and returns its length.
+func (w XY) Length() float64 {
+	return math.Sqrt(w.Dot(w))
+}
+
 // Less gives an ordering on XYs. If two XYs have different X values, then the
 // one with the lower X value is ordered before the one with the higher X
 // value. If the X values are then same, then the Y values are used (the lower
@@ -0,0 +1,327 @@
+package mvt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Unmarshal decodes the layers of a vector tile. The tile covers the region
+// given by bounds, which must have a non-zero width and height. Geometries are
+// transformed from tile coordinates into the bounds' coordinate system, and
+// are constructed using the supplied options.
+//
+// Point features are decoded as Points or MultiPoints, line features are
+// decoded as LineStrings or MultiLineStrings, and polygon features are
+// decoded as Polygons or MultiPolygons. Rings with zero area are omitted.
+func Unmarshal(p []byte, bounds geom.Envelope, opts ...geom.ConstructorOption) ([]Layer, error) {
+	if bounds.Width() <= 0 || bounds.Height() <= 0 {
+		return nil, errors.New("tile bounds must have a non-zero width and height")
+	}
+	var layers []Layer
+	r := pbReader{p}
+	for !r.done() {
+		f, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if f.num != tileLayers || f.wireType != wireBytes {
+			continue
+		}
+		l, err := unmarshalLayer(f.bytes, bounds, opts)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %v", len(layers), err)
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+func unmarshalLayer(p []byte, bounds geom.Envelope, opts []geom.ConstructorOption) (Layer, error) {
+	var (
+		l        Layer
+		keys     []string
+		values   []interface{}
+		features [][]byte
+	)
+	r := pbReader{p}
+	for !r.done() {
+		f, err := r.next()
+		if err != nil {
+			return Layer{}, err
+		}
+		switch {
+		case f.num == layerVersion && f.wireType == wireVarint:
+			if f.varint != 1 && f.varint != 2 {
+				return Layer{}, fmt.Errorf("unsupported version: %d", f.varint)
+			}
+		case f.num == layerName && f.wireType == wireBytes:
+			l.Name = string(f.bytes)
+		case f.num == layerFeatures && f.wireType == wireBytes:
+			features = append(features, f.bytes)
+		case f.num == layerKeys && f.wireType == wireBytes:
+			keys = append(keys, string(f.bytes))
+		case f.num == layerValues && f.wireType == wireBytes:
+			v, err := unmarshalValue(f.bytes)
+			if err != nil {
+				return Layer{}, err
+			}
+			values = append(values, v)
+		case f.num == layerExtent && f.wireType == wireVarint:
+			if f.varint == 0 || f.varint > math.MaxUint32 {
+				return Layer{}, fmt.Errorf("invalid extent: %d", f.varint)
+			}
+			l.Extent = uint32(f.varint)
+		}
+	}
+
+	dec := featureDecoder{
+		tr:     newTileTransform(bounds, l.extent()),
+		keys:   keys,
+		values: values,
+		opts:   opts,
+	}
+	for i, p := range features {
+		f, err := dec.decode(p)
+		if err != nil {
+			return Layer{}, fmt.Errorf("feature %d: %v", i, err)
+		}
+		l.Features = append(l.Features, f)
+	}
+	return l, nil
+}
+
+func unmarshalValue(p []byte) (interface{}, error) {
+	var v interface{}
+	r := pbReader{p}
+	for !r.done() {
+		f, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case f.num == valueString && f.wireType == wireBytes:
+			v = string(f.bytes)
+		case f.num == valueFloat && f.wireType == wireFixed32:
+			v = float64(math.Float32frombits(uint32(f.varint)))
+		case f.num == valueDouble && f.wireType == wireFixed64:
+			v = math.Float64frombits(f.varint)
+		case f.num == valueInt && f.wireType == wireVarint:
+			v = int64(f.varint)
+		case f.num == valueUint && f.wireType == wireVarint:
+			v = f.varint
+		case f.num == valueSint && f.wireType == wireVarint:
+			v = zigzagDecode(f.varint)
+		case f.num == valueBool && f.wireType == wireVarint:
+			v = f.varint != 0
+		}
+	}
+	if v == nil {
+		return nil, errors.New("value has no supported fields")
+	}
+	return v, nil
+}
+
+// featureDecoder decodes the features of a single layer.
+type featureDecoder struct {
+	tr     tileTransform
+	keys   []string
+	values []interface{}
+	opts   []geom.ConstructorOption
+}
+
+func (d featureDecoder) decode(p []byte) (geom.GeoJSONFeature, error) {
+	var (
+		feat     geom.GeoJSONFeature
+		tags     []uint32
+		geomType uint64
+		cmds     []uint32
+	)
+	r := pbReader{p}
+	for !r.done() {
+		f, err := r.next()
+		if err != nil {
+			return geom.GeoJSONFeature{}, err
+		}
+		switch {
+		case f.num == featureID && f.wireType == wireVarint:
+			feat.ID = f.varint
+		case f.num == featureTags:
+			tags, err = appendRepeated(tags, f)
+		case f.num == featureType && f.wireType == wireVarint:
+			geomType = f.varint
+		case f.num == featureGeometry:
+			cmds, err = appendRepeated(cmds, f)
+		}
+		if err != nil {
+			return geom.GeoJSONFeature{}, err
+		}
+	}
+
+	if len(tags)%2 != 0 {
+		return geom.GeoJSONFeature{}, errors.New("odd number of tags")
+	}
+	if len(tags) > 0 {
+		feat.Properties = make(map[string]interface{}, len(tags)/2)
+	}
+	for i := 0; i < len(tags); i += 2 {
+		k, v := tags[i], tags[i+1]
+		if int(k) >= len(d.keys) || int(v) >= len(d.values) {
+			return geom.GeoJSONFeature{}, errors.New("tag index out of range")
+		}
+		feat.Properties[d.keys[k]] = d.values[v]
+	}
+
+	parts, err := parseCommands(cmds)
+	if err != nil {
+		return geom.GeoJSONFeature{}, err
+	}
+	switch geomType {
+	case geomTypePoint:
+		feat.Geometry, err = d.points(parts)
+	case geomTypeLineString:
+		feat.Geometry, err = d.lineStrings(parts)
+	case geomTypePolygon:
+		feat.Geometry, err = d.polygons(parts)
+	default:
+		err = fmt.Errorf("unsupported geometry type: %d", geomType)
+	}
+	return feat, err
+}
+
+// appendRepeated appends the values of a repeated uint32 field, which may
+// either be packed or unpacked.
+func appendRepeated(dst []uint32, f pbField) ([]uint32, error) {
+	switch f.wireType {
+	case wireBytes:
+		vs, err := unpackVarints(f.bytes)
+		return append(dst, vs...), err
+	case wireVarint:
+		if f.varint > math.MaxUint32 {
+			return nil, errors.New("repeated value overflows uint32")
+		}
+		return append(dst, uint32(f.varint)), nil
+	default:
+		return nil, fmt.Errorf("unexpected wire type for repeated field: %d", f.wireType)
+	}
+}
+
+// commandPart is a sequence of points (in tile coordinates) that starts with
+// a MoveTo command.
+type commandPart struct {
+	pts    []tilePoint
+	closed bool
+}
+
+// parseCommands splits geometry commands into parts.
+func parseCommands(cmds []uint32) ([]commandPart, error) {
+	var (
+		parts  []commandPart
+		cursor tilePoint
+	)
+	for i := 0; i < len(cmds); {
+		id, count := int(cmds[i]&0x7), int(cmds[i]>>3)
+		i++
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if count > (len(cmds)-i)/2 {
+				return nil, errors.New("geometry command has too few parameters")
+			}
+			if id == cmdLineTo && len(parts) == 0 {
+				return nil, errors.New("LineTo command without preceding MoveTo")
+			}
+			for j := 0; j < count; j++ {
+				cursor.x += zigzagDecode(uint64(cmds[i]))
+				cursor.y += zigzagDecode(uint64(cmds[i+1]))
+				i += 2
+				if id == cmdMoveTo {
+					parts = append(parts, commandPart{})
+				}
+				last := &parts[len(parts)-1]
+				last.pts = append(last.pts, cursor)
+			}
+		case cmdClosePath:
+			if len(parts) == 0 {
+				return nil, errors.New("ClosePath command without preceding MoveTo")
+			}
+			parts[len(parts)-1].closed = true
+		default:
+			return nil, fmt.Errorf("unknown geometry command: %d", id)
+		}
+	}
+	return parts, nil
+}
+
+func (d featureDecoder) toXYs(tps []tilePoint) []geom.XY {
+	xys := make([]geom.XY, len(tps))
+	for i, tp := range tps {
+		xys[i] = d.tr.fromTile(geom.XY{X: float64(tp.x), Y: float64(tp.y)})
+	}
+	return xys
+}
+
+func (d featureDecoder) points(parts []commandPart) (geom.Geometry, error) {
+	var tps []tilePoint
+	for _, part := range parts {
+		tps = append(tps, part.pts...)
+	}
+	xys := d.toXYs(tps)
+	switch len(xys) {
+	case 0:
+		return geom.Geometry{}, nil
+	case 1:
+		return geom.NewPointXY(xys[0], d.opts...).AsGeometry(), nil
+	default:
+		return geom.NewMultiPointXY(xys, d.opts...).AsGeometry(), nil
+	}
+}
+
+func (d featureDecoder) lineStrings(parts []commandPart) (geom.Geometry, error) {
+	var lss []geom.LineString
+	for _, part := range parts {
+		ls, err := geom.NewLineStringXY(d.toXYs(part.pts), d.opts...)
+		if err != nil {
+			return geom.Geometry{}, err
+		}
+		lss = append(lss, ls)
+	}
+	switch len(lss) {
+	case 0:
+		return geom.Geometry{}, nil
+	case 1:
+		return lss[0].AsGeometry(), nil
+	default:
+		return geom.NewMultiLineString(lss, d.opts...).AsGeometry(), nil
+	}
+}
+
+func (d featureDecoder) polygons(parts []commandPart) (geom.Geometry, error) {
+	var polys [][][]geom.XY
+	for _, part := range parts {
+		area := ringArea(part.pts)
+		if area == 0 {
+			continue
+		}
+		ring := d.toXYs(append(part.pts, part.pts[0]))
+		if area > 0 {
+			polys = append(polys, [][]geom.XY{ring})
+			continue
+		}
+		if len(polys) == 0 {
+			return geom.Geometry{}, errors.New("interior ring without preceding exterior ring")
+		}
+		polys[len(polys)-1] = append(polys[len(polys)-1], ring)
+	}
+	switch len(polys) {
+	case 0:
+		return geom.Geometry{}, nil
+	case 1:
+		poly, err := geom.NewPolygonXY(polys[0], d.opts...)
+		return poly.AsGeometry(), err
+	default:
+		mp, err := geom.NewMultiPolygonXY(polys, d.opts...)
+		return mp.AsGeometry(), err
+	}
+}
@@ -0,0 +1,512 @@
+package mvt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Marshal encodes layers into a vector tile. The tile covers the region given
+// by bounds, which must have a non-zero width and height.
+//
+// Geometries are clipped to the tile bounds (expanded by a buffer), and then
+// quantised to the tile's integer coordinates. Parts of geometries that
+// collapse during quantisation are omitted, and polygons that become invalid
+// are repaired. Polygon rings are wound as required by the specification
+// (exterior rings are clockwise and interior rings are counterclockwise,
+// when viewed with the Y axis pointing down).
+//
+// Because each vector tile feature has a single geometry type, features that
+// have GeometryCollections containing a mix of points, lines, and polygons
+// are encoded as multiple features (one for each geometry type) with the same
+// ID and properties. Features with geometries that are entirely clipped away
+// are omitted.
+func Marshal(bounds geom.Envelope, layers []Layer, opts ...MarshalOption) ([]byte, error) {
+	if bounds.Width() <= 0 || bounds.Height() <= 0 {
+		return nil, errors.New("tile bounds must have a non-zero width and height")
+	}
+	os := newMarshalOptionSet(opts)
+	var tile []byte
+	for _, l := range layers {
+		layer, err := marshalLayer(bounds, l, os)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: %v", l.Name, err)
+		}
+		tile = appendBytesField(tile, tileLayers, layer)
+	}
+	return tile, nil
+}
+
+// layerEncoder builds up the keys and values tables of a layer.
+type layerEncoder struct {
+	keys     []string
+	keyIdx   map[string]uint32
+	values   [][]byte
+	valueIdx map[string]uint32
+}
+
+func marshalLayer(bounds geom.Envelope, l Layer, os marshalOptionSet) ([]byte, error) {
+	extent := l.extent()
+	enc := &layerEncoder{
+		keyIdx:   make(map[string]uint32),
+		valueIdx: make(map[string]uint32),
+	}
+	geomEnc := geometryEncoder{
+		tr:     newTileTransform(bounds, extent),
+		extent: extent,
+		opts:   os,
+	}
+
+	var layer []byte
+	layer = appendVarintField(layer, layerVersion, 2)
+	layer = appendBytesField(layer, layerName, []byte(l.Name))
+	for i, f := range l.Features {
+		tags, err := enc.tags(f.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		id, hasID := featureIDValue(f.ID)
+		parts, err := geomEnc.encode(f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		for _, part := range parts {
+			var feat []byte
+			if hasID {
+				feat = appendVarintField(feat, featureID, id)
+			}
+			if len(tags) > 0 {
+				feat = appendPackedField(feat, featureTags, tags)
+			}
+			feat = appendVarintField(feat, featureType, uint64(part.geomType))
+			feat = appendPackedField(feat, featureGeometry, part.cmds)
+			layer = appendBytesField(layer, layerFeatures, feat)
+		}
+	}
+	for _, k := range enc.keys {
+		layer = appendBytesField(layer, layerKeys, []byte(k))
+	}
+	for _, v := range enc.values {
+		layer = appendBytesField(layer, layerValues, v)
+	}
+	layer = appendVarintField(layer, layerExtent, uint64(extent))
+	return layer, nil
+}
+
+// tags gives the tags of a feature, which are pairs of indices into the keys
+// and values tables.
+func (e *layerEncoder) tags(props map[string]interface{}) ([]uint32, error) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var tags []uint32
+	for _, k := range keys {
+		v, ok, err := encodeValue(props[k])
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", k, err)
+		}
+		if !ok {
+			continue
+		}
+		ki, ok := e.keyIdx[k]
+		if !ok {
+			ki = uint32(len(e.keys))
+			e.keyIdx[k] = ki
+			e.keys = append(e.keys, k)
+		}
+		vi, ok := e.valueIdx[string(v)]
+		if !ok {
+			vi = uint32(len(e.values))
+			e.valueIdx[string(v)] = vi
+			e.values = append(e.values, v)
+		}
+		tags = append(tags, ki, vi)
+	}
+	return tags, nil
+}
+
+// encodeValue encodes a property value as a vector tile Value message. It
+// returns false if the value should be omitted.
+func encodeValue(v interface{}) ([]byte, bool, error) {
+	var buf []byte
+	switch v := v.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		buf = appendBytesField(buf, valueString, []byte(v))
+	case bool:
+		var b uint64
+		if v {
+			b = 1
+		}
+		buf = appendVarintField(buf, valueBool, b)
+	case float32:
+		buf = appendFixed32Field(buf, valueFloat, math.Float32bits(v))
+	case float64:
+		buf = appendFixed64Field(buf, valueDouble, math.Float64bits(v))
+	case int:
+		buf = appendSignedValue(buf, int64(v))
+	case int8:
+		buf = appendSignedValue(buf, int64(v))
+	case int16:
+		buf = appendSignedValue(buf, int64(v))
+	case int32:
+		buf = appendSignedValue(buf, int64(v))
+	case int64:
+		buf = appendSignedValue(buf, v)
+	case uint:
+		buf = appendVarintField(buf, valueUint, uint64(v))
+	case uint8:
+		buf = appendVarintField(buf, valueUint, uint64(v))
+	case uint16:
+		buf = appendVarintField(buf, valueUint, uint64(v))
+	case uint32:
+		buf = appendVarintField(buf, valueUint, uint64(v))
+	case uint64:
+		buf = appendVarintField(buf, valueUint, v)
+	default:
+		j, err := json.Marshal(v)
+		if err != nil {
+			return nil, false, err
+		}
+		buf = appendBytesField(buf, valueString, j)
+	}
+	return buf, true, nil
+}
+
+// appendSignedValue appends a signed integer value. Negative values use the
+// zigzag encoding, which is more compact.
+func appendSignedValue(dst []byte, v int64) []byte {
+	if v < 0 {
+		return appendVarintField(dst, valueSint, zigzagEncode(v))
+	}
+	return appendVarintField(dst, valueInt, uint64(v))
+}
+
+// featureIDValue converts a feature ID to a vector tile ID. It returns false
+// if the ID can't be represented in a vector tile.
+func featureIDValue(id interface{}) (uint64, bool) {
+	var signed int64
+	switch id := id.(type) {
+	case uint:
+		return uint64(id), true
+	case uint8:
+		return uint64(id), true
+	case uint16:
+		return uint64(id), true
+	case uint32:
+		return uint64(id), true
+	case uint64:
+		return id, true
+	case int:
+		signed = int64(id)
+	case int8:
+		signed = int64(id)
+	case int16:
+		signed = int64(id)
+	case int32:
+		signed = int64(id)
+	case int64:
+		signed = id
+	case float64:
+		if id < 0 || id >= 1<<64 || id != math.Trunc(id) {
+			return 0, false
+		}
+		return uint64(id), true
+	default:
+		return 0, false
+	}
+	if signed < 0 {
+		return 0, false
+	}
+	return uint64(signed), true
+}
+
+// geometryEncoder converts geometries into vector tile geometry commands.
+type geometryEncoder struct {
+	tr     tileTransform
+	extent uint32
+	opts   marshalOptionSet
+}
+
+// encodedGeometry is the geometry of a single vector tile feature.
+type encodedGeometry struct {
+	geomType int
+	cmds     []uint32
+}
+
+// tilePoint is a point in (quantised) tile coordinates.
+type tilePoint struct {
+	x, y int64
+}
+
+func (g geometryEncoder) quantise(xy geom.XY) geom.XY {
+	t := g.tr.toTile(xy)
+	return geom.XY{X: math.Round(t.X), Y: math.Round(t.Y)}
+}
+
+func (g geometryEncoder) toTilePoint(xy geom.XY) tilePoint {
+	t := g.tr.toTile(xy)
+	return tilePoint{int64(math.Round(t.X)), int64(math.Round(t.Y))}
+}
+
+// clipEnvelope gives the buffered tile bounds (in the bounds' coordinate
+// system).
+func (g geometryEncoder) clipEnvelope() geom.Envelope {
+	buf := float64(g.opts.buffer)
+	env, _ := geom.NewEnvelope(g.tr.min, g.tr.max).ExpandBy(buf/g.tr.sx, buf/g.tr.sy)
+	return env
+}
+
+// clip clips a geometry to the buffered tile bounds (unless clipping is
+// disabled, or the geometry is already entirely within the bounds).
+func (g geometryEncoder) clip(in geom.Geometry) (geom.Geometry, error) {
+	if !g.opts.clip {
+		return in, nil
+	}
+	clipEnv := g.clipEnvelope()
+	if env, ok := in.Envelope(); !ok || clipEnv.Covers(env) {
+		return in, nil
+	}
+	return in.Intersection(clipEnv.AsGeometry())
+}
+
+func (g geometryEncoder) encode(in geom.Geometry) ([]encodedGeometry, error) {
+	var parts geometryParts
+	parts.add(in)
+
+	var out []encodedGeometry
+	if cmds := g.encodePoints(parts.points); len(cmds) > 0 {
+		out = append(out, encodedGeometry{geomTypePoint, cmds})
+	}
+	cmds, err := g.encodeLineStrings(parts.lines)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) > 0 {
+		out = append(out, encodedGeometry{geomTypeLineString, cmds})
+	}
+	cmds, err = g.encodePolygons(parts.polys)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) > 0 {
+		out = append(out, encodedGeometry{geomTypePolygon, cmds})
+	}
+	return out, nil
+}
+
+func (g geometryEncoder) encodePoints(pts []geom.Point) []uint32 {
+	var tps []tilePoint
+	lo := -int64(g.opts.buffer)
+	hi := int64(g.extent) + int64(g.opts.buffer)
+	for _, pt := range pts {
+		tp := g.toTilePoint(pt.XY())
+		if g.opts.clip && (tp.x < lo || tp.x > hi || tp.y < lo || tp.y > hi) {
+			continue
+		}
+		tps = append(tps, tp)
+	}
+	if len(tps) == 0 {
+		return nil
+	}
+	var cw commandWriter
+	cw.moveTo(tps)
+	return cw.cmds
+}
+
+func (g geometryEncoder) encodeLineStrings(lines []geom.LineString) ([]uint32, error) {
+	var cw commandWriter
+	for _, ls := range lines {
+		clipped, err := g.clip(ls.AsGeometry())
+		if err != nil {
+			return nil, err
+		}
+		var parts geometryParts
+		parts.add(clipped)
+		for _, part := range parts.lines {
+			tps := g.lineStringTilePoints(part)
+			if len(tps) < 2 {
+				continue
+			}
+			cw.moveTo(tps[:1])
+			cw.lineTo(tps[1:])
+		}
+	}
+	return cw.cmds, nil
+}
+
+func (g geometryEncoder) encodePolygons(polys []geom.Polygon) ([]uint32, error) {
+	var cw commandWriter
+	for _, poly := range polys {
+		clipped, err := g.clip(poly.AsGeometry())
+		if err != nil {
+			return nil, err
+		}
+		quantised, err := clipped.TransformXY(g.quantise, geom.DisableAllValidations)
+		if err != nil {
+			return nil, err
+		}
+		if !quantised.IsValid() {
+			quantised, err = geom.MakeValid(quantised)
+			if err != nil {
+				return nil, err
+			}
+		}
+		var parts geometryParts
+		parts.add(quantised)
+		for _, part := range parts.polys {
+			// The exterior ring has a positive area, and interior rings
+			// have negative areas (in tile coordinates).
+			ext := ringTilePoints(part.ExteriorRing(), +1)
+			if ext == nil {
+				continue
+			}
+			cw.ring(ext)
+			for i := 0; i < part.NumInteriorRings(); i++ {
+				if hole := ringTilePoints(part.InteriorRingN(i), -1); hole != nil {
+					cw.ring(hole)
+				}
+			}
+		}
+	}
+	return cw.cmds, nil
+}
+
+// lineStringTilePoints quantises the points of a LineString, omitting
+// consecutive duplicates.
+func (g geometryEncoder) lineStringTilePoints(ls geom.LineString) []tilePoint {
+	var tps []tilePoint
+	for i := 0; i < ls.NumPoints(); i++ {
+		tp := g.toTilePoint(ls.PointN(i).XY())
+		if len(tps) == 0 || tps[len(tps)-1] != tp {
+			tps = append(tps, tp)
+		}
+	}
+	return tps
+}
+
+// ringTilePoints gives the points of a ring (that is already in tile
+// coordinates), omitting consecutive duplicates and the closing point. The
+// points are ordered so that the sign of the ring's area matches the
+// requested sign. It returns nil if the ring has zero area.
+func ringTilePoints(ring geom.LineString, sign int64) []tilePoint {
+	var tps []tilePoint
+	for i := 0; i < ring.NumPoints(); i++ {
+		xy := ring.PointN(i).XY()
+		tp := tilePoint{int64(math.Round(xy.X)), int64(math.Round(xy.Y))}
+		if len(tps) == 0 || tps[len(tps)-1] != tp {
+			tps = append(tps, tp)
+		}
+	}
+	if len(tps) > 1 && tps[0] == tps[len(tps)-1] {
+		tps = tps[:len(tps)-1]
+	}
+	area := ringArea(tps)
+	if area == 0 {
+		return nil
+	}
+	if (area > 0) != (sign > 0) {
+		for i, j := 0, len(tps)-1; i < j; i, j = i+1, j-1 {
+			tps[i], tps[j] = tps[j], tps[i]
+		}
+	}
+	return tps
+}
+
+// ringArea gives twice the signed area of a ring (using the surveyor's
+// formula, as described in the specification). The ring's closing point
+// isn't repeated.
+func ringArea(tps []tilePoint) int64 {
+	var sum int64
+	for i, p := range tps {
+		q := tps[(i+1)%len(tps)]
+		sum += p.x*q.y - q.x*p.y
+	}
+	return sum
+}
+
+// commandWriter writes geometry commands, keeping track of the cursor
+// position.
+type commandWriter struct {
+	cmds   []uint32
+	cursor tilePoint
+}
+
+func command(id, count int) uint32 {
+	return uint32(id&0x7) | uint32(count)<<3
+}
+
+func (w *commandWriter) params(tps []tilePoint) {
+	for _, tp := range tps {
+		w.cmds = append(w.cmds,
+			uint32(zigzagEncode(tp.x-w.cursor.x)),
+			uint32(zigzagEncode(tp.y-w.cursor.y)),
+		)
+		w.cursor = tp
+	}
+}
+
+func (w *commandWriter) moveTo(tps []tilePoint) {
+	w.cmds = append(w.cmds, command(cmdMoveTo, len(tps)))
+	w.params(tps)
+}
+
+func (w *commandWriter) lineTo(tps []tilePoint) {
+	w.cmds = append(w.cmds, command(cmdLineTo, len(tps)))
+	w.params(tps)
+}
+
+func (w *commandWriter) ring(tps []tilePoint) {
+	w.moveTo(tps[:1])
+	w.lineTo(tps[1:])
+	w.cmds = append(w.cmds, command(cmdClosePath, 1))
+}
+
+// geometryParts holds the atomic parts of a geometry.
+type geometryParts struct {
+	points []geom.Point
+	lines  []geom.LineString
+	polys  []geom.Polygon
+}
+
+func (p *geometryParts) add(g geom.Geometry) {
+	switch {
+	case g.IsEmpty():
+	case g.IsPoint():
+		p.points = append(p.points, g.AsPoint())
+	case g.IsMultiPoint():
+		mp := g.AsMultiPoint()
+		for i := 0; i < mp.NumPoints(); i++ {
+			p.points = append(p.points, mp.PointN(i))
+		}
+	case g.IsLine():
+		p.lines = append(p.lines, g.AsLine().AsLineString())
+	case g.IsLineString():
+		p.lines = append(p.lines, g.AsLineString())
+	case g.IsMultiLineString():
+		mls := g.AsMultiLineString()
+		for i := 0; i < mls.NumLineStrings(); i++ {
+			p.lines = append(p.lines, mls.LineStringN(i))
+		}
+	case g.IsPolygon():
+		p.polys = append(p.polys, g.AsPolygon())
+	case g.IsMultiPolygon():
+		mp := g.AsMultiPolygon()
+		for i := 0; i < mp.NumPolygons(); i++ {
+			p.polys = append(p.polys, mp.PolygonN(i))
+		}
+	case g.IsGeometryCollection():
+		gc := g.AsGeometryCollection()
+		for i := 0; i < gc.NumGeometries(); i++ {
+			p.add(gc.GeometryN(i))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package mvt encodes and decodes Mapbox Vector Tiles (version 2.1 of the
+// specification, see https://github.com/mapbox/vector-tile-spec).
+//
+// A tile covers a rectangular region (its bounds), which is given as an
+// Envelope in the same coordinate system as the geometries being encoded
+// (typically Web Mercator). Geometries are transformed into the tile's
+// integer coordinate system when they're encoded, and transformed back into
+// the bounds' coordinate system when they're decoded.
+package mvt
+
+import "github.com/peterstace/simplefeatures/geom"
+
+// DefaultExtent is the extent used for layers that don't specify one.
+const DefaultExtent = 4096
+
+// Layer is a named layer of features within a vector tile.
+type Layer struct {
+	// Name is the name of the layer. Each layer in a tile should have a
+	// unique name.
+	Name string
+
+	// Extent is the width and height of the tile in tile coordinates. If it's
+	// zero, then DefaultExtent is used.
+	Extent uint32
+
+	// Features are the features in the layer.
+	//
+	// When encoding, each feature's ID is encoded if it's a non-negative
+	// integer (of any integer type, or a float64 with an integral value).
+	// Properties with string, bool, integer, float32, and float64 values are
+	// encoded as the equivalent vector tile values. Properties with nil
+	// values are omitted, and properties with other values are encoded as
+	// their JSON representation (as strings).
+	//
+	// When decoding, IDs are uint64 values (or nil if the feature doesn't
+	// have an ID). Property values are string, float64, int64, uint64, or
+	// bool.
+	Features []geom.GeoJSONFeature
+}
+
+func (l Layer) extent() uint32 {
+	if l.Extent == 0 {
+		return DefaultExtent
+	}
+	return l.Extent
+}
+
+// MarshalOption allows the behaviour of Marshal to be modified.
+type MarshalOption func(s *marshalOptionSet)
+
+type marshalOptionSet struct {
+	buffer uint32
+	clip   bool
+}
+
+func newMarshalOptionSet(opts []MarshalOption) marshalOptionSet {
+	// The defaults match the defaults used by PostGIS.
+	s := marshalOptionSet{
+		buffer: 256,
+		clip:   true,
+	}
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+// Buffer sets the size of the buffer around the tile (in tile coordinates)
+// that geometries are clipped to. The default is 256.
+func Buffer(n uint32) MarshalOption {
+	return func(s *marshalOptionSet) {
+		s.buffer = n
+	}
+}
+
+// DisableClipping causes geometries to not be clipped to the buffered tile
+// bounds. Geometries are still quantised to tile coordinates.
+func DisableClipping(s *marshalOptionSet) {
+	s.clip = false
+}
+
+// Protocol buffer field numbers, from the vector tile specification.
+const (
+	tileLayers = 3
+
+	layerVersion  = 15
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueFloat  = 2
+	valueDouble = 3
+	valueInt    = 4
+	valueUint   = 5
+	valueSint   = 6
+	valueBool   = 7
+)
+
+// Geometry types, from the vector tile specification.
+const (
+	geomTypeUnknown    = 0
+	geomTypePoint      = 1
+	geomTypeLineString = 2
+	geomTypePolygon    = 3
+)
+
+// Geometry commands, from the vector tile specification.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// tileTransform converts between the bounds' coordinate system and tile
+// coordinates (which have the Y axis pointing down).
+type tileTransform struct {
+	min, max geom.XY
+	sx, sy   float64
+}
+
+func newTileTransform(bounds geom.Envelope, extent uint32) tileTransform {
+	min, max := bounds.Min(), bounds.Max()
+	return tileTransform{
+		min: min,
+		max: max,
+		sx:  float64(extent) / (max.X - min.X),
+		sy:  float64(extent) / (max.Y - min.Y),
+	}
+}
+
+func (t tileTransform) toTile(xy geom.XY) geom.XY {
+	return geom.XY{
+		X: (xy.X - t.min.X) * t.sx,
+		Y: (t.max.Y - xy.Y) * t.sy,
+	}
+}
+
+func (t tileTransform) fromTile(xy geom.XY) geom.XY {
+	return geom.XY{
+		X: t.min.X + xy.X/t.sx,
+		Y: t.max.Y - xy.Y/t.sy,
+	}
+}
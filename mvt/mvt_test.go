@@ -0,0 +1,442 @@
+package mvt
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func geomFromWKT(t *testing.T, wkt string) geom.Geometry {
+	t.Helper()
+	g, err := geom.UnmarshalWKT(strings.NewReader(wkt))
+	if err != nil {
+		t.Fatalf("could not unmarshal WKT: %v", err)
+	}
+	return g
+}
+
+// testBounds maps 1:1 onto tile coordinates (with the default extent), except
+// that the Y axis is flipped.
+var testBounds = geom.NewEnvelope(geom.XY{X: 0, Y: 0}, geom.XY{X: 4096, Y: 4096})
+
+func TestZigzag(t *testing.T) {
+	for i, tt := range []struct {
+		n int64
+		z uint64
+	}{
+		{0, 0},
+		{-1, 1},
+		{1, 2},
+		{-2, 3},
+		{2, 4},
+		{2147483647, 4294967294},
+		{-2147483648, 4294967295},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := zigzagEncode(tt.n); got != tt.z {
+				t.Errorf("encode: got=%d want=%d", got, tt.z)
+			}
+			if got := zigzagDecode(tt.z); got != tt.n {
+				t.Errorf("decode: got=%d want=%d", got, tt.n)
+			}
+		})
+	}
+}
+
+func TestEncodeGeometryCommands(t *testing.T) {
+	// The expected commands are the examples from the vector tile
+	// specification. The bounds are set up so that input coordinates are
+	// the same as tile coordinates.
+	bounds := geom.NewEnvelope(geom.XY{X: 0, Y: -4096}, geom.XY{X: 4096, Y: 0})
+	for i, tt := range []struct {
+		wkt  string
+		typ  int
+		cmds []uint32
+	}{
+		{
+			wkt:  "POINT(25 -17)",
+			typ:  geomTypePoint,
+			cmds: []uint32{9, 50, 34},
+		},
+		{
+			wkt:  "MULTIPOINT(5 -7,3 -2)",
+			typ:  geomTypePoint,
+			cmds: []uint32{17, 10, 14, 3, 9},
+		},
+		{
+			wkt:  "LINESTRING(2 -2,2 -10,10 -10)",
+			typ:  geomTypeLineString,
+			cmds: []uint32{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			wkt:  "MULTILINESTRING((2 -2,2 -10,10 -10),(1 -1,3 -5))",
+			typ:  geomTypeLineString,
+			cmds: []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			wkt:  "POLYGON((3 -6,8 -12,20 -34,3 -6))",
+			typ:  geomTypePolygon,
+			cmds: []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			wkt: `MULTIPOLYGON(
+				((0 0,10 0,10 -10,0 -10,0 0)),
+				((11 -11,20 -11,20 -20,11 -20,11 -11),(13 -13,13 -17,17 -17,17 -13,13 -13))
+			)`,
+			typ: geomTypePolygon,
+			cmds: []uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			enc := geometryEncoder{
+				tr:     newTileTransform(bounds, DefaultExtent),
+				extent: DefaultExtent,
+				opts:   newMarshalOptionSet(nil),
+			}
+			got, err := enc.encode(geomFromWKT(t, tt.wkt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []encodedGeometry{{tt.typ, tt.cmds}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	for i, tt := range []struct {
+		input string
+		want  string
+	}{
+		{"POINT(1 2)", "POINT(1 2)"},
+		{"POINT(1.4 2.6)", "POINT(1 3)"},
+		{"MULTIPOINT((1 2),(3 4))", "MULTIPOINT((1 2),(3 4))"},
+		{"LINESTRING(0 0,10 0,10 10)", "LINESTRING(0 0,10 0,10 10)"},
+		{"LINESTRING(0 0,0.1 0.1,10 0)", "LINESTRING(0 0,10 0)"},
+		{"MULTILINESTRING((0 0,1 1),(2 2,3 3))", "MULTILINESTRING((0 0,1 1),(2 2,3 3))"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", "POLYGON((0 0,10 0,10 10,0 10,0 0))"},
+		{"POLYGON((0 0,0 10,10 10,10 0,0 0))", "POLYGON((0 0,0 10,10 10,10 0,0 0))"},
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2))",
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2))",
+		},
+		{
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((2 2,3 2,3 3,2 3,2 2)))",
+			"MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((2 2,3 2,3 3,2 3,2 2)))",
+		},
+
+		// Polygons and rings that collapse are omitted.
+		{"POLYGON((0 0,0.1 0,0.1 0.1,0 0))", "GEOMETRYCOLLECTION EMPTY"},
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2.1 2,2.1 2.1,2 2))",
+			"POLYGON((0 0,10 0,10 10,0 10,0 0))",
+		},
+
+		// Polygons that become invalid due to quantisation are repaired.
+		{
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 0.3,8 0.3,8 5,2 5,2 0.3))",
+			"POLYGON((0 0,2 0,2 5,8 5,8 0,10 0,10 10,0 10,0 0))",
+		},
+
+		// Geometries are clipped to the buffered tile bounds.
+		{"POINT(-300 100)", "GEOMETRYCOLLECTION EMPTY"},
+		{"POINT(-200 100)", "POINT(-200 100)"},
+		{"LINESTRING(-1000 100,100 100)", "LINESTRING(-256 100,100 100)"},
+		{
+			"POLYGON((-1000 -1000,100 -1000,100 100,-1000 100,-1000 -1000))",
+			"POLYGON((-256 -256,100 -256,100 100,-256 100,-256 -256))",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			layer := Layer{
+				Name:     "test",
+				Features: []geom.GeoJSONFeature{{Geometry: geomFromWKT(t, tt.input)}},
+			}
+			buf, err := Marshal(testBounds, []Layer{layer})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Unmarshal(buf, testBounds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].Name != "test" || got[0].Extent != DefaultExtent {
+				t.Fatalf("unexpected layers: %v", got)
+			}
+			want := geomFromWKT(t, tt.want)
+			if want.IsEmpty() {
+				if len(got[0].Features) != 0 {
+					t.Errorf("expected no features, got %v", got[0].Features)
+				}
+				return
+			}
+			if len(got[0].Features) != 1 {
+				t.Fatalf("expected 1 feature, got %d", len(got[0].Features))
+			}
+			gotG := got[0].Features[0].Geometry
+			if !gotG.EqualsExact(want, geom.IgnoreOrder) {
+				t.Errorf("\ngot:  %v\nwant: %v", gotG.AsText(), want.AsText())
+			}
+		})
+	}
+}
+
+func TestMarshalDisableClipping(t *testing.T) {
+	layer := Layer{
+		Name: "test",
+		Features: []geom.GeoJSONFeature{{
+			Geometry: geomFromWKT(t, "LINESTRING(-1000 100,100 100)"),
+		}},
+	}
+	buf, err := Marshal(testBounds, []Layer{layer}, DisableClipping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(buf, testBounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotG := got[0].Features[0].Geometry
+	want := geomFromWKT(t, "LINESTRING(-1000 100,100 100)")
+	if !gotG.EqualsExact(want, geom.IgnoreOrder) {
+		t.Errorf("\ngot:  %v\nwant: %v", gotG.AsText(), want.AsText())
+	}
+}
+
+func TestMarshalBuffer(t *testing.T) {
+	layer := Layer{
+		Name: "test",
+		Features: []geom.GeoJSONFeature{{
+			Geometry: geomFromWKT(t, "LINESTRING(-1000 100,100 100)"),
+		}},
+	}
+	buf, err := Marshal(testBounds, []Layer{layer}, Buffer(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(buf, testBounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotG := got[0].Features[0].Geometry
+	want := geomFromWKT(t, "LINESTRING(0 100,100 100)")
+	if !gotG.EqualsExact(want, geom.IgnoreOrder) {
+		t.Errorf("\ngot:  %v\nwant: %v", gotG.AsText(), want.AsText())
+	}
+}
+
+func TestMarshalPolygonWinding(t *testing.T) {
+	// Exterior rings must have a positive area and interior rings must have a
+	// negative area (in tile coordinates), regardless of the input winding.
+	for i, wkt := range []string{
+		"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2))",
+		"POLYGON((0 0,0 10,10 10,10 0,0 0),(2 2,8 2,8 8,2 8,2 2))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			enc := geometryEncoder{
+				tr:     newTileTransform(testBounds, DefaultExtent),
+				extent: DefaultExtent,
+				opts:   newMarshalOptionSet(nil),
+			}
+			got, err := enc.encode(geomFromWKT(t, wkt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts, err := parseCommands(got[0].cmds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(parts) != 2 {
+				t.Fatalf("expected 2 rings, got %d", len(parts))
+			}
+			if a := ringArea(parts[0].pts); a <= 0 {
+				t.Errorf("exterior ring has non-positive area: %d", a)
+			}
+			if a := ringArea(parts[1].pts); a >= 0 {
+				t.Errorf("interior ring has non-negative area: %d", a)
+			}
+		})
+	}
+}
+
+func TestMarshalUnmarshalProperties(t *testing.T) {
+	layer := Layer{
+		Name:   "props",
+		Extent: 512,
+		Features: []geom.GeoJSONFeature{
+			{
+				ID:       42,
+				Geometry: geomFromWKT(t, "POINT(1 2)"),
+				Properties: map[string]interface{}{
+					"str":    "hello",
+					"bool":   true,
+					"int":    -7,
+					"uint":   uint16(7),
+					"float":  float32(1.5),
+					"double": 2.25,
+					"nil":    nil,
+					"array":  []int{1, 2},
+				},
+			},
+			{
+				ID:       float64(43),
+				Geometry: geomFromWKT(t, "POINT(3 4)"),
+				Properties: map[string]interface{}{
+					"str": "hello",
+					"int": int64(8),
+				},
+			},
+			{
+				ID:       "not-an-integer",
+				Geometry: geomFromWKT(t, "POINT(5 6)"),
+			},
+		},
+	}
+	buf, err := Marshal(testBounds, []Layer{layer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(buf, testBounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "props" || got[0].Extent != 512 {
+		t.Fatalf("unexpected layers: %v", got)
+	}
+	feats := got[0].Features
+	if len(feats) != 3 {
+		t.Fatalf("expected 3 features, got %d", len(feats))
+	}
+
+	wantIDs := []interface{}{uint64(42), uint64(43), nil}
+	wantProps := []map[string]interface{}{
+		{
+			"str":    "hello",
+			"bool":   true,
+			"int":    int64(-7),
+			"uint":   uint64(7),
+			"float":  1.5,
+			"double": 2.25,
+			"array":  "[1,2]",
+		},
+		{
+			"str": "hello",
+			"int": int64(8),
+		},
+		nil,
+	}
+	for i, f := range feats {
+		if f.ID != wantIDs[i] {
+			t.Errorf("feature %d: got id=%v want=%v", i, f.ID, wantIDs[i])
+		}
+		if !reflect.DeepEqual(f.Properties, wantProps[i]) {
+			t.Errorf("feature %d:\ngot:  %v\nwant: %v", i, f.Properties, wantProps[i])
+		}
+	}
+}
+
+func TestMarshalGeometryCollectionSplitsByType(t *testing.T) {
+	layer := Layer{
+		Name: "test",
+		Features: []geom.GeoJSONFeature{{
+			ID: 1,
+			Geometry: geomFromWKT(t, `GEOMETRYCOLLECTION(
+				POINT(1 2),
+				LINESTRING(0 0,5 5),
+				POLYGON((0 0,10 0,10 10,0 10,0 0))
+			)`),
+			Properties: map[string]interface{}{"k": "v"},
+		}},
+	}
+	buf, err := Marshal(testBounds, []Layer{layer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(buf, testBounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feats := got[0].Features
+	if len(feats) != 3 {
+		t.Fatalf("expected 3 features, got %d", len(feats))
+	}
+	for i, is := range []func(geom.Geometry) bool{
+		geom.Geometry.IsPoint,
+		geom.Geometry.IsLineString,
+		geom.Geometry.IsPolygon,
+	} {
+		if !is(feats[i].Geometry) {
+			t.Errorf("feature %d: unexpected geometry: %v", i, feats[i].Geometry.AsText())
+		}
+		if feats[i].ID != uint64(1) || feats[i].Properties["k"] != "v" {
+			t.Errorf("feature %d: unexpected id or properties: %v %v", i, feats[i].ID, feats[i].Properties)
+		}
+	}
+}
+
+func TestMarshalNonUnitBounds(t *testing.T) {
+	bounds := geom.NewEnvelope(geom.XY{X: 100, Y: 200}, geom.XY{X: 200, Y: 300})
+	layer := Layer{
+		Name:   "test",
+		Extent: 100,
+		Features: []geom.GeoJSONFeature{{
+			Geometry: geomFromWKT(t, "LINESTRING(110 210,150 290)"),
+		}},
+	}
+	buf, err := Marshal(bounds, []Layer{layer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(buf, bounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotG := got[0].Features[0].Geometry
+	want := geomFromWKT(t, "LINESTRING(110 210,150 290)")
+	if !gotG.EqualsExact(want, geom.IgnoreOrder) {
+		t.Errorf("\ngot:  %v\nwant: %v", gotG.AsText(), want.AsText())
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for i, tt := range []struct {
+		name string
+		buf  []byte
+	}{
+		{"truncated", []byte{0x1a, 0x05, 0x0a}},
+		{"bad command", func() []byte {
+			var feat []byte
+			feat = appendVarintField(feat, featureType, geomTypePoint)
+			feat = appendPackedField(feat, featureGeometry, []uint32{command(3, 1), 0, 0})
+			var layer []byte
+			layer = appendVarintField(layer, layerVersion, 2)
+			layer = appendBytesField(layer, layerFeatures, feat)
+			return appendBytesField(nil, tileLayers, layer)
+		}()},
+		{"tag out of range", func() []byte {
+			var feat []byte
+			feat = appendPackedField(feat, featureTags, []uint32{0, 0})
+			feat = appendVarintField(feat, featureType, geomTypePoint)
+			feat = appendPackedField(feat, featureGeometry, []uint32{9, 0, 0})
+			var layer []byte
+			layer = appendVarintField(layer, layerVersion, 2)
+			layer = appendBytesField(layer, layerFeatures, feat)
+			return appendBytesField(nil, tileLayers, layer)
+		}()},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := Unmarshal(tt.buf, testBounds); err == nil {
+				t.Errorf("%s: expected error but got nil", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package mvt
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendVarint(dst []byte, v uint64) []byte {
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+func appendTag(dst []byte, field, wireType int) []byte {
+	return appendVarint(dst, uint64(field<<3|wireType))
+}
+
+func appendVarintField(dst []byte, field int, v uint64) []byte {
+	dst = appendTag(dst, field, wireVarint)
+	return appendVarint(dst, v)
+}
+
+func appendBytesField(dst []byte, field int, p []byte) []byte {
+	dst = appendTag(dst, field, wireBytes)
+	dst = appendVarint(dst, uint64(len(p)))
+	return append(dst, p...)
+}
+
+func appendFixed32Field(dst []byte, field int, v uint32) []byte {
+	dst = appendTag(dst, field, wireFixed32)
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+func appendFixed64Field(dst []byte, field int, v uint64) []byte {
+	dst = appendTag(dst, field, wireFixed64)
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(dst, buf[:]...)
+}
+
+// appendPackedField appends a packed repeated field of varints.
+func appendPackedField(dst []byte, field int, vs []uint32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = appendVarint(packed, uint64(v))
+	}
+	return appendBytesField(dst, field, packed)
+}
+
+func zigzagEncode(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func zigzagDecode(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+var errTruncated = errors.New("protobuf message is truncated")
+
+// pbReader reads the fields of a protocol buffer message.
+type pbReader struct {
+	buf []byte
+}
+
+// pbField is a single field read from a protocol buffer message. Only one of
+// varint (for varint, fixed32, and fixed64 wire types) and bytes (for the
+// length delimited wire type) is populated.
+type pbField struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+func (r *pbReader) done() bool {
+	return len(r.buf) == 0
+}
+
+func (r *pbReader) readVarint() (uint64, error) {
+	var v uint64
+	for i := 0; i < 10; i++ {
+		if i >= len(r.buf) {
+			return 0, errTruncated
+		}
+		b := r.buf[i]
+		v |= uint64(b&0x7f) << (7 * uint(i))
+		if b < 0x80 {
+			r.buf = r.buf[i+1:]
+			return v, nil
+		}
+	}
+	return 0, errors.New("protobuf varint is too long")
+}
+
+func (r *pbReader) next() (pbField, error) {
+	tag, err := r.readVarint()
+	if err != nil {
+		return pbField{}, err
+	}
+	f := pbField{num: int(tag >> 3), wireType: int(tag & 7)}
+	switch f.wireType {
+	case wireVarint:
+		f.varint, err = r.readVarint()
+		return f, err
+	case wireFixed64:
+		if len(r.buf) < 8 {
+			return pbField{}, errTruncated
+		}
+		f.varint = binary.LittleEndian.Uint64(r.buf)
+		r.buf = r.buf[8:]
+		return f, nil
+	case wireFixed32:
+		if len(r.buf) < 4 {
+			return pbField{}, errTruncated
+		}
+		f.varint = uint64(binary.LittleEndian.Uint32(r.buf))
+		r.buf = r.buf[4:]
+		return f, nil
+	case wireBytes:
+		n, err := r.readVarint()
+		if err != nil {
+			return pbField{}, err
+		}
+		if n > uint64(len(r.buf)) {
+			return pbField{}, errTruncated
+		}
+		f.bytes = r.buf[:n]
+		r.buf = r.buf[n:]
+		return f, nil
+	default:
+		return pbField{}, errors.New("unsupported protobuf wire type")
+	}
+}
+
+// unpackVarints reads the values of a packed repeated field of varints.
+func unpackVarints(p []byte) ([]uint32, error) {
+	r := pbReader{p}
+	var vs []uint32
+	for !r.done() {
+		v, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if v > math.MaxUint32 {
+			return nil, errors.New("packed value overflows uint32")
+		}
+		vs = append(vs, uint32(v))
+	}
+	return vs, nil
+}
@@ -0,0 +1,34 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Equirectangular is the spherical equirectangular projection, in which
+// longitude and latitude are scaled linearly. The radius of the sphere is the
+// WGS84 semi-major axis.
+//
+// Distances are true along meridians, and along the standard parallels (at
+// StandardParallel degrees north and south). The zero value has the equator
+// as its standard parallel, and is the plate carrée projection.
+type Equirectangular struct {
+	StandardParallel float64
+}
+
+// Forward converts a longitude/latitude coordinate to equirectangular.
+func (e Equirectangular) Forward(lonLat geom.XY) geom.XY {
+	return geom.XY{
+		X: wgs84A * lonLat.X * degToRad * math.Cos(e.StandardParallel*degToRad),
+		Y: wgs84A * lonLat.Y * degToRad,
+	}
+}
+
+// Inverse converts an equirectangular coordinate to longitude/latitude.
+func (e Equirectangular) Inverse(xy geom.XY) geom.XY {
+	return geom.XY{
+		X: xy.X / wgs84A * radToDeg / math.Cos(e.StandardParallel*degToRad),
+		Y: xy.Y / wgs84A * radToDeg,
+	}
+}
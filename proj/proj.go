@@ -0,0 +1,44 @@
+// Package proj implements some commonly used map projections, for converting
+// between longitude/latitude coordinates (in degrees, on the WGS84 datum, as
+// used by EPSG:4326) and projected coordinates (in metres).
+//
+// Each projection has Forward and Inverse methods that have the right
+// signature to be passed directly to geom.Geometry's TransformXY method. XY
+// values that represent longitude/latitude coordinates store the longitude in
+// X and the latitude in Y.
+//
+// A common use is to project a geometry into the UTM zone that it's located
+// in, so that lengths, areas, and buffers can be calculated in metres:
+//
+//	utm, _ := proj.UTMZoneForGeometry(g)
+//	projected, err := g.TransformXY(utm.Forward)
+package proj
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Projection converts between longitude/latitude coordinates and projected
+// coordinates.
+type Projection interface {
+	// Forward converts a longitude/latitude coordinate (in degrees) to a
+	// projected coordinate.
+	Forward(lonLat geom.XY) geom.XY
+
+	// Inverse converts a projected coordinate to a longitude/latitude
+	// coordinate (in degrees).
+	Inverse(xy geom.XY) geom.XY
+}
+
+// Parameters of the WGS84 ellipsoid.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+)
+
+const (
+	degToRad = math.Pi / 180
+	radToDeg = 180 / math.Pi
+)
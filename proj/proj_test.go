@@ -0,0 +1,179 @@
+package proj
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func expectXYWithin(t *testing.T, got, want geom.XY, tolerance float64) {
+	t.Helper()
+	if math.Abs(got.X-want.X) > tolerance || math.Abs(got.Y-want.Y) > tolerance {
+		t.Errorf("\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	const max = 20037508.342789244
+	for i, tt := range []struct {
+		lonLat, xy geom.XY
+	}{
+		{geom.XY{X: 0, Y: 0}, geom.XY{X: 0, Y: 0}},
+		{geom.XY{X: 180, Y: 0}, geom.XY{X: max, Y: 0}},
+		{geom.XY{X: -180, Y: 0}, geom.XY{X: -max, Y: 0}},
+		{geom.XY{X: 0, Y: WebMercatorMaxLatitude}, geom.XY{X: 0, Y: max}},
+		{geom.XY{X: 0, Y: -WebMercatorMaxLatitude}, geom.XY{X: 0, Y: -max}},
+		{geom.XY{X: 151.2093, Y: -33.8688}, geom.XY{X: 16832542.279, Y: -4011198.647}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var p WebMercator
+			expectXYWithin(t, p.Forward(tt.lonLat), tt.xy, 0.01)
+			expectXYWithin(t, p.Inverse(tt.xy), tt.lonLat, 1e-7)
+		})
+	}
+}
+
+func TestEquirectangular(t *testing.T) {
+	const quarter = math.Pi / 2 * wgs84A
+	for i, tt := range []struct {
+		p          Equirectangular
+		lonLat, xy geom.XY
+	}{
+		{Equirectangular{}, geom.XY{X: 0, Y: 0}, geom.XY{X: 0, Y: 0}},
+		{Equirectangular{}, geom.XY{X: 90, Y: 90}, geom.XY{X: quarter, Y: quarter}},
+		{Equirectangular{}, geom.XY{X: -180, Y: -45}, geom.XY{X: -2 * quarter, Y: -quarter / 2}},
+		{Equirectangular{StandardParallel: 60}, geom.XY{X: 90, Y: 90}, geom.XY{X: quarter / 2, Y: quarter}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectXYWithin(t, tt.p.Forward(tt.lonLat), tt.xy, 1e-6)
+			expectXYWithin(t, tt.p.Inverse(tt.xy), tt.lonLat, 1e-12)
+		})
+	}
+}
+
+func TestUTMZone(t *testing.T) {
+	for i, tt := range []struct {
+		lonLat geom.XY
+		want   UTM
+		epsg   int
+	}{
+		{geom.XY{X: -180, Y: 0}, UTM{1, false}, 32601},
+		{geom.XY{X: -177, Y: -10}, UTM{1, true}, 32701},
+		{geom.XY{X: -174, Y: 10}, UTM{2, false}, 32602},
+		{geom.XY{X: 179.9, Y: 10}, UTM{60, false}, 32660},
+		{geom.XY{X: 180, Y: 10}, UTM{60, false}, 32660},
+		{geom.XY{X: -0.1276, Y: 51.5072}, UTM{30, false}, 32630},
+		{geom.XY{X: 174.7762, Y: -41.2865}, UTM{60, true}, 32760},
+		{geom.XY{X: 183, Y: 10}, UTM{1, false}, 32601},
+
+		// Norway.
+		{geom.XY{X: 5, Y: 60}, UTM{32, false}, 32632},
+		{geom.XY{X: 5, Y: 55}, UTM{31, false}, 32631},
+		{geom.XY{X: 2, Y: 60}, UTM{31, false}, 32631},
+
+		// Svalbard.
+		{geom.XY{X: 8, Y: 78}, UTM{31, false}, 32631},
+		{geom.XY{X: 10, Y: 78}, UTM{33, false}, 32633},
+		{geom.XY{X: 25, Y: 78}, UTM{35, false}, 32635},
+		{geom.XY{X: 40, Y: 78}, UTM{37, false}, 32637},
+		{geom.XY{X: 43, Y: 78}, UTM{38, false}, 32638},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := UTMZone(tt.lonLat)
+			if got != tt.want {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+			if got.EPSG() != tt.epsg {
+				t.Errorf("got EPSG=%v want=%v", got.EPSG(), tt.epsg)
+			}
+		})
+	}
+}
+
+func TestUTMZoneForGeometry(t *testing.T) {
+	g, err := geom.UnmarshalWKT(strings.NewReader("LINESTRING(174.7 -41.3,174.9 -41.2)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := UTMZoneForGeometry(g)
+	if !ok || got != (UTM{60, true}) {
+		t.Errorf("got=%v,%v want=%v,true", got, ok, UTM{60, true})
+	}
+
+	_, ok = UTMZoneForGeometry(geom.NewEmptyPoint().AsGeometry())
+	if ok {
+		t.Error("expected false for empty geometry")
+	}
+}
+
+func TestUTM(t *testing.T) {
+	for i, tt := range []struct {
+		utm        UTM
+		lonLat, xy geom.XY
+	}{
+		{UTM{31, false}, geom.XY{X: 3, Y: 0}, geom.XY{X: 500000, Y: 0}},
+		{UTM{31, false}, geom.XY{X: 3, Y: 45}, geom.XY{X: 500000, Y: 4982950.400}},
+		{UTM{18, false}, geom.XY{X: -75, Y: 40}, geom.XY{X: 500000, Y: 4427757.219}},
+		{UTM{32, false}, geom.XY{X: 5, Y: 60}, geom.XY{X: 276979.926, Y: 6658157.202}},
+		{UTM{32, false}, geom.XY{X: 7.5, Y: 10}, geom.XY{X: 335588.969, Y: 1105786.269}},
+		{UTM{30, false}, geom.XY{X: -0.1276, Y: 51.5072}, geom.XY{X: 699330.984, Y: 5710142.067}},
+		{UTM{60, true}, geom.XY{X: 174.7762, Y: -41.2865}, geom.XY{X: 313781.070, Y: 5427052.795}},
+		{UTM{1, false}, geom.XY{X: -179, Y: 10}, geom.XY{X: 280766.861, Y: 1106077.130}},
+		{UTM{60, false}, geom.XY{X: -179, Y: 10}, geom.XY{X: 938719.288, Y: 1108075.001}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expectXYWithin(t, tt.utm.Forward(tt.lonLat), tt.xy, 0.001)
+			expectXYWithin(t, tt.utm.Inverse(tt.xy), tt.lonLat, 1e-8)
+		})
+	}
+}
+
+func TestUTMRoundTrip(t *testing.T) {
+	for zone := 1; zone <= 60; zone += 7 {
+		for _, south := range []bool{false, true} {
+			u := UTM{zone, south}
+			for dLon := -20.0; dLon <= 20; dLon += 2.5 {
+				for lat := -80.0; lat <= 84; lat += 4 {
+					lonLat := geom.XY{X: normaliseLongitude(u.CentralMeridian() + dLon), Y: lat}
+					got := u.Inverse(u.Forward(lonLat))
+					expectXYWithin(t, got, lonLat, 1e-9)
+				}
+			}
+		}
+	}
+}
+
+func TestProjectionsWithTransformXY(t *testing.T) {
+	g, err := geom.UnmarshalWKT(strings.NewReader("LINESTRING(3 45,3 46)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range []Projection{WebMercator{}, Equirectangular{}, UTM{31, false}} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			projected, err := g.TransformXY(p.Forward)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := projected.TransformXY(p.Inverse)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.EqualsExact(g, geom.Tolerance(1e-9)) {
+				t.Errorf("got=%v want=%v", got.AsText(), g.AsText())
+			}
+		})
+	}
+
+	// A degree of latitude is about 111km long (reduced slightly by the UTM
+	// scale factor along the central meridian).
+	projected, err := g.TransformXY(UTM{31, false}.Forward)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if length := projected.Length(); math.Abs(length-0.9996*111141.5) > 0.1 {
+		t.Errorf("unexpected length: %v", length)
+	}
+}
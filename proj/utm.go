@@ -0,0 +1,215 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// UTM is a Universal Transverse Mercator zone on the WGS84 datum
+// (EPSG:32601 to EPSG:32660 in the northern hemisphere, and EPSG:32701 to
+// EPSG:32760 in the southern hemisphere).
+//
+// The transverse Mercator projection is calculated using Krüger's series
+// (to 6th order in the third flattening), as described by Karney (2011). It's
+// accurate to within a few nanometres within the zone, and to within a
+// millimetre up to several thousand kilometres from the zone's central
+// meridian.
+type UTM struct {
+	// Zone is the UTM zone number, between 1 and 60 (inclusive).
+	Zone int
+
+	// South is true for the southern hemisphere variant of the zone, which
+	// has a false northing of 10,000,000 metres.
+	South bool
+}
+
+// UTMZone gives the UTM zone that a longitude/latitude coordinate is in.
+// The exceptions to the regular zone boundaries around Norway and Svalbard
+// are taken into account.
+func UTMZone(lonLat geom.XY) UTM {
+	lon, lat := normaliseLongitude(lonLat.X), lonLat.Y
+	zone := int(math.Floor((lon+180)/6)) + 1
+	if zone > 60 {
+		zone = 60
+	}
+	switch {
+	case lat >= 56 && lat < 64 && lon >= 3 && lon < 12:
+		zone = 32
+	case lat >= 72 && lat <= 84 && lon >= 0 && lon < 42:
+		switch {
+		case lon < 9:
+			zone = 31
+		case lon < 21:
+			zone = 33
+		case lon < 33:
+			zone = 35
+		default:
+			zone = 37
+		}
+	}
+	return UTM{Zone: zone, South: lat < 0}
+}
+
+// UTMZoneForGeometry gives the UTM zone that the centroid of a geometry
+// (with longitude/latitude coordinates) is in. It returns false if the
+// geometry is empty.
+func UTMZoneForGeometry(g geom.Geometry) (UTM, bool) {
+	c, ok := g.Centroid()
+	if !ok {
+		return UTM{}, false
+	}
+	return UTMZone(c.XY()), true
+}
+
+// EPSG gives the EPSG code of the zone.
+func (u UTM) EPSG() int {
+	if u.South {
+		return 32700 + u.Zone
+	}
+	return 32600 + u.Zone
+}
+
+// CentralMeridian gives the longitude (in degrees) of the zone's central
+// meridian.
+func (u UTM) CentralMeridian() float64 {
+	return float64(u.Zone*6 - 183)
+}
+
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+)
+
+func (u UTM) falseNorthing() float64 {
+	if u.South {
+		return utmFalseNorthing
+	}
+	return 0
+}
+
+// Forward converts a longitude/latitude coordinate to UTM.
+func (u UTM) Forward(lonLat geom.XY) geom.XY {
+	lon := normaliseLongitude(lonLat.X - u.CentralMeridian())
+	xi, eta := tm.forward(lon*degToRad, lonLat.Y*degToRad)
+	return geom.XY{
+		X: utmFalseEasting + utmScale*tm.rectifyingRadius*eta,
+		Y: u.falseNorthing() + utmScale*tm.rectifyingRadius*xi,
+	}
+}
+
+// Inverse converts a UTM coordinate to longitude/latitude.
+func (u UTM) Inverse(xy geom.XY) geom.XY {
+	xi := (xy.Y - u.falseNorthing()) / (utmScale * tm.rectifyingRadius)
+	eta := (xy.X - utmFalseEasting) / (utmScale * tm.rectifyingRadius)
+	lon, lat := tm.inverse(xi, eta)
+	return geom.XY{
+		X: normaliseLongitude(lon*radToDeg + u.CentralMeridian()),
+		Y: lat * radToDeg,
+	}
+}
+
+// normaliseLongitude wraps a longitude (in degrees) into the range [-180,
+// 180].
+func normaliseLongitude(lon float64) float64 {
+	if lon >= -180 && lon <= 180 {
+		return lon
+	}
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// transverseMercator holds the coefficients of the Krüger series for an
+// ellipsoid. Coordinates are in radians, and projected coordinates are
+// normalised (they need to be multiplied by the rectifying radius and the
+// scale factor to give metres).
+type transverseMercator struct {
+	e                float64 // eccentricity
+	rectifyingRadius float64
+	alpha, beta      [6]float64
+}
+
+var tm = newTransverseMercator(wgs84F, wgs84A)
+
+func newTransverseMercator(f, a float64) transverseMercator {
+	n := f / (2 - f)
+	n2 := n * n
+	n3 := n2 * n
+	n4 := n3 * n
+	n5 := n4 * n
+	n6 := n5 * n
+	return transverseMercator{
+		e:                math.Sqrt(f * (2 - f)),
+		rectifyingRadius: a / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		alpha: [6]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [6]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+	}
+}
+
+// forward projects a longitude (relative to the central meridian) and
+// latitude to normalised northing (xi) and easting (eta) values.
+func (t transverseMercator) forward(lon, lat float64) (xi, eta float64) {
+	// Tangent of the conformal latitude.
+	sinLat := math.Sin(lat)
+	tau := math.Sinh(math.Atanh(sinLat) - t.e*math.Atanh(t.e*sinLat))
+
+	xiPrime := math.Atan2(tau, math.Cos(lon))
+	etaPrime := math.Asinh(math.Sin(lon) / math.Hypot(tau, math.Cos(lon)))
+
+	xi, eta = xiPrime, etaPrime
+	for j, a := range t.alpha {
+		k := 2 * float64(j+1)
+		xi += a * math.Sin(k*xiPrime) * math.Cosh(k*etaPrime)
+		eta += a * math.Cos(k*xiPrime) * math.Sinh(k*etaPrime)
+	}
+	return xi, eta
+}
+
+// inverse is the inverse of forward.
+func (t transverseMercator) inverse(xi, eta float64) (lon, lat float64) {
+	xiPrime, etaPrime := xi, eta
+	for j, b := range t.beta {
+		k := 2 * float64(j+1)
+		xiPrime -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	// Tangent of the conformal latitude.
+	tauPrime := math.Sin(xiPrime) / math.Hypot(math.Sinh(etaPrime), math.Cos(xiPrime))
+	lon = math.Atan2(math.Sinh(etaPrime), math.Cos(xiPrime))
+
+	// Find the tangent of the latitude using Newton's method (see Karney
+	// 2011, equations 7 to 9).
+	e2 := t.e * t.e
+	tau := tauPrime
+	for i := 0; i < 10; i++ {
+		sqrt1PlusTau2 := math.Hypot(1, tau)
+		sigma := math.Sinh(t.e * math.Atanh(t.e*tau/sqrt1PlusTau2))
+		tauI := tau*math.Hypot(1, sigma) - sigma*sqrt1PlusTau2
+		delta := (tauPrime - tauI) / math.Hypot(1, tauI) *
+			(1 + (1-e2)*tau*tau) / ((1 - e2) * sqrt1PlusTau2)
+		tau += delta
+		if math.Abs(delta) <= 1e-14*math.Max(1, math.Abs(tau)) {
+			break
+		}
+	}
+	return lon, math.Atan(tau)
+}
@@ -0,0 +1,36 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// WebMercatorMaxLatitude is the latitude (in degrees) at which the Web
+// Mercator projection maps the world to a square.
+const WebMercatorMaxLatitude = 85.051128779806604
+
+// WebMercator is the spherical Mercator projection used by most web mapping
+// applications (EPSG:3857). The projection treats WGS84 coordinates as if
+// they were on a sphere, so it isn't conformal on the ellipsoid.
+//
+// Latitudes further from the equator than WebMercatorMaxLatitude are
+// projected outside of the usual square extent, and the poles are projected
+// to infinity.
+type WebMercator struct{}
+
+// Forward converts a longitude/latitude coordinate to Web Mercator.
+func (WebMercator) Forward(lonLat geom.XY) geom.XY {
+	return geom.XY{
+		X: wgs84A * lonLat.X * degToRad,
+		Y: wgs84A * math.Log(math.Tan(math.Pi/4+lonLat.Y*degToRad/2)),
+	}
+}
+
+// Inverse converts a Web Mercator coordinate to longitude/latitude.
+func (WebMercator) Inverse(xy geom.XY) geom.XY {
+	return geom.XY{
+		X: xy.X / wgs84A * radToDeg,
+		Y: (2*math.Atan(math.Exp(xy.Y/wgs84A)) - math.Pi/2) * radToDeg,
+	}
+}
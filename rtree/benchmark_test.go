@@ -0,0 +1,64 @@
+package rtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func BenchmarkBulkLoad(b *testing.B) {
+	for _, sz := range []int{100, 10000, 100000} {
+		b.Run(fmt.Sprintf("n=%d", sz), func(b *testing.B) {
+			items := randomItems(rand.New(rand.NewSource(0)), sz)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				BulkLoad(items)
+			}
+		})
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	for _, sz := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("n=%d", sz), func(b *testing.B) {
+			items := randomItems(rand.New(rand.NewSource(0)), sz)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var tr RTree
+				for _, item := range items {
+					tr.Insert(item.Box, item.RecordID)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRangeSearch(b *testing.B) {
+	for _, sz := range []int{100, 10000, 100000} {
+		b.Run(fmt.Sprintf("n=%d", sz), func(b *testing.B) {
+			rnd := rand.New(rand.NewSource(0))
+			tr := BulkLoad(randomItems(rnd, sz))
+			box := randomBox(rnd)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := tr.RangeSearch(box, func(int) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNearest(b *testing.B) {
+	for _, sz := range []int{100, 10000, 100000} {
+		b.Run(fmt.Sprintf("n=%d", sz), func(b *testing.B) {
+			rnd := rand.New(rand.NewSource(0))
+			tr := BulkLoad(randomItems(rnd, sz))
+			box := randomBox(rnd)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tr.Nearest(box)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package rtree
+
+import "github.com/peterstace/simplefeatures/geom"
+
+// Delete removes a single record with the given box and record ID from the
+// tree. It returns false if no matching record could be found.
+func (t *RTree) Delete(box geom.Envelope, recordID int) bool {
+	if t.root == nil {
+		return false
+	}
+	leaf, idx := t.findRecord(t.root, box, recordID)
+	if leaf == nil {
+		return false
+	}
+	leaf.entries = append(leaf.entries[:idx], leaf.entries[idx+1:]...)
+	t.count--
+	t.condense(leaf)
+	return true
+}
+
+// findRecord finds the leaf node (and index within that node) of the record
+// with the given box and record ID.
+func (t *RTree) findRecord(n *node, box geom.Envelope, recordID int) (*node, int) {
+	for i, e := range n.entries {
+		if !e.box.Covers(box) {
+			continue
+		}
+		if n.isLeaf {
+			if e.recordID == recordID && e.box == box {
+				return n, i
+			}
+			continue
+		}
+		if leaf, idx := t.findRecord(e.child, box, recordID); leaf != nil {
+			return leaf, idx
+		}
+	}
+	return nil, 0
+}
+
+// condense removes any underfull nodes between a leaf (that just had a
+// record removed from it) and the root. Records that were under removed nodes
+// are re-inserted into the tree.
+func (t *RTree) condense(n *node) {
+	var orphans []entry
+	for n.parent != nil {
+		parent := n.parent
+		if len(n.entries) < minChildren {
+			idx := parent.indexOf(n)
+			parent.entries = append(parent.entries[:idx], parent.entries[idx+1:]...)
+			orphans = n.appendRecords(orphans)
+		} else {
+			parent.entries[parent.indexOf(n)].box = n.box()
+		}
+		n = parent
+	}
+
+	// The root is shortened if it only has a single child.
+	for !t.root.isLeaf && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+		t.root.parent = nil
+	}
+	if len(t.root.entries) == 0 {
+		t.root = nil
+	}
+
+	for _, e := range orphans {
+		t.count--
+		t.Insert(e.box, e.recordID)
+	}
+}
+
+// appendRecords appends all records in the subtree rooted at the node.
+func (n *node) appendRecords(records []entry) []entry {
+	if n.isLeaf {
+		return append(records, n.entries...)
+	}
+	for _, e := range n.entries {
+		records = e.child.appendRecords(records)
+	}
+	return records
+}
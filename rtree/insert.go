@@ -0,0 +1,162 @@
+package rtree
+
+import (
+	"math"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Insert adds a new record to the tree.
+func (t *RTree) Insert(box geom.Envelope, recordID int) {
+	t.count++
+	if t.root == nil {
+		t.root = &node{isLeaf: true}
+	}
+	leaf := t.chooseLeaf(box)
+	t.addEntry(leaf, entry{box: box, recordID: recordID})
+}
+
+// chooseLeaf finds the leaf node that a new record with the given box
+// should be added to. At each level, the child needing the least enlargement
+// to include the box is chosen (with ties broken by the smallest area).
+func (t *RTree) chooseLeaf(box geom.Envelope) *node {
+	n := t.root
+	for !n.isLeaf {
+		best := -1
+		var bestEnlargement, bestArea float64
+		for i, e := range n.entries {
+			area := e.box.Area()
+			enlargement := e.box.ExpandToIncludeEnvelope(box).Area() - area
+			if best == -1 ||
+				enlargement < bestEnlargement ||
+				(enlargement == bestEnlargement && area < bestArea) {
+				best, bestEnlargement, bestArea = i, enlargement, area
+			}
+		}
+		n = n.entries[best].child
+	}
+	return n
+}
+
+// addEntry adds an entry to a node, splitting the node (and its ancestors) if
+// it becomes overfull. The boxes of the node's ancestors are adjusted to
+// include the new entry.
+func (t *RTree) addEntry(n *node, e entry) {
+	if e.child != nil {
+		e.child.parent = n
+	}
+	n.entries = append(n.entries, e)
+	if len(n.entries) <= maxChildren {
+		t.adjustBoxesUpwards(n)
+		return
+	}
+
+	sibling := n.split()
+	if n.parent == nil {
+		t.root = &node{entries: []entry{
+			{box: n.box(), child: n},
+			{box: sibling.box(), child: sibling},
+		}}
+		n.parent = t.root
+		sibling.parent = t.root
+		return
+	}
+	n.parent.entries[n.parent.indexOf(n)].box = n.box()
+	t.addEntry(n.parent, entry{box: sibling.box(), child: sibling})
+}
+
+// adjustBoxesUpwards recalculates the boxes of the entries pointing to a node
+// and all of its ancestors.
+func (t *RTree) adjustBoxesUpwards(n *node) {
+	for n.parent != nil {
+		n.parent.entries[n.parent.indexOf(n)].box = n.box()
+		n = n.parent
+	}
+}
+
+func (n *node) indexOf(child *node) int {
+	for i, e := range n.entries {
+		if e.child == child {
+			return i
+		}
+	}
+	panic("child not found in parent")
+}
+
+// split splits an overfull node into two using Guttman's quadratic split
+// algorithm. The node retains some of its entries, and the remainder are
+// moved into a new sibling node (which is returned).
+func (n *node) split() *node {
+	entries := n.entries
+
+	// Pick the pair of entries that would waste the most area if they were
+	// put in the same node as the seeds of the two groups.
+	seedA, seedB := 0, 1
+	worst := math.Inf(-1)
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			combined := entries[i].box.ExpandToIncludeEnvelope(entries[j].box)
+			waste := combined.Area() - entries[i].box.Area() - entries[j].box.Area()
+			if waste > worst {
+				seedA, seedB, worst = i, j, waste
+			}
+		}
+	}
+
+	groupA := []entry{entries[seedA]}
+	groupB := []entry{entries[seedB]}
+	boxA, boxB := entries[seedA].box, entries[seedB].box
+	var remaining []entry
+	for i, e := range entries {
+		if i != seedA && i != seedB {
+			remaining = append(remaining, e)
+		}
+	}
+
+	for len(remaining) > 0 {
+		// If one group needs all of the remaining entries to reach the
+		// minimum size, then give them all to it.
+		if len(groupA)+len(remaining) <= minChildren {
+			groupA = append(groupA, remaining...)
+			break
+		}
+		if len(groupB)+len(remaining) <= minChildren {
+			groupB = append(groupB, remaining...)
+			break
+		}
+
+		// Pick the entry that has the greatest preference for one group
+		// over the other.
+		next := 0
+		var nextDiff, nextCostA, nextCostB float64
+		for i, e := range remaining {
+			costA := boxA.ExpandToIncludeEnvelope(e.box).Area() - boxA.Area()
+			costB := boxB.ExpandToIncludeEnvelope(e.box).Area() - boxB.Area()
+			if diff := math.Abs(costA - costB); i == 0 || diff > nextDiff {
+				next, nextDiff, nextCostA, nextCostB = i, diff, costA, costB
+			}
+		}
+		e := remaining[next]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+
+		toA := nextCostA < nextCostB ||
+			(nextCostA == nextCostB && boxA.Area() < boxB.Area()) ||
+			(nextCostA == nextCostB && boxA.Area() == boxB.Area() && len(groupA) <= len(groupB))
+		if toA {
+			groupA = append(groupA, e)
+			boxA = boxA.ExpandToIncludeEnvelope(e.box)
+		} else {
+			groupB = append(groupB, e)
+			boxB = boxB.ExpandToIncludeEnvelope(e.box)
+		}
+	}
+
+	n.entries = groupA
+	sibling := &node{entries: groupB, isLeaf: n.isLeaf}
+	for _, e := range sibling.entries {
+		if e.child != nil {
+			e.child.parent = sibling
+		}
+	}
+	return sibling
+}
@@ -0,0 +1,156 @@
+// Package rtree implements an in-memory R-Tree spatial index, keyed on
+// geom.Envelope values.
+//
+// Trees can either be bulk loaded (using the Sort-Tile-Recursive algorithm)
+// or built incrementally using Insert. Bulk loading is much faster and
+// results in a tree that gives better query performance, so should be
+// preferred when all records are known up front.
+package rtree
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+const (
+	minChildren = 6
+	maxChildren = 16
+)
+
+// Stop is a special error value that can be returned from a callback to stop
+// a search early. When it's returned, the search method returns nil (rather
+// than the Stop error).
+var Stop = errors.New("stop")
+
+// RTree is an in-memory R-Tree data structure. Each record in the tree is
+// identified by an integer record ID, and is indexed by a rectangular box.
+// The zero value is an empty tree that is ready to use.
+type RTree struct {
+	root  *node
+	count int
+}
+
+type node struct {
+	entries []entry
+	isLeaf  bool
+	parent  *node
+}
+
+// entry is either a record (in a leaf node) or a child node (in a non-leaf
+// node).
+type entry struct {
+	box      geom.Envelope
+	child    *node
+	recordID int
+}
+
+// BulkItem is a single record that is loaded into an RTree using BulkLoad.
+type BulkItem struct {
+	Box      geom.Envelope
+	RecordID int
+}
+
+// BulkLoad creates a new RTree containing the supplied items. The items are
+// packed into nodes using the Sort-Tile-Recursive (STR) algorithm.
+func BulkLoad(items []BulkItem) *RTree {
+	if len(items) == 0 {
+		return &RTree{}
+	}
+	entries := make([]entry, len(items))
+	for i, item := range items {
+		entries[i] = entry{box: item.Box, recordID: item.RecordID}
+	}
+	isLeaf := true
+	for {
+		nodes := packSTR(entries, isLeaf)
+		if len(nodes) == 1 {
+			return &RTree{root: nodes[0], count: len(items)}
+		}
+		entries = make([]entry, len(nodes))
+		for i, n := range nodes {
+			entries[i] = entry{box: n.box(), child: n}
+		}
+		isLeaf = false
+	}
+}
+
+// packSTR packs a level of entries into nodes. The entries are sorted into
+// vertical slices by the X coordinate of their centers, and then each slice
+// is sorted by the Y coordinate of their centers and packed into nodes.
+func packSTR(entries []entry, isLeaf bool) []*node {
+	numNodes := (len(entries) + maxChildren - 1) / maxChildren
+	numSlices := int(math.Ceil(math.Sqrt(float64(numNodes))))
+	sliceSize := numSlices * maxChildren
+
+	sortEntries(entries, func(e entry) float64 { return e.box.Center().X })
+	var nodes []*node
+	for start := 0; start < len(entries); start += sliceSize {
+		slice := entries[start:minInt(start+sliceSize, len(entries))]
+		sortEntries(slice, func(e entry) float64 { return e.box.Center().Y })
+		for _, group := range splitEvenly(slice, maxChildren) {
+			n := &node{isLeaf: isLeaf}
+			n.entries = append(n.entries, group...)
+			for _, e := range n.entries {
+				if e.child != nil {
+					e.child.parent = n
+				}
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// splitEvenly splits entries into the minimum number of groups such that
+// each group has at most max entries. The groups have sizes that are as close
+// to each other as possible (so that no group is left with too few entries).
+func splitEvenly(entries []entry, max int) [][]entry {
+	numGroups := (len(entries) + max - 1) / max
+	var groups [][]entry
+	for i := 0; i < numGroups; i++ {
+		start := i * len(entries) / numGroups
+		end := (i + 1) * len(entries) / numGroups
+		groups = append(groups, entries[start:end])
+	}
+	return groups
+}
+
+// Count gives the number of records in the tree.
+func (t *RTree) Count() int {
+	return t.count
+}
+
+// Extent gives the smallest box that contains all records in the tree. It
+// returns false if the tree is empty.
+func (t *RTree) Extent() (geom.Envelope, bool) {
+	if t.root == nil || len(t.root.entries) == 0 {
+		return geom.Envelope{}, false
+	}
+	return t.root.box(), true
+}
+
+// box calculates the smallest box containing all of the node's entries.
+func (n *node) box() geom.Envelope {
+	box := n.entries[0].box
+	for _, e := range n.entries[1:] {
+		box = box.ExpandToIncludeEnvelope(e.box)
+	}
+	return box
+}
+
+// sortEntries sorts entries in ascending order of a key.
+func sortEntries(entries []entry, key func(entry) float64) {
+	sort.Slice(entries, func(i, j int) bool {
+		return key(entries[i]) < key(entries[j])
+	})
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
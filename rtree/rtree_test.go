@@ -0,0 +1,306 @@
+package rtree
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func randomBox(rnd *rand.Rand) geom.Envelope {
+	x, y := rnd.Float64()*100, rnd.Float64()*100
+	return geom.NewEnvelope(geom.XY{X: x, Y: y}, geom.XY{X: x + rnd.Float64()*5, Y: y + rnd.Float64()*5})
+}
+
+func randomItems(rnd *rand.Rand, n int) []BulkItem {
+	items := make([]BulkItem, n)
+	for i := range items {
+		items[i] = BulkItem{Box: randomBox(rnd), RecordID: i}
+	}
+	return items
+}
+
+// checkInvariants checks that the tree is balanced, that each node's box
+// covers its children, that parent pointers are consistent, and that the
+// tree has the expected records.
+func checkInvariants(t *testing.T, tr *RTree, want []BulkItem) {
+	t.Helper()
+	if tr.Count() != len(want) {
+		t.Fatalf("count: got %d want %d", tr.Count(), len(want))
+	}
+	if tr.root == nil {
+		if len(want) != 0 {
+			t.Fatal("unexpected nil root")
+		}
+		return
+	}
+	if tr.root.parent != nil {
+		t.Fatal("root has parent")
+	}
+
+	leafDepth := -1
+	var got []BulkItem
+	var check func(n *node, depth int)
+	check = func(n *node, depth int) {
+		if len(n.entries) > maxChildren {
+			t.Fatalf("node has %d entries", len(n.entries))
+		}
+		if n.isLeaf {
+			if leafDepth == -1 {
+				leafDepth = depth
+			}
+			if depth != leafDepth {
+				t.Fatalf("unbalanced: leaves at depth %d and %d", leafDepth, depth)
+			}
+			for _, e := range n.entries {
+				got = append(got, BulkItem{e.box, e.recordID})
+			}
+			return
+		}
+		for _, e := range n.entries {
+			if e.child.parent != n {
+				t.Fatal("inconsistent parent pointer")
+			}
+			if e.box != e.child.box() {
+				t.Fatalf("entry box %v doesn't match child box %v", e.box, e.child.box())
+			}
+			check(e.child, depth+1)
+		}
+	}
+	check(tr.root, 0)
+
+	sortItems := func(items []BulkItem) {
+		sort.Slice(items, func(i, j int) bool { return items[i].RecordID < items[j].RecordID })
+	}
+	sortItems(got)
+	want = append([]BulkItem(nil), want...)
+	sortItems(want)
+	if len(got) != len(want) {
+		t.Fatalf("got %d records but want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("record mismatch: got %v want %v", got[i], want[i])
+		}
+	}
+}
+
+func checkSearch(t *testing.T, tr *RTree, items []BulkItem, rnd *rand.Rand) {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		box := randomBox(rnd)
+		var got []int
+		err := tr.RangeSearch(box, func(id int) error {
+			got = append(got, id)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []int
+		for _, item := range items {
+			if item.Box.Intersects(box) {
+				want = append(want, item.RecordID)
+			}
+		}
+		sort.Ints(got)
+		sort.Ints(want)
+		if len(got) != len(want) {
+			t.Fatalf("range search: got %v want %v", got, want)
+		}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Fatalf("range search: got %v want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestBulkLoad(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 15, 16, 17, 100, 256, 257, 1000, 5000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(n)))
+			items := randomItems(rnd, n)
+			tr := BulkLoad(items)
+			checkInvariants(t, tr, items)
+			checkSearch(t, tr, items, rnd)
+		})
+	}
+}
+
+func TestInsertAndDelete(t *testing.T) {
+	for _, n := range []int{1, 10, 100, 1000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(n)))
+			items := randomItems(rnd, n)
+			var tr RTree
+			for i, item := range items {
+				tr.Insert(item.Box, item.RecordID)
+				if i%50 == 0 {
+					checkInvariants(t, &tr, items[:i+1])
+				}
+			}
+			checkInvariants(t, &tr, items)
+			checkSearch(t, &tr, items, rnd)
+
+			rnd.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+			for len(items) > 0 {
+				if !tr.Delete(items[0].Box, items[0].RecordID) {
+					t.Fatalf("could not delete %v", items[0])
+				}
+				items = items[1:]
+				if len(items)%50 == 0 {
+					checkInvariants(t, &tr, items)
+					checkSearch(t, &tr, items, rnd)
+				}
+			}
+			checkInvariants(t, &tr, nil)
+		})
+	}
+}
+
+func TestDeleteFromBulkLoaded(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	items := randomItems(rnd, 500)
+	tr := BulkLoad(items)
+	for i := 0; i < 300; i++ {
+		if !tr.Delete(items[0].Box, items[0].RecordID) {
+			t.Fatalf("could not delete %v", items[0])
+		}
+		items = items[1:]
+	}
+	checkInvariants(t, tr, items)
+	checkSearch(t, tr, items, rnd)
+}
+
+func TestDeleteMissing(t *testing.T) {
+	box := geom.NewEnvelope(geom.XY{X: 0, Y: 0}, geom.XY{X: 1, Y: 1})
+	var tr RTree
+	if tr.Delete(box, 1) {
+		t.Error("deleted from empty tree")
+	}
+	tr.Insert(box, 1)
+	if tr.Delete(box, 2) {
+		t.Error("deleted record with wrong ID")
+	}
+	if tr.Delete(geom.NewEnvelope(geom.XY{X: 0, Y: 0}), 1) {
+		t.Error("deleted record with wrong box")
+	}
+	if !tr.Delete(box, 1) {
+		t.Error("could not delete record")
+	}
+	if tr.Count() != 0 {
+		t.Errorf("unexpected count: %d", tr.Count())
+	}
+}
+
+func TestPrioritySearch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	items := randomItems(rnd, 1000)
+	tr := BulkLoad(items)
+	for i := 0; i < 10; i++ {
+		box := randomBox(rnd)
+		var got []int
+		err := tr.PrioritySearch(box, func(id int) error {
+			got = append(got, id)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(items) {
+			t.Fatalf("got %d records but want %d", len(got), len(items))
+		}
+		prev := 0.0
+		for _, id := range got {
+			d := items[id].Box.Distance(box)
+			if d < prev {
+				t.Fatalf("records out of order: %v after %v", d, prev)
+			}
+			prev = d
+		}
+
+		nearest, ok := tr.Nearest(box)
+		if !ok || items[nearest].Box.Distance(box) != items[got[0]].Box.Distance(box) {
+			t.Errorf("unexpected nearest: %v %v", nearest, ok)
+		}
+		nearestK := tr.NearestK(box, 5)
+		if len(nearestK) != 5 {
+			t.Fatalf("unexpected nearest k: %v", nearestK)
+		}
+		for j, id := range nearestK {
+			if items[id].Box.Distance(box) != items[got[j]].Box.Distance(box) {
+				t.Errorf("unexpected nearest k: %v", nearestK)
+			}
+		}
+	}
+}
+
+func TestEmptyTree(t *testing.T) {
+	var tr RTree
+	box := geom.NewEnvelope(geom.XY{X: 0, Y: 0})
+	if _, ok := tr.Extent(); ok {
+		t.Error("expected no extent")
+	}
+	if _, ok := tr.Nearest(box); ok {
+		t.Error("expected no nearest")
+	}
+	if got := tr.NearestK(box, 3); len(got) != 0 {
+		t.Errorf("unexpected nearest k: %v", got)
+	}
+	err := tr.RangeSearch(box, func(int) error {
+		t.Error("unexpected callback")
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExtent(t *testing.T) {
+	tr := BulkLoad([]BulkItem{
+		{geom.NewEnvelope(geom.XY{X: 0, Y: 1}, geom.XY{X: 2, Y: 3}), 0},
+		{geom.NewEnvelope(geom.XY{X: -1, Y: 5}), 1},
+	})
+	got, ok := tr.Extent()
+	want := geom.NewEnvelope(geom.XY{X: -1, Y: 1}, geom.XY{X: 2, Y: 5})
+	if !ok || got != want {
+		t.Errorf("got %v %v want %v", got, ok, want)
+	}
+}
+
+func TestSearchStopAndError(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	tr := BulkLoad(randomItems(rnd, 100))
+	everything := geom.NewEnvelope(geom.XY{X: math.Inf(-1), Y: math.Inf(-1)}, geom.XY{X: math.Inf(1), Y: math.Inf(1)})
+
+	for _, search := range []func(geom.Envelope, func(int) error) error{
+		tr.RangeSearch, tr.PrioritySearch,
+	} {
+		var count int
+		err := search(everything, func(int) error {
+			count++
+			if count == 3 {
+				return Stop
+			}
+			return nil
+		})
+		if err != nil || count != 3 {
+			t.Errorf("stop: err=%v count=%d", err, count)
+		}
+
+		userErr := errors.New("user error")
+		count = 0
+		err = search(everything, func(int) error {
+			count++
+			return userErr
+		})
+		if err != userErr || count != 1 {
+			t.Errorf("error: err=%v count=%d", err, count)
+		}
+	}
+}
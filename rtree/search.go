@@ -0,0 +1,125 @@
+package rtree
+
+import (
+	"container/heap"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// RangeSearch looks for any records in the tree whose box intersects with
+// the given box. The callback is called with the record ID of each match
+// (in no particular order). The search is stopped early if the callback
+// returns an error. The error is returned from RangeSearch, unless it's the
+// Stop error (in which case nil is returned).
+func (t *RTree) RangeSearch(box geom.Envelope, callback func(recordID int) error) error {
+	if t.root == nil {
+		return nil
+	}
+	err := rangeSearch(t.root, box, callback)
+	if err == Stop {
+		return nil
+	}
+	return err
+}
+
+func rangeSearch(n *node, box geom.Envelope, callback func(recordID int) error) error {
+	for _, e := range n.entries {
+		if !e.box.Intersects(box) {
+			continue
+		}
+		var err error
+		if n.isLeaf {
+			err = callback(e.recordID)
+		} else {
+			err = rangeSearch(e.child, box, callback)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrioritySearch iterates over the records in the tree in order of
+// increasing distance from the given box (calculated using
+// Envelope.Distance). The callback is called with the record ID of each
+// record. Records that are the same distance from the box are given in no
+// particular order. Iteration is stopped early if the callback returns an
+// error, which allows the k-nearest neighbours to be found efficiently. The
+// error is returned from PrioritySearch, unless it's the Stop error (in which
+// case nil is returned).
+func (t *RTree) PrioritySearch(box geom.Envelope, callback func(recordID int) error) error {
+	if t.root == nil {
+		return nil
+	}
+	queue := &entryQueue{}
+	for _, e := range t.root.entries {
+		heap.Push(queue, queueItem{e, t.root.isLeaf, e.box.Distance(box)})
+	}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(queueItem)
+		if item.isRecord {
+			if err := callback(item.entry.recordID); err != nil {
+				if err == Stop {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+		child := item.entry.child
+		for _, e := range child.entries {
+			heap.Push(queue, queueItem{e, child.isLeaf, e.box.Distance(box)})
+		}
+	}
+	return nil
+}
+
+// Nearest finds the record in the tree that is closest to the given box. It
+// returns false if the tree is empty.
+func (t *RTree) Nearest(box geom.Envelope) (recordID int, found bool) {
+	_ = t.PrioritySearch(box, func(id int) error {
+		recordID, found = id, true
+		return Stop
+	})
+	return recordID, found
+}
+
+// NearestK finds the k records in the tree that are closest to the given box,
+// ordered by increasing distance. Fewer than k records are returned if the
+// tree has less than k records.
+func (t *RTree) NearestK(box geom.Envelope, k int) []int {
+	var recordIDs []int
+	if k <= 0 {
+		return nil
+	}
+	_ = t.PrioritySearch(box, func(id int) error {
+		recordIDs = append(recordIDs, id)
+		if len(recordIDs) == k {
+			return Stop
+		}
+		return nil
+	})
+	return recordIDs
+}
+
+type queueItem struct {
+	entry    entry
+	isRecord bool
+	dist     float64
+}
+
+// entryQueue is a min heap of entries, ordered by their distance to the
+// search box.
+type entryQueue []queueItem
+
+func (q entryQueue) Len() int            { return len(q) }
+func (q entryQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q entryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *entryQueue) Push(x interface{}) { *q = append(*q, x.(queueItem)) }
+func (q *entryQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
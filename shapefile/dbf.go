@@ -0,0 +1,332 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of an attribute field in the dBase file.
+type FieldType byte
+
+// The field types supported by this package.
+const (
+	// FieldCharacter fields hold strings.
+	FieldCharacter FieldType = 'C'
+
+	// FieldNumeric fields hold numbers. When read, values are int64 if the
+	// field has no decimal places, and float64 otherwise.
+	FieldNumeric FieldType = 'N'
+
+	// FieldFloat fields hold numbers. When read, values are float64.
+	FieldFloat FieldType = 'F'
+
+	// FieldLogical fields hold bool values.
+	FieldLogical FieldType = 'L'
+
+	// FieldDate fields hold dates (without times). When read, values are
+	// time.Time values (in UTC).
+	FieldDate FieldType = 'D'
+)
+
+// Field describes an attribute field in the dBase file. Each field
+// corresponds to a property in a GeoJSONFeature.
+type Field struct {
+	// Name is the name of the field (and of the GeoJSONFeature property). It
+	// can be at most 10 bytes long.
+	Name string
+
+	// Type is the type of the field.
+	Type FieldType
+
+	// Length is the width of the field in bytes. For FieldLogical and
+	// FieldDate fields, it may be left as zero when writing.
+	Length int
+
+	// Decimals is the number of decimal places for FieldNumeric and
+	// FieldFloat fields.
+	Decimals int
+}
+
+func (f *Field) validate() error {
+	if f.Name == "" || len(f.Name) > 10 {
+		return fmt.Errorf("field name must be between 1 and 10 bytes long: %q", f.Name)
+	}
+	switch f.Type {
+	case FieldCharacter:
+		if f.Length < 1 || f.Length > 254 {
+			return fmt.Errorf("field %q: character fields must have a length between 1 and 254", f.Name)
+		}
+	case FieldNumeric, FieldFloat:
+		if f.Length < 1 || f.Length > 20 {
+			return fmt.Errorf("field %q: numeric fields must have a length between 1 and 20", f.Name)
+		}
+		if f.Decimals < 0 || (f.Decimals > 0 && f.Decimals > f.Length-2) {
+			return fmt.Errorf("field %q: too many decimal places for field length", f.Name)
+		}
+	case FieldLogical:
+		f.Length = 1
+	case FieldDate:
+		f.Length = 8
+	default:
+		return fmt.Errorf("field %q: unsupported field type: %q", f.Name, byte(f.Type))
+	}
+	if f.Type != FieldNumeric && f.Type != FieldFloat {
+		f.Decimals = 0
+	}
+	return nil
+}
+
+const (
+	dbfVersion       = 0x03
+	dbfHeaderSize    = 32
+	dbfFieldSize     = 32
+	dbfHeaderEnd     = 0x0d
+	dbfFileEnd       = 0x1a
+	dbfDeleted       = '*'
+	dbfNotDeleted    = ' '
+	dbfDateFormat    = "20060102"
+	dbfMaxFieldCount = 255
+)
+
+// dbfReader reads the records of a dBase file.
+type dbfReader struct {
+	r          io.Reader
+	fields     []Field
+	numRecords int
+	recordLen  int
+	read       int
+	buf        []byte
+}
+
+func newDBFReader(r io.Reader) (*dbfReader, error) {
+	hdr := make([]byte, dbfHeaderSize)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("reading dbf header: %v", err)
+	}
+	d := &dbfReader{
+		r:          r,
+		numRecords: int(binary.LittleEndian.Uint32(hdr[4:])),
+		recordLen:  int(binary.LittleEndian.Uint16(hdr[10:])),
+	}
+	headerLen := int(binary.LittleEndian.Uint16(hdr[8:]))
+	if headerLen < dbfHeaderSize+1 {
+		return nil, errors.New("invalid dbf header length")
+	}
+	rest := make([]byte, headerLen-dbfHeaderSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("reading dbf header: %v", err)
+	}
+
+	offset := 1 // the deletion flag comes before the fields
+	for len(rest) >= dbfFieldSize && rest[0] != dbfHeaderEnd {
+		desc := rest[:dbfFieldSize]
+		rest = rest[dbfFieldSize:]
+		name := desc[:11]
+		if i := bytes.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		f := Field{
+			Name:     strings.TrimSpace(string(name)),
+			Type:     FieldType(desc[11]),
+			Length:   int(desc[16]),
+			Decimals: int(desc[17]),
+		}
+		offset += f.Length
+		d.fields = append(d.fields, f)
+	}
+	if offset > d.recordLen {
+		return nil, errors.New("dbf fields are longer than the record length")
+	}
+	d.buf = make([]byte, d.recordLen)
+	return d, nil
+}
+
+// next reads the next record. It returns io.EOF once all records have been
+// read.
+func (d *dbfReader) next() (props map[string]interface{}, deleted bool, err error) {
+	if d.read >= d.numRecords {
+		return nil, false, io.EOF
+	}
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return nil, false, fmt.Errorf("reading dbf record: %v", err)
+	}
+	d.read++
+
+	props = make(map[string]interface{}, len(d.fields))
+	rec := d.buf[1:]
+	for _, f := range d.fields {
+		raw := rec[:f.Length]
+		rec = rec[f.Length:]
+		v, err := decodeDBFValue(f, raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("field %q: %v", f.Name, err)
+		}
+		props[f.Name] = v
+	}
+	return props, d.buf[0] == dbfDeleted, nil
+}
+
+func decodeDBFValue(f Field, raw []byte) (interface{}, error) {
+	switch f.Type {
+	case FieldCharacter:
+		return strings.TrimRight(string(raw), " \x00"), nil
+	case FieldNumeric, FieldFloat:
+		s := strings.Trim(string(raw), " \x00")
+		if s == "" || s[0] == '*' {
+			return nil, nil
+		}
+		if f.Type == FieldNumeric && f.Decimals == 0 {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		return strconv.ParseFloat(s, 64)
+	case FieldLogical:
+		switch raw[0] {
+		case 'T', 't', 'Y', 'y':
+			return true, nil
+		case 'F', 'f', 'N', 'n':
+			return false, nil
+		default:
+			return nil, nil
+		}
+	case FieldDate:
+		s := strings.Trim(string(raw), " \x00")
+		if s == "" || s == "00000000" {
+			return nil, nil
+		}
+		return time.Parse(dbfDateFormat, s)
+	default:
+		// Other field types (e.g. memo fields) are returned as raw strings.
+		return strings.TrimRight(string(raw), " \x00"), nil
+	}
+}
+
+// encodeDBFHeader encodes the header of a dBase file (including the field
+// descriptors).
+func encodeDBFHeader(fields []Field, numRecords int, updated time.Time) []byte {
+	headerLen := dbfHeaderSize + dbfFieldSize*len(fields) + 1
+	recordLen := 1
+	for _, f := range fields {
+		recordLen += f.Length
+	}
+
+	buf := make([]byte, headerLen)
+	buf[0] = dbfVersion
+	buf[1] = byte(updated.Year() - 1900)
+	buf[2] = byte(updated.Month())
+	buf[3] = byte(updated.Day())
+	binary.LittleEndian.PutUint32(buf[4:], uint32(numRecords))
+	binary.LittleEndian.PutUint16(buf[8:], uint16(headerLen))
+	binary.LittleEndian.PutUint16(buf[10:], uint16(recordLen))
+	for i, f := range fields {
+		desc := buf[dbfHeaderSize+dbfFieldSize*i:]
+		copy(desc[:11], f.Name)
+		desc[11] = byte(f.Type)
+		desc[16] = byte(f.Length)
+		desc[17] = byte(f.Decimals)
+	}
+	buf[headerLen-1] = dbfHeaderEnd
+	return buf
+}
+
+// encodeDBFRecord encodes a record from the properties of a feature.
+// Properties that don't have a corresponding field are ignored.
+func encodeDBFRecord(dst []byte, fields []Field, props map[string]interface{}) ([]byte, error) {
+	dst = append(dst, dbfNotDeleted)
+	for _, f := range fields {
+		s, err := encodeDBFValue(f, props[f.Name])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", f.Name, err)
+		}
+		if len(s) > f.Length {
+			return nil, fmt.Errorf("field %q: value %q is longer than the field length (%d)", f.Name, s, f.Length)
+		}
+		pad := strings.Repeat(" ", f.Length-len(s))
+		if f.Type == FieldNumeric || f.Type == FieldFloat {
+			// Numbers are right aligned.
+			s = pad + s
+		} else {
+			s = s + pad
+		}
+		dst = append(dst, s...)
+	}
+	return dst, nil
+}
+
+func encodeDBFValue(f Field, v interface{}) (string, error) {
+	if v == nil {
+		if f.Type == FieldLogical {
+			return "?", nil
+		}
+		return "", nil
+	}
+	switch f.Type {
+	case FieldCharacter:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(v), nil
+	case FieldNumeric, FieldFloat:
+		var x float64
+		switch v := v.(type) {
+		case int:
+			return formatInt(f, int64(v)), nil
+		case int8:
+			return formatInt(f, int64(v)), nil
+		case int16:
+			return formatInt(f, int64(v)), nil
+		case int32:
+			return formatInt(f, int64(v)), nil
+		case int64:
+			return formatInt(f, v), nil
+		case uint:
+			x = float64(v)
+		case uint8:
+			x = float64(v)
+		case uint16:
+			x = float64(v)
+		case uint32:
+			x = float64(v)
+		case uint64:
+			x = float64(v)
+		case float32:
+			x = float64(v)
+		case float64:
+			x = v
+		default:
+			return "", fmt.Errorf("unsupported value type for numeric field: %T", v)
+		}
+		return strconv.FormatFloat(x, 'f', f.Decimals, 64), nil
+	case FieldLogical:
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("unsupported value type for logical field: %T", v)
+		}
+		if b {
+			return "T", nil
+		}
+		return "F", nil
+	case FieldDate:
+		t, ok := v.(time.Time)
+		if !ok {
+			return "", fmt.Errorf("unsupported value type for date field: %T", v)
+		}
+		return t.Format(dbfDateFormat), nil
+	default:
+		return "", fmt.Errorf("unsupported field type: %q", byte(f.Type))
+	}
+}
+
+func formatInt(f Field, v int64) string {
+	if f.Decimals == 0 {
+		return strconv.FormatInt(v, 10)
+	}
+	return strconv.FormatFloat(float64(v), 'f', f.Decimals, 64)
+}
@@ -0,0 +1,420 @@
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Reader reads the features of a shapefile, one at a time.
+//
+// Only the main file (.shp) and the dBase file (.dbf) are needed to read a
+// shapefile sequentially, so the index file (.shx) isn't used.
+type Reader struct {
+	shp     *bufio.Reader
+	dbf     *dbfReader
+	header  header
+	offset  int64
+	opts    []geom.ConstructorOption
+	err     error
+	scratch []byte
+}
+
+// NewReader creates a Reader that reads geometries from the main file (shp)
+// and their attributes from the dBase file (dbf). The dBase file may be nil,
+// in which case the features have no properties. The geometries are
+// constructed using the supplied options.
+func NewReader(shp, dbf io.Reader, opts ...geom.ConstructorOption) (*Reader, error) {
+	r := &Reader{
+		shp:    bufio.NewReader(shp),
+		offset: headerSize,
+		opts:   opts,
+	}
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(r.shp, buf); err != nil {
+		return nil, fmt.Errorf("reading shapefile header: %v", err)
+	}
+	var err error
+	r.header, err = decodeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if dbf != nil {
+		r.dbf, err = newDBFReader(bufio.NewReader(dbf))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ShapeType gives the shape type of the shapefile.
+func (r *Reader) ShapeType() ShapeType {
+	return r.header.shapeType
+}
+
+// Fields gives the attribute fields of the dBase file.
+func (r *Reader) Fields() []Field {
+	if r.dbf == nil {
+		return nil
+	}
+	return append([]Field(nil), r.dbf.fields...)
+}
+
+// Read reads the next feature. It returns io.EOF once all features have been
+// read. Once Read returns an error, subsequent calls return the same error.
+// Records that are marked as deleted in the dBase file are skipped.
+//
+// Point shapes are read as Points, MultiPoint shapes are read as MultiPoints,
+// PolyLine shapes are read as MultiLineStrings, and Polygon shapes are read
+// as MultiPolygons. Null shapes are read as empty GeometryCollections.
+//
+// The Z variants of shape types are read with Z values (and M values if
+// they're present). The M variants of shape types are read with M values.
+// M values are omitted from a geometry if any of its M values are missing.
+//
+// The rings of polygons are assembled into MultiPolygons using their
+// orientation: outer rings are clockwise, and inner rings are
+// counterclockwise. Each inner ring is assigned to the smallest outer ring
+// that contains it (inner rings may touch their outer ring). Inner rings that
+// aren't contained by any outer ring are treated as outer rings.
+func (r *Reader) Read() (geom.GeoJSONFeature, error) {
+	if r.err != nil {
+		return geom.GeoJSONFeature{}, r.err
+	}
+	for {
+		f, deleted, err := r.read()
+		if err != nil {
+			r.err = err
+			return geom.GeoJSONFeature{}, err
+		}
+		if !deleted {
+			return f, nil
+		}
+	}
+}
+
+func (r *Reader) read() (geom.GeoJSONFeature, bool, error) {
+	if r.offset >= r.header.fileLength {
+		return geom.GeoJSONFeature{}, false, io.EOF
+	}
+
+	var recHeader [8]byte
+	if _, err := io.ReadFull(r.shp, recHeader[:]); err != nil {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf("reading record header: %v", err)
+	}
+	recNum := binary.BigEndian.Uint32(recHeader[0:])
+	contentLen := 2 * int64(binary.BigEndian.Uint32(recHeader[4:]))
+	if remaining := r.header.fileLength - r.offset - 8; contentLen > remaining {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf(
+			"record %d: content length %d exceeds remaining file length %d",
+			recNum, contentLen, remaining,
+		)
+	}
+	if int64(cap(r.scratch)) < contentLen {
+		r.scratch = make([]byte, contentLen)
+	}
+	content := r.scratch[:contentLen]
+	if _, err := io.ReadFull(r.shp, content); err != nil {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf("reading record %d: %v", recNum, err)
+	}
+	r.offset += 8 + contentLen
+
+	g, err := decodeRecord(content, r.header.shapeType, r.opts)
+	if err != nil {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf("record %d: %v", recNum, err)
+	}
+	f := geom.GeoJSONFeature{Geometry: g}
+
+	if r.dbf == nil {
+		return f, false, nil
+	}
+	props, deleted, err := r.dbf.next()
+	if err == io.EOF {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf("record %d: missing dbf record", recNum)
+	}
+	if err != nil {
+		return geom.GeoJSONFeature{}, false, fmt.Errorf("record %d: %v", recNum, err)
+	}
+	f.Properties = props
+	return f, deleted, nil
+}
+
+// recordDecoder reads the fields of a record's content.
+type recordDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *recordDecoder) int32() int {
+	if len(d.buf) < 4 {
+		d.err = errors.New("record is truncated")
+		return 0
+	}
+	v := int32(binary.LittleEndian.Uint32(d.buf))
+	d.buf = d.buf[4:]
+	return int(v)
+}
+
+func (d *recordDecoder) float64() float64 {
+	if len(d.buf) < 8 {
+		d.err = errors.New("record is truncated")
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *recordDecoder) skip(n int) {
+	if len(d.buf) < n {
+		d.err = errors.New("record is truncated")
+		return
+	}
+	d.buf = d.buf[n:]
+}
+
+func decodeRecord(content []byte, fileType ShapeType, opts []geom.ConstructorOption) (geom.Geometry, error) {
+	d := &recordDecoder{buf: content}
+	typ := ShapeType(d.int32())
+	if d.err != nil {
+		return geom.Geometry{}, d.err
+	}
+	if typ == Null {
+		return geom.Geometry{}, nil
+	}
+	if typ != fileType {
+		return geom.Geometry{}, fmt.Errorf("shape type %v doesn't match file shape type %v", typ, fileType)
+	}
+
+	var (
+		parts  []int
+		coords []geom.Coordinates
+	)
+	switch typ.base() {
+	case Point:
+		coords = make([]geom.Coordinates, 1)
+		coords[0].X = d.float64()
+		coords[0].Y = d.float64()
+		if typ.hasZ() {
+			coords[0].Z = d.float64()
+			coords[0].Type = geom.DimXYZ
+		}
+		if (typ.hasZ() || typ.hasM()) && len(d.buf) >= 8 {
+			coords[0].M = d.float64()
+			if !isNoData(coords[0].M) {
+				coords[0].Type |= geom.DimXYM
+			}
+		}
+	case MultiPoint, PolyLine, Polygon:
+		d.skip(32) // bbox
+		numParts := 0
+		if typ.base() != MultiPoint {
+			numParts = d.int32()
+		}
+		numPoints := d.int32()
+		if d.err != nil {
+			return geom.Geometry{}, d.err
+		}
+		if numParts < 0 || numPoints < 0 || 4*numParts+16*numPoints > len(d.buf) {
+			return geom.Geometry{}, errors.New("record is truncated")
+		}
+		parts = make([]int, numParts)
+		for i := range parts {
+			parts[i] = d.int32()
+			if parts[i] < 0 || parts[i] > numPoints || (i > 0 && parts[i] < parts[i-1]) {
+				return geom.Geometry{}, errors.New("invalid part index")
+			}
+		}
+		coords = make([]geom.Coordinates, numPoints)
+		for i := range coords {
+			coords[i].X = d.float64()
+			coords[i].Y = d.float64()
+		}
+		if typ.hasZ() {
+			d.skip(16) // Z range
+			for i := range coords {
+				coords[i].Z = d.float64()
+				coords[i].Type = geom.DimXYZ
+			}
+		}
+		if (typ.hasZ() || typ.hasM()) && len(d.buf) >= 16+8*numPoints {
+			d.skip(16) // M range
+			allM := true
+			for i := range coords {
+				coords[i].M = d.float64()
+				allM = allM && !isNoData(coords[i].M)
+			}
+			for i := range coords {
+				if allM {
+					coords[i].Type |= geom.DimXYM
+				} else {
+					coords[i].M = 0
+				}
+			}
+		}
+	}
+	if d.err != nil {
+		return geom.Geometry{}, d.err
+	}
+
+	switch typ.base() {
+	case Point:
+		return geom.NewPointC(coords[0], opts...).AsGeometry(), nil
+	case MultiPoint:
+		return geom.NewMultiPointC(coords, opts...).AsGeometry(), nil
+	case PolyLine:
+		lss := make([]geom.LineString, len(parts))
+		for i, part := range splitParts(coords, parts) {
+			var err error
+			lss[i], err = geom.NewLineStringC(part, opts...)
+			if err != nil {
+				return geom.Geometry{}, err
+			}
+		}
+		return geom.NewMultiLineString(lss, opts...).AsGeometry(), nil
+	default:
+		mp, err := assemblePolygons(splitParts(coords, parts), opts)
+		return mp.AsGeometry(), err
+	}
+}
+
+func splitParts(coords []geom.Coordinates, parts []int) [][]geom.Coordinates {
+	split := make([][]geom.Coordinates, len(parts))
+	for i, start := range parts {
+		end := len(coords)
+		if i+1 < len(parts) {
+			end = parts[i+1]
+		}
+		split[i] = coords[start:end]
+	}
+	return split
+}
+
+// ring is a polygon ring that's being assembled into a MultiPolygon.
+type ring struct {
+	coords []geom.Coordinates
+	area   float64 // signed (positive if counterclockwise)
+	holes  [][]geom.Coordinates
+}
+
+// assemblePolygons assembles rings into a MultiPolygon, using their
+// orientation to determine which rings are outer rings and which are inner
+// rings.
+func assemblePolygons(rings [][]geom.Coordinates, opts []geom.ConstructorOption) (geom.MultiPolygon, error) {
+	var outers, inners []*ring
+	for _, coords := range rings {
+		if len(coords) > 0 && coords[0].XY != coords[len(coords)-1].XY {
+			coords = append(coords[:len(coords):len(coords)], coords[0])
+		}
+		r := &ring{coords: coords, area: signedArea(coords)}
+		switch {
+		case r.area < 0:
+			outers = append(outers, r)
+		case r.area > 0:
+			inners = append(inners, r)
+		}
+	}
+
+	// Each inner ring is assigned to the smallest outer ring that contains
+	// it, so outer rings are considered in order of increasing area.
+	sort.SliceStable(outers, func(i, j int) bool {
+		return -outers[i].area < -outers[j].area
+	})
+	for _, in := range inners {
+		var container *ring
+		for _, out := range outers {
+			if ringContainsRing(out.coords, in.coords) {
+				container = out
+				break
+			}
+		}
+		if container == nil {
+			outers = append(outers, in)
+			continue
+		}
+		container.holes = append(container.holes, in.coords)
+	}
+
+	polys := make([]geom.Polygon, len(outers))
+	for i, out := range outers {
+		outer, err := geom.NewLineStringC(out.coords, opts...)
+		if err != nil {
+			return geom.MultiPolygon{}, err
+		}
+		holes := make([]geom.LineString, len(out.holes))
+		for j, h := range out.holes {
+			holes[j], err = geom.NewLineStringC(h, opts...)
+			if err != nil {
+				return geom.MultiPolygon{}, err
+			}
+		}
+		polys[i], err = geom.NewPolygon(outer, holes, opts...)
+		if err != nil {
+			return geom.MultiPolygon{}, err
+		}
+	}
+	return geom.NewMultiPolygon(polys, opts...)
+}
+
+// signedArea gives the signed area of a closed ring. It's positive if the
+// ring is counterclockwise.
+func signedArea(coords []geom.Coordinates) float64 {
+	var sum float64
+	for i := 0; i+1 < len(coords); i++ {
+		p, q := coords[i].XY, coords[i+1].XY
+		sum += p.X*q.Y - q.X*p.Y
+	}
+	return sum / 2
+}
+
+// ringContainsRing checks if a closed inner ring is inside a closed outer
+// ring. The inner ring may touch the outer ring, so the check is made using
+// the first of the inner ring's vertices and edge midpoints that isn't on the
+// outer ring.
+func ringContainsRing(outer, inner []geom.Coordinates) bool {
+	for i := 0; i+1 < len(inner); i++ {
+		p, q := inner[i].XY, inner[i+1].XY
+		for _, pt := range [2]geom.XY{p, p.Add(q).Scale(0.5)} {
+			if !onRing(outer, pt) {
+				return ringContains(outer, pt)
+			}
+		}
+	}
+	return false
+}
+
+// onRing checks if a point is on any of the segments of a ring.
+func onRing(coords []geom.Coordinates, pt geom.XY) bool {
+	for i := 0; i+1 < len(coords); i++ {
+		p, q := coords[i].XY, coords[i+1].XY
+		if q.Sub(p).Cross(pt.Sub(p)) == 0 &&
+			pt.X >= math.Min(p.X, q.X) && pt.X <= math.Max(p.X, q.X) &&
+			pt.Y >= math.Min(p.Y, q.Y) && pt.Y <= math.Max(p.Y, q.Y) {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains checks if a point is inside a closed ring, using the crossing
+// number algorithm.
+func ringContains(coords []geom.Coordinates, pt geom.XY) bool {
+	inside := false
+	for i := 0; i+1 < len(coords); i++ {
+		p, q := coords[i].XY, coords[i+1].XY
+		if (p.Y > pt.Y) != (q.Y > pt.Y) {
+			x := p.X + (pt.Y-p.Y)*(q.X-p.X)/(q.Y-p.Y)
+			if pt.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
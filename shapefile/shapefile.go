@@ -0,0 +1,175 @@
+// Package shapefile reads and writes ESRI Shapefiles.
+//
+// A shapefile consists of (at least) three files: the main file (.shp)
+// containing the geometries, the index file (.shx) containing the offset of
+// each geometry in the main file, and the dBase file (.dbf) containing the
+// attributes associated with each geometry. Each geometry and its attributes
+// are represented as a GeoJSONFeature.
+//
+// All of the shape types other than MultiPatch are supported.
+package shapefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ShapeType is the type of the geometries stored in a shapefile. All
+// (non-null) geometries in a shapefile have the same shape type.
+type ShapeType int32
+
+// The shape types supported by this package.
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+)
+
+// String gives the name of the shape type.
+func (t ShapeType) String() string {
+	switch t {
+	case Null:
+		return "Null"
+	case Point:
+		return "Point"
+	case PolyLine:
+		return "PolyLine"
+	case Polygon:
+		return "Polygon"
+	case MultiPoint:
+		return "MultiPoint"
+	case PointZ:
+		return "PointZ"
+	case PolyLineZ:
+		return "PolyLineZ"
+	case PolygonZ:
+		return "PolygonZ"
+	case MultiPointZ:
+		return "MultiPointZ"
+	case PointM:
+		return "PointM"
+	case PolyLineM:
+		return "PolyLineM"
+	case PolygonM:
+		return "PolygonM"
+	case MultiPointM:
+		return "MultiPointM"
+	default:
+		return fmt.Sprintf("ShapeType(%d)", int32(t))
+	}
+}
+
+func (t ShapeType) valid() bool {
+	switch t {
+	case Null,
+		Point, PolyLine, Polygon, MultiPoint,
+		PointZ, PolyLineZ, PolygonZ, MultiPointZ,
+		PointM, PolyLineM, PolygonM, MultiPointM:
+		return true
+	default:
+		return false
+	}
+}
+
+// base gives the shape type without any Z or M variant.
+func (t ShapeType) base() ShapeType {
+	switch t {
+	case PointZ, PointM:
+		return Point
+	case PolyLineZ, PolyLineM:
+		return PolyLine
+	case PolygonZ, PolygonM:
+		return Polygon
+	case MultiPointZ, MultiPointM:
+		return MultiPoint
+	default:
+		return t
+	}
+}
+
+// hasZ is true for shape types that store Z values (these shape types also
+// store M values, although the M values are optional).
+func (t ShapeType) hasZ() bool {
+	return t >= PointZ && t <= MultiPointZ
+}
+
+// hasM is true for shape types that store M values (but not Z values).
+func (t ShapeType) hasM() bool {
+	return t >= PointM && t <= MultiPointM
+}
+
+const (
+	fileCode   = 9994
+	version    = 1000
+	headerSize = 100
+)
+
+// noData is the value used for missing M values. Any M value less than
+// -10^38 is considered missing.
+const noData = -1e39
+
+func isNoData(m float64) bool {
+	return m < -1e38
+}
+
+// header is the header of both the main and index files.
+type header struct {
+	fileLength int64 // in bytes
+	shapeType  ShapeType
+	bbox       bbox
+}
+
+// bbox is the bounding box of a shape (or of all shapes).
+type bbox struct {
+	minX, minY, maxX, maxY float64
+	minZ, maxZ, minM, maxM float64
+}
+
+func decodeHeader(buf []byte) (header, error) {
+	if len(buf) < headerSize {
+		return header{}, errors.New("shapefile header is truncated")
+	}
+	if code := binary.BigEndian.Uint32(buf[0:]); code != fileCode {
+		return header{}, fmt.Errorf("invalid shapefile file code: %d", code)
+	}
+	if v := binary.LittleEndian.Uint32(buf[28:]); v != version {
+		return header{}, fmt.Errorf("unsupported shapefile version: %d", v)
+	}
+	h := header{
+		fileLength: 2 * int64(binary.BigEndian.Uint32(buf[24:])),
+		shapeType:  ShapeType(binary.LittleEndian.Uint32(buf[32:])),
+	}
+	if !h.shapeType.valid() {
+		return header{}, fmt.Errorf("unsupported shape type: %v", h.shapeType)
+	}
+	f := func(i int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf[36+8*i:]))
+	}
+	h.bbox = bbox{f(0), f(1), f(2), f(3), f(4), f(5), f(6), f(7)}
+	return h, nil
+}
+
+func encodeHeader(h header) []byte {
+	buf := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(buf[0:], fileCode)
+	binary.BigEndian.PutUint32(buf[24:], uint32(h.fileLength/2))
+	binary.LittleEndian.PutUint32(buf[28:], version)
+	binary.LittleEndian.PutUint32(buf[32:], uint32(h.shapeType))
+	b := h.bbox
+	for i, f := range []float64{b.minX, b.minY, b.maxX, b.maxY, b.minZ, b.maxZ, b.minM, b.maxM} {
+		binary.LittleEndian.PutUint64(buf[36+8*i:], math.Float64bits(f))
+	}
+	return buf
+}
@@ -0,0 +1,559 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+func geomFromWKT(t *testing.T, wkt string) geom.Geometry {
+	t.Helper()
+	g, err := geom.UnmarshalWKT(strings.NewReader(wkt))
+	if err != nil {
+		t.Fatalf("could not unmarshal WKT: %v", err)
+	}
+	return g
+}
+
+func expectNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// memFile is an in-memory io.WriteSeeker.
+type memFile struct {
+	buf []byte
+	pos int
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + len(p); end > len(f.buf) {
+		f.buf = append(f.buf, make([]byte, end-len(f.buf))...)
+	}
+	n := copy(f.buf[f.pos:], p)
+	f.pos += n
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.pos = int(offset)
+	case io.SeekCurrent:
+		f.pos += int(offset)
+	case io.SeekEnd:
+		f.pos = len(f.buf) + int(offset)
+	}
+	if f.pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	return int64(f.pos), nil
+}
+
+type shapefile struct {
+	shp, shx, dbf memFile
+}
+
+func writeShapefile(t *testing.T, typ ShapeType, fields []Field, fs []geom.GeoJSONFeature) *shapefile {
+	t.Helper()
+	var sf shapefile
+	w, err := NewWriter(&sf.shp, &sf.shx, &sf.dbf, typ, fields)
+	expectNoErr(t, err)
+	for _, f := range fs {
+		expectNoErr(t, w.Write(f))
+	}
+	expectNoErr(t, w.Close())
+	return &sf
+}
+
+func readShapefile(t *testing.T, sf *shapefile) (*Reader, []geom.GeoJSONFeature) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(sf.shp.buf), bytes.NewReader(sf.dbf.buf))
+	expectNoErr(t, err)
+	var fs []geom.GeoJSONFeature
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			return r, fs
+		}
+		expectNoErr(t, err)
+		fs = append(fs, f)
+	}
+}
+
+func TestWriteReadGeometries(t *testing.T) {
+	for i, tt := range []struct {
+		typ   ShapeType
+		input string
+		want  string
+	}{
+		{Point, "POINT(1 2)", "POINT(1 2)"},
+		{Point, "POINT Z(1 2 3)", "POINT(1 2)"},
+		{PointZ, "POINT Z(1 2 3)", "POINT Z(1 2 3)"},
+		{PointZ, "POINT(1 2)", "POINT Z(1 2 0)"},
+		{PointZ, "POINT ZM(1 2 3 4)", "POINT ZM(1 2 3 4)"},
+		{PointM, "POINT M(1 2 4)", "POINT M(1 2 4)"},
+		{PointM, "POINT(1 2)", "POINT(1 2)"},
+		{Point, "POINT EMPTY", "GEOMETRYCOLLECTION EMPTY"},
+
+		{MultiPoint, "MULTIPOINT((1 2),(3 4))", "MULTIPOINT((1 2),(3 4))"},
+		{MultiPoint, "POINT(1 2)", "MULTIPOINT((1 2))"},
+		{MultiPointZ, "MULTIPOINT Z((1 2 3),(3 4 5))", "MULTIPOINT Z((1 2 3),(3 4 5))"},
+		{MultiPointM, "MULTIPOINT M((1 2 3),(3 4 5))", "MULTIPOINT M((1 2 3),(3 4 5))"},
+
+		{PolyLine, "LINESTRING(0 0,1 1,2 0)", "MULTILINESTRING((0 0,1 1,2 0))"},
+		{PolyLine, "LINESTRING(0 0,1 1)", "MULTILINESTRING((0 0,1 1))"},
+		{PolyLine, "MULTILINESTRING((0 0,1 1),(2 2,3 3,4 2))", "MULTILINESTRING((0 0,1 1),(2 2,3 3,4 2))"},
+		{PolyLineZ, "LINESTRING Z(0 0 1,1 1 2)", "MULTILINESTRING Z((0 0 1,1 1 2))"},
+		{PolyLineZ, "LINESTRING ZM(0 0 1 5,1 1 2 6)", "MULTILINESTRING ZM((0 0 1 5,1 1 2 6))"},
+		{PolyLineM, "LINESTRING M(0 0 1,1 1 2)", "MULTILINESTRING M((0 0 1,1 1 2))"},
+		{PolyLine, "LINESTRING EMPTY", "GEOMETRYCOLLECTION EMPTY"},
+
+		{Polygon, "POLYGON((0 0,1 0,0 1,0 0))", "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))"},
+		{Polygon, "POLYGON((0 0,0 1,1 0,0 0))", "MULTIPOLYGON(((0 0,1 0,0 1,0 0)))"},
+		{
+			Polygon,
+			"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,4 2,2 2),(6 6,8 6,8 8,6 8,6 6))",
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,4 2,2 2),(6 6,8 6,8 8,6 8,6 6)))",
+		},
+		{
+			Polygon,
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2)),((4 4,6 4,6 6,4 6,4 4)),((20 0,30 0,30 10,20 0)))",
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2)),((4 4,6 4,6 6,4 6,4 4)),((20 0,30 0,30 10,20 0)))",
+		},
+		{
+			// The inner ring's first vertex is on the outer ring.
+			Polygon,
+			"POLYGON((0 0,4 0,4 4,0 4,0 0),(4 2,2 3,2 1,4 2))",
+			"MULTIPOLYGON(((0 0,4 0,4 4,0 4,0 0),(4 2,2 3,2 1,4 2)))",
+		},
+		{
+			PolygonZ,
+			"POLYGON Z((0 0 1,1 0 2,0 1 3,0 0 1))",
+			"MULTIPOLYGON Z(((0 0 1,1 0 2,0 1 3,0 0 1)))",
+		},
+		{
+			PolygonM,
+			"POLYGON M((0 0 1,1 0 2,0 1 3,0 0 1))",
+			"MULTIPOLYGON M(((0 0 1,1 0 2,0 1 3,0 0 1)))",
+		},
+		{Polygon, "MULTIPOLYGON EMPTY", "GEOMETRYCOLLECTION EMPTY"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			sf := writeShapefile(t, tt.typ, nil, []geom.GeoJSONFeature{
+				{Geometry: geomFromWKT(t, tt.input)},
+			})
+			r, fs := readShapefile(t, sf)
+			if r.ShapeType() != tt.typ {
+				t.Errorf("got shape type %v, want %v", r.ShapeType(), tt.typ)
+			}
+			if len(fs) != 1 {
+				t.Fatalf("expected 1 feature, got %d", len(fs))
+			}
+			got := fs[0].Geometry
+			want := geomFromWKT(t, tt.want)
+			if !got.EqualsExact(want, geom.IgnoreOrder) {
+				t.Errorf("\ngot:  %v\nwant: %v", got.AsText(), want.AsText())
+			}
+		})
+	}
+}
+
+func TestWriteIncompatibleGeometry(t *testing.T) {
+	for i, tt := range []struct {
+		typ ShapeType
+		wkt string
+	}{
+		{Point, "MULTIPOINT((1 2))"},
+		{MultiPoint, "LINESTRING(0 0,1 1)"},
+		{PolyLine, "POLYGON((0 0,1 0,0 1,0 0))"},
+		{Polygon, "LINESTRING(0 0,1 1)"},
+		{Polygon, "GEOMETRYCOLLECTION(POINT(1 2))"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var shp, shx, dbf memFile
+			w, err := NewWriter(&shp, &shx, &dbf, tt.typ, nil)
+			expectNoErr(t, err)
+			if err := w.Write(geom.GeoJSONFeature{Geometry: geomFromWKT(t, tt.wkt)}); err == nil {
+				t.Error("expected error but got nil")
+			}
+
+			// The Writer is still usable after an incompatible geometry.
+			expectNoErr(t, w.Close())
+		})
+	}
+}
+
+func TestWriteFileStructure(t *testing.T) {
+	sf := writeShapefile(t, PolyLine, nil, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "LINESTRING(1 2,3 4)")},
+		{Geometry: geomFromWKT(t, "LINESTRING(-1 5,0 0,2 -3)")},
+	})
+
+	for _, buf := range [][]byte{sf.shp.buf, sf.shx.buf} {
+		h, err := decodeHeader(buf)
+		expectNoErr(t, err)
+		if h.fileLength != int64(len(buf)) {
+			t.Errorf("file length in header is %d but file is %d bytes", h.fileLength, len(buf))
+		}
+		if h.shapeType != PolyLine {
+			t.Errorf("unexpected shape type: %v", h.shapeType)
+		}
+		want := bbox{minX: -1, minY: -3, maxX: 3, maxY: 5}
+		if h.bbox != want {
+			t.Errorf("got bbox %v, want %v", h.bbox, want)
+		}
+	}
+
+	// Each index record gives the offset and length of a main file record
+	// (in 16-bit words).
+	if len(sf.shx.buf) != headerSize+2*8 {
+		t.Fatalf("unexpected index file length: %d", len(sf.shx.buf))
+	}
+	offset := headerSize
+	for i := 0; i < 2; i++ {
+		idx := sf.shx.buf[headerSize+8*i:]
+		gotOffset := 2 * int(binary.BigEndian.Uint32(idx[0:]))
+		gotLen := 2 * int(binary.BigEndian.Uint32(idx[4:]))
+		if gotOffset != offset {
+			t.Errorf("record %d: got offset %d, want %d", i, gotOffset, offset)
+		}
+		recNum := binary.BigEndian.Uint32(sf.shp.buf[offset:])
+		recLen := 2 * int(binary.BigEndian.Uint32(sf.shp.buf[offset+4:]))
+		if recNum != uint32(i+1) || recLen != gotLen {
+			t.Errorf("record %d: got number %d and length %d, want %d and %d", i, recNum, recLen, i+1, gotLen)
+		}
+		offset += 8 + recLen
+	}
+}
+
+func TestWriteRingOrientation(t *testing.T) {
+	// Outer rings must be clockwise, and inner rings must be
+	// counterclockwise.
+	for i, wkt := range []string{
+		"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2))",
+		"POLYGON((0 0,0 10,10 10,10 0,0 0),(2 2,8 2,8 8,2 8,2 2))",
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			content, _, err := encodeRecord(Polygon, geomFromWKT(t, wkt))
+			expectNoErr(t, err)
+			parts, err := decodeRawParts(content)
+			expectNoErr(t, err)
+			if len(parts) != 2 {
+				t.Fatalf("expected 2 rings, got %d", len(parts))
+			}
+			if a := signedArea(parts[0]); a >= 0 {
+				t.Errorf("outer ring isn't clockwise (signed area %v)", a)
+			}
+			if a := signedArea(parts[1]); a <= 0 {
+				t.Errorf("inner ring isn't counterclockwise (signed area %v)", a)
+			}
+		})
+	}
+}
+
+// decodeRawParts decodes the XY parts of a PolyLine or Polygon record,
+// without assembling them into a geometry.
+func decodeRawParts(content []byte) ([][]geom.Coordinates, error) {
+	d := &recordDecoder{buf: content}
+	d.int32() // shape type
+	d.skip(32)
+	parts := make([]int, d.int32())
+	coords := make([]geom.Coordinates, d.int32())
+	for i := range parts {
+		parts[i] = d.int32()
+	}
+	for i := range coords {
+		coords[i].X = d.float64()
+		coords[i].Y = d.float64()
+	}
+	return splitParts(coords, parts), d.err
+}
+
+func TestAssemblePolygons(t *testing.T) {
+	ring := func(wkt string) []geom.Coordinates {
+		return geomFromWKT(t, wkt).AsLineString().Coordinates()
+	}
+	var (
+		bigCW    = ring("LINESTRING(0 0,0 10,10 10,10 0,0 0)")
+		bigCCW   = ring("LINESTRING(0 0,10 0,10 10,0 10,0 0)")
+		lakeCCW  = ring("LINESTRING(2 2,8 2,8 8,2 8,2 2)")
+		islandCW = ring("LINESTRING(4 4,4 6,6 6,6 4,4 4)")
+		farCW    = ring("LINESTRING(20 0,20 10,30 10,30 0,20 0)")
+		farCCW   = ring("LINESTRING(20 0,30 0,30 10,20 10,20 0)")
+	)
+	for i, tt := range []struct {
+		rings [][]geom.Coordinates
+		want  string
+	}{
+		{
+			rings: [][]geom.Coordinates{bigCW},
+			want:  "MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0)))",
+		},
+		{
+			// Inner rings may come before their outer rings.
+			rings: [][]geom.Coordinates{lakeCCW, bigCW},
+			want:  "MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0),(2 2,8 2,8 8,2 8,2 2)))",
+		},
+		{
+			// An island in a lake is a separate polygon.
+			rings: [][]geom.Coordinates{bigCW, lakeCCW, islandCW, farCW},
+			want: `MULTIPOLYGON(
+				((0 0,0 10,10 10,10 0,0 0),(2 2,8 2,8 8,2 8,2 2)),
+				((4 4,4 6,6 6,6 4,4 4)),
+				((20 0,20 10,30 10,30 0,20 0))
+			)`,
+		},
+		{
+			// Inner rings that aren't inside an outer ring are treated as
+			// outer rings.
+			rings: [][]geom.Coordinates{bigCW, farCCW},
+			want:  "MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0)),((20 0,30 0,30 10,20 10,20 0)))",
+		},
+		{
+			// Inner rings may touch their outer rings.
+			rings: [][]geom.Coordinates{
+				bigCW,
+				ring("LINESTRING(10 5,5 8,5 2,10 5)"),
+			},
+			want: "MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0),(10 5,5 8,5 2,10 5)))",
+		},
+		{
+			rings: [][]geom.Coordinates{bigCCW},
+			want:  "MULTIPOLYGON(((0 0,10 0,10 10,0 10,0 0)))",
+		},
+		{
+			// Unclosed rings are closed.
+			rings: [][]geom.Coordinates{bigCW[:4]},
+			want:  "MULTIPOLYGON(((0 0,0 10,10 10,10 0,0 0)))",
+		},
+		{
+			rings: nil,
+			want:  "MULTIPOLYGON EMPTY",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := assemblePolygons(tt.rings, nil)
+			expectNoErr(t, err)
+			want := geomFromWKT(t, tt.want)
+			if !got.AsGeometry().EqualsExact(want, geom.IgnoreOrder) {
+				t.Errorf("\ngot:  %v\nwant: %v", got.AsText(), want.AsText())
+			}
+		})
+	}
+}
+
+func TestWriteReadProperties(t *testing.T) {
+	fields := []Field{
+		{Name: "name", Type: FieldCharacter, Length: 10},
+		{Name: "count", Type: FieldNumeric, Length: 8},
+		{Name: "ratio", Type: FieldNumeric, Length: 10, Decimals: 3},
+		{Name: "temp", Type: FieldFloat, Length: 12, Decimals: 2},
+		{Name: "ok", Type: FieldLogical},
+		{Name: "when", Type: FieldDate},
+	}
+	date := time.Date(2020, time.March, 14, 0, 0, 0, 0, time.UTC)
+	sf := writeShapefile(t, Point, fields, []geom.GeoJSONFeature{
+		{
+			Geometry: geomFromWKT(t, "POINT(1 2)"),
+			Properties: map[string]interface{}{
+				"name":    "hello",
+				"count":   42,
+				"ratio":   0.125,
+				"temp":    float32(-3.5),
+				"ok":      true,
+				"when":    date,
+				"ignored": "not a field",
+			},
+		},
+		{
+			Geometry: geomFromWKT(t, "POINT(3 4)"),
+			Properties: map[string]interface{}{
+				"name":  "world",
+				"count": int64(-7),
+				"ok":    false,
+			},
+		},
+		{
+			Geometry: geomFromWKT(t, "POINT(5 6)"),
+		},
+	})
+	r, fs := readShapefile(t, sf)
+
+	wantFields := append([]Field(nil), fields...)
+	wantFields[4].Length = 1
+	wantFields[5].Length = 8
+	if !reflect.DeepEqual(r.Fields(), wantFields) {
+		t.Errorf("\ngot fields:  %v\nwant fields: %v", r.Fields(), wantFields)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"name":  "hello",
+			"count": int64(42),
+			"ratio": 0.125,
+			"temp":  -3.5,
+			"ok":    true,
+			"when":  date,
+		},
+		{
+			"name":  "world",
+			"count": int64(-7),
+			"ratio": nil,
+			"temp":  nil,
+			"ok":    false,
+			"when":  nil,
+		},
+		{
+			"name":  "",
+			"count": nil,
+			"ratio": nil,
+			"temp":  nil,
+			"ok":    nil,
+			"when":  nil,
+		},
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("expected %d features, got %d", len(want), len(fs))
+	}
+	for i, f := range fs {
+		if !reflect.DeepEqual(f.Properties, want[i]) {
+			t.Errorf("feature %d:\ngot:  %v\nwant: %v", i, f.Properties, want[i])
+		}
+	}
+}
+
+func TestWriteInvalidProperties(t *testing.T) {
+	for i, tt := range []struct {
+		field Field
+		value interface{}
+	}{
+		{Field{Name: "f", Type: FieldCharacter, Length: 3}, "toolong"},
+		{Field{Name: "f", Type: FieldNumeric, Length: 3}, 12345},
+		{Field{Name: "f", Type: FieldNumeric, Length: 10}, "12"},
+		{Field{Name: "f", Type: FieldLogical}, "true"},
+		{Field{Name: "f", Type: FieldDate}, "2020-01-01"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var shp, shx, dbf memFile
+			w, err := NewWriter(&shp, &shx, &dbf, Point, []Field{tt.field})
+			expectNoErr(t, err)
+			err = w.Write(geom.GeoJSONFeature{
+				Geometry:   geomFromWKT(t, "POINT(1 2)"),
+				Properties: map[string]interface{}{"f": tt.value},
+			})
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestNewWriterInvalidFields(t *testing.T) {
+	for i, fields := range [][]Field{
+		{{Name: "", Type: FieldCharacter, Length: 1}},
+		{{Name: "elevenchars", Type: FieldCharacter, Length: 1}},
+		{{Name: "f", Type: FieldCharacter, Length: 0}},
+		{{Name: "f", Type: FieldNumeric, Length: 21}},
+		{{Name: "f", Type: FieldNumeric, Length: 4, Decimals: 3}},
+		{{Name: "f", Type: 'X', Length: 1}},
+		{{Name: "f", Type: FieldLogical}, {Name: "f", Type: FieldLogical}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var shp, shx, dbf memFile
+			if _, err := NewWriter(&shp, &shx, &dbf, Point, fields); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestReadSkipsDeletedRecords(t *testing.T) {
+	fields := []Field{{Name: "id", Type: FieldNumeric, Length: 4}}
+	var features []geom.GeoJSONFeature
+	for i := 0; i < 3; i++ {
+		features = append(features, geom.GeoJSONFeature{
+			Geometry:   geom.NewPointF(float64(i), 0).AsGeometry(),
+			Properties: map[string]interface{}{"id": i},
+		})
+	}
+	sf := writeShapefile(t, Point, fields, features)
+
+	// Mark the middle record as deleted.
+	headerLen := int(binary.LittleEndian.Uint16(sf.dbf.buf[8:]))
+	recordLen := int(binary.LittleEndian.Uint16(sf.dbf.buf[10:]))
+	sf.dbf.buf[headerLen+recordLen] = dbfDeleted
+
+	_, fs := readShapefile(t, sf)
+	if len(fs) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fs))
+	}
+	for i, want := range []int64{0, 2} {
+		if got := fs[i].Properties["id"]; got != want {
+			t.Errorf("feature %d: got id %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestReadWithoutDBF(t *testing.T) {
+	sf := writeShapefile(t, Point, nil, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT(1 2)")},
+	})
+	r, err := NewReader(bytes.NewReader(sf.shp.buf), nil)
+	expectNoErr(t, err)
+	f, err := r.Read()
+	expectNoErr(t, err)
+	if f.Properties != nil || !f.Geometry.EqualsExact(geomFromWKT(t, "POINT(1 2)")) {
+		t.Errorf("unexpected feature: %v %v", f.Geometry.AsText(), f.Properties)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected EOF but got %v", err)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	valid := writeShapefile(t, Point, nil, []geom.GeoJSONFeature{
+		{Geometry: geomFromWKT(t, "POINT(1 2)")},
+	})
+	for i, mutate := range []func(shp []byte) []byte{
+		func(shp []byte) []byte { return shp[:50] },
+		func(shp []byte) []byte { return shp[:len(shp)-4] },
+		func(shp []byte) []byte {
+			binary.BigEndian.PutUint32(shp[0:], 1234)
+			return shp
+		},
+		func(shp []byte) []byte {
+			binary.LittleEndian.PutUint32(shp[32:], 31) // MultiPatch
+			return shp
+		},
+		func(shp []byte) []byte {
+			binary.LittleEndian.PutUint32(shp[headerSize+8:], uint32(PolyLine))
+			return shp
+		},
+		func(shp []byte) []byte {
+			binary.BigEndian.PutUint32(shp[headerSize+4:], 0x7fffffff)
+			return shp
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			shp := mutate(append([]byte(nil), valid.shp.buf...))
+			r, err := NewReader(bytes.NewReader(shp), nil)
+			if err == nil {
+				_, err = r.Read()
+			}
+			if err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/peterstace/simplefeatures/geom"
+)
+
+// Writer writes features to a shapefile, one at a time.
+//
+// Because the headers of each file contain information about all of the
+// features (such as their count and bounding box), the headers are written
+// when the Writer is closed. Close must be called once all features have been
+// written.
+type Writer struct {
+	shp, shx, dbf          io.WriteSeeker
+	shpBuf, shxBuf, dbfBuf *bufio.Writer
+
+	shapeType  ShapeType
+	fields     []Field
+	numRecords int
+	offset     int64 // length of the main file (in bytes)
+	x, y, z, m span
+
+	closed bool
+	err    error
+}
+
+// NewWriter creates a Writer that writes a shapefile with the given shape type
+// and attribute fields. Geometries are written to the main file (shp), their
+// offsets are written to the index file (shx), and their attributes are
+// written to the dBase file (dbf).
+func NewWriter(shp, shx, dbf io.WriteSeeker, shapeType ShapeType, fields []Field) (*Writer, error) {
+	if !shapeType.valid() || shapeType == Null {
+		return nil, fmt.Errorf("unsupported shape type: %v", shapeType)
+	}
+	if len(fields) > dbfMaxFieldCount {
+		return nil, fmt.Errorf("too many fields: %d", len(fields))
+	}
+	fields = append([]Field(nil), fields...)
+	names := make(map[string]bool, len(fields))
+	for i := range fields {
+		if err := fields[i].validate(); err != nil {
+			return nil, err
+		}
+		if names[fields[i].Name] {
+			return nil, fmt.Errorf("duplicate field name: %q", fields[i].Name)
+		}
+		names[fields[i].Name] = true
+	}
+
+	w := &Writer{
+		shp:       shp,
+		shx:       shx,
+		dbf:       dbf,
+		shpBuf:    bufio.NewWriter(shp),
+		shxBuf:    bufio.NewWriter(shx),
+		dbfBuf:    bufio.NewWriter(dbf),
+		shapeType: shapeType,
+		fields:    fields,
+		offset:    headerSize,
+	}
+
+	// Placeholder headers are written, and then overwritten with the real
+	// headers when the Writer is closed.
+	if _, err := w.shpBuf.Write(make([]byte, headerSize)); err != nil {
+		return nil, err
+	}
+	if _, err := w.shxBuf.Write(make([]byte, headerSize)); err != nil {
+		return nil, err
+	}
+	if _, err := w.dbfBuf.Write(encodeDBFHeader(fields, 0, time.Now())); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes a single feature. The feature's geometry must be compatible
+// with the shapefile's shape type: Point shapes can be written from Points,
+// MultiPoint shapes from Points and MultiPoints, PolyLine shapes from Lines,
+// LineStrings, and MultiLineStrings, and Polygon shapes from Polygons and
+// MultiPolygons.
+//
+// Empty geometries are written as Null shapes. Polygon rings are reoriented
+// as required (outer rings are written clockwise, and inner rings are written
+// counterclockwise). Z values are written as 0 if the geometry doesn't have
+// them, and M values are written as "no data" if the geometry doesn't have
+// them.
+//
+// Properties of the feature are written to the fields with the same name.
+// Properties without a field are ignored, and fields without a property are
+// left blank.
+//
+// Once Write returns an error (other than an error caused by an incompatible
+// geometry or property), subsequent calls return the same error.
+func (w *Writer) Write(f geom.GeoJSONFeature) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return errors.New("write to closed shapefile Writer")
+	}
+
+	content, ext, err := encodeRecord(w.shapeType, f.Geometry)
+	if err != nil {
+		return err
+	}
+	rec, err := encodeDBFRecord(nil, w.fields, f.Properties)
+	if err != nil {
+		return err
+	}
+
+	var recHeader [8]byte
+	binary.BigEndian.PutUint32(recHeader[0:], uint32(w.numRecords+1))
+	binary.BigEndian.PutUint32(recHeader[4:], uint32(len(content)/2))
+	var idx [8]byte
+	binary.BigEndian.PutUint32(idx[0:], uint32(w.offset/2))
+	binary.BigEndian.PutUint32(idx[4:], uint32(len(content)/2))
+
+	for _, write := range []struct {
+		w   *bufio.Writer
+		buf []byte
+	}{
+		{w.shpBuf, recHeader[:]},
+		{w.shpBuf, content},
+		{w.shxBuf, idx[:]},
+		{w.dbfBuf, rec},
+	} {
+		if _, err := write.w.Write(write.buf); err != nil {
+			w.err = err
+			return err
+		}
+	}
+
+	w.numRecords++
+	w.offset += int64(len(recHeader) + len(content))
+	w.x.merge(ext.x)
+	w.y.merge(ext.y)
+	w.z.merge(ext.z)
+	w.m.merge(ext.m)
+	return nil
+}
+
+// Close writes the headers of each file. It doesn't close the underlying
+// io.WriteSeekers.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.dbfBuf.WriteByte(dbfFileEnd); err != nil {
+		w.err = err
+		return err
+	}
+
+	hdr := header{shapeType: w.shapeType}
+	hdr.bbox.minX, hdr.bbox.maxX = w.x.bounds()
+	hdr.bbox.minY, hdr.bbox.maxY = w.y.bounds()
+	hdr.bbox.minZ, hdr.bbox.maxZ = w.z.bounds()
+	hdr.bbox.minM, hdr.bbox.maxM = w.m.bounds()
+	shpHeader := hdr
+	shpHeader.fileLength = w.offset
+	shxHeader := hdr
+	shxHeader.fileLength = headerSize + 8*int64(w.numRecords)
+
+	for _, f := range []struct {
+		buf *bufio.Writer
+		ws  io.WriteSeeker
+		hdr []byte
+	}{
+		{w.shpBuf, w.shp, encodeHeader(shpHeader)},
+		{w.shxBuf, w.shx, encodeHeader(shxHeader)},
+		{w.dbfBuf, w.dbf, encodeDBFHeader(w.fields, w.numRecords, time.Now())},
+	} {
+		if err := f.buf.Flush(); err != nil {
+			w.err = err
+			return err
+		}
+		if _, err := f.ws.Seek(0, io.SeekStart); err != nil {
+			w.err = err
+			return err
+		}
+		if _, err := f.ws.Write(f.hdr); err != nil {
+			w.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// span is a range of values.
+type span struct {
+	min, max float64
+	valid    bool
+}
+
+func (s *span) add(v float64) {
+	if !s.valid {
+		*s = span{v, v, true}
+		return
+	}
+	s.min = math.Min(s.min, v)
+	s.max = math.Max(s.max, v)
+}
+
+func (s *span) merge(o span) {
+	if o.valid {
+		s.add(o.min)
+		s.add(o.max)
+	}
+}
+
+// bounds gives the range, or zeros if the range is empty.
+func (s span) bounds() (float64, float64) {
+	if !s.valid {
+		return 0, 0
+	}
+	return s.min, s.max
+}
+
+// extent holds the ranges of each ordinate of a shape.
+type extent struct {
+	x, y, z, m span
+}
+
+// recordEncoder builds up the content of a record.
+type recordEncoder struct {
+	buf []byte
+}
+
+func (e *recordEncoder) int32(v int) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(int32(v)))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *recordEncoder) float64(v float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *recordEncoder) span(s span) {
+	min, max := s.bounds()
+	e.float64(min)
+	e.float64(max)
+}
+
+// encodeRecord encodes the content of a record (not including the record
+// header).
+func encodeRecord(typ ShapeType, g geom.Geometry) ([]byte, extent, error) {
+	var e recordEncoder
+	if g.IsEmpty() {
+		e.int32(int(Null))
+		return e.buf, extent{}, nil
+	}
+	parts, err := shapeParts(typ, g)
+	if err != nil {
+		return nil, extent{}, err
+	}
+
+	var ext extent
+	var numPoints int
+	for _, part := range parts {
+		for _, c := range part {
+			ext.x.add(c.X)
+			ext.y.add(c.Y)
+			if typ.hasZ() {
+				ext.z.add(zValue(c))
+			}
+			if c.Type.IsMeasured() {
+				ext.m.add(c.M)
+			}
+		}
+		numPoints += len(part)
+	}
+
+	e.int32(int(typ))
+	if typ.base() == Point {
+		c := parts[0][0]
+		e.float64(c.X)
+		e.float64(c.Y)
+		if typ.hasZ() {
+			e.float64(zValue(c))
+		}
+		if typ.hasZ() || typ.hasM() {
+			e.float64(mValue(c))
+		}
+		return e.buf, ext, nil
+	}
+
+	e.float64(ext.x.min)
+	e.float64(ext.y.min)
+	e.float64(ext.x.max)
+	e.float64(ext.y.max)
+	if typ.base() != MultiPoint {
+		e.int32(len(parts))
+	}
+	e.int32(numPoints)
+	if typ.base() != MultiPoint {
+		var start int
+		for _, part := range parts {
+			e.int32(start)
+			start += len(part)
+		}
+	}
+	for _, part := range parts {
+		for _, c := range part {
+			e.float64(c.X)
+			e.float64(c.Y)
+		}
+	}
+	if typ.hasZ() {
+		e.span(ext.z)
+		for _, part := range parts {
+			for _, c := range part {
+				e.float64(zValue(c))
+			}
+		}
+	}
+	if typ.hasZ() || typ.hasM() {
+		e.span(ext.m)
+		for _, part := range parts {
+			for _, c := range part {
+				e.float64(mValue(c))
+			}
+		}
+	}
+	return e.buf, ext, nil
+}
+
+func zValue(c geom.Coordinates) float64 {
+	if c.Type.Is3D() {
+		return c.Z
+	}
+	return 0
+}
+
+func mValue(c geom.Coordinates) float64 {
+	if c.Type.IsMeasured() {
+		return c.M
+	}
+	return noData
+}
+
+// shapeParts gives the parts of a (non-empty) geometry that's being written
+// as a shape. For MultiPoint shapes, each part is a single point.
+func shapeParts(typ ShapeType, g geom.Geometry) ([][]geom.Coordinates, error) {
+	switch base := typ.base(); {
+	case base == Point && g.IsPoint():
+		return [][]geom.Coordinates{{g.AsPoint().Coordinates()}}, nil
+	case base == MultiPoint && g.IsPoint():
+		return [][]geom.Coordinates{{g.AsPoint().Coordinates()}}, nil
+	case base == MultiPoint && g.IsMultiPoint():
+		return [][]geom.Coordinates{g.AsMultiPoint().Coordinates()}, nil
+	case base == PolyLine && g.IsLine():
+		return [][]geom.Coordinates{g.AsLine().Coordinates()}, nil
+	case base == PolyLine && g.IsLineString():
+		return [][]geom.Coordinates{g.AsLineString().Coordinates()}, nil
+	case base == PolyLine && g.IsMultiLineString():
+		return nonEmptyParts(g.AsMultiLineString().Coordinates()), nil
+	case base == Polygon && g.IsPolygon():
+		return orientRings(g.AsPolygon().Coordinates()), nil
+	case base == Polygon && g.IsMultiPolygon():
+		var parts [][]geom.Coordinates
+		for _, p := range g.AsMultiPolygon().Coordinates() {
+			parts = append(parts, orientRings(p)...)
+		}
+		return nonEmptyParts(parts), nil
+	default:
+		return nil, fmt.Errorf("geometry can't be written as a %v shape", typ)
+	}
+}
+
+func nonEmptyParts(parts [][]geom.Coordinates) [][]geom.Coordinates {
+	nonEmpty := parts[:0]
+	for _, p := range parts {
+		if len(p) > 0 {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return nonEmpty
+}
+
+// orientRings orients the rings of a polygon so that the outer ring is
+// clockwise and inner rings are counterclockwise.
+func orientRings(rings [][]geom.Coordinates) [][]geom.Coordinates {
+	for i, r := range rings {
+		area := signedArea(r)
+		if (i == 0 && area > 0) || (i > 0 && area < 0) {
+			for j, k := 0, len(r)-1; j < k; j, k = j+1, k-1 {
+				r[j], r[k] = r[k], r[j]
+			}
+		}
+	}
+	return rings
+}